@@ -3,12 +3,21 @@ package handler
 import (
 	"golang-whatsapp-clone/server"
 	"net/http"
+	"sync"
 )
 
-// Handler is the main entry point of the application. Think of it like the main() method
+var (
+	setupOnce   sync.Once
+	rootHandler http.Handler
+)
+
+// Handler is the main entry point of the application. Think of it like the main() method.
+// The app is built by the first request and reused while the instance is warm. The background
+// jobs are not started, they need a long running server like the one of main.go
 func Handler(w http.ResponseWriter, r *http.Request) {
-	app, _, rootHandler := server.NewServer()
-	defer app.DBpool.Close()
+	setupOnce.Do(func() {
+		_, _, rootHandler = server.NewServer()
+	})
 
 	rootHandler.ServeHTTP(w, r)
 }
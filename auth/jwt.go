@@ -5,26 +5,46 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	// the access tokens are short lived because they can't be revoked, the clients use the
+	// refresh token to get a new one
+	AccessTokenDuration  = 15 * time.Minute
+	RefreshTokenDuration = 30 * 24 * time.Hour
+
+	// the audience (aud) tells the type of the token, so one type is never accepted as the
+	// other even if the secrets were the same
+	accessTokenAudience  = "access"
+	refreshTokenAudience = "refresh"
 )
 
 type JWTService struct {
-	secret []byte
+	secret        []byte
+	refreshSecret []byte
 }
 
-func NewJWTService(secret string) *JWTService {
+func NewJWTService(secret string, refreshSecret string) *JWTService {
 	return &JWTService{
-		secret: []byte(secret),
+		secret:        []byte(secret),
+		refreshSecret: []byte(refreshSecret),
 	}
 }
 
-func (j *JWTService) GenerateToken(userID, email string) (string, error) {
+// GenerateToken signs a new access token of the session, its id (jti) is the session id so
+// the session can be checked on every request
+func (j *JWTService) GenerateToken(userID, email, sessionID string) (string, error) {
+	now := time.Now()
 	claims := JWTClaims{
 		UserID: userID,
 		Email:  email,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * 7 * time.Hour)), //  7 days
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        sessionID,
+			Audience:  jwt.ClaimStrings{accessTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenDuration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
@@ -34,25 +54,62 @@ func (j *JWTService) GenerateToken(userID, email string) (string, error) {
 }
 
 func (j *JWTService) ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
+	claims := &JWTClaims{}
+	if err := parseToken(tokenString, claims, j.secret, accessTokenAudience); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// GenerateRefreshToken signs a new refresh token of the family with the refresh secret, its id
+// (jti) is the one stored in the refresh_tokens table
+func (j *JWTService) GenerateRefreshToken(userID, email, familyID string) (string, *RefreshClaims, error) {
+	now := time.Now()
+	claims := &RefreshClaims{
+		UserID:   userID,
+		Email:    email,
+		FamilyID: familyID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Audience:  jwt.ClaimStrings{refreshTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(RefreshTokenDuration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(j.refreshSecret)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, claims, nil
+}
+
+func (j *JWTService) ValidateRefreshToken(tokenString string) (*RefreshClaims, error) {
+	claims := &RefreshClaims{}
+	if err := parseToken(tokenString, claims, j.refreshSecret, refreshTokenAudience); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func parseToken(tokenString string, claims jwt.Claims, secret []byte, audience string) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		// validate that the token has the correct signin method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signin method: %v", token.Header["alg"])
 		}
 
 		// return the secret used to parse and validate this token
-		return j.secret, nil
-	})
+		return secret, nil
+	}, jwt.WithAudience(audience))
 
 	if err != nil || !token.Valid {
-		return nil, fmt.Errorf("invalid token: %w", err)
+		return fmt.Errorf("invalid token: %w", err)
 	}
 
-	// make sure the token claims has our structure (and it has not been modified with extra data)
-	claims, ok := token.Claims.(*JWTClaims)
-	if !ok {
-		return nil, fmt.Errorf("invalid token claims")
-	}
-
-	return claims, nil
+	return nil
 }
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestTokenTypesAreNotInterchangeable(t *testing.T) {
+	// even with the same secret a token is only accepted as its own type
+	jwtService := NewJWTService("secret", "secret")
+
+	accessToken, err := jwtService.GenerateToken(uuid.NewString(), "ana@example.com", uuid.NewString())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	refreshToken, _, err := jwtService.GenerateRefreshToken(uuid.NewString(), "ana@example.com", uuid.NewString())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := jwtService.ValidateToken(accessToken); err != nil {
+		t.Errorf("expected the access token to be valid, got %v", err)
+	}
+	if _, err := jwtService.ValidateRefreshToken(refreshToken); err != nil {
+		t.Errorf("expected the refresh token to be valid, got %v", err)
+	}
+
+	if _, err := jwtService.ValidateToken(refreshToken); err == nil {
+		t.Error("expected the refresh token to be rejected as an access token")
+	}
+	if _, err := jwtService.ValidateRefreshToken(accessToken); err == nil {
+		t.Error("expected the access token to be rejected as a refresh token")
+	}
+}
@@ -3,69 +3,243 @@ package auth
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
-	"encoding/json"
-	"fmt"
+	"encoding/hex"
+	"errors"
 	"golang-whatsapp-clone/config"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 )
 
+const (
+	// OAuthStateDuration is how long the user has to log in the provider
+	OAuthStateDuration = 10 * time.Minute
+	// AuthCodeDuration is how long the mobile app has to exchange the one-time code
+	AuthCodeDuration = time.Minute
+)
+
+const maxAppStateLength = 255
+
+// OAuthUser is the account of the user in the provider it logged in with
+type OAuthUser struct {
+	// Subject is the id of the user in the provider
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	AvatarURL     string
+}
+
+// OAuthProvider is an identity provider with the authorization code flow
+type OAuthProvider interface {
+	// Name identifies the provider in the routes and in the user identities
+	Name() string
+	// AuthCodeURL is the url of the login page of the provider, the code verifier is the pkce
+	// verifier of the authorization code
+	AuthCodeURL(ctx context.Context, state string, codeVerifier string) (string, error)
+	// Authenticate exchanges the authorization code sent to the callback for the user, the
+	// state and the code verifier are the ones of the login page url
+	Authenticate(ctx context.Context, code string, state string, codeVerifier string) (*OAuthUser, error)
+}
+
+// LoginRequest is how the client started the login
+type LoginRequest struct {
+	ClientType string
+	// CodeChallenge is the S256 pkce challenge of the mobile app, it's required to exchange the
+	// one-time code returned to the app for the tokens
+	CodeChallenge string
+	// AppState is sent back to the mobile app with the one-time code
+	AppState string
+}
+
+// OAuthService holds the providers enabled in the AppConfig and the logins in progress. The state
+// of a login is stored in the database and it can only be used once, the mobile app gets a
+// one-time code bound to its pkce challenge instead of the tokens, so the tokens are never
+// written in an url
 type OAuthService struct {
-	config     *oauth2.Config
-	appConfig  *config.AppConfig
-	jwtService *JWTService
+	appConfig           *config.AppConfig
+	jwtService          *JWTService
+	oauthFlowRepository repository.OAuthFlowRepository
+	logger              *zerolog.Logger
+	providers           map[string]OAuthProvider
 }
 
-func NewOAuthService(appConfig *config.AppConfig, jwtService *JWTService) *OAuthService {
-	googleOAuth := &oauth2.Config{
-		ClientID:     appConfig.GoogleClientID,
-		ClientSecret: appConfig.GoogleClientSecret,
-		RedirectURL:  appConfig.BaseURL + "/api/v1/auth/google/callback",
-		Scopes: []string{
-			"https://www.googleapis.com/auth/userinfo.email",
-			"https://www.googleapis.com/auth/userinfo.profile",
-		},
-		Endpoint: google.Endpoint,
+func NewOAuthService(
+	appConfig *config.AppConfig,
+	jwtService *JWTService,
+	oauthFlowRepository repository.OAuthFlowRepository,
+	logger *zerolog.Logger,
+	providers ...OAuthProvider,
+) *OAuthService {
+	service := &OAuthService{
+		appConfig:           appConfig,
+		jwtService:          jwtService,
+		oauthFlowRepository: oauthFlowRepository,
+		logger:              logger,
+		providers:           map[string]OAuthProvider{},
+	}
+
+	for _, provider := range providers {
+		service.providers[provider.Name()] = provider
+	}
+
+	return service
+}
+
+// NewOAuthProviders returns the providers configured in the AppConfig, google is always enabled
+func NewOAuthProviders(appConfig *config.AppConfig) []OAuthProvider {
+	providers := []OAuthProvider{NewGoogleProvider(appConfig)}
+
+	if appConfig.GitHubClientID != "" {
+		providers = append(providers, NewGitHubProvider(appConfig))
+	}
+
+	if appConfig.OIDCIssuerURL != "" {
+		providers = append(providers, NewOIDCProvider(
+			appConfig.OIDCProviderName,
+			appConfig.OIDCIssuerURL,
+			appConfig.OIDCClientID,
+			appConfig.OIDCClientSecret,
+			callbackURL(appConfig, appConfig.OIDCProviderName),
+		))
 	}
 
-	return &OAuthService{
-		config:     googleOAuth,
-		jwtService: jwtService,
-		appConfig:  appConfig,
+	return providers
+}
+
+// Provider returns customerrors.ErrUnknownOAuthProvider when the provider is not enabled
+func (o *OAuthService) Provider(name string) (OAuthProvider, error) {
+	provider, ok := o.providers[name]
+	if !ok {
+		return nil, customerrors.ErrUnknownOAuthProvider
 	}
+
+	return provider, nil
 }
 
-func (o *OAuthService) GenerateAuthURL() (url string, state string, err error) {
+// StartLogin stores the state of a new login and returns the url of the login page of the provider
+func (o *OAuthService) StartLogin(ctx context.Context, provider OAuthProvider, request LoginRequest) (url string, state string, err error) {
+	if request.ClientType != repository.CLIENT_TYPE_MOBILE {
+		request.ClientType = repository.CLIENT_TYPE_WEB
+	}
+
+	if request.ClientType == repository.CLIENT_TYPE_MOBILE && !IsS256Challenge(request.CodeChallenge) {
+		return "", "", customerrors.ErrInvalidCodeChallenge
+	}
+
+	if len(request.AppState) > maxAppStateLength {
+		return "", "", customerrors.ErrInvalidOAuthState
+	}
+
+	// the abandoned logins are removed from time to time by the new ones
+	if err := o.oauthFlowRepository.DeleteExpired(ctx); err != nil {
+		o.logger.Error().Msgf("OAuthService: error to delete the expired logins: %v", err)
+	}
+
 	state, err = o.generateState()
 	if err != nil {
 		return "", "", err
 	}
 
-	url = o.config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+	codeVerifier := oauth2.GenerateVerifier()
+
+	err = o.oauthFlowRepository.CreateOAuthState(ctx, db.OauthState{
+		ID:               hashToken(state),
+		Provider:         provider.Name(),
+		ClientType:       request.ClientType,
+		CodeVerifier:     codeVerifier,
+		AppCodeChallenge: pgtype.Text{String: request.CodeChallenge, Valid: request.CodeChallenge != ""},
+		AppState:         pgtype.Text{String: request.AppState, Valid: request.AppState != ""},
+		ExpiresAt:        pgtype.Timestamptz{Time: time.Now().Add(OAuthStateDuration), Valid: true},
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	url, err = provider.AuthCodeURL(ctx, state, codeVerifier)
+	if err != nil {
+		return "", "", err
+	}
 
 	return url, state, nil
 }
 
-func (o *OAuthService) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
-	return o.config.Exchange(ctx, code)
+// CompleteLogin uses the state of the login and exchanges the authorization code for the user.
+// customerrors.ErrInvalidOAuthState is returned when the state is unknown, it was already used,
+// it expired or it belongs to another provider. The state of a web login is only used when the
+// browser sends the same state in its cookie, the browser of the mobile app doesn't share the
+// cookies of the login and its one-time code is bound to the app by its pkce challenge instead
+func (o *OAuthService) CompleteLogin(ctx context.Context, provider OAuthProvider, state string, browserState string, code string) (*OAuthUser, *db.OauthState, error) {
+	if state == "" {
+		return nil, nil, customerrors.ErrInvalidOAuthState
+	}
+
+	stored, err := o.oauthFlowRepository.ConsumeOAuthState(ctx, hashToken(state), hashToken(browserState))
+	if err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) {
+			return nil, nil, customerrors.ErrInvalidOAuthState
+		}
+		return nil, nil, err
+	}
+
+	if stored.Provider != provider.Name() {
+		return nil, nil, customerrors.ErrInvalidOAuthState
+	}
+
+	user, err := provider.Authenticate(ctx, code, state, stored.CodeVerifier)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, stored, nil
+}
+
+// IssueAuthCode returns a one-time code of the user for the mobile app, it can be exchanged for
+// the tokens with the verifier of the code challenge
+func (o *OAuthService) IssueAuthCode(ctx context.Context, userID string, codeChallenge string) (string, error) {
+	code, err := o.generateState()
+	if err != nil {
+		return "", err
+	}
+
+	err = o.oauthFlowRepository.CreateAuthCode(ctx, hashToken(code), userID, codeChallenge, time.Now().Add(AuthCodeDuration))
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
 }
 
-func (o *OAuthService) GetUserInfo(ctx context.Context, token *oauth2.Token) (*GoogleUser, error) {
-	client := o.config.Client(ctx, token)
-	resp, err := client.Get(o.appConfig.GoogleUserInfoUrl)
+// ExchangeAuthCode uses the one-time code and returns its user, the code is used even when the
+// verifier is wrong so it can't be guessed. customerrors.ErrInvalidAuthCode is returned when the
+// code or the verifier are not valid
+func (o *OAuthService) ExchangeAuthCode(ctx context.Context, code string, codeVerifier string) (userID string, email string, err error) {
+	if code == "" || codeVerifier == "" {
+		return "", "", customerrors.ErrInvalidAuthCode
+	}
+
+	stored, err := o.oauthFlowRepository.ConsumeAuthCode(ctx, hashToken(code))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %w", err)
+		if errors.Is(err, customerrors.ErrResourceNotFound) {
+			return "", "", customerrors.ErrInvalidAuthCode
+		}
+		return "", "", err
 	}
-	defer resp.Body.Close()
 
-	var googleUser GoogleUser
-	if err := json.NewDecoder(resp.Body).Decode(&googleUser); err != nil {
-		return nil, fmt.Errorf("failed to decode user info: %w", err)
+	if !VerifyCodeChallenge(codeVerifier, stored.CodeChallenge) {
+		return "", "", customerrors.ErrInvalidAuthCode
 	}
 
-	return &googleUser, nil
+	return stored.UserID.String(), stored.Email, nil
 }
 
 func (o *OAuthService) generateState() (string, error) {
@@ -76,3 +250,26 @@ func (o *OAuthService) generateState() (string, error) {
 
 	return base64.URLEncoding.EncodeToString(b), nil
 }
+
+// callbackURL is the redirect url registered in the provider
+func callbackURL(appConfig *config.AppConfig, provider string) string {
+	return strings.TrimSuffix(appConfig.BaseURL, "/") + "/api/v1/auth/" + provider + "/callback"
+}
+
+// hashToken is how the states and the one-time codes are stored, a leak of the table doesn't
+// leak valid values
+func hashToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsS256Challenge checks the format of a S256 pkce challenge, the base64url of a sha256 without padding
+func IsS256Challenge(challenge string) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(challenge)
+	return err == nil && len(decoded) == sha256.Size
+}
+
+// VerifyCodeChallenge tells if the verifier is the one of the S256 pkce challenge
+func VerifyCodeChallenge(codeVerifier string, challenge string) bool {
+	return codeVerifier != "" && subtle.ConstantTimeCompare([]byte(oauth2.S256ChallengeFromVerifier(codeVerifier)), []byte(challenge)) == 1
+}
@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"golang-whatsapp-clone/config"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+	"golang.org/x/oauth2"
+)
+
+// fakeOAuthFlowRepository keeps the states and the codes in memory with the same rules as the queries
+type fakeOAuthFlowRepository struct {
+	mutex  sync.Mutex
+	states map[string]db.OauthState
+	codes  map[string]db.AuthCode
+}
+
+func newFakeOAuthFlowRepository() *fakeOAuthFlowRepository {
+	return &fakeOAuthFlowRepository{states: map[string]db.OauthState{}, codes: map[string]db.AuthCode{}}
+}
+
+func (r *fakeOAuthFlowRepository) CreateOAuthState(ctx context.Context, state db.OauthState) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.states[state.ID] = state
+	return nil
+}
+
+func (r *fakeOAuthFlowRepository) ConsumeOAuthState(ctx context.Context, stateHash string, browserStateHash string) (*db.OauthState, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	state, ok := r.states[stateHash]
+	if !ok || state.ExpiresAt.Time.Before(time.Now()) || (state.ClientType == "web" && browserStateHash != stateHash) {
+		return nil, customerrors.ErrResourceNotFound
+	}
+	delete(r.states, stateHash)
+
+	return &state, nil
+}
+
+func (r *fakeOAuthFlowRepository) CreateAuthCode(ctx context.Context, codeHash string, userID string, codeChallenge string, expiresAt time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.codes[codeHash] = db.AuthCode{
+		ID:            codeHash,
+		UserID:        toTestUUID(userID),
+		CodeChallenge: codeChallenge,
+		ExpiresAt:     pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	}
+	return nil
+}
+
+func (r *fakeOAuthFlowRepository) ConsumeAuthCode(ctx context.Context, codeHash string) (*db.ConsumeAuthCodeRow, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	code, ok := r.codes[codeHash]
+	delete(r.codes, codeHash)
+	if !ok || code.ExpiresAt.Time.Before(time.Now()) {
+		return nil, customerrors.ErrResourceNotFound
+	}
+
+	return &db.ConsumeAuthCodeRow{UserID: code.UserID, CodeChallenge: code.CodeChallenge, Email: "ana@example.com"}, nil
+}
+
+func (r *fakeOAuthFlowRepository) DeleteExpired(ctx context.Context) error {
+	return nil
+}
+
+// fakeOAuthProvider authenticates any code when the verifier is the one of the login page url
+type fakeOAuthProvider struct {
+	name          string
+	codeVerifiers map[string]string
+}
+
+func (p *fakeOAuthProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeOAuthProvider) AuthCodeURL(ctx context.Context, state string, codeVerifier string) (string, error) {
+	p.codeVerifiers[state] = codeVerifier
+	return "https://provider.example.com/authorize?" + url.Values{
+		"state":          {state},
+		"code_challenge": {oauth2.S256ChallengeFromVerifier(codeVerifier)},
+	}.Encode(), nil
+}
+
+func (p *fakeOAuthProvider) Authenticate(ctx context.Context, code string, state string, codeVerifier string) (*OAuthUser, error) {
+	if p.codeVerifiers[state] != codeVerifier {
+		return nil, errors.New("invalid code verifier")
+	}
+
+	return &OAuthUser{Subject: "user-123", Email: "ana@example.com", EmailVerified: true}, nil
+}
+
+func newOAuthTestService() (*OAuthService, *fakeOAuthFlowRepository) {
+	oauthFlowRepository := newFakeOAuthFlowRepository()
+	logger := zerolog.Nop()
+	providers := []OAuthProvider{
+		&fakeOAuthProvider{name: "google", codeVerifiers: map[string]string{}},
+		&fakeOAuthProvider{name: "github", codeVerifiers: map[string]string{}},
+	}
+
+	return NewOAuthService(&config.AppConfig{}, nil, oauthFlowRepository, &logger, providers...), oauthFlowRepository
+}
+
+func TestOAuthStateIsSingleUse(t *testing.T) {
+	oauthService, _ := newOAuthTestService()
+	ctx := context.Background()
+	google, _ := oauthService.Provider("google")
+	github, _ := oauthService.Provider("github")
+
+	_, state, err := oauthService.StartLogin(ctx, google, LoginRequest{ClientType: "web"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the state of a provider can't be used in the callback of another one
+	if _, _, err := oauthService.CompleteLogin(ctx, github, state, state, "code"); !errors.Is(err, customerrors.ErrInvalidOAuthState) {
+		t.Fatalf("expected the state of another provider to be rejected, got %v", err)
+	}
+
+	_, state, _ = oauthService.StartLogin(ctx, google, LoginRequest{ClientType: "web"})
+	user, login, err := oauthService.CompleteLogin(ctx, google, state, state, "code")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if user.Subject != "user-123" || login.ClientType != "web" {
+		t.Errorf("unexpected login %+v of %+v", login, user)
+	}
+
+	if _, _, err := oauthService.CompleteLogin(ctx, google, state, state, "code"); !errors.Is(err, customerrors.ErrInvalidOAuthState) {
+		t.Errorf("expected the state to be used once, got %v", err)
+	}
+}
+
+func TestWebLoginRequiresTheStateOfTheBrowser(t *testing.T) {
+	oauthService, oauthFlowRepository := newOAuthTestService()
+	ctx := context.Background()
+	google, _ := oauthService.Provider("google")
+
+	_, state, err := oauthService.StartLogin(ctx, google, LoginRequest{ClientType: "web"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a callback opened in another browser neither uses the state nor exchanges the code
+	for _, browserState := range []string{"", "another-state"} {
+		if _, _, err := oauthService.CompleteLogin(ctx, google, state, browserState, "code"); !errors.Is(err, customerrors.ErrInvalidOAuthState) {
+			t.Errorf("expected the browser state %q to be rejected, got %v", browserState, err)
+		}
+	}
+
+	if _, ok := oauthFlowRepository.states[hashToken(state)]; !ok {
+		t.Fatal("expected the state to be kept after the rejected callbacks")
+	}
+
+	if _, _, err := oauthService.CompleteLogin(ctx, google, state, state, "code"); err != nil {
+		t.Errorf("expected the browser that started the login to complete it, got %v", err)
+	}
+
+	// the browser of the mobile app doesn't send the cookie
+	_, state, _ = oauthService.StartLogin(ctx, google, LoginRequest{ClientType: "mobile", CodeChallenge: oauth2.S256ChallengeFromVerifier(oauth2.GenerateVerifier())})
+	if _, _, err := oauthService.CompleteLogin(ctx, google, state, "", "code"); err != nil {
+		t.Errorf("expected the mobile login to be completed without the cookie, got %v", err)
+	}
+}
+
+func TestMobileLoginRequiresCodeChallenge(t *testing.T) {
+	oauthService, _ := newOAuthTestService()
+	google, _ := oauthService.Provider("google")
+
+	for _, challenge := range []string{"", "plain-verifier", oauth2.S256ChallengeFromVerifier("verifier") + "A"} {
+		_, _, err := oauthService.StartLogin(context.Background(), google, LoginRequest{ClientType: "mobile", CodeChallenge: challenge})
+		if !errors.Is(err, customerrors.ErrInvalidCodeChallenge) {
+			t.Errorf("expected the challenge %q to be rejected, got %v", challenge, err)
+		}
+	}
+}
+
+func TestExchangeAuthCode(t *testing.T) {
+	oauthService, oauthFlowRepository := newOAuthTestService()
+	ctx := context.Background()
+	userID := uuid.NewString()
+	verifier := oauth2.GenerateVerifier()
+
+	code, err := oauthService.IssueAuthCode(ctx, userID, oauth2.S256ChallengeFromVerifier(verifier))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := oauthFlowRepository.codes[code]; ok {
+		t.Error("expected the code to be stored hashed")
+	}
+
+	exchangedUserID, email, err := oauthService.ExchangeAuthCode(ctx, code, verifier)
+	if err != nil || exchangedUserID != userID || email != "ana@example.com" {
+		t.Fatalf("expected the user of the code, got %s %s (%v)", exchangedUserID, email, err)
+	}
+
+	if _, _, err := oauthService.ExchangeAuthCode(ctx, code, verifier); !errors.Is(err, customerrors.ErrInvalidAuthCode) {
+		t.Errorf("expected the code to be used once, got %v", err)
+	}
+
+	// an intercepted code is useless without the verifier, and a wrong guess burns it
+	code, _ = oauthService.IssueAuthCode(ctx, userID, oauth2.S256ChallengeFromVerifier(verifier))
+	if _, _, err := oauthService.ExchangeAuthCode(ctx, code, oauth2.GenerateVerifier()); !errors.Is(err, customerrors.ErrInvalidAuthCode) {
+		t.Errorf("expected another verifier to be rejected, got %v", err)
+	}
+
+	if _, _, err := oauthService.ExchangeAuthCode(ctx, code, verifier); !errors.Is(err, customerrors.ErrInvalidAuthCode) {
+		t.Errorf("expected the code to be burnt by the wrong verifier, got %v", err)
+	}
+
+	// the expired codes can't be exchanged
+	code, _ = oauthService.IssueAuthCode(ctx, userID, oauth2.S256ChallengeFromVerifier(verifier))
+	stored := oauthFlowRepository.codes[hashToken(code)]
+	stored.ExpiresAt.Time = time.Now().Add(-time.Second)
+	oauthFlowRepository.codes[hashToken(code)] = stored
+
+	if _, _, err := oauthService.ExchangeAuthCode(ctx, code, verifier); !errors.Is(err, customerrors.ErrInvalidAuthCode) {
+		t.Errorf("expected the expired code to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"golang-whatsapp-clone/config"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+const gitHubAPIURL = "https://api.github.com"
+
+type GitHubProvider struct {
+	config *oauth2.Config
+	apiURL string
+}
+
+type gitHubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type gitHubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+func NewGitHubProvider(appConfig *config.AppConfig) *GitHubProvider {
+	return &GitHubProvider{
+		config: &oauth2.Config{
+			ClientID:     appConfig.GitHubClientID,
+			ClientSecret: appConfig.GitHubClientSecret,
+			RedirectURL:  callbackURL(appConfig, "github"),
+			Scopes:       []string{"read:user", "user:email"},
+			Endpoint:     github.Endpoint,
+		},
+		apiURL: gitHubAPIURL,
+	}
+}
+
+func (p *GitHubProvider) Name() string {
+	return "github"
+}
+
+func (p *GitHubProvider) AuthCodeURL(ctx context.Context, state string, codeVerifier string) (string, error) {
+	return p.config.AuthCodeURL(state, oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+func (p *GitHubProvider) Authenticate(ctx context.Context, code string, state string, codeVerifier string) (*OAuthUser, error) {
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange the code: %w", err)
+	}
+
+	client := p.config.Client(ctx, token)
+
+	var user gitHubUser
+	if err := p.get(client, "/user", &user); err != nil {
+		return nil, err
+	}
+
+	// the email of the profile can be hidden or not verified, the primary one of the
+	// account is used instead
+	var emails []gitHubEmail
+	if err := p.get(client, "/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	oauthUser := &OAuthUser{
+		Subject:   strconv.FormatInt(user.ID, 10),
+		Name:      user.Name,
+		AvatarURL: user.AvatarURL,
+	}
+	if oauthUser.Name == "" {
+		oauthUser.Name = user.Login
+	}
+
+	for _, email := range emails {
+		if email.Primary {
+			oauthUser.Email = email.Email
+			oauthUser.EmailVerified = email.Verified
+			break
+		}
+	}
+
+	return oauthUser, nil
+}
+
+func (p *GitHubProvider) get(client *http.Client, path string, dst any) error {
+	req, err := http.NewRequest(http.MethodGet, p.apiURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get %s: status %d", path, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	return nil
+}
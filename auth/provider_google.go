@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"golang-whatsapp-clone/config"
+	"net/http"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+type GoogleProvider struct {
+	config      *oauth2.Config
+	userInfoURL string
+}
+
+func NewGoogleProvider(appConfig *config.AppConfig) *GoogleProvider {
+	return &GoogleProvider{
+		config: &oauth2.Config{
+			ClientID:     appConfig.GoogleClientID,
+			ClientSecret: appConfig.GoogleClientSecret,
+			RedirectURL:  callbackURL(appConfig, "google"),
+			Scopes: []string{
+				"https://www.googleapis.com/auth/userinfo.email",
+				"https://www.googleapis.com/auth/userinfo.profile",
+			},
+			Endpoint: google.Endpoint,
+		},
+		userInfoURL: appConfig.GoogleUserInfoUrl,
+	}
+}
+
+func (p *GoogleProvider) Name() string {
+	return "google"
+}
+
+func (p *GoogleProvider) AuthCodeURL(ctx context.Context, state string, codeVerifier string) (string, error) {
+	return p.config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+func (p *GoogleProvider) Authenticate(ctx context.Context, code string, state string, codeVerifier string) (*OAuthUser, error) {
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange the code: %w", err)
+	}
+
+	client := p.config.Client(ctx, token)
+	resp, err := client.Get(p.userInfoURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get user info: status %d", resp.StatusCode)
+	}
+
+	var googleUser GoogleUser
+	if err := json.NewDecoder(resp.Body).Decode(&googleUser); err != nil {
+		return nil, fmt.Errorf("failed to decode user info: %w", err)
+	}
+
+	return &OAuthUser{
+		Subject:       googleUser.ID,
+		Email:         googleUser.Email,
+		EmailVerified: googleUser.VerifiedEmail,
+		Name:          googleUser.Name,
+		AvatarURL:     googleUser.Picture,
+	}, nil
+}
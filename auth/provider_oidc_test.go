@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// fakeOIDCServer is an openid connect provider that issues an id token with the claims of the
+// test for any authorization code
+type fakeOIDCServer struct {
+	*httptest.Server
+	key       *rsa.PrivateKey
+	signKey   *rsa.PrivateKey
+	idToken   jwt.MapClaims
+	jwksCalls atomic.Int32
+}
+
+func newFakeOIDCServer(t *testing.T) *fakeOIDCServer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate the key: %v", err)
+	}
+
+	server := &fakeOIDCServer{key: key, signKey: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 server.URL,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+			"jwks_uri":               server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		server.jwksCalls.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key-1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, server.idToken)
+		token.Header["kid"] = "key-1"
+		idToken, err := token.SignedString(server.signKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+
+	server.Server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (s *fakeOIDCServer) claims(state string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            s.URL,
+		"aud":            "client-id",
+		"sub":            "user-123",
+		"email":          "ana@example.com",
+		"email_verified": true,
+		"name":           "Ana",
+		"picture":        "https://example.com/ana.png",
+		"nonce":          oidcNonce(state),
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+}
+
+func TestOIDCProviderAuthenticate(t *testing.T) {
+	server := newFakeOIDCServer(t)
+	provider := NewOIDCProvider("acme", server.URL, "client-id", "client-secret", "http://localhost/api/v1/auth/acme/callback")
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "verifier-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parsed, _ := url.Parse(authURL)
+	if !strings.HasPrefix(authURL, server.URL+"/authorize") || parsed.Query().Get("nonce") != oidcNonce("state-1") ||
+		parsed.Query().Get("code_challenge") != oauth2.S256ChallengeFromVerifier("verifier-1") {
+		t.Errorf("expected the authorization endpoint with the nonce and the code challenge, got %s", authURL)
+	}
+
+	server.idToken = server.claims("state-1")
+	user, err := provider.Authenticate(ctx, "code", "state-1", "verifier-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if user.Subject != "user-123" || user.Email != "ana@example.com" || !user.EmailVerified || user.Name != "Ana" {
+		t.Errorf("unexpected user %+v", user)
+	}
+
+	// the keys are cached between logins
+	if _, err := provider.Authenticate(ctx, "code", "state-1", "verifier-1"); err != nil || server.jwksCalls.Load() != 1 {
+		t.Errorf("expected the keys to be fetched once, got %d calls (%v)", server.jwksCalls.Load(), err)
+	}
+}
+
+func TestOIDCProviderRejectsInvalidIDTokens(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate the key: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(server *fakeOIDCServer, claims jwt.MapClaims)
+	}{
+		{"another nonce", func(server *fakeOIDCServer, claims jwt.MapClaims) {
+			claims["nonce"] = oidcNonce("state-2")
+		}},
+		{"another audience", func(server *fakeOIDCServer, claims jwt.MapClaims) {
+			claims["aud"] = "another-client"
+		}},
+		{"another issuer", func(server *fakeOIDCServer, claims jwt.MapClaims) {
+			claims["iss"] = "https://evil.example.com"
+		}},
+		{"expired", func(server *fakeOIDCServer, claims jwt.MapClaims) {
+			claims["exp"] = time.Now().Add(-time.Minute).Unix()
+		}},
+		{"signed with another key", func(server *fakeOIDCServer, claims jwt.MapClaims) {
+			server.signKey = otherKey
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeOIDCServer(t)
+			provider := NewOIDCProvider("acme", server.URL, "client-id", "client-secret", "http://localhost/callback")
+
+			claims := server.claims("state-1")
+			tt.modify(server, claims)
+			server.idToken = claims
+
+			if _, err := provider.Authenticate(context.Background(), "code", "state-1", "verifier-1"); err == nil {
+				t.Error("expected the id token to be rejected")
+			}
+		})
+	}
+}
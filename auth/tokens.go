@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// a client that refreshes from several requests at the same time presents the same token
+// more than once, the uses in this window after the first one are not a reuse
+const refreshTokenReuseGrace = 10 * time.Second
+
+type TokenPair struct {
+	SessionID             string
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+// TokenService issues the access and refresh tokens. The refresh tokens rotate: every refresh
+// uses the current token and returns a new one of the same family. When a used token is
+// presented again it was stolen or leaked, so the whole family is revoked and both the
+// attacker and the user must log in again. The concurrent refreshes of the same client within
+// refreshTokenReuseGrace get new tokens of the family instead
+type TokenService struct {
+	jwtService             *JWTService
+	refreshTokenRepository repository.RefreshTokenRepository
+	logger                 *zerolog.Logger
+	// called after a reused token revokes its family, to close the connections of the session
+	onFamilyRevoked func(familyID string)
+}
+
+func NewTokenService(
+	jwtService *JWTService,
+	refreshTokenRepository repository.RefreshTokenRepository,
+	logger *zerolog.Logger,
+	onFamilyRevoked func(familyID string),
+) *TokenService {
+	return &TokenService{
+		jwtService:             jwtService,
+		refreshTokenRepository: refreshTokenRepository,
+		logger:                 logger,
+		onFamilyRevoked:        onFamilyRevoked,
+	}
+}
+
+// IssueTokens starts the family of refresh tokens of a new session, it's called after the user
+// logs in. The session id is the family id of the refresh tokens and the jti of the access tokens
+func (s *TokenService) IssueTokens(ctx context.Context, userID string, email string, sessionID string) (*TokenPair, error) {
+	return s.issueTokens(ctx, userID, email, sessionID)
+}
+
+// Refresh rotates the refresh token and returns a new access token
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	claims, err := s.jwtService.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, customerrors.ErrInvalidRefreshToken
+	}
+
+	_, err = s.refreshTokenRepository.UseRefreshToken(ctx, claims.ID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return s.refreshUsedToken(ctx, claims)
+		}
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, claims.UserID, claims.Email, claims.FamilyID)
+}
+
+// Revoke invalidates the family of the refresh token and its session, the id of the session is
+// returned
+func (s *TokenService) Revoke(ctx context.Context, refreshToken string) (string, error) {
+	claims, err := s.jwtService.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return "", customerrors.ErrInvalidRefreshToken
+	}
+
+	return claims.FamilyID, s.refreshTokenRepository.RevokeFamily(ctx, claims.FamilyID)
+}
+
+// refreshUsedToken finds out why the token couldn't be used. A token used in the last
+// refreshTokenReuseGrace is a concurrent refresh and gets new tokens of the family, any other
+// use of a used token revokes its family and the session, and its open connections are closed
+func (s *TokenService) refreshUsedToken(ctx context.Context, claims *RefreshClaims) (*TokenPair, error) {
+	stored, err := s.refreshTokenRepository.GetRefreshToken(ctx, claims.ID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidRefreshToken
+	}
+
+	if !stored.UsedAt.Valid || stored.RevokedAt.Valid {
+		return nil, customerrors.ErrInvalidRefreshToken
+	}
+
+	if time.Since(stored.UsedAt.Time) < refreshTokenReuseGrace {
+		return s.issueTokens(ctx, claims.UserID, claims.Email, claims.FamilyID)
+	}
+
+	s.logger.Warn().Msgf("refresh token %s of user %s was reused, revoking its family %s", claims.ID, claims.UserID, claims.FamilyID)
+
+	if err := s.refreshTokenRepository.RevokeFamily(ctx, claims.FamilyID); err != nil {
+		s.logger.Error().Msgf("error to revoke the refresh token family %s: %v", claims.FamilyID, err)
+	}
+
+	s.onFamilyRevoked(claims.FamilyID)
+
+	return nil, customerrors.ErrRefreshTokenReused
+}
+
+func (s *TokenService) issueTokens(ctx context.Context, userID string, email string, familyID string) (*TokenPair, error) {
+	accessToken, err := s.jwtService.GenerateToken(userID, email, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshClaims, err := s.jwtService.GenerateRefreshToken(userID, email, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.refreshTokenRepository.CreateRefreshToken(ctx, refreshClaims.ID, userID, familyID, refreshClaims.ExpiresAt.Time)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		SessionID:             familyID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  time.Now().Add(AccessTokenDuration),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshClaims.ExpiresAt.Time,
+	}, nil
+}
@@ -0,0 +1,249 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+)
+
+// fakeRefreshTokenRepository keeps the tokens in memory with the same rules as the queries
+type fakeRefreshTokenRepository struct {
+	mutex  sync.Mutex
+	tokens map[string]*db.RefreshToken
+}
+
+func newFakeRefreshTokenRepository() *fakeRefreshTokenRepository {
+	return &fakeRefreshTokenRepository{tokens: map[string]*db.RefreshToken{}}
+}
+
+func toTestUUID(value string) pgtype.UUID {
+	return pgtype.UUID{Bytes: uuid.MustParse(value), Valid: true}
+}
+
+func (r *fakeRefreshTokenRepository) CreateRefreshToken(ctx context.Context, tokenID string, userID string, familyID string, expiresAt time.Time) (*db.RefreshToken, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	token := &db.RefreshToken{
+		ID:        toTestUUID(tokenID),
+		UserID:    toTestUUID(userID),
+		FamilyID:  toTestUUID(familyID),
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	}
+	r.tokens[tokenID] = token
+
+	return token, nil
+}
+
+func (r *fakeRefreshTokenRepository) UseRefreshToken(ctx context.Context, tokenID string) (*db.RefreshToken, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	token, ok := r.tokens[tokenID]
+	if !ok || token.UsedAt.Valid || token.RevokedAt.Valid || token.ExpiresAt.Time.Before(time.Now()) {
+		return nil, customerrors.ErrResourceNotFound
+	}
+	token.UsedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	return token, nil
+}
+
+func (r *fakeRefreshTokenRepository) GetRefreshToken(ctx context.Context, tokenID string) (*db.RefreshToken, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	token, ok := r.tokens[tokenID]
+	if !ok {
+		return nil, customerrors.ErrResourceNotFound
+	}
+
+	return token, nil
+}
+
+func (r *fakeRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, token := range r.tokens {
+		if token.FamilyID.String() == familyID && !token.RevokedAt.Valid {
+			token.RevokedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+		}
+	}
+
+	return nil
+}
+
+// ageRefreshToken moves the use of the token before the grace of the concurrent refreshes
+func ageRefreshToken(t *testing.T, tokenService *TokenService, refreshToken string) {
+	t.Helper()
+
+	claims, err := tokenService.jwtService.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	refreshTokenRepository := tokenService.refreshTokenRepository.(*fakeRefreshTokenRepository)
+	refreshTokenRepository.mutex.Lock()
+	defer refreshTokenRepository.mutex.Unlock()
+
+	refreshTokenRepository.tokens[claims.ID].UsedAt.Time = time.Now().Add(-refreshTokenReuseGrace - time.Second)
+}
+
+func newTestTokenService() (*TokenService, *[]string) {
+	logger := zerolog.Nop()
+	revokedFamilies := &[]string{}
+	tokenService := NewTokenService(NewJWTService("access-secret", "refresh-secret"), newFakeRefreshTokenRepository(), &logger, func(familyID string) {
+		*revokedFamilies = append(*revokedFamilies, familyID)
+	})
+
+	return tokenService, revokedFamilies
+}
+
+func TestRefreshRotatesTheToken(t *testing.T) {
+	tokenService, _ := newTestTokenService()
+	ctx := context.Background()
+	userID := uuid.NewString()
+	sessionID := uuid.NewString()
+
+	tokens, err := tokenService.IssueTokens(ctx, userID, "ana@example.com", sessionID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	claims, err := tokenService.jwtService.ValidateToken(tokens.AccessToken)
+	if err != nil || claims.UserID != userID || claims.ID != sessionID {
+		t.Fatalf("expected a valid access token of the session, got %+v, %v", claims, err)
+	}
+
+	// the refresh token is signed with a different secret
+	if _, err := tokenService.jwtService.ValidateToken(tokens.RefreshToken); err == nil {
+		t.Error("expected the refresh token to be rejected as an access token")
+	}
+
+	refreshed, err := tokenService.Refresh(ctx, tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if refreshed.RefreshToken == tokens.RefreshToken {
+		t.Error("expected a new refresh token")
+	}
+
+	if refreshed.SessionID != sessionID {
+		t.Errorf("expected the refreshed tokens to keep the session %s, got %s", sessionID, refreshed.SessionID)
+	}
+
+	if _, err := tokenService.Refresh(ctx, refreshed.RefreshToken); err != nil {
+		t.Errorf("expected the new refresh token to be valid, got %v", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesTheFamily(t *testing.T) {
+	tokenService, revokedFamilies := newTestTokenService()
+	ctx := context.Background()
+	sessionID := uuid.NewString()
+
+	tokens, _ := tokenService.IssueTokens(ctx, uuid.NewString(), "ana@example.com", sessionID)
+	otherSession, _ := tokenService.IssueTokens(ctx, uuid.NewString(), "bob@example.com", uuid.NewString())
+
+	refreshed, err := tokenService.Refresh(ctx, tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// an attacker uses the stolen token after the user refreshed it
+	ageRefreshToken(t, tokenService, tokens.RefreshToken)
+	if _, err := tokenService.Refresh(ctx, tokens.RefreshToken); !errors.Is(err, customerrors.ErrRefreshTokenReused) {
+		t.Fatalf("expected the reuse to be detected, got %v", err)
+	}
+
+	if _, err := tokenService.Refresh(ctx, refreshed.RefreshToken); !errors.Is(err, customerrors.ErrInvalidRefreshToken) {
+		t.Errorf("expected the whole family to be revoked, got %v", err)
+	}
+
+	// the websockets of the session are closed too
+	if len(*revokedFamilies) != 1 || (*revokedFamilies)[0] != sessionID {
+		t.Errorf("expected the connections of the session %s to be closed, got %v", sessionID, *revokedFamilies)
+	}
+
+	if _, err := tokenService.Refresh(ctx, otherSession.RefreshToken); err != nil {
+		t.Errorf("expected the other families to stay valid, got %v", err)
+	}
+}
+
+func TestRevokeRefreshToken(t *testing.T) {
+	tokenService, _ := newTestTokenService()
+	ctx := context.Background()
+
+	tokens, _ := tokenService.IssueTokens(ctx, uuid.NewString(), "ana@example.com", uuid.NewString())
+
+	sessionID, err := tokenService.Revoke(ctx, tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sessionID != tokens.SessionID {
+		t.Errorf("expected the session %s to be revoked, got %s", tokens.SessionID, sessionID)
+	}
+
+	if _, err := tokenService.Refresh(ctx, tokens.RefreshToken); !errors.Is(err, customerrors.ErrInvalidRefreshToken) {
+		t.Errorf("expected the revoked token to be rejected, got %v", err)
+	}
+
+	if _, err := tokenService.Refresh(ctx, "not a token"); !errors.Is(err, customerrors.ErrInvalidRefreshToken) {
+		t.Errorf("expected an invalid token error, got %v", err)
+	}
+}
+
+func TestConcurrentRefreshesOfTheSameToken(t *testing.T) {
+	tokenService, revokedFamilies := newTestTokenService()
+	ctx := context.Background()
+	sessionID := uuid.NewString()
+
+	tokens, _ := tokenService.IssueTokens(ctx, uuid.NewString(), "ana@example.com", sessionID)
+
+	// the app refreshes from several requests at the same time with the same token
+	refreshed := make([]*TokenPair, 5)
+	errs := make([]error, len(refreshed))
+	var wg sync.WaitGroup
+	for i := range refreshed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			refreshed[i], errs[i] = tokenService.Refresh(ctx, tokens.RefreshToken)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("expected the concurrent refreshes to succeed, got %v", err)
+		}
+
+		if refreshed[i].SessionID != sessionID {
+			t.Errorf("expected the tokens of the session %s, got %s", sessionID, refreshed[i].SessionID)
+		}
+
+		if _, err := tokenService.Refresh(ctx, refreshed[i].RefreshToken); err != nil {
+			t.Errorf("expected the new refresh token to be valid, got %v", err)
+		}
+	}
+
+	if len(*revokedFamilies) != 0 {
+		t.Errorf("expected the session to stay open, got %v revoked", *revokedFamilies)
+	}
+
+	// after the grace the same token is a reuse
+	ageRefreshToken(t, tokenService, tokens.RefreshToken)
+	if _, err := tokenService.Refresh(ctx, tokens.RefreshToken); !errors.Is(err, customerrors.ErrRefreshTokenReused) {
+		t.Errorf("expected the reuse to be detected after the grace, got %v", err)
+	}
+}
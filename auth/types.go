@@ -1,6 +1,10 @@
 package auth
 
-import "github.com/golang-jwt/jwt/v5"
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
 
 type GoogleUser struct {
 	ID            string `json:"id"`
@@ -16,8 +20,18 @@ type JWTClaims struct {
 	jwt.RegisteredClaims
 }
 
+type RefreshClaims struct {
+	UserID   string `json:"user_id"`
+	Email    string `json:"email"`
+	FamilyID string `json:"family_id"`
+	jwt.RegisteredClaims
+}
+
 // represents the authenticated user in graphql context
 type UserContext struct {
-	UserID string
-	Email  string
+	UserID    string
+	Email     string
+	SessionID string
+	// when the access token expires, it's zero when the user didn't come from a token
+	ExpiresAt time.Time
 }
@@ -18,10 +18,26 @@ func GetUserFromContext(ctx context.Context) *UserContext {
 }
 
 // adds user context to GraphQL context
-func WithUserContext(ctx context.Context, userID string, email string) context.Context {
+func WithUserContext(ctx context.Context, userID string, email string, sessionID string) context.Context {
 	userCtx := &UserContext{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		SessionID: sessionID,
+	}
+
+	return context.WithValue(ctx, UserContextKey, userCtx)
+}
+
+// WithTokenClaims adds the user of a validated access token to the context
+func WithTokenClaims(ctx context.Context, claims *JWTClaims) context.Context {
+	userCtx := &UserContext{
+		UserID:    claims.UserID,
+		Email:     claims.Email,
+		SessionID: claims.ID,
+	}
+
+	if claims.ExpiresAt != nil {
+		userCtx.ExpiresAt = claims.ExpiresAt.Time
 	}
 
 	return context.WithValue(ctx, UserContextKey, userCtx)
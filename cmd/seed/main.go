@@ -37,22 +37,11 @@ func main() {
 
 	fmt.Println("Starting seed data... ⏳")
 	// main user
-	mainUser, _ := dbQueries.UpsertUserByGoogleAuthSafe(ctx, db.UpsertUserByGoogleAuthSafeParams{
-		Name:      fromStringToPGText("Main User"),
-		GoogleID:  fromStringToPGText("google-1"),
-		Email:     "main-user-test@gmail.com",
-		AvatarUrl: fromStringToPGText(getUserAvatar()),
-	})
+	mainUser := seedUser(ctx, dbQueries, "Main User", "google-1", "main-user-test@gmail.com")
 	fmt.Printf("Main user created: %s ✔️\n", mainUser.Email)
 
 	for i := range 10 {
-		user := db.UpsertUserByGoogleAuthSafeParams{
-			Name:      fromStringToPGText(fmt.Sprintf("User Test %d", i)),
-			GoogleID:  fromStringToPGText(fmt.Sprintf("google-%d", i)),
-			Email:     fmt.Sprintf("user-%d-test@fake.com", i),
-			AvatarUrl: fromStringToPGText(getUserAvatar()),
-		}
-		_, _ = dbQueries.UpsertUserByGoogleAuthSafe(ctx, user)
+		user := seedUser(ctx, dbQueries, fmt.Sprintf("User Test %d", i), fmt.Sprintf("google-test-%d", i), fmt.Sprintf("user-%d-test@fake.com", i))
 		fmt.Printf("User created: %s ✔️\n", user.Email)
 	}
 
@@ -60,6 +49,24 @@ func main() {
 
 }
 
+// seedUser creates the user with a google identity, like the ones created after logging in
+func seedUser(ctx context.Context, dbQueries *db.Queries, name string, googleID string, email string) db.User {
+	user, _ := dbQueries.UpsertUserByEmail(ctx, db.UpsertUserByEmailParams{
+		Name:      fromStringToPGText(name),
+		Email:     email,
+		AvatarUrl: fromStringToPGText(getUserAvatar()),
+	})
+
+	_, _ = dbQueries.CreateUserIdentity(ctx, db.CreateUserIdentityParams{
+		UserID:         user.ID,
+		Provider:       "google",
+		ProviderUserID: googleID,
+		Email:          email,
+	})
+
+	return user
+}
+
 func getUserAvatar() string {
 	userId = userId + 1
 	return fmt.Sprintf("https://randomuser.me/api/portraits/men/%d.jpg", userId)
@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/url"
+	"slices"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
@@ -14,12 +17,48 @@ type AppConfig struct {
 	GoogleClientID     string
 	GoogleClientSecret string
 	GoogleUserInfoUrl  string
+	// the github and openid connect providers are optional, they're enabled when configured
+	GitHubClientID     string
+	GitHubClientSecret string
+	OIDCProviderName   string
+	OIDCIssuerURL      string
+	OIDCClientID       string
+	OIDCClientSecret   string
 	MobileAppSchema    string
 	BaseURL            string
 	AppEnv             string // development, qa, or production
 	JWTSecret          string
 	JWTRefreshSecret   string
 	CookieName         string
+	// AllowedOrigins are the browser origins allowed to call the api and open websockets
+	AllowedOrigins []string
+	// Mailer is log, file or smtp, the log and file mailers don't send the emails so they're
+	// not allowed in production
+	Mailer       string
+	MailerDir    string
+	MailFrom     string
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword string
+	// RateLimitStore is memory or postgres, postgres shares the limits between the instances.
+	// It's postgres by default on Vercel
+	RateLimitStore string
+	// TrustProxyHeaders reads the ip of the clients from the X-Real-IP header of the proxy
+	TrustProxyHeaders bool
+	// DeletedAccountDirectConversations is delete or keep, what happens to the direct
+	// conversations of a deleted account. The kept ones are anonymized like the groups
+	DeletedAccountDirectConversations string
+}
+
+// origins allowed by default in every environment besides the one of BASE_URL
+var defaultAllowedOrigins = map[string][]string{
+	"development": {
+		"http://localhost",
+		"http://localhost:3000",
+		"http://localhost:4000",
+		"http://localhost:8081",
+		"https://sandbox.embed.apollographql.com",
+	},
 }
 
 func SetupAppConfig() *AppConfig {
@@ -46,6 +85,29 @@ func SetupAppConfig() *AppConfig {
 		log.Fatal("env vars GOOGLE_CLIENT_ID or GOOGLE_CLIENT_SECRET or GOOGLE_USER_INFO_URL are not set")
 	}
 
+	// github auth
+	gitHubClientId := viper.GetString("GITHUB_CLIENT_ID")
+	gitHubClientSecret := viper.GetString("GITHUB_CLIENT_SECRET")
+	if gitHubClientId != "" && gitHubClientSecret == "" {
+		log.Fatal("env var GITHUB_CLIENT_SECRET is not set")
+	}
+
+	// openid connect auth, the name is the one used in the auth routes
+	oidcIssuerUrl := viper.GetString("OIDC_ISSUER_URL")
+	oidcClientId := viper.GetString("OIDC_CLIENT_ID")
+	oidcClientSecret := viper.GetString("OIDC_CLIENT_SECRET")
+	oidcProviderName := viper.GetString("OIDC_PROVIDER_NAME")
+	if oidcProviderName == "" {
+		oidcProviderName = "oidc"
+	}
+	if oidcIssuerUrl != "" && oidcClientId == "" {
+		log.Fatal("env var OIDC_CLIENT_ID is not set")
+	}
+	// the name is a segment of the auth routes, it can't be another provider or auth route
+	if slices.Contains([]string{"google", "github", "refresh", "logout", "token", "email"}, oidcProviderName) {
+		log.Fatal("env var OIDC_PROVIDER_NAME can't be the name of another provider or auth route")
+	}
+
 	mobileAppSchema := viper.GetString("MOBILE_APP_SCHEME")
 	if mobileAppSchema == "" {
 		log.Fatal("env vars MOBILE_APP_SCHEME is not set")
@@ -66,21 +128,121 @@ func SetupAppConfig() *AppConfig {
 	if jwtSecret == "" || jwtRefreshSecret == "" {
 		log.Fatal("env vars JWT_SECRET or JWT_REFRESH_SECRET are not set")
 	}
+	if jwtSecret == jwtRefreshSecret {
+		log.Fatal("env vars JWT_SECRET and JWT_REFRESH_SECRET must be different")
+	}
 
 	cookieName := viper.GetString("COOKIE_NAME")
 	if cookieName == "" {
 		log.Fatal("env vars COOKIE_NAME is not set")
 	}
 
+	// comma separated, when it's not set the defaults of the environment are used
+	allowedOrigins := parseOrigins(viper.GetString("ALLOWED_ORIGINS"))
+	if len(allowedOrigins) == 0 {
+		allowedOrigins = defaultAllowedOrigins[appEnv]
+	}
+	if baseOrigin := toOrigin(baseUrl); baseOrigin != "" && !slices.Contains(allowedOrigins, baseOrigin) {
+		allowedOrigins = append(allowedOrigins, baseOrigin)
+	}
+
+	mailer := viper.GetString("MAILER")
+	if mailer == "" {
+		mailer = "log"
+	}
+	if !slices.Contains([]string{"log", "file", "smtp"}, mailer) {
+		log.Fatal("env var MAILER must be log, file or smtp")
+	}
+	if appEnv == "production" && mailer != "smtp" {
+		log.Fatal("env var MAILER must be smtp in production")
+	}
+
+	mailerDir := viper.GetString("MAILER_DIR")
+	if mailerDir == "" {
+		mailerDir = "tmp/mails"
+	}
+
+	mailFrom := viper.GetString("MAIL_FROM")
+	smtpAddr := viper.GetString("SMTP_ADDR")
+	if mailer == "smtp" && (mailFrom == "" || smtpAddr == "") {
+		log.Fatal("env vars MAIL_FROM or SMTP_ADDR are not set")
+	}
+
+	rateLimitStore := viper.GetString("RATE_LIMIT_STORE")
+	if rateLimitStore == "" {
+		rateLimitStore = "memory"
+		// every serverless instance has its own memory and lives for a few requests
+		if viper.GetBool("VERCEL") {
+			rateLimitStore = "postgres"
+		}
+	}
+	if rateLimitStore != "memory" && rateLimitStore != "postgres" {
+		log.Fatal("env var RATE_LIMIT_STORE must be memory or postgres")
+	}
+
+	deletedAccountDirectConversations := viper.GetString("DELETED_ACCOUNT_DIRECT_CONVERSATIONS")
+	if deletedAccountDirectConversations == "" {
+		deletedAccountDirectConversations = "delete"
+	}
+	if deletedAccountDirectConversations != "delete" && deletedAccountDirectConversations != "keep" {
+		log.Fatal("env var DELETED_ACCOUNT_DIRECT_CONVERSATIONS must be delete or keep")
+	}
+
 	return &AppConfig{
 		Port:               port,
 		DatabaseURL:        dbUrl,
 		GoogleClientID:     googleClientId,
 		GoogleClientSecret: googleClientSecret,
 		GoogleUserInfoUrl:  googleUserInfo,
+		GitHubClientID:     gitHubClientId,
+		GitHubClientSecret: gitHubClientSecret,
+		OIDCProviderName:   oidcProviderName,
+		OIDCIssuerURL:      oidcIssuerUrl,
+		OIDCClientID:       oidcClientId,
+		OIDCClientSecret:   oidcClientSecret,
 		MobileAppSchema:    mobileAppSchema,
 		BaseURL:            baseUrl,
 		AppEnv:             appEnv,
+		JWTSecret:          jwtSecret,
+		JWTRefreshSecret:   jwtRefreshSecret,
 		CookieName:         cookieName,
+		AllowedOrigins:     allowedOrigins,
+		Mailer:             mailer,
+		MailerDir:          mailerDir,
+		MailFrom:           mailFrom,
+		SMTPAddr:           smtpAddr,
+		SMTPUsername:       viper.GetString("SMTP_USERNAME"),
+		SMTPPassword:       viper.GetString("SMTP_PASSWORD"),
+		RateLimitStore:     rateLimitStore,
+		TrustProxyHeaders:  viper.GetBool("TRUST_PROXY_HEADERS"),
+
+		DeletedAccountDirectConversations: deletedAccountDirectConversations,
+	}
+}
+
+// IsAllowedOrigin reports if the value of an Origin header is in the allowlist
+func (c *AppConfig) IsAllowedOrigin(origin string) bool {
+	origin = toOrigin(origin)
+	return origin != "" && slices.Contains(c.AllowedOrigins, origin)
+}
+
+func parseOrigins(value string) []string {
+	origins := []string{}
+	for item := range strings.SplitSeq(value, ",") {
+		if origin := toOrigin(strings.TrimSpace(item)); origin != "" {
+			origins = append(origins, origin)
+		}
 	}
+
+	return origins
+}
+
+// toOrigin normalizes an url to scheme://host[:port], it's empty when the url is not valid
+func toOrigin(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+
+	return strings.ToLower(u.Scheme + "://" + u.Host)
 }
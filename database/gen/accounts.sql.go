@@ -0,0 +1,80 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: accounts.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const anonymizeUserMessages = `-- name: AnonymizeUserMessages :exec
+UPDATE messages
+SET sender_id = $1
+WHERE sender_id = $2
+`
+
+type AnonymizeUserMessagesParams struct {
+	DeletedUserID pgtype.UUID
+	UserID        pgtype.UUID
+}
+
+// the messages of the user that are kept are sent by the deleted account from now on
+func (q *Queries) AnonymizeUserMessages(ctx context.Context, arg AnonymizeUserMessagesParams) error {
+	_, err := q.db.Exec(ctx, anonymizeUserMessages, arg.DeletedUserID, arg.UserID)
+	return err
+}
+
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1
+`
+
+// the rest of the data of the user is removed with it
+func (q *Queries) DeleteUser(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUserDirectConversations = `-- name: DeleteUserDirectConversations :exec
+DELETE FROM conversations c
+USING conversation_participants cp
+WHERE cp.conversation_id = c.id
+    AND cp.user_id = $1
+    AND c.type = 'DIRECT'
+`
+
+// removes the direct conversations of the user with all their messages, for both participants
+func (q *Queries) DeleteUserDirectConversations(ctx context.Context, userID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserDirectConversations, userID)
+	return err
+}
+
+const transferUserGroupOwnerships = `-- name: TransferUserGroupOwnerships :exec
+UPDATE conversation_participants
+SET
+    role = 'owner',
+    updated_at = CURRENT_TIMESTAMP
+WHERE conversation_participants.id IN (
+    SELECT DISTINCT ON (heir.conversation_id) heir.id
+    FROM conversation_participants current_owner
+    JOIN conversation_participants heir ON heir.conversation_id = current_owner.conversation_id
+        AND heir.user_id <> current_owner.user_id
+        AND heir.is_active = true
+    WHERE current_owner.user_id = $1
+        AND current_owner.role = 'owner'
+    ORDER BY heir.conversation_id, (heir.role = 'admin') DESC, heir.joined_at ASC
+)
+`
+
+// the groups owned by the user get a new owner, the oldest admin or else the oldest member
+func (q *Queries) TransferUserGroupOwnerships(ctx context.Context, userID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, transferUserGroupOwnerships, userID)
+	return err
+}
@@ -11,6 +11,21 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countPinnedConversations = `-- name: CountPinnedConversations :one
+SELECT COUNT(*)::INTEGER
+FROM conversation_participants
+WHERE user_id = $1
+    AND is_active = true
+    AND pin_order IS NOT NULL
+`
+
+func (q *Queries) CountPinnedConversations(ctx context.Context, userID pgtype.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, countPinnedConversations, userID)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const countUnreadMessages = `-- name: CountUnreadMessages :one
 SELECT COUNT(*)::INTEGER
 FROM messages m
@@ -20,6 +35,7 @@ WHERE cp.user_id = $1
     AND cp.is_active = true
     AND m.created_at > cp.last_read_at
     AND m.sender_id != $1
+    AND (m.expires_at IS NULL OR m.expires_at > CURRENT_TIMESTAMP)
 `
 
 type CountUnreadMessagesParams struct {
@@ -27,6 +43,7 @@ type CountUnreadMessagesParams struct {
 	ConversationID pgtype.UUID
 }
 
+// the expired disappearing messages are not counted, even if the reaper didn't delete them yet
 func (q *Queries) CountUnreadMessages(ctx context.Context, arg CountUnreadMessagesParams) (int32, error) {
 	row := q.db.QueryRow(ctx, countUnreadMessages, arg.UserID, arg.ConversationID)
 	var column_1 int32
@@ -40,8 +57,93 @@ type CreateParticipantsParams struct {
 	Role           string
 }
 
+const getActiveParticipantsByConversationIDs = `-- name: GetActiveParticipantsByConversationIDs :many
+SELECT id, conversation_id, user_id, joined_at, last_read_at, is_active, role, created_at, updated_at, is_archived, muted_until, pin_order
+FROM conversation_participants
+WHERE conversation_id = ANY($1::uuid[])
+    AND is_active = true
+ORDER BY conversation_id, joined_at
+`
+
+func (q *Queries) GetActiveParticipantsByConversationIDs(ctx context.Context, conversationIds []pgtype.UUID) ([]ConversationParticipant, error) {
+	rows, err := q.db.Query(ctx, getActiveParticipantsByConversationIDs, conversationIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ConversationParticipant
+	for rows.Next() {
+		var i ConversationParticipant
+		if err := rows.Scan(
+			&i.ID,
+			&i.ConversationID,
+			&i.UserID,
+			&i.JoinedAt,
+			&i.LastReadAt,
+			&i.IsActive,
+			&i.Role,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.IsArchived,
+			&i.MutedUntil,
+			&i.PinOrder,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getConversationParticipantUsers = `-- name: GetConversationParticipantUsers :many
+SELECT u.id, u.name, u.email, u.avatar_url, u.created_at, u.updated_at, u.last_seen_at, u.last_seen_privacy, u.about, u.name_edited_at, u.avatar_edited_at
+FROM conversation_participants cp
+JOIN users u ON cp.user_id = u.id
+WHERE cp.conversation_id = $1
+    AND cp.is_active = true
+`
+
+type GetConversationParticipantUsersRow struct {
+	User User
+}
+
+func (q *Queries) GetConversationParticipantUsers(ctx context.Context, conversationID pgtype.UUID) ([]GetConversationParticipantUsersRow, error) {
+	rows, err := q.db.Query(ctx, getConversationParticipantUsers, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetConversationParticipantUsersRow
+	for rows.Next() {
+		var i GetConversationParticipantUsersRow
+		if err := rows.Scan(
+			&i.User.ID,
+			&i.User.Name,
+			&i.User.Email,
+			&i.User.AvatarUrl,
+			&i.User.CreatedAt,
+			&i.User.UpdatedAt,
+			&i.User.LastSeenAt,
+			&i.User.LastSeenPrivacy,
+			&i.User.About,
+			&i.User.NameEditedAt,
+			&i.User.AvatarEditedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getParticipantByUserAndConversation = `-- name: GetParticipantByUserAndConversation :one
-SELECT id, conversation_id, user_id, joined_at, last_read_at, is_active, role, created_at, updated_at FROM conversation_participants
+SELECT id, conversation_id, user_id, joined_at, last_read_at, is_active, role, created_at, updated_at, is_archived, muted_until, pin_order FROM conversation_participants
 WHERE user_id = $1 AND conversation_id = $2 AND is_active = true
 `
 
@@ -63,6 +165,126 @@ func (q *Queries) GetParticipantByUserAndConversation(ctx context.Context, arg G
 		&i.Role,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsArchived,
+		&i.MutedUntil,
+		&i.PinOrder,
+	)
+	return i, err
+}
+
+const pinParticipantConversation = `-- name: PinParticipantConversation :one
+UPDATE conversation_participants cp
+SET
+    pin_order = (
+        SELECT COALESCE(MAX(pinned.pin_order), 0) + 1
+        FROM conversation_participants pinned
+        WHERE pinned.user_id = $1
+    ),
+    is_archived = false,
+    updated_at = CURRENT_TIMESTAMP
+WHERE cp.user_id = $1
+    AND cp.conversation_id = $2
+    AND cp.is_active = true
+RETURNING cp.id, cp.conversation_id, cp.user_id, cp.joined_at, cp.last_read_at, cp.is_active, cp.role, cp.created_at, cp.updated_at, cp.is_archived, cp.muted_until, cp.pin_order
+`
+
+type PinParticipantConversationParams struct {
+	UserID         pgtype.UUID
+	ConversationID pgtype.UUID
+}
+
+// the pinned conversation goes on top of the ones that were already pinned, and it leaves the archive
+func (q *Queries) PinParticipantConversation(ctx context.Context, arg PinParticipantConversationParams) (ConversationParticipant, error) {
+	row := q.db.QueryRow(ctx, pinParticipantConversation, arg.UserID, arg.ConversationID)
+	var i ConversationParticipant
+	err := row.Scan(
+		&i.ID,
+		&i.ConversationID,
+		&i.UserID,
+		&i.JoinedAt,
+		&i.LastReadAt,
+		&i.IsActive,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsArchived,
+		&i.MutedUntil,
+		&i.PinOrder,
+	)
+	return i, err
+}
+
+const unpinParticipantConversation = `-- name: UnpinParticipantConversation :one
+UPDATE conversation_participants
+SET
+    pin_order = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+    AND conversation_id = $2
+    AND is_active = true
+RETURNING id, conversation_id, user_id, joined_at, last_read_at, is_active, role, created_at, updated_at, is_archived, muted_until, pin_order
+`
+
+type UnpinParticipantConversationParams struct {
+	UserID         pgtype.UUID
+	ConversationID pgtype.UUID
+}
+
+func (q *Queries) UnpinParticipantConversation(ctx context.Context, arg UnpinParticipantConversationParams) (ConversationParticipant, error) {
+	row := q.db.QueryRow(ctx, unpinParticipantConversation, arg.UserID, arg.ConversationID)
+	var i ConversationParticipant
+	err := row.Scan(
+		&i.ID,
+		&i.ConversationID,
+		&i.UserID,
+		&i.JoinedAt,
+		&i.LastReadAt,
+		&i.IsActive,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsArchived,
+		&i.MutedUntil,
+		&i.PinOrder,
+	)
+	return i, err
+}
+
+const updateParticipantArchived = `-- name: UpdateParticipantArchived :one
+UPDATE conversation_participants
+SET
+    is_archived = $1,
+    pin_order = CASE WHEN $1 THEN NULL ELSE pin_order END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $2
+    AND conversation_id = $3
+    AND is_active = true
+RETURNING id, conversation_id, user_id, joined_at, last_read_at, is_active, role, created_at, updated_at, is_archived, muted_until, pin_order
+`
+
+type UpdateParticipantArchivedParams struct {
+	IsArchived     bool
+	UserID         pgtype.UUID
+	ConversationID pgtype.UUID
+}
+
+// archiving a conversation also unpins it, like WhatsApp does
+func (q *Queries) UpdateParticipantArchived(ctx context.Context, arg UpdateParticipantArchivedParams) (ConversationParticipant, error) {
+	row := q.db.QueryRow(ctx, updateParticipantArchived, arg.IsArchived, arg.UserID, arg.ConversationID)
+	var i ConversationParticipant
+	err := row.Scan(
+		&i.ID,
+		&i.ConversationID,
+		&i.UserID,
+		&i.JoinedAt,
+		&i.LastReadAt,
+		&i.IsActive,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsArchived,
+		&i.MutedUntil,
+		&i.PinOrder,
 	)
 	return i, err
 }
@@ -85,3 +307,40 @@ func (q *Queries) UpdateParticipantLastReadAt(ctx context.Context, arg UpdatePar
 	_, err := q.db.Exec(ctx, updateParticipantLastReadAt, arg.UserID, arg.LastReadAt, arg.ConversationID)
 	return err
 }
+
+const updateParticipantMutedUntil = `-- name: UpdateParticipantMutedUntil :one
+UPDATE conversation_participants
+SET
+    muted_until = $3,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+    AND conversation_id = $2
+    AND is_active = true
+RETURNING id, conversation_id, user_id, joined_at, last_read_at, is_active, role, created_at, updated_at, is_archived, muted_until, pin_order
+`
+
+type UpdateParticipantMutedUntilParams struct {
+	UserID         pgtype.UUID
+	ConversationID pgtype.UUID
+	MutedUntil     pgtype.Timestamptz
+}
+
+func (q *Queries) UpdateParticipantMutedUntil(ctx context.Context, arg UpdateParticipantMutedUntilParams) (ConversationParticipant, error) {
+	row := q.db.QueryRow(ctx, updateParticipantMutedUntil, arg.UserID, arg.ConversationID, arg.MutedUntil)
+	var i ConversationParticipant
+	err := row.Scan(
+		&i.ID,
+		&i.ConversationID,
+		&i.UserID,
+		&i.JoinedAt,
+		&i.LastReadAt,
+		&i.IsActive,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsArchived,
+		&i.MutedUntil,
+		&i.PinOrder,
+	)
+	return i, err
+}
@@ -14,7 +14,7 @@ import (
 const createConversation = `-- name: CreateConversation :one
 INSERT INTO conversations (type)
 VALUES ($1)
-RETURNING id, type, name, description, avatar_url, created_at, updated_at, last_message_at
+RETURNING id, type, name, description, avatar_url, created_at, updated_at, last_message_at, disappearing_messages_seconds
 `
 
 func (q *Queries) CreateConversation(ctx context.Context, type_ string) (Conversation, error) {
@@ -29,12 +29,13 @@ func (q *Queries) CreateConversation(ctx context.Context, type_ string) (Convers
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastMessageAt,
+		&i.DisappearingMessagesSeconds,
 	)
 	return i, err
 }
 
 const findDirectConversation = `-- name: FindDirectConversation :one
-SELECT c.id, c.type, c.name, c.description, c.avatar_url, c.created_at, c.updated_at, c.last_message_at
+SELECT c.id, c.type, c.name, c.description, c.avatar_url, c.created_at, c.updated_at, c.last_message_at, c.disappearing_messages_seconds
 FROM conversations c
 WHERE c.type = 'DIRECT'
     AND EXISTS (
@@ -68,12 +69,99 @@ func (q *Queries) FindDirectConversation(ctx context.Context, arg FindDirectConv
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastMessageAt,
+		&i.DisappearingMessagesSeconds,
 	)
 	return i, err
 }
 
-const getLastMessage = `-- name: GetLastMessage :many
+const getConversationByID = `-- name: GetConversationByID :one
+SELECT id, type, name, description, avatar_url, created_at, updated_at, last_message_at, disappearing_messages_seconds FROM conversations
+WHERE id = $1
+`
+
+func (q *Queries) GetConversationByID(ctx context.Context, id pgtype.UUID) (Conversation, error) {
+	row := q.db.QueryRow(ctx, getConversationByID, id)
+	var i Conversation
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Name,
+		&i.Description,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastMessageAt,
+		&i.DisappearingMessagesSeconds,
+	)
+	return i, err
+}
+
+const getConversationParticipants = `-- name: GetConversationParticipants :many
+SELECT cp.id, cp.conversation_id, cp.user_id, cp.joined_at, cp.last_read_at, cp.is_active, cp.role, cp.created_at, cp.updated_at, cp.is_archived, cp.muted_until, cp.pin_order, u.id, u.name, u.email, u.avatar_url, u.created_at, u.updated_at, u.last_seen_at, u.last_seen_privacy, u.about, u.name_edited_at, u.avatar_edited_at
+FROM conversation_participants cp
+JOIN users u ON cp.user_id = u.id
+WHERE cp.conversation_id = $1
+ORDER BY cp.is_active DESC, cp.joined_at ASC, cp.id
+LIMIT $3 OFFSET $2
+`
 
+type GetConversationParticipantsParams struct {
+	ConversationID pgtype.UUID
+	OffsetCount    int32
+	LimitCount     int32
+}
+
+type GetConversationParticipantsRow struct {
+	ConversationParticipant ConversationParticipant
+	User                    User
+}
+
+// active participants first, the ones that left the conversation are listed after them
+func (q *Queries) GetConversationParticipants(ctx context.Context, arg GetConversationParticipantsParams) ([]GetConversationParticipantsRow, error) {
+	rows, err := q.db.Query(ctx, getConversationParticipants, arg.ConversationID, arg.OffsetCount, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetConversationParticipantsRow
+	for rows.Next() {
+		var i GetConversationParticipantsRow
+		if err := rows.Scan(
+			&i.ConversationParticipant.ID,
+			&i.ConversationParticipant.ConversationID,
+			&i.ConversationParticipant.UserID,
+			&i.ConversationParticipant.JoinedAt,
+			&i.ConversationParticipant.LastReadAt,
+			&i.ConversationParticipant.IsActive,
+			&i.ConversationParticipant.Role,
+			&i.ConversationParticipant.CreatedAt,
+			&i.ConversationParticipant.UpdatedAt,
+			&i.ConversationParticipant.IsArchived,
+			&i.ConversationParticipant.MutedUntil,
+			&i.ConversationParticipant.PinOrder,
+			&i.User.ID,
+			&i.User.Name,
+			&i.User.Email,
+			&i.User.AvatarUrl,
+			&i.User.CreatedAt,
+			&i.User.UpdatedAt,
+			&i.User.LastSeenAt,
+			&i.User.LastSeenPrivacy,
+			&i.User.About,
+			&i.User.NameEditedAt,
+			&i.User.AvatarEditedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLastMessage = `-- name: GetLastMessage :many
 WITH ranked_messages AS (
     SELECT
         m.id,
@@ -87,6 +175,9 @@ WITH ranked_messages AS (
         m.edited_at,
         m.delivered_at,
         m.read_at,
+        m.is_forwarded,
+        m.forward_count,
+        m.expires_at,
         sender.name as sender_name,
         sender.avatar_url as sender_avatar_url,
         sender.email as sender_email,
@@ -96,6 +187,7 @@ WITH ranked_messages AS (
     FROM messages m
     JOIN users sender ON m.sender_id = sender.id
     WHERE m.conversation_id = ANY($1::uuid[])
+        AND (m.expires_at IS NULL OR m.expires_at > CURRENT_TIMESTAMP)
 )
 SELECT
     id,
@@ -109,6 +201,9 @@ SELECT
     created_at,
     delivered_at,
     read_at,
+    is_forwarded,
+    forward_count,
+    expires_at,
     sender_name,
     sender_avatar_url,
     sender_email,
@@ -131,6 +226,9 @@ type GetLastMessageRow struct {
 	CreatedAt        pgtype.Timestamptz
 	DeliveredAt      pgtype.Timestamptz
 	ReadAt           pgtype.Timestamptz
+	IsForwarded      bool
+	ForwardCount     int32
+	ExpiresAt        pgtype.Timestamptz
 	SenderName       pgtype.Text
 	SenderAvatarUrl  pgtype.Text
 	SenderEmail      string
@@ -138,26 +236,6 @@ type GetLastMessageRow struct {
 	SenderUpdatedAt  pgtype.Timestamptz
 }
 
-// -- name: GetConversationParticipants :many
-// SELECT
-//
-//	cp.id,
-//	cp.conversation_id,
-//	cp.user_id,
-//	cp.joined_at,
-//	cp.last_read_at,
-//	cp.is_active,
-//	u.id as user_id,
-//	u.name as user_name,
-//	u.avatar_url as user_avatar_url
-//
-// FROM conversation_participants cp
-// JOIN users u ON cp.user_id = u.id
-// WHERE cp.conversation_id = ANY($1::uuid[])
-//
-//	AND cp.is_active = true
-//
-// ORDER BY cp.joined_at ASC;
 func (q *Queries) GetLastMessage(ctx context.Context, dollar_1 []pgtype.UUID) ([]GetLastMessageRow, error) {
 	rows, err := q.db.Query(ctx, getLastMessage, dollar_1)
 	if err != nil {
@@ -179,6 +257,9 @@ func (q *Queries) GetLastMessage(ctx context.Context, dollar_1 []pgtype.UUID) ([
 			&i.CreatedAt,
 			&i.DeliveredAt,
 			&i.ReadAt,
+			&i.IsForwarded,
+			&i.ForwardCount,
+			&i.ExpiresAt,
 			&i.SenderName,
 			&i.SenderAvatarUrl,
 			&i.SenderEmail,
@@ -199,33 +280,65 @@ const getUserConversations = `-- name: GetUserConversations :many
 SELECT
     c.id,
     c.type,
+    c.name,
+    c.avatar_url,
     c.last_message_at,
     c.created_at,
     c.updated_at,
+    (
+        SELECT COUNT(*)::INTEGER
+        FROM conversation_participants members
+        WHERE members.conversation_id = c.id
+            AND members.is_active = true
+    ) as participant_count,
     (
         SELECT COUNT(*)::INTEGER
         FROM messages unread_m
         WHERE unread_m.conversation_id = c.id
             AND unread_m.created_at > cp.last_read_at
             AND unread_m.sender_id != $1
-    ) as unread_count
+            AND (unread_m.expires_at IS NULL OR unread_m.expires_at > CURRENT_TIMESTAMP)
+    ) as unread_count,
+    draft.content as draft_content,
+    draft.reply_to_message_id as draft_reply_to_message_id,
+    draft.updated_at as draft_updated_at,
+    cp.is_archived,
+    cp.muted_until,
+    cp.pin_order
 FROM conversations c
 JOIN conversation_participants cp ON c.id = cp.conversation_id
+LEFT JOIN message_drafts draft ON draft.conversation_id = c.id AND draft.user_id = cp.user_id
 WHERE cp.user_id = $1 AND cp.is_active = true
-ORDER BY c.last_message_at DESC NULLS LAST
+    AND ($2::BOOLEAN OR cp.is_archived = false)
+ORDER BY cp.pin_order DESC NULLS LAST, c.last_message_at DESC NULLS LAST
 `
 
+type GetUserConversationsParams struct {
+	UserID          pgtype.UUID
+	IncludeArchived bool
+}
+
 type GetUserConversationsRow struct {
-	ID            pgtype.UUID
-	Type          string
-	LastMessageAt pgtype.Timestamptz
-	CreatedAt     pgtype.Timestamptz
-	UpdatedAt     pgtype.Timestamptz
-	UnreadCount   int32
+	ID                    pgtype.UUID
+	Type                  string
+	Name                  pgtype.Text
+	AvatarUrl             pgtype.Text
+	LastMessageAt         pgtype.Timestamptz
+	CreatedAt             pgtype.Timestamptz
+	UpdatedAt             pgtype.Timestamptz
+	ParticipantCount      int32
+	UnreadCount           int32
+	DraftContent          pgtype.Text
+	DraftReplyToMessageID pgtype.UUID
+	DraftUpdatedAt        pgtype.Timestamptz
+	IsArchived            bool
+	MutedUntil            pgtype.Timestamptz
+	PinOrder              pgtype.Int4
 }
 
-func (q *Queries) GetUserConversations(ctx context.Context, senderID pgtype.UUID) ([]GetUserConversationsRow, error) {
-	rows, err := q.db.Query(ctx, getUserConversations, senderID)
+// pinned conversations go first, the archived ones are only returned when they are requested
+func (q *Queries) GetUserConversations(ctx context.Context, arg GetUserConversationsParams) ([]GetUserConversationsRow, error) {
+	rows, err := q.db.Query(ctx, getUserConversations, arg.UserID, arg.IncludeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -236,10 +349,19 @@ func (q *Queries) GetUserConversations(ctx context.Context, senderID pgtype.UUID
 		if err := rows.Scan(
 			&i.ID,
 			&i.Type,
+			&i.Name,
+			&i.AvatarUrl,
 			&i.LastMessageAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ParticipantCount,
 			&i.UnreadCount,
+			&i.DraftContent,
+			&i.DraftReplyToMessageID,
+			&i.DraftUpdatedAt,
+			&i.IsArchived,
+			&i.MutedUntil,
+			&i.PinOrder,
 		); err != nil {
 			return nil, err
 		}
@@ -251,6 +373,49 @@ func (q *Queries) GetUserConversations(ctx context.Context, senderID pgtype.UUID
 	return items, nil
 }
 
+const lockConversation = `-- name: LockConversation :exec
+SELECT id FROM conversations
+WHERE id = $1
+FOR UPDATE
+`
+
+// serializes the changes that check a limit of the conversation before writing
+func (q *Queries) LockConversation(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, lockConversation, id)
+	return err
+}
+
+const updateConversationDisappearingMessages = `-- name: UpdateConversationDisappearingMessages :one
+UPDATE conversations
+SET
+    disappearing_messages_seconds = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, type, name, description, avatar_url, created_at, updated_at, last_message_at, disappearing_messages_seconds
+`
+
+type UpdateConversationDisappearingMessagesParams struct {
+	ID                          pgtype.UUID
+	DisappearingMessagesSeconds int32
+}
+
+func (q *Queries) UpdateConversationDisappearingMessages(ctx context.Context, arg UpdateConversationDisappearingMessagesParams) (Conversation, error) {
+	row := q.db.QueryRow(ctx, updateConversationDisappearingMessages, arg.ID, arg.DisappearingMessagesSeconds)
+	var i Conversation
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Name,
+		&i.Description,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastMessageAt,
+		&i.DisappearingMessagesSeconds,
+	)
+	return i, err
+}
+
 const updateConversationLastMessageAt = `-- name: UpdateConversationLastMessageAt :exec
 UPDATE conversations
 SET
@@ -9,6 +9,41 @@ import (
 	"context"
 )
 
+// iteratorForCreateMessageMentions implements pgx.CopyFromSource.
+type iteratorForCreateMessageMentions struct {
+	rows                 []CreateMessageMentionsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCreateMessageMentions) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCreateMessageMentions) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].MessageID,
+		r.rows[0].MentionedUserID,
+		r.rows[0].OffsetStart,
+		r.rows[0].Length,
+	}, nil
+}
+
+func (r iteratorForCreateMessageMentions) Err() error {
+	return nil
+}
+
+func (q *Queries) CreateMessageMentions(ctx context.Context, arg []CreateMessageMentionsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"message_mentions"}, []string{"message_id", "mentioned_user_id", "offset_start", "length"}, &iteratorForCreateMessageMentions{rows: arg})
+}
+
 // iteratorForCreateParticipants implements pgx.CopyFromSource.
 type iteratorForCreateParticipants struct {
 	rows                 []CreateParticipantsParams
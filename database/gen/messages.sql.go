@@ -11,6 +11,44 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countMessageReplies = `-- name: CountMessageReplies :many
+SELECT
+    m.reply_to_message_id::uuid as message_id,
+    COUNT(*)::INTEGER as reply_count
+FROM messages m
+JOIN messages parent ON parent.id = m.reply_to_message_id
+WHERE m.reply_to_message_id = ANY($1::uuid[])
+    AND m.conversation_id = parent.conversation_id
+    AND m.is_deleted = false
+    AND (m.expires_at IS NULL OR m.expires_at > CURRENT_TIMESTAMP)
+GROUP BY m.reply_to_message_id
+`
+
+type CountMessageRepliesRow struct {
+	MessageID  pgtype.UUID
+	ReplyCount int32
+}
+
+func (q *Queries) CountMessageReplies(ctx context.Context, messageIds []pgtype.UUID) ([]CountMessageRepliesRow, error) {
+	rows, err := q.db.Query(ctx, countMessageReplies, messageIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountMessageRepliesRow
+	for rows.Next() {
+		var i CountMessageRepliesRow
+		if err := rows.Scan(&i.MessageID, &i.ReplyCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createMessage = `-- name: CreateMessage :one
 INSERT INTO messages (
     conversation_id,
@@ -18,10 +56,19 @@ INSERT INTO messages (
     content,
     message_type,
     reply_to_message_id,
-    status
+    status,
+    expires_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
-) RETURNING id, conversation_id, sender_id, content, message_type, status, reply_to_message_id, media_url, media_filename, media_size, media_mime_type, location_latitude, location_longitude, location_address, is_deleted, created_at, edited_at, deleted_at, delivered_at, read_at
+    $1, $2, $3, $4, $5, $6,
+    (
+        SELECT CASE
+            WHEN c.disappearing_messages_seconds > 0
+            THEN CURRENT_TIMESTAMP + make_interval(secs => c.disappearing_messages_seconds)
+        END
+        FROM conversations c
+        WHERE c.id = $1
+    )
+) RETURNING id, conversation_id, sender_id, content, message_type, status, reply_to_message_id, media_url, media_filename, media_size, media_mime_type, location_latitude, location_longitude, location_address, is_deleted, created_at, edited_at, deleted_at, delivered_at, read_at, is_forwarded, forward_count, forwarded_from_message_id, expires_at
 `
 
 type CreateMessageParams struct {
@@ -64,13 +111,148 @@ func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (M
 		&i.DeletedAt,
 		&i.DeliveredAt,
 		&i.ReadAt,
+		&i.IsForwarded,
+		&i.ForwardCount,
+		&i.ForwardedFromMessageID,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const deleteExpiredMessages = `-- name: DeleteExpiredMessages :many
+DELETE FROM messages
+WHERE id IN (
+    SELECT expired.id
+    FROM messages expired
+    WHERE expired.expires_at <= CURRENT_TIMESTAMP
+    ORDER BY expired.expires_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, conversation_id
+`
+
+type DeleteExpiredMessagesRow struct {
+	ID             pgtype.UUID
+	ConversationID pgtype.UUID
+}
+
+// SKIP LOCKED lets several instances run the reaper at the same time without waiting for each other
+func (q *Queries) DeleteExpiredMessages(ctx context.Context, limit int32) ([]DeleteExpiredMessagesRow, error) {
+	rows, err := q.db.Query(ctx, deleteExpiredMessages, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeleteExpiredMessagesRow
+	for rows.Next() {
+		var i DeleteExpiredMessagesRow
+		if err := rows.Scan(&i.ID, &i.ConversationID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const forwardMessage = `-- name: ForwardMessage :one
+INSERT INTO messages (
+    conversation_id,
+    sender_id,
+    content,
+    message_type,
+    status,
+    media_url,
+    media_filename,
+    media_size,
+    media_mime_type,
+    location_latitude,
+    location_longitude,
+    location_address,
+    is_forwarded,
+    forward_count,
+    forwarded_from_message_id,
+    expires_at
+)
+SELECT
+    $1::uuid,
+    $2::uuid,
+    src.content,
+    src.message_type,
+    $3::VARCHAR,
+    src.media_url,
+    src.media_filename,
+    src.media_size,
+    src.media_mime_type,
+    src.location_latitude,
+    src.location_longitude,
+    src.location_address,
+    true,
+    src.forward_count + 1,
+    src.id,
+    -- the copy follows the timer of the target conversation
+    CASE
+        WHEN target.disappearing_messages_seconds > 0
+        THEN CURRENT_TIMESTAMP + make_interval(secs => target.disappearing_messages_seconds)
+    END
+FROM messages src
+JOIN conversations target ON target.id = $1::uuid
+WHERE src.id = $4::uuid
+    AND src.is_deleted = false
+    AND (src.expires_at IS NULL OR src.expires_at > CURRENT_TIMESTAMP)
+RETURNING id, conversation_id, sender_id, content, message_type, status, reply_to_message_id, media_url, media_filename, media_size, media_mime_type, location_latitude, location_longitude, location_address, is_deleted, created_at, edited_at, deleted_at, delivered_at, read_at, is_forwarded, forward_count, forwarded_from_message_id, expires_at
+`
+
+type ForwardMessageParams struct {
+	ConversationID  pgtype.UUID
+	SenderID        pgtype.UUID
+	Status          string
+	SourceMessageID pgtype.UUID
+}
+
+func (q *Queries) ForwardMessage(ctx context.Context, arg ForwardMessageParams) (Message, error) {
+	row := q.db.QueryRow(ctx, forwardMessage,
+		arg.ConversationID,
+		arg.SenderID,
+		arg.Status,
+		arg.SourceMessageID,
+	)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.ConversationID,
+		&i.SenderID,
+		&i.Content,
+		&i.MessageType,
+		&i.Status,
+		&i.ReplyToMessageID,
+		&i.MediaUrl,
+		&i.MediaFilename,
+		&i.MediaSize,
+		&i.MediaMimeType,
+		&i.LocationLatitude,
+		&i.LocationLongitude,
+		&i.LocationAddress,
+		&i.IsDeleted,
+		&i.CreatedAt,
+		&i.EditedAt,
+		&i.DeletedAt,
+		&i.DeliveredAt,
+		&i.ReadAt,
+		&i.IsForwarded,
+		&i.ForwardCount,
+		&i.ForwardedFromMessageID,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
 
 const getConversationMessages = `-- name: GetConversationMessages :many
 SELECT
-    m.id, m.conversation_id, m.sender_id, m.content, m.message_type, m.status, m.reply_to_message_id, m.media_url, m.media_filename, m.media_size, m.media_mime_type, m.location_latitude, m.location_longitude, m.location_address, m.is_deleted, m.created_at, m.edited_at, m.deleted_at, m.delivered_at, m.read_at,
+    m.id, m.conversation_id, m.sender_id, m.content, m.message_type, m.status, m.reply_to_message_id, m.media_url, m.media_filename, m.media_size, m.media_mime_type, m.location_latitude, m.location_longitude, m.location_address, m.is_deleted, m.created_at, m.edited_at, m.deleted_at, m.delivered_at, m.read_at, m.is_forwarded, m.forward_count, m.forwarded_from_message_id, m.expires_at,
     sender.id as sender_id,
     sender.name as sender_name,
     sender.email as sender_email,
@@ -86,6 +268,8 @@ JOIN users sender ON m.sender_id = sender.id
 LEFT JOIN messages reply_msg ON m.reply_to_message_id = reply_msg.id
 LEFT JOIN users reply_sender ON reply_msg.sender_id = reply_sender.id
 WHERE m.conversation_id = $1
+    -- the expired messages can still be there until the reaper removes them
+    AND (m.expires_at IS NULL OR m.expires_at > CURRENT_TIMESTAMP)
 ORDER BY m.created_at DESC
 LIMIT $2 OFFSET $3
 `
@@ -97,36 +281,40 @@ type GetConversationMessagesParams struct {
 }
 
 type GetConversationMessagesRow struct {
-	ID                pgtype.UUID
-	ConversationID    pgtype.UUID
-	SenderID          pgtype.UUID
-	Content           string
-	MessageType       string
-	Status            string
-	ReplyToMessageID  pgtype.UUID
-	MediaUrl          pgtype.Text
-	MediaFilename     pgtype.Text
-	MediaSize         pgtype.Int8
-	MediaMimeType     pgtype.Text
-	LocationLatitude  pgtype.Numeric
-	LocationLongitude pgtype.Numeric
-	LocationAddress   pgtype.Text
-	IsDeleted         pgtype.Bool
-	CreatedAt         pgtype.Timestamptz
-	EditedAt          pgtype.Timestamptz
-	DeletedAt         pgtype.Timestamptz
-	DeliveredAt       pgtype.Timestamptz
-	ReadAt            pgtype.Timestamptz
-	SenderID_2        pgtype.UUID
-	SenderName        pgtype.Text
-	SenderEmail       string
-	SenderAvatarUrl   pgtype.Text
-	SenderCreatedAt   pgtype.Timestamptz
-	SenderUpdatedAt   pgtype.Timestamptz
-	ReplyID           pgtype.UUID
-	ReplyContent      pgtype.Text
-	ReplyMessageType  pgtype.Text
-	ReplySenderName   pgtype.Text
+	ID                     pgtype.UUID
+	ConversationID         pgtype.UUID
+	SenderID               pgtype.UUID
+	Content                string
+	MessageType            string
+	Status                 string
+	ReplyToMessageID       pgtype.UUID
+	MediaUrl               pgtype.Text
+	MediaFilename          pgtype.Text
+	MediaSize              pgtype.Int8
+	MediaMimeType          pgtype.Text
+	LocationLatitude       pgtype.Numeric
+	LocationLongitude      pgtype.Numeric
+	LocationAddress        pgtype.Text
+	IsDeleted              pgtype.Bool
+	CreatedAt              pgtype.Timestamptz
+	EditedAt               pgtype.Timestamptz
+	DeletedAt              pgtype.Timestamptz
+	DeliveredAt            pgtype.Timestamptz
+	ReadAt                 pgtype.Timestamptz
+	IsForwarded            bool
+	ForwardCount           int32
+	ForwardedFromMessageID pgtype.UUID
+	ExpiresAt              pgtype.Timestamptz
+	SenderID_2             pgtype.UUID
+	SenderName             pgtype.Text
+	SenderEmail            string
+	SenderAvatarUrl        pgtype.Text
+	SenderCreatedAt        pgtype.Timestamptz
+	SenderUpdatedAt        pgtype.Timestamptz
+	ReplyID                pgtype.UUID
+	ReplyContent           pgtype.Text
+	ReplyMessageType       pgtype.Text
+	ReplySenderName        pgtype.Text
 }
 
 func (q *Queries) GetConversationMessages(ctx context.Context, arg GetConversationMessagesParams) ([]GetConversationMessagesRow, error) {
@@ -159,6 +347,10 @@ func (q *Queries) GetConversationMessages(ctx context.Context, arg GetConversati
 			&i.DeletedAt,
 			&i.DeliveredAt,
 			&i.ReadAt,
+			&i.IsForwarded,
+			&i.ForwardCount,
+			&i.ForwardedFromMessageID,
+			&i.ExpiresAt,
 			&i.SenderID_2,
 			&i.SenderName,
 			&i.SenderEmail,
@@ -179,3 +371,232 @@ func (q *Queries) GetConversationMessages(ctx context.Context, arg GetConversati
 	}
 	return items, nil
 }
+
+const getMessageByID = `-- name: GetMessageByID :one
+SELECT id, conversation_id, sender_id, content, message_type, status, reply_to_message_id, media_url, media_filename, media_size, media_mime_type, location_latitude, location_longitude, location_address, is_deleted, created_at, edited_at, deleted_at, delivered_at, read_at, is_forwarded, forward_count, forwarded_from_message_id, expires_at FROM messages
+WHERE id = $1
+    AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+`
+
+// an expired message is not returned even before the reaper removes it
+func (q *Queries) GetMessageByID(ctx context.Context, id pgtype.UUID) (Message, error) {
+	row := q.db.QueryRow(ctx, getMessageByID, id)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.ConversationID,
+		&i.SenderID,
+		&i.Content,
+		&i.MessageType,
+		&i.Status,
+		&i.ReplyToMessageID,
+		&i.MediaUrl,
+		&i.MediaFilename,
+		&i.MediaSize,
+		&i.MediaMimeType,
+		&i.LocationLatitude,
+		&i.LocationLongitude,
+		&i.LocationAddress,
+		&i.IsDeleted,
+		&i.CreatedAt,
+		&i.EditedAt,
+		&i.DeletedAt,
+		&i.DeliveredAt,
+		&i.ReadAt,
+		&i.IsForwarded,
+		&i.ForwardCount,
+		&i.ForwardedFromMessageID,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getMessageReplies = `-- name: GetMessageReplies :many
+SELECT
+    m.id, m.conversation_id, m.sender_id, m.content, m.message_type, m.status, m.reply_to_message_id, m.media_url, m.media_filename, m.media_size, m.media_mime_type, m.location_latitude, m.location_longitude, m.location_address, m.is_deleted, m.created_at, m.edited_at, m.deleted_at, m.delivered_at, m.read_at, m.is_forwarded, m.forward_count, m.forwarded_from_message_id, m.expires_at,
+    sender.id, sender.name, sender.email, sender.avatar_url, sender.created_at, sender.updated_at, sender.last_seen_at, sender.last_seen_privacy, sender.about, sender.name_edited_at, sender.avatar_edited_at
+FROM messages m
+JOIN users sender ON m.sender_id = sender.id
+JOIN messages parent ON parent.id = m.reply_to_message_id
+WHERE m.reply_to_message_id = $1
+    AND m.conversation_id = parent.conversation_id
+    AND m.is_deleted = false
+    AND (m.expires_at IS NULL OR m.expires_at > CURRENT_TIMESTAMP)
+ORDER BY m.created_at ASC
+LIMIT $2 OFFSET $3
+`
+
+type GetMessageRepliesParams struct {
+	ReplyToMessageID pgtype.UUID
+	Limit            int32
+	Offset           int32
+}
+
+type GetMessageRepliesRow struct {
+	Message Message
+	User    User
+}
+
+func (q *Queries) GetMessageReplies(ctx context.Context, arg GetMessageRepliesParams) ([]GetMessageRepliesRow, error) {
+	rows, err := q.db.Query(ctx, getMessageReplies, arg.ReplyToMessageID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMessageRepliesRow
+	for rows.Next() {
+		var i GetMessageRepliesRow
+		if err := rows.Scan(
+			&i.Message.ID,
+			&i.Message.ConversationID,
+			&i.Message.SenderID,
+			&i.Message.Content,
+			&i.Message.MessageType,
+			&i.Message.Status,
+			&i.Message.ReplyToMessageID,
+			&i.Message.MediaUrl,
+			&i.Message.MediaFilename,
+			&i.Message.MediaSize,
+			&i.Message.MediaMimeType,
+			&i.Message.LocationLatitude,
+			&i.Message.LocationLongitude,
+			&i.Message.LocationAddress,
+			&i.Message.IsDeleted,
+			&i.Message.CreatedAt,
+			&i.Message.EditedAt,
+			&i.Message.DeletedAt,
+			&i.Message.DeliveredAt,
+			&i.Message.ReadAt,
+			&i.Message.IsForwarded,
+			&i.Message.ForwardCount,
+			&i.Message.ForwardedFromMessageID,
+			&i.Message.ExpiresAt,
+			&i.User.ID,
+			&i.User.Name,
+			&i.User.Email,
+			&i.User.AvatarUrl,
+			&i.User.CreatedAt,
+			&i.User.UpdatedAt,
+			&i.User.LastSeenAt,
+			&i.User.LastSeenPrivacy,
+			&i.User.About,
+			&i.User.NameEditedAt,
+			&i.User.AvatarEditedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMessageThread = `-- name: GetMessageThread :many
+WITH RECURSIVE thread AS (
+    SELECT
+        root.id,
+        root.conversation_id,
+        0 as depth
+    FROM messages root
+    WHERE root.id = $3::uuid
+    UNION ALL
+    SELECT
+        child.id,
+        child.conversation_id,
+        thread.depth + 1
+    FROM messages child
+    JOIN thread ON child.reply_to_message_id = thread.id
+    WHERE thread.depth < $4::INTEGER
+        AND child.conversation_id = thread.conversation_id
+)
+SELECT
+    m.id, m.conversation_id, m.sender_id, m.content, m.message_type, m.status, m.reply_to_message_id, m.media_url, m.media_filename, m.media_size, m.media_mime_type, m.location_latitude, m.location_longitude, m.location_address, m.is_deleted, m.created_at, m.edited_at, m.deleted_at, m.delivered_at, m.read_at, m.is_forwarded, m.forward_count, m.forwarded_from_message_id, m.expires_at,
+    sender.id, sender.name, sender.email, sender.avatar_url, sender.created_at, sender.updated_at, sender.last_seen_at, sender.last_seen_privacy, sender.about, sender.name_edited_at, sender.avatar_edited_at,
+    thread.depth::INTEGER as depth
+FROM thread
+JOIN messages m ON m.id = thread.id
+JOIN users sender ON m.sender_id = sender.id
+WHERE m.is_deleted = false
+    AND (m.expires_at IS NULL OR m.expires_at > CURRENT_TIMESTAMP)
+ORDER BY m.created_at ASC
+LIMIT $2 OFFSET $1
+`
+
+type GetMessageThreadParams struct {
+	OffsetCount int32
+	LimitCount  int32
+	MessageID   pgtype.UUID
+	MaxDepth    int32
+}
+
+type GetMessageThreadRow struct {
+	Message Message
+	User    User
+	Depth   int32
+}
+
+// the message and all the nested replies, a deleted reply is not returned but its replies are.
+// Only the replies in the conversation of the message are followed
+func (q *Queries) GetMessageThread(ctx context.Context, arg GetMessageThreadParams) ([]GetMessageThreadRow, error) {
+	rows, err := q.db.Query(ctx, getMessageThread,
+		arg.OffsetCount,
+		arg.LimitCount,
+		arg.MessageID,
+		arg.MaxDepth,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMessageThreadRow
+	for rows.Next() {
+		var i GetMessageThreadRow
+		if err := rows.Scan(
+			&i.Message.ID,
+			&i.Message.ConversationID,
+			&i.Message.SenderID,
+			&i.Message.Content,
+			&i.Message.MessageType,
+			&i.Message.Status,
+			&i.Message.ReplyToMessageID,
+			&i.Message.MediaUrl,
+			&i.Message.MediaFilename,
+			&i.Message.MediaSize,
+			&i.Message.MediaMimeType,
+			&i.Message.LocationLatitude,
+			&i.Message.LocationLongitude,
+			&i.Message.LocationAddress,
+			&i.Message.IsDeleted,
+			&i.Message.CreatedAt,
+			&i.Message.EditedAt,
+			&i.Message.DeletedAt,
+			&i.Message.DeliveredAt,
+			&i.Message.ReadAt,
+			&i.Message.IsForwarded,
+			&i.Message.ForwardCount,
+			&i.Message.ForwardedFromMessageID,
+			&i.Message.ExpiresAt,
+			&i.User.ID,
+			&i.User.Name,
+			&i.User.Email,
+			&i.User.AvatarUrl,
+			&i.User.CreatedAt,
+			&i.User.UpdatedAt,
+			&i.User.LastSeenAt,
+			&i.User.LastSeenPrivacy,
+			&i.User.About,
+			&i.User.NameEditedAt,
+			&i.User.AvatarEditedAt,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -8,15 +8,39 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type Conversation struct {
-	ID            pgtype.UUID
-	Type          string
-	Name          pgtype.Text
-	Description   pgtype.Text
-	AvatarUrl     pgtype.Text
+type AccountExport struct {
+	ID          pgtype.UUID
+	UserID      pgtype.UUID
+	Status      string
+	CreatedAt   pgtype.Timestamptz
+	StartedAt   pgtype.Timestamptz
+	CompletedAt pgtype.Timestamptz
+	ExpiresAt   pgtype.Timestamptz
+}
+
+type AccountExportArchive struct {
+	ExportID pgtype.UUID
+	Data     []byte
+}
+
+type AuthCode struct {
+	ID            string
+	UserID        pgtype.UUID
+	CodeChallenge string
+	ExpiresAt     pgtype.Timestamptz
 	CreatedAt     pgtype.Timestamptz
-	UpdatedAt     pgtype.Timestamptz
-	LastMessageAt pgtype.Timestamptz
+}
+
+type Conversation struct {
+	ID                          pgtype.UUID
+	Type                        string
+	Name                        pgtype.Text
+	Description                 pgtype.Text
+	AvatarUrl                   pgtype.Text
+	CreatedAt                   pgtype.Timestamptz
+	UpdatedAt                   pgtype.Timestamptz
+	LastMessageAt               pgtype.Timestamptz
+	DisappearingMessagesSeconds int32
 }
 
 type ConversationParticipant struct {
@@ -29,37 +53,178 @@ type ConversationParticipant struct {
 	Role           string
 	CreatedAt      pgtype.Timestamptz
 	UpdatedAt      pgtype.Timestamptz
+	IsArchived     bool
+	MutedUntil     pgtype.Timestamptz
+	PinOrder       pgtype.Int4
+}
+
+type EmailLogin struct {
+	ID            pgtype.UUID
+	Email         string
+	CodeHash      string
+	LinkTokenHash string
+	ClientType    string
+	Attempts      int32
+	ExpiresAt     pgtype.Timestamptz
+	UsedAt        pgtype.Timestamptz
+	CreatedAt     pgtype.Timestamptz
+	CodeChallenge pgtype.Text
 }
 
 type Message struct {
-	ID                pgtype.UUID
-	ConversationID    pgtype.UUID
-	SenderID          pgtype.UUID
-	Content           string
-	MessageType       string
-	Status            string
-	ReplyToMessageID  pgtype.UUID
-	MediaUrl          pgtype.Text
-	MediaFilename     pgtype.Text
-	MediaSize         pgtype.Int8
-	MediaMimeType     pgtype.Text
-	LocationLatitude  pgtype.Numeric
-	LocationLongitude pgtype.Numeric
-	LocationAddress   pgtype.Text
-	IsDeleted         pgtype.Bool
-	CreatedAt         pgtype.Timestamptz
-	EditedAt          pgtype.Timestamptz
-	DeletedAt         pgtype.Timestamptz
-	DeliveredAt       pgtype.Timestamptz
-	ReadAt            pgtype.Timestamptz
+	ID                     pgtype.UUID
+	ConversationID         pgtype.UUID
+	SenderID               pgtype.UUID
+	Content                string
+	MessageType            string
+	Status                 string
+	ReplyToMessageID       pgtype.UUID
+	MediaUrl               pgtype.Text
+	MediaFilename          pgtype.Text
+	MediaSize              pgtype.Int8
+	MediaMimeType          pgtype.Text
+	LocationLatitude       pgtype.Numeric
+	LocationLongitude      pgtype.Numeric
+	LocationAddress        pgtype.Text
+	IsDeleted              pgtype.Bool
+	CreatedAt              pgtype.Timestamptz
+	EditedAt               pgtype.Timestamptz
+	DeletedAt              pgtype.Timestamptz
+	DeliveredAt            pgtype.Timestamptz
+	ReadAt                 pgtype.Timestamptz
+	IsForwarded            bool
+	ForwardCount           int32
+	ForwardedFromMessageID pgtype.UUID
+	ExpiresAt              pgtype.Timestamptz
 }
 
-type User struct {
+type MessageDraft struct {
+	ID               pgtype.UUID
+	UserID           pgtype.UUID
+	ConversationID   pgtype.UUID
+	Content          string
+	ReplyToMessageID pgtype.UUID
+	UpdatedAt        pgtype.Timestamptz
+}
+
+type MessageLinkPreview struct {
+	ID          pgtype.UUID
+	MessageID   pgtype.UUID
+	Url         string
+	Title       string
+	Description pgtype.Text
+	ImageUrl    pgtype.Text
+	SiteName    pgtype.Text
+	CreatedAt   pgtype.Timestamptz
+}
+
+type MessageMention struct {
+	ID              pgtype.UUID
+	MessageID       pgtype.UUID
+	MentionedUserID pgtype.UUID
+	OffsetStart     int32
+	Length          int32
+	CreatedAt       pgtype.Timestamptz
+}
+
+type MessageReaction struct {
 	ID        pgtype.UUID
-	Name      pgtype.Text
-	GoogleID  pgtype.Text
-	Email     string
-	AvatarUrl pgtype.Text
+	MessageID pgtype.UUID
+	UserID    pgtype.UUID
+	Emoji     string
 	CreatedAt pgtype.Timestamptz
 	UpdatedAt pgtype.Timestamptz
 }
+
+type OauthState struct {
+	ID               string
+	Provider         string
+	ClientType       string
+	CodeVerifier     string
+	AppCodeChallenge pgtype.Text
+	AppState         pgtype.Text
+	ExpiresAt        pgtype.Timestamptz
+	CreatedAt        pgtype.Timestamptz
+}
+
+type PinnedMessage struct {
+	ID             pgtype.UUID
+	ConversationID pgtype.UUID
+	MessageID      pgtype.UUID
+	PinnedBy       pgtype.UUID
+	PinnedAt       pgtype.Timestamptz
+}
+
+type RateLimitBucket struct {
+	Key       string
+	Tokens    float64
+	Allowed   bool
+	UpdatedAt pgtype.Timestamptz
+}
+
+type RefreshToken struct {
+	ID        pgtype.UUID
+	UserID    pgtype.UUID
+	FamilyID  pgtype.UUID
+	ExpiresAt pgtype.Timestamptz
+	UsedAt    pgtype.Timestamptz
+	RevokedAt pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+}
+
+type ScheduledMessage struct {
+	ID               pgtype.UUID
+	ConversationID   pgtype.UUID
+	SenderID         pgtype.UUID
+	Content          string
+	MessageType      string
+	ReplyToMessageID pgtype.UUID
+	SendAt           pgtype.Timestamptz
+	Status           string
+	ClaimedAt        pgtype.Timestamptz
+	MessageID        pgtype.UUID
+	CreatedAt        pgtype.Timestamptz
+	UpdatedAt        pgtype.Timestamptz
+}
+
+type Session struct {
+	ID         pgtype.UUID
+	UserID     pgtype.UUID
+	ClientType string
+	UserAgent  string
+	IpAddress  string
+	CreatedAt  pgtype.Timestamptz
+	LastUsedAt pgtype.Timestamptz
+	RevokedAt  pgtype.Timestamptz
+}
+
+type StarredMessage struct {
+	ID        pgtype.UUID
+	UserID    pgtype.UUID
+	MessageID pgtype.UUID
+	StarredAt pgtype.Timestamptz
+}
+
+type User struct {
+	ID              pgtype.UUID
+	Name            pgtype.Text
+	Email           string
+	AvatarUrl       pgtype.Text
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+	LastSeenAt      pgtype.Timestamptz
+	LastSeenPrivacy string
+	About           pgtype.Text
+	NameEditedAt    pgtype.Timestamptz
+	AvatarEditedAt  pgtype.Timestamptz
+}
+
+type UserIdentity struct {
+	ID             pgtype.UUID
+	UserID         pgtype.UUID
+	Provider       string
+	ProviderUserID string
+	Email          string
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
@@ -12,7 +12,7 @@ import (
 )
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, name, google_id, email, avatar_url, created_at, updated_at
+SELECT id, name, email, avatar_url, created_at, updated_at, last_seen_at, last_seen_privacy, about, name_edited_at, avatar_edited_at
 FROM users
 WHERE email = $1
 `
@@ -23,84 +23,298 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
-		&i.GoogleID,
 		&i.Email,
 		&i.AvatarUrl,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LastSeenAt,
+		&i.LastSeenPrivacy,
+		&i.About,
+		&i.NameEditedAt,
+		&i.AvatarEditedAt,
 	)
 	return i, err
 }
 
-const getUserByGoogleID = `-- name: GetUserByGoogleID :one
-SELECT id, name, google_id, email, avatar_url, created_at, updated_at
+const getUserContactIDs = `-- name: GetUserContactIDs :many
+SELECT DISTINCT other.user_id
+FROM conversation_participants mine
+JOIN conversations c ON c.id = mine.conversation_id AND c.type = 'DIRECT'
+JOIN conversation_participants other ON other.conversation_id = mine.conversation_id
+    AND other.user_id <> mine.user_id
+    AND other.is_active = true
+WHERE mine.user_id = $1
+    AND mine.is_active = true
+`
+
+// the contacts of a user are the other participants of its direct conversations
+func (q *Queries) GetUserContactIDs(ctx context.Context, userID pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, getUserContactIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var user_id pgtype.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUsersByIDs = `-- name: GetUsersByIDs :many
+SELECT id, name, email, avatar_url, created_at, updated_at, last_seen_at, last_seen_privacy, about, name_edited_at, avatar_edited_at
 FROM users
-WHERE google_id = $1
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) GetUsersByIDs(ctx context.Context, userIds []pgtype.UUID) ([]User, error) {
+	rows, err := q.db.Query(ctx, getUsersByIDs, userIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.AvatarUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastSeenAt,
+			&i.LastSeenPrivacy,
+			&i.About,
+			&i.NameEditedAt,
+			&i.AvatarEditedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockUser = `-- name: LockUser :exec
+SELECT id FROM users
+WHERE id = $1
+FOR NO KEY UPDATE
+`
+
+// serializes the changes that check a limit of the user before writing, NO KEY doesn't block
+// the rows that reference the user
+func (q *Queries) LockUser(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, lockUser, id)
+	return err
+}
+
+const removeAllUsers = `-- name: RemoveAllUsers :exec
+DELETE FROM users
+WHERE email NOT LIKE '%@gmail.com'
+    AND id <> '00000000-0000-0000-0000-000000000000'
+`
+
+// delete all except the one with email ending in @gmail.com and the sender of the deleted accounts
+func (q *Queries) RemoveAllUsers(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, removeAllUsers)
+	return err
+}
+
+const updateUserFromProvider = `-- name: UpdateUserFromProvider :one
+UPDATE users
+SET
+    name = CASE
+        WHEN users.name_edited_at IS NOT NULL THEN users.name
+        WHEN users.name IS NULL OR users.name = '' THEN $1
+        ELSE COALESCE($1, users.name)
+    END,
+    avatar_url = CASE
+        WHEN users.avatar_edited_at IS NOT NULL THEN users.avatar_url
+        ELSE COALESCE($2, users.avatar_url)
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $3
+RETURNING id, name, email, avatar_url, created_at, updated_at, last_seen_at, last_seen_privacy, about, name_edited_at, avatar_edited_at
 `
 
-func (q *Queries) GetUserByGoogleID(ctx context.Context, googleID pgtype.Text) (User, error) {
-	row := q.db.QueryRow(ctx, getUserByGoogleID, googleID)
+type UpdateUserFromProviderParams struct {
+	Name      pgtype.Text
+	AvatarUrl pgtype.Text
+	ID        pgtype.UUID
+}
+
+// updates the profile with the data of the provider the user logged in with, the name and the
+// avatar edited by the user are kept
+func (q *Queries) UpdateUserFromProvider(ctx context.Context, arg UpdateUserFromProviderParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserFromProvider, arg.Name, arg.AvatarUrl, arg.ID)
 	var i User
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
-		&i.GoogleID,
 		&i.Email,
 		&i.AvatarUrl,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LastSeenAt,
+		&i.LastSeenPrivacy,
+		&i.About,
+		&i.NameEditedAt,
+		&i.AvatarEditedAt,
 	)
 	return i, err
 }
 
-const removeAllUsers = `-- name: RemoveAllUsers :exec
-DELETE FROM users
-WHERE email NOT LIKE '%@gmail.com'
+const updateUserLastSeen = `-- name: UpdateUserLastSeen :exec
+UPDATE users
+SET last_seen_at = $1
+WHERE id = $2
 `
 
-// delete all except the one with email ending in @gmail.com
-func (q *Queries) RemoveAllUsers(ctx context.Context) error {
-	_, err := q.db.Exec(ctx, removeAllUsers)
+type UpdateUserLastSeenParams struct {
+	LastSeenAt pgtype.Timestamptz
+	ID         pgtype.UUID
+}
+
+func (q *Queries) UpdateUserLastSeen(ctx context.Context, arg UpdateUserLastSeenParams) error {
+	_, err := q.db.Exec(ctx, updateUserLastSeen, arg.LastSeenAt, arg.ID)
 	return err
 }
 
-const upsertUserByGoogleAuthSafe = `-- name: UpsertUserByGoogleAuthSafe :one
-INSERT INTO users (name, google_id, email, avatar_url, updated_at)
-VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+const updateUserLastSeenPrivacy = `-- name: UpdateUserLastSeenPrivacy :one
+UPDATE users
+SET
+    last_seen_privacy = $1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+RETURNING id, name, email, avatar_url, created_at, updated_at, last_seen_at, last_seen_privacy, about, name_edited_at, avatar_edited_at
+`
+
+type UpdateUserLastSeenPrivacyParams struct {
+	LastSeenPrivacy string
+	ID              pgtype.UUID
+}
+
+func (q *Queries) UpdateUserLastSeenPrivacy(ctx context.Context, arg UpdateUserLastSeenPrivacyParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserLastSeenPrivacy, arg.LastSeenPrivacy, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastSeenAt,
+		&i.LastSeenPrivacy,
+		&i.About,
+		&i.NameEditedAt,
+		&i.AvatarEditedAt,
+	)
+	return i, err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+SET
+    name = CASE WHEN $1::bool THEN $2 ELSE users.name END,
+    name_edited_at = CASE WHEN $1::bool THEN CURRENT_TIMESTAMP ELSE users.name_edited_at END,
+    about = CASE WHEN $3::bool THEN $4 ELSE users.about END,
+    avatar_url = CASE WHEN $5::bool THEN $6 ELSE users.avatar_url END,
+    avatar_edited_at = CASE WHEN $5::bool THEN CURRENT_TIMESTAMP ELSE users.avatar_edited_at END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $7
+RETURNING id, name, email, avatar_url, created_at, updated_at, last_seen_at, last_seen_privacy, about, name_edited_at, avatar_edited_at
+`
+
+type UpdateUserProfileParams struct {
+	SetName      bool
+	Name         pgtype.Text
+	SetAbout     bool
+	About        pgtype.Text
+	SetAvatarUrl bool
+	AvatarUrl    pgtype.Text
+	ID           pgtype.UUID
+}
+
+// only the fields with set_* are updated, so the concurrent edits of different fields don't
+// overwrite each other
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserProfile,
+		arg.SetName,
+		arg.Name,
+		arg.SetAbout,
+		arg.About,
+		arg.SetAvatarUrl,
+		arg.AvatarUrl,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastSeenAt,
+		&i.LastSeenPrivacy,
+		&i.About,
+		&i.NameEditedAt,
+		&i.AvatarEditedAt,
+	)
+	return i, err
+}
+
+const upsertUserByEmail = `-- name: UpsertUserByEmail :one
+INSERT INTO users (name, email, avatar_url, updated_at)
+VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
 ON CONFLICT (email) DO UPDATE SET
     name = CASE
+        WHEN users.name_edited_at IS NOT NULL THEN users.name
         WHEN users.name IS NULL OR users.name = '' THEN EXCLUDED.name
         ELSE COALESCE(EXCLUDED.name, users.name)
     END,
-    google_id = COALESCE(users.google_id, EXCLUDED.google_id),
-    avatar_url = COALESCE(EXCLUDED.avatar_url, users.avatar_url),
+    avatar_url = CASE
+        WHEN users.avatar_edited_at IS NOT NULL THEN users.avatar_url
+        ELSE COALESCE(EXCLUDED.avatar_url, users.avatar_url)
+    END,
     updated_at = CURRENT_TIMESTAMP
-RETURNING id, name, google_id, email, avatar_url, created_at, updated_at
+RETURNING id, name, email, avatar_url, created_at, updated_at, last_seen_at, last_seen_privacy, about, name_edited_at, avatar_edited_at
 `
 
-type UpsertUserByGoogleAuthSafeParams struct {
+type UpsertUserByEmailParams struct {
 	Name      pgtype.Text
-	GoogleID  pgtype.Text
 	Email     string
 	AvatarUrl pgtype.Text
 }
 
-func (q *Queries) UpsertUserByGoogleAuthSafe(ctx context.Context, arg UpsertUserByGoogleAuthSafeParams) (User, error) {
-	row := q.db.QueryRow(ctx, upsertUserByGoogleAuthSafe,
-		arg.Name,
-		arg.GoogleID,
-		arg.Email,
-		arg.AvatarUrl,
-	)
+// the name and the avatar edited by the user are kept
+func (q *Queries) UpsertUserByEmail(ctx context.Context, arg UpsertUserByEmailParams) (User, error) {
+	row := q.db.QueryRow(ctx, upsertUserByEmail, arg.Name, arg.Email, arg.AvatarUrl)
 	var i User
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
-		&i.GoogleID,
 		&i.Email,
 		&i.AvatarUrl,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LastSeenAt,
+		&i.LastSeenPrivacy,
+		&i.About,
+		&i.NameEditedAt,
+		&i.AvatarEditedAt,
 	)
 	return i, err
 }
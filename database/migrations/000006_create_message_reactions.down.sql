@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS message_reactions;
+
+DROP INDEX IF EXISTS idx_message_reactions_message_id;
+DROP INDEX IF EXISTS idx_message_reactions_user_id;
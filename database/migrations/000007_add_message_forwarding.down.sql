@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_messages_forwarded_from;
+
+ALTER TABLE messages
+  DROP COLUMN IF EXISTS forwarded_from_message_id,
+  DROP COLUMN IF EXISTS forward_count,
+  DROP COLUMN IF EXISTS is_forwarded;
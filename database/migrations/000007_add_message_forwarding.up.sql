@@ -0,0 +1,8 @@
+ALTER TABLE messages
+  ADD COLUMN IF NOT EXISTS is_forwarded BOOLEAN NOT NULL DEFAULT FALSE,
+  -- how many times the content was forwarded until reaching this message (WhatsApp shows "forwarded many times" after 5)
+  ADD COLUMN IF NOT EXISTS forward_count INTEGER NOT NULL DEFAULT 0,
+  -- the message it was copied from, we keep the copy if the original is removed
+  ADD COLUMN IF NOT EXISTS forwarded_from_message_id UUID REFERENCES messages(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_messages_forwarded_from ON messages(forwarded_from_message_id) WHERE forwarded_from_message_id IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS starred_messages;
+DROP TABLE IF EXISTS pinned_messages;
+
+DROP INDEX IF EXISTS idx_starred_messages_user_starred_at;
+DROP INDEX IF EXISTS idx_pinned_messages_conversation_pinned_at;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS pinned_messages (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+  message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+  -- we keep the pin if the user that pinned it is removed
+  pinned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  pinned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  UNIQUE(conversation_id, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pinned_messages_conversation_pinned_at ON pinned_messages(conversation_id, pinned_at DESC);
+
+-- starred messages are personal, only the user that starred the message can see it
+CREATE TABLE IF NOT EXISTS starred_messages (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+  starred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  UNIQUE(user_id, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_starred_messages_user_starred_at ON starred_messages(user_id, starred_at DESC);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS message_mentions;
+
+DROP INDEX IF EXISTS idx_message_mentions_message_id;
+DROP INDEX IF EXISTS idx_message_mentions_mentioned_user;
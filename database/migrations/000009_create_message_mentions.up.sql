@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS message_mentions (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+  mentioned_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  -- position of the mention token (including the @) in the message content, counted in unicode code points
+  offset_start INTEGER NOT NULL,
+  length INTEGER NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  UNIQUE(message_id, offset_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_mentions_message_id ON message_mentions(message_id);
+CREATE INDEX IF NOT EXISTS idx_message_mentions_mentioned_user ON message_mentions(mentioned_user_id, created_at DESC);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS message_link_previews (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+  url TEXT NOT NULL,
+  title VARCHAR(300) NOT NULL,
+  description TEXT,
+  image_url TEXT,
+  site_name VARCHAR(300),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  -- only the first link of the message is unfurled, like whatsapp does
+  UNIQUE(message_id)
+);
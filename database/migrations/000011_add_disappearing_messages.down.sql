@@ -0,0 +1,10 @@
+ALTER TABLE messages
+  DROP CONSTRAINT IF EXISTS messages_reply_to_message_id_fkey,
+  ADD CONSTRAINT messages_reply_to_message_id_fkey
+    FOREIGN KEY (reply_to_message_id) REFERENCES messages(id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_messages_expires_at;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS expires_at;
+
+ALTER TABLE conversations DROP COLUMN IF EXISTS disappearing_messages_seconds;
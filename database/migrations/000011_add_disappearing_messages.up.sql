@@ -0,0 +1,16 @@
+-- 0 means the messages don't disappear
+ALTER TABLE conversations
+  ADD COLUMN IF NOT EXISTS disappearing_messages_seconds INTEGER NOT NULL DEFAULT 0;
+
+-- stamped when the message is created with the timer of the conversation at that moment,
+-- changing the timer later doesn't change the messages that were already sent
+ALTER TABLE messages
+  ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_messages_expires_at ON messages(expires_at) WHERE expires_at IS NOT NULL;
+
+-- the replies have their own expiration, removing an expired message must not remove them
+ALTER TABLE messages
+  DROP CONSTRAINT IF EXISTS messages_reply_to_message_id_fkey,
+  ADD CONSTRAINT messages_reply_to_message_id_fkey
+    FOREIGN KEY (reply_to_message_id) REFERENCES messages(id) ON DELETE SET NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS scheduled_messages;
+
+DROP INDEX IF EXISTS idx_scheduled_messages_due;
+DROP INDEX IF EXISTS idx_scheduled_messages_sender;
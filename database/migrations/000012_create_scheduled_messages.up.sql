@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS scheduled_messages (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+  sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  content TEXT NOT NULL,
+  message_type VARCHAR(255) NOT NULL,
+  reply_to_message_id UUID REFERENCES messages(id) ON DELETE SET NULL,
+  send_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  -- must be PENDING, SENDING, SENT, CANCELLED or FAILED
+  status VARCHAR(32) NOT NULL DEFAULT 'PENDING',
+  -- when an instance of the scheduler took it, a message stuck in SENDING was never delivered
+  claimed_at TIMESTAMP WITH TIME ZONE,
+  -- the message created when it was sent
+  message_id UUID REFERENCES messages(id) ON DELETE SET NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_due ON scheduled_messages(send_at) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_sender ON scheduled_messages(sender_id, send_at);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS message_drafts (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+  content TEXT NOT NULL,
+  reply_to_message_id UUID REFERENCES messages(id) ON DELETE SET NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  -- one draft per user per conversation, shared by all the devices of the user
+  UNIQUE(user_id, conversation_id)
+);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_conversation_participants_user_pinned;
+
+ALTER TABLE conversation_participants
+  DROP COLUMN IF EXISTS is_archived,
+  DROP COLUMN IF EXISTS muted_until,
+  DROP COLUMN IF EXISTS pin_order;
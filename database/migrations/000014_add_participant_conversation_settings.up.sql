@@ -0,0 +1,9 @@
+-- settings of the conversation that only affect the user of the participant row
+ALTER TABLE conversation_participants
+  ADD COLUMN IF NOT EXISTS is_archived BOOLEAN NOT NULL DEFAULT FALSE,
+  -- NULL when it's not muted, 'infinity' when it's muted until the user unmutes it
+  ADD COLUMN IF NOT EXISTS muted_until TIMESTAMP WITH TIME ZONE,
+  -- NULL when it's not pinned, the highest order goes first
+  ADD COLUMN IF NOT EXISTS pin_order INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_conversation_participants_user_pinned ON conversation_participants(user_id, pin_order) WHERE pin_order IS NOT NULL;
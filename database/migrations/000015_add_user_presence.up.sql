@@ -0,0 +1,5 @@
+ALTER TABLE users
+  -- updated when the last websocket connection of the user is closed
+  ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP WITH TIME ZONE,
+  -- who can see if the user is online and its last seen: EVERYONE, CONTACTS or NOBODY
+  ADD COLUMN IF NOT EXISTS last_seen_privacy VARCHAR(20) NOT NULL DEFAULT 'EVERYONE';
@@ -0,0 +1,6 @@
+ALTER TABLE refresh_tokens
+  DROP CONSTRAINT IF EXISTS fk_refresh_tokens_session;
+
+DROP TABLE IF EXISTS sessions;
+
+DROP INDEX IF EXISTS idx_sessions_user_id;
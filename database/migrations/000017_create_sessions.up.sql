@@ -0,0 +1,22 @@
+-- a session is created on every login, the access tokens carry its id in the jti claim and
+-- its refresh tokens use it as family id
+CREATE TABLE IF NOT EXISTS sessions (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  -- web or mobile
+  client_type VARCHAR(20) NOT NULL,
+  user_agent TEXT NOT NULL DEFAULT '',
+  ip_address VARCHAR(64) NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  -- updated when the tokens are refreshed
+  last_used_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+
+-- the refresh tokens issued before the sessions existed can't be linked to one
+DELETE FROM refresh_tokens;
+
+ALTER TABLE refresh_tokens
+  ADD CONSTRAINT fk_refresh_tokens_session FOREIGN KEY (family_id) REFERENCES sessions(id) ON DELETE CASCADE;
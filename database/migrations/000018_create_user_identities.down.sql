@@ -0,0 +1,13 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS google_id VARCHAR(255) NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_google_id ON users(google_id) WHERE google_id IS NOT NULL;
+
+UPDATE users
+SET google_id = ui.provider_user_id
+FROM user_identities ui
+WHERE ui.user_id = users.id
+    AND ui.provider = 'google';
+
+DROP TABLE IF EXISTS user_identities;
+
+DROP INDEX IF EXISTS idx_user_identities_user_id;
@@ -0,0 +1,24 @@
+-- the accounts of the oauth providers linked to each user, a user can log in with any of them
+CREATE TABLE IF NOT EXISTS user_identities (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  -- google, github or the name of the openid connect provider
+  provider VARCHAR(50) NOT NULL,
+  -- id of the user in the provider (the sub claim)
+  provider_user_id VARCHAR(255) NOT NULL,
+  email VARCHAR(255) NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (provider, provider_user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+
+INSERT INTO user_identities (user_id, provider, provider_user_id, email)
+SELECT id, 'google', google_id, email
+FROM users
+WHERE google_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_users_google_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS google_id;
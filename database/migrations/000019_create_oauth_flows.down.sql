@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS auth_codes;
+
+DROP TABLE IF EXISTS oauth_states;
+
+DROP INDEX IF EXISTS idx_auth_codes_expires_at;
+
+DROP INDEX IF EXISTS idx_oauth_states_expires_at;
@@ -0,0 +1,29 @@
+-- the logins in progress, the state sent to the provider is only valid once and only until it expires
+CREATE TABLE IF NOT EXISTS oauth_states (
+  -- sha256 of the state, the state itself is not stored
+  id VARCHAR(64) PRIMARY KEY,
+  provider VARCHAR(50) NOT NULL,
+  client_type VARCHAR(20) NOT NULL,
+  -- pkce verifier of the authorization code of the provider
+  code_verifier VARCHAR(128) NOT NULL,
+  -- pkce challenge of the mobile app for the one-time code
+  app_code_challenge VARCHAR(128),
+  -- state of the mobile app, it's sent back with the one-time code
+  app_state VARCHAR(255),
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_states_expires_at ON oauth_states(expires_at);
+
+-- the one-time codes returned to the mobile app after the login, they're exchanged for the tokens
+CREATE TABLE IF NOT EXISTS auth_codes (
+  -- sha256 of the code
+  id VARCHAR(64) PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  code_challenge VARCHAR(128) NOT NULL,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_codes_expires_at ON auth_codes(expires_at);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS email_logins;
+
+DROP INDEX IF EXISTS idx_email_logins_expires_at;
+
+DROP INDEX IF EXISTS idx_email_logins_email;
@@ -0,0 +1,18 @@
+-- the passwordless logins, the email gets a short code and a link and either of them logs in once
+CREATE TABLE IF NOT EXISTS email_logins (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  email VARCHAR(255) NOT NULL,
+  -- keyed hash of the short code, the code itself is not stored
+  code_hash VARCHAR(64) NOT NULL,
+  -- sha256 of the token of the link
+  link_token_hash VARCHAR(64) NOT NULL UNIQUE,
+  client_type VARCHAR(20) NOT NULL,
+  -- the guesses of the code, the login is locked when they reach the limit
+  attempts INTEGER NOT NULL DEFAULT 0,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  used_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_logins_email ON email_logins(email, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_email_logins_expires_at ON email_logins(expires_at);
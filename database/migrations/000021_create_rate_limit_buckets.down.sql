@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS rate_limit_buckets;
+
+DROP INDEX IF EXISTS idx_rate_limit_buckets_updated_at;
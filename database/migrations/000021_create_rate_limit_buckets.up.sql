@@ -0,0 +1,11 @@
+-- the token buckets of the rate limiter shared by the instances of the api
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets (
+  -- the limited route or operation and the user or ip
+  key VARCHAR(255) PRIMARY KEY,
+  tokens DOUBLE PRECISION NOT NULL,
+  -- if the last request took a token
+  allowed BOOLEAN NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets(updated_at);
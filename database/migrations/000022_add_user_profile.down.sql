@@ -0,0 +1,4 @@
+ALTER TABLE users
+  DROP COLUMN IF EXISTS about,
+  DROP COLUMN IF EXISTS name_edited_at,
+  DROP COLUMN IF EXISTS avatar_edited_at;
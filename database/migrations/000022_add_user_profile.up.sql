@@ -0,0 +1,6 @@
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS about VARCHAR(500),
+  -- set when the user edits the name or the avatar, then the logins keep them instead of using
+  -- the ones of the provider
+  ADD COLUMN IF NOT EXISTS name_edited_at TIMESTAMP WITH TIME ZONE,
+  ADD COLUMN IF NOT EXISTS avatar_edited_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,18 @@
+-- the messages of the deleted accounts are anonymized with the placeholder user, deleting it would
+-- delete them too, so the migration is only reverted while there are none
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM messages WHERE sender_id = '00000000-0000-0000-0000-000000000000') THEN
+    RAISE EXCEPTION 'the messages of the deleted accounts are sent by the placeholder user 00000000-0000-0000-0000-000000000000, reverting the migration would delete them';
+  END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_account_exports_expires_at;
+
+DROP INDEX IF EXISTS idx_account_exports_pending;
+
+DROP INDEX IF EXISTS idx_account_exports_user_id;
+
+DROP TABLE IF EXISTS account_exports;
+
+DELETE FROM users WHERE id = '00000000-0000-0000-0000-000000000000';
@@ -0,0 +1,25 @@
+-- the sender of the messages of the deleted accounts that are kept in the conversations, the
+-- email is not a valid address so nobody can log in with it
+INSERT INTO users (id, name, email)
+VALUES ('00000000-0000-0000-0000-000000000000', 'Deleted account', 'deleted-account')
+ON CONFLICT (id) DO NOTHING;
+
+-- the data exports requested by the users, a background job builds the zip file
+CREATE TABLE IF NOT EXISTS account_exports (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  -- PENDING, PROCESSING, READY or FAILED
+  status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+  -- the zip file, set when it's READY
+  file_path TEXT,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  -- when a job claimed the export
+  started_at TIMESTAMP WITH TIME ZONE,
+  completed_at TIMESTAMP WITH TIME ZONE,
+  -- the file is removed after this time
+  expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_exports_user_id ON account_exports(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_account_exports_pending ON account_exports(created_at) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_account_exports_expires_at ON account_exports(expires_at) WHERE expires_at IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE email_logins DROP COLUMN IF EXISTS code_challenge;
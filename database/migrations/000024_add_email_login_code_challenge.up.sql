@@ -0,0 +1,2 @@
+-- the S256 pkce challenge of the mobile app, the link of its login is only used with the verifier
+ALTER TABLE email_logins ADD COLUMN IF NOT EXISTS code_challenge VARCHAR(64);
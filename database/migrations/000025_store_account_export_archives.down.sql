@@ -0,0 +1,6 @@
+ALTER TABLE account_exports ADD COLUMN IF NOT EXISTS file_path TEXT;
+
+-- the archives have no file, their exports can be requested again
+UPDATE account_exports SET status = 'FAILED' WHERE status = 'READY';
+
+DROP TABLE IF EXISTS account_export_archives;
@@ -0,0 +1,11 @@
+-- the zip files of the exports are kept in the database, so any instance of the api can serve
+-- them and they're removed with their export
+CREATE TABLE IF NOT EXISTS account_export_archives (
+  export_id UUID PRIMARY KEY REFERENCES account_exports(id) ON DELETE CASCADE,
+  data BYTEA NOT NULL
+);
+
+-- the files written to the disk of an instance are not moved, their exports can be requested again
+UPDATE account_exports SET status = 'FAILED' WHERE status = 'READY';
+
+ALTER TABLE account_exports DROP COLUMN IF EXISTS file_path;
@@ -0,0 +1,99 @@
+-- name: CreateAccountExport :one
+INSERT INTO account_exports (user_id)
+VALUES ($1)
+RETURNING *;
+
+-- name: GetLatestAccountExport :one
+SELECT *
+FROM account_exports
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: GetAccountExport :one
+SELECT *
+FROM account_exports
+WHERE id = $1
+    AND user_id = $2;
+
+-- claims the oldest pending export, the locked rows are skipped so every export is claimed by
+-- only one instance
+-- name: ClaimAccountExport :one
+UPDATE account_exports
+SET
+    status = 'PROCESSING',
+    started_at = CURRENT_TIMESTAMP
+WHERE account_exports.id = (
+    SELECT pending.id
+    FROM account_exports pending
+    WHERE pending.status = 'PENDING'
+    ORDER BY pending.created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- stores the archive with the export, no row is inserted when the user was deleted while the
+-- export was built
+-- name: MarkAccountExportReady :execrows
+WITH ready AS (
+    UPDATE account_exports
+    SET
+        status = 'READY',
+        completed_at = CURRENT_TIMESTAMP,
+        expires_at = sqlc.arg(expires_at)
+    WHERE account_exports.id = sqlc.arg(id)
+    RETURNING account_exports.id
+)
+INSERT INTO account_export_archives (export_id, data)
+SELECT ready.id, sqlc.arg(data)::bytea
+FROM ready;
+
+-- name: GetAccountExportArchive :one
+SELECT data
+FROM account_export_archives
+WHERE export_id = $1;
+
+-- name: MarkAccountExportFailed :exec
+UPDATE account_exports
+SET
+    status = 'FAILED',
+    completed_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- the exports claimed by an instance that stopped before finishing them
+-- name: FailStaleAccountExports :execrows
+UPDATE account_exports
+SET
+    status = 'FAILED',
+    completed_at = CURRENT_TIMESTAMP
+WHERE status = 'PROCESSING'
+    AND started_at < sqlc.arg(claimed_before);
+
+-- the archives are removed with their exports
+-- name: DeleteExpiredAccountExports :exec
+DELETE FROM account_exports
+WHERE expires_at < CURRENT_TIMESTAMP;
+
+-- the conversations of the user, including the ones the user left
+-- name: GetUserExportConversations :many
+SELECT
+    sqlc.embed(c),
+    cp.role,
+    cp.joined_at,
+    cp.is_active
+FROM conversation_participants cp
+JOIN conversations c ON c.id = cp.conversation_id
+WHERE cp.user_id = $1
+ORDER BY c.created_at ASC;
+
+-- the messages sent by the user, after the given one so the export reads them in pages.
+-- The disappearing messages that already expired are not exported, even if the reaper didn't delete them yet
+-- name: GetUserExportMessages :many
+SELECT *
+FROM messages
+WHERE sender_id = sqlc.arg(sender_id)
+    AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+    AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::uuid)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(limit_count);
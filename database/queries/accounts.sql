@@ -0,0 +1,35 @@
+-- removes the direct conversations of the user with all their messages, for both participants
+-- name: DeleteUserDirectConversations :exec
+DELETE FROM conversations c
+USING conversation_participants cp
+WHERE cp.conversation_id = c.id
+    AND cp.user_id = sqlc.arg(user_id)
+    AND c.type = 'DIRECT';
+
+-- the messages of the user that are kept are sent by the deleted account from now on
+-- name: AnonymizeUserMessages :exec
+UPDATE messages
+SET sender_id = sqlc.arg(deleted_user_id)
+WHERE sender_id = sqlc.arg(user_id);
+
+-- the groups owned by the user get a new owner, the oldest admin or else the oldest member
+-- name: TransferUserGroupOwnerships :exec
+UPDATE conversation_participants
+SET
+    role = 'owner',
+    updated_at = CURRENT_TIMESTAMP
+WHERE conversation_participants.id IN (
+    SELECT DISTINCT ON (heir.conversation_id) heir.id
+    FROM conversation_participants current_owner
+    JOIN conversation_participants heir ON heir.conversation_id = current_owner.conversation_id
+        AND heir.user_id <> current_owner.user_id
+        AND heir.is_active = true
+    WHERE current_owner.user_id = sqlc.arg(user_id)
+        AND current_owner.role = 'owner'
+    ORDER BY heir.conversation_id, (heir.role = 'admin') DESC, heir.joined_at ASC
+);
+
+-- the rest of the data of the user is removed with it
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1;
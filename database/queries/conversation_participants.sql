@@ -18,6 +18,7 @@ SET
     updated_at = CURRENT_TIMESTAMP
 WHERE user_id = $1 AND conversation_id = $3;
 
+-- the expired disappearing messages are not counted, even if the reaper didn't delete them yet
 -- name: CountUnreadMessages :one
 SELECT COUNT(*)::INTEGER
 FROM messages m
@@ -26,4 +27,74 @@ WHERE cp.user_id = $1
     AND cp.conversation_id = $2
     AND cp.is_active = true
     AND m.created_at > cp.last_read_at
-    AND m.sender_id != $1;
\ No newline at end of file
+    AND m.sender_id != $1
+    AND (m.expires_at IS NULL OR m.expires_at > CURRENT_TIMESTAMP);
+
+-- name: GetConversationParticipantUsers :many
+SELECT sqlc.embed(u)
+FROM conversation_participants cp
+JOIN users u ON cp.user_id = u.id
+WHERE cp.conversation_id = $1
+    AND cp.is_active = true;
+
+-- name: GetActiveParticipantsByConversationIDs :many
+SELECT *
+FROM conversation_participants
+WHERE conversation_id = ANY(sqlc.arg(conversation_ids)::uuid[])
+    AND is_active = true
+ORDER BY conversation_id, joined_at;
+
+-- archiving a conversation also unpins it, like WhatsApp does
+-- name: UpdateParticipantArchived :one
+UPDATE conversation_participants
+SET
+    is_archived = sqlc.arg(is_archived),
+    pin_order = CASE WHEN sqlc.arg(is_archived) THEN NULL ELSE pin_order END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg(user_id)
+    AND conversation_id = sqlc.arg(conversation_id)
+    AND is_active = true
+RETURNING *;
+
+-- name: UpdateParticipantMutedUntil :one
+UPDATE conversation_participants
+SET
+    muted_until = $3,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+    AND conversation_id = $2
+    AND is_active = true
+RETURNING *;
+
+-- the pinned conversation goes on top of the ones that were already pinned, and it leaves the archive
+-- name: PinParticipantConversation :one
+UPDATE conversation_participants cp
+SET
+    pin_order = (
+        SELECT COALESCE(MAX(pinned.pin_order), 0) + 1
+        FROM conversation_participants pinned
+        WHERE pinned.user_id = sqlc.arg(user_id)
+    ),
+    is_archived = false,
+    updated_at = CURRENT_TIMESTAMP
+WHERE cp.user_id = sqlc.arg(user_id)
+    AND cp.conversation_id = sqlc.arg(conversation_id)
+    AND cp.is_active = true
+RETURNING cp.*;
+
+-- name: UnpinParticipantConversation :one
+UPDATE conversation_participants
+SET
+    pin_order = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+    AND conversation_id = $2
+    AND is_active = true
+RETURNING *;
+
+-- name: CountPinnedConversations :one
+SELECT COUNT(*)::INTEGER
+FROM conversation_participants
+WHERE user_id = $1
+    AND is_active = true
+    AND pin_order IS NOT NULL;
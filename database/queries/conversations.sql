@@ -3,41 +3,51 @@ INSERT INTO conversations (type)
 VALUES ($1)
 RETURNING *;
 
+-- pinned conversations go first, the archived ones are only returned when they are requested
 -- name: GetUserConversations :many
 SELECT
     c.id,
     c.type,
+    c.name,
+    c.avatar_url,
     c.last_message_at,
     c.created_at,
     c.updated_at,
+    (
+        SELECT COUNT(*)::INTEGER
+        FROM conversation_participants members
+        WHERE members.conversation_id = c.id
+            AND members.is_active = true
+    ) as participant_count,
     (
         SELECT COUNT(*)::INTEGER
         FROM messages unread_m
         WHERE unread_m.conversation_id = c.id
             AND unread_m.created_at > cp.last_read_at
-            AND unread_m.sender_id != $1
-    ) as unread_count
+            AND unread_m.sender_id != sqlc.arg(user_id)
+            AND (unread_m.expires_at IS NULL OR unread_m.expires_at > CURRENT_TIMESTAMP)
+    ) as unread_count,
+    draft.content as draft_content,
+    draft.reply_to_message_id as draft_reply_to_message_id,
+    draft.updated_at as draft_updated_at,
+    cp.is_archived,
+    cp.muted_until,
+    cp.pin_order
 FROM conversations c
 JOIN conversation_participants cp ON c.id = cp.conversation_id
-WHERE cp.user_id = $1 AND cp.is_active = true
-ORDER BY c.last_message_at DESC NULLS LAST;
+LEFT JOIN message_drafts draft ON draft.conversation_id = c.id AND draft.user_id = cp.user_id
+WHERE cp.user_id = sqlc.arg(user_id) AND cp.is_active = true
+    AND (sqlc.arg(include_archived)::BOOLEAN OR cp.is_archived = false)
+ORDER BY cp.pin_order DESC NULLS LAST, c.last_message_at DESC NULLS LAST;
 
--- -- name: GetConversationParticipants :many
--- SELECT
---     cp.id,
---     cp.conversation_id,
---     cp.user_id,
---     cp.joined_at,
---     cp.last_read_at,
---     cp.is_active,
---     u.id as user_id,
---     u.name as user_name,
---     u.avatar_url as user_avatar_url
--- FROM conversation_participants cp
--- JOIN users u ON cp.user_id = u.id
--- WHERE cp.conversation_id = ANY($1::uuid[])
---     AND cp.is_active = true
--- ORDER BY cp.joined_at ASC;
+-- active participants first, the ones that left the conversation are listed after them
+-- name: GetConversationParticipants :many
+SELECT sqlc.embed(cp), sqlc.embed(u)
+FROM conversation_participants cp
+JOIN users u ON cp.user_id = u.id
+WHERE cp.conversation_id = sqlc.arg(conversation_id)
+ORDER BY cp.is_active DESC, cp.joined_at ASC, cp.id
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
 
 -- name: GetLastMessage :many
 WITH ranked_messages AS (
@@ -53,6 +63,9 @@ WITH ranked_messages AS (
         m.edited_at,
         m.delivered_at,
         m.read_at,
+        m.is_forwarded,
+        m.forward_count,
+        m.expires_at,
         sender.name as sender_name,
         sender.avatar_url as sender_avatar_url,
         sender.email as sender_email,
@@ -62,6 +75,7 @@ WITH ranked_messages AS (
     FROM messages m
     JOIN users sender ON m.sender_id = sender.id
     WHERE m.conversation_id = ANY($1::uuid[])
+        AND (m.expires_at IS NULL OR m.expires_at > CURRENT_TIMESTAMP)
 )
 SELECT
     id,
@@ -75,6 +89,9 @@ SELECT
     created_at,
     delivered_at,
     read_at,
+    is_forwarded,
+    forward_count,
+    expires_at,
     sender_name,
     sender_avatar_url,
     sender_email,
@@ -107,3 +124,21 @@ SET
     last_message_at = $2,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1;
+
+-- name: GetConversationByID :one
+SELECT * FROM conversations
+WHERE id = $1;
+
+-- name: UpdateConversationDisappearingMessages :one
+UPDATE conversations
+SET
+    disappearing_messages_seconds = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- serializes the changes that check a limit of the conversation before writing
+-- name: LockConversation :exec
+SELECT id FROM conversations
+WHERE id = $1
+FOR UPDATE;
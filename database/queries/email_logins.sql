@@ -0,0 +1,54 @@
+-- name: CreateEmailLogin :one
+INSERT INTO email_logins (email, code_hash, link_token_hash, client_type, code_challenge, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- serializes the requests of the same email until the end of the transaction, the email may
+-- have no login to lock yet
+-- name: LockEmailLogins :exec
+SELECT pg_advisory_xact_lock(hashtextextended('email_logins:' || @email::text, 0));
+
+-- name: GetLatestEmailLogin :one
+SELECT * FROM email_logins
+WHERE email = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- a new login replaces the previous ones of the email
+-- name: DeleteEmailLogins :exec
+DELETE FROM email_logins
+WHERE email = $1;
+
+-- counts a guess of the code of the latest pending login before it's compared, so the
+-- concurrent guesses can't go over the limit
+-- name: ClaimEmailLoginAttempt :one
+UPDATE email_logins
+SET attempts = attempts + 1
+WHERE id = (
+    SELECT latest.id FROM email_logins latest
+    WHERE latest.email = @email
+        AND latest.used_at IS NULL
+        AND latest.expires_at > CURRENT_TIMESTAMP
+    ORDER BY latest.created_at DESC
+    LIMIT 1
+)
+    AND attempts < @max_attempts::int
+RETURNING *;
+
+-- name: UseEmailLogin :execrows
+UPDATE email_logins
+SET used_at = CURRENT_TIMESTAMP
+WHERE id = $1
+    AND used_at IS NULL;
+
+-- name: UseEmailLoginLink :one
+UPDATE email_logins
+SET used_at = CURRENT_TIMESTAMP
+WHERE link_token_hash = $1
+    AND used_at IS NULL
+    AND expires_at > CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteExpiredEmailLogins :exec
+DELETE FROM email_logins
+WHERE expires_at <= CURRENT_TIMESTAMP;
@@ -0,0 +1,18 @@
+-- name: UpsertMessageDraft :one
+INSERT INTO message_drafts (
+    user_id,
+    conversation_id,
+    content,
+    reply_to_message_id
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (user_id, conversation_id) DO UPDATE SET
+    content = EXCLUDED.content,
+    reply_to_message_id = EXCLUDED.reply_to_message_id,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteMessageDraft :execrows
+DELETE FROM message_drafts
+WHERE user_id = $1 AND conversation_id = $2;
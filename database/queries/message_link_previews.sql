@@ -0,0 +1,22 @@
+-- name: UpsertMessageLinkPreview :one
+INSERT INTO message_link_previews (
+    message_id,
+    url,
+    title,
+    description,
+    image_url,
+    site_name
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (message_id) DO UPDATE SET
+    url = EXCLUDED.url,
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    image_url = EXCLUDED.image_url,
+    site_name = EXCLUDED.site_name
+RETURNING *;
+
+-- name: GetMessageLinkPreviews :many
+SELECT * FROM message_link_previews
+WHERE message_id = ANY(sqlc.arg(message_ids)::uuid[]);
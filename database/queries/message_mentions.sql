@@ -0,0 +1,37 @@
+-- name: CreateMessageMentions :copyfrom
+INSERT INTO message_mentions (
+    message_id,
+    mentioned_user_id,
+    offset_start,
+    length
+) VALUES (
+    $1, $2, $3, $4
+);
+
+-- name: GetMessageMentions :many
+SELECT
+    mm.message_id,
+    mm.offset_start,
+    mm.length,
+    sqlc.embed(u)
+FROM message_mentions mm
+JOIN users u ON mm.mentioned_user_id = u.id
+WHERE mm.message_id = ANY(sqlc.arg(message_ids)::uuid[])
+ORDER BY mm.message_id, mm.offset_start ASC;
+
+-- only the messages of the conversations where the user is still an active participant
+-- name: GetUserMentions :many
+SELECT DISTINCT ON (m.created_at, m.id)
+    sqlc.embed(m),
+    sqlc.embed(sender)
+FROM message_mentions mm
+JOIN messages m ON mm.message_id = m.id
+JOIN users sender ON m.sender_id = sender.id
+JOIN conversation_participants cp ON cp.conversation_id = m.conversation_id
+    AND cp.user_id = mm.mentioned_user_id
+    AND cp.is_active = true
+WHERE mm.mentioned_user_id = $1
+    AND m.is_deleted = false
+    AND (m.expires_at IS NULL OR m.expires_at > CURRENT_TIMESTAMP)
+ORDER BY m.created_at DESC, m.id
+LIMIT $2 OFFSET $3;
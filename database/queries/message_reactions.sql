@@ -0,0 +1,22 @@
+-- name: UpsertMessageReaction :one
+INSERT INTO message_reactions (message_id, user_id, emoji)
+VALUES ($1, $2, $3)
+ON CONFLICT (message_id, user_id) DO UPDATE SET
+    emoji = EXCLUDED.emoji,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteMessageReaction :execrows
+DELETE FROM message_reactions
+WHERE message_id = $1 AND user_id = $2;
+
+-- name: GetMessageReactionSummaries :many
+SELECT
+    mr.message_id,
+    mr.emoji,
+    COUNT(*)::INTEGER as reaction_count,
+    BOOL_OR(mr.user_id = sqlc.arg(viewer_id)::uuid)::BOOLEAN as reacted_by_me
+FROM message_reactions mr
+WHERE mr.message_id = ANY(sqlc.arg(message_ids)::uuid[])
+GROUP BY mr.message_id, mr.emoji
+ORDER BY mr.message_id, reaction_count DESC, MIN(mr.created_at) ASC;
@@ -5,9 +5,18 @@ INSERT INTO messages (
     content,
     message_type,
     reply_to_message_id,
-    status
+    status,
+    expires_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5, $6,
+    (
+        SELECT CASE
+            WHEN c.disappearing_messages_seconds > 0
+            THEN CURRENT_TIMESTAMP + make_interval(secs => c.disappearing_messages_seconds)
+        END
+        FROM conversations c
+        WHERE c.id = $1
+    )
 ) RETURNING *;
 
 -- name: GetConversationMessages :many
@@ -28,5 +37,131 @@ JOIN users sender ON m.sender_id = sender.id
 LEFT JOIN messages reply_msg ON m.reply_to_message_id = reply_msg.id
 LEFT JOIN users reply_sender ON reply_msg.sender_id = reply_sender.id
 WHERE m.conversation_id = $1
+    -- the expired messages can still be there until the reaper removes them
+    AND (m.expires_at IS NULL OR m.expires_at > CURRENT_TIMESTAMP)
 ORDER BY m.created_at DESC
-LIMIT $2 OFFSET $3;
\ No newline at end of file
+LIMIT $2 OFFSET $3;
+
+-- an expired message is not returned even before the reaper removes it
+-- name: GetMessageByID :one
+SELECT * FROM messages
+WHERE id = $1
+    AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP);
+
+-- name: ForwardMessage :one
+INSERT INTO messages (
+    conversation_id,
+    sender_id,
+    content,
+    message_type,
+    status,
+    media_url,
+    media_filename,
+    media_size,
+    media_mime_type,
+    location_latitude,
+    location_longitude,
+    location_address,
+    is_forwarded,
+    forward_count,
+    forwarded_from_message_id,
+    expires_at
+)
+SELECT
+    sqlc.arg(conversation_id)::uuid,
+    sqlc.arg(sender_id)::uuid,
+    src.content,
+    src.message_type,
+    sqlc.arg(status)::VARCHAR,
+    src.media_url,
+    src.media_filename,
+    src.media_size,
+    src.media_mime_type,
+    src.location_latitude,
+    src.location_longitude,
+    src.location_address,
+    true,
+    src.forward_count + 1,
+    src.id,
+    -- the copy follows the timer of the target conversation
+    CASE
+        WHEN target.disappearing_messages_seconds > 0
+        THEN CURRENT_TIMESTAMP + make_interval(secs => target.disappearing_messages_seconds)
+    END
+FROM messages src
+JOIN conversations target ON target.id = sqlc.arg(conversation_id)::uuid
+WHERE src.id = sqlc.arg(source_message_id)::uuid
+    AND src.is_deleted = false
+    AND (src.expires_at IS NULL OR src.expires_at > CURRENT_TIMESTAMP)
+RETURNING *;
+
+-- name: GetMessageReplies :many
+SELECT
+    sqlc.embed(m),
+    sqlc.embed(sender)
+FROM messages m
+JOIN users sender ON m.sender_id = sender.id
+JOIN messages parent ON parent.id = m.reply_to_message_id
+WHERE m.reply_to_message_id = $1
+    AND m.conversation_id = parent.conversation_id
+    AND m.is_deleted = false
+    AND (m.expires_at IS NULL OR m.expires_at > CURRENT_TIMESTAMP)
+ORDER BY m.created_at ASC
+LIMIT $2 OFFSET $3;
+
+-- name: CountMessageReplies :many
+SELECT
+    m.reply_to_message_id::uuid as message_id,
+    COUNT(*)::INTEGER as reply_count
+FROM messages m
+JOIN messages parent ON parent.id = m.reply_to_message_id
+WHERE m.reply_to_message_id = ANY(sqlc.arg(message_ids)::uuid[])
+    AND m.conversation_id = parent.conversation_id
+    AND m.is_deleted = false
+    AND (m.expires_at IS NULL OR m.expires_at > CURRENT_TIMESTAMP)
+GROUP BY m.reply_to_message_id;
+
+-- the message and all the nested replies, a deleted reply is not returned but its replies are.
+-- Only the replies in the conversation of the message are followed
+-- name: GetMessageThread :many
+WITH RECURSIVE thread AS (
+    SELECT
+        root.id,
+        root.conversation_id,
+        0 as depth
+    FROM messages root
+    WHERE root.id = sqlc.arg(message_id)::uuid
+    UNION ALL
+    SELECT
+        child.id,
+        child.conversation_id,
+        thread.depth + 1
+    FROM messages child
+    JOIN thread ON child.reply_to_message_id = thread.id
+    WHERE thread.depth < sqlc.arg(max_depth)::INTEGER
+        AND child.conversation_id = thread.conversation_id
+)
+SELECT
+    sqlc.embed(m),
+    sqlc.embed(sender),
+    thread.depth::INTEGER as depth
+FROM thread
+JOIN messages m ON m.id = thread.id
+JOIN users sender ON m.sender_id = sender.id
+WHERE m.is_deleted = false
+    AND (m.expires_at IS NULL OR m.expires_at > CURRENT_TIMESTAMP)
+ORDER BY m.created_at ASC
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
+
+-- SKIP LOCKED lets several instances run the reaper at the same time without waiting for each other
+-- name: DeleteExpiredMessages :many
+DELETE FROM messages
+WHERE id IN (
+    SELECT expired.id
+    FROM messages expired
+    WHERE expired.expires_at <= CURRENT_TIMESTAMP
+    ORDER BY expired.expires_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, conversation_id;
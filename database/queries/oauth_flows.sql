@@ -0,0 +1,35 @@
+-- name: CreateOAuthState :exec
+INSERT INTO oauth_states (id, provider, client_type, code_verifier, app_code_challenge, app_state, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- the state is deleted when it's used, so it can't be used twice. The state of a web login is
+-- only used by the browser that started it, the one with the state cookie
+-- name: ConsumeOAuthState :one
+DELETE FROM oauth_states
+WHERE id = sqlc.arg(id)
+    AND expires_at > CURRENT_TIMESTAMP
+    AND (client_type <> 'web' OR id = sqlc.arg(browser_state_id))
+RETURNING *;
+
+-- name: CreateAuthCode :exec
+INSERT INTO auth_codes (id, user_id, code_challenge, expires_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: ConsumeAuthCode :one
+WITH consumed AS (
+    DELETE FROM auth_codes
+    WHERE auth_codes.id = $1
+        AND auth_codes.expires_at > CURRENT_TIMESTAMP
+    RETURNING auth_codes.user_id, auth_codes.code_challenge
+)
+SELECT consumed.user_id, consumed.code_challenge, u.email
+FROM consumed
+JOIN users u ON u.id = consumed.user_id;
+
+-- name: DeleteExpiredOAuthFlows :exec
+WITH expired_states AS (
+    DELETE FROM oauth_states
+    WHERE oauth_states.expires_at <= CURRENT_TIMESTAMP
+)
+DELETE FROM auth_codes
+WHERE auth_codes.expires_at <= CURRENT_TIMESTAMP;
@@ -0,0 +1,34 @@
+-- name: PinMessage :exec
+INSERT INTO pinned_messages (conversation_id, message_id, pinned_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (conversation_id, message_id) DO NOTHING;
+
+-- name: UnpinMessage :execrows
+DELETE FROM pinned_messages
+WHERE conversation_id = $1 AND message_id = $2;
+
+-- name: IsMessagePinned :one
+SELECT EXISTS (
+    SELECT 1 FROM pinned_messages
+    WHERE conversation_id = $1 AND message_id = $2
+);
+
+-- name: CountPinnedMessages :one
+SELECT COUNT(*)::INTEGER
+FROM pinned_messages
+WHERE conversation_id = $1;
+
+-- name: GetPinnedMessages :many
+SELECT
+    sqlc.embed(m),
+    sqlc.embed(sender),
+    pm.pinned_by,
+    pm.pinned_at
+FROM pinned_messages pm
+JOIN messages m ON pm.message_id = m.id
+JOIN users sender ON m.sender_id = sender.id
+WHERE pm.conversation_id = $1
+    AND m.is_deleted = false
+    AND (m.expires_at IS NULL OR m.expires_at > CURRENT_TIMESTAMP)
+ORDER BY pm.pinned_at DESC
+LIMIT $2 OFFSET $3;
@@ -0,0 +1,20 @@
+-- refills the bucket for the time since its last update and takes a token when there is one, in a
+-- single statement so the concurrent requests of every instance see the same bucket. A new
+-- bucket starts full
+-- name: TakeRateLimitToken :one
+INSERT INTO rate_limit_buckets AS bucket (key, tokens, allowed, updated_at)
+VALUES (sqlc.arg(key), sqlc.arg(burst)::float8 - 1, TRUE, CURRENT_TIMESTAMP)
+ON CONFLICT (key) DO UPDATE SET
+    tokens = LEAST(sqlc.arg(burst)::float8, bucket.tokens + EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - bucket.updated_at)::float8 * sqlc.arg(rate)::float8)
+        - CASE
+            WHEN LEAST(sqlc.arg(burst)::float8, bucket.tokens + EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - bucket.updated_at)::float8 * sqlc.arg(rate)::float8) >= 1 THEN 1
+            ELSE 0
+        END,
+    allowed = LEAST(sqlc.arg(burst)::float8, bucket.tokens + EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - bucket.updated_at)::float8 * sqlc.arg(rate)::float8) >= 1,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING bucket.tokens, bucket.allowed;
+
+-- the buckets that weren't used for a while are full again, they're the same as no bucket
+-- name: DeleteIdleRateLimitBuckets :exec
+DELETE FROM rate_limit_buckets
+WHERE updated_at < @idle_since;
@@ -0,0 +1,32 @@
+-- name: CreateRefreshToken :one
+INSERT INTO refresh_tokens (id, user_id, family_id, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- marks the token as used only when it can still be used, so two requests can't use the same token
+-- name: UseRefreshToken :one
+UPDATE refresh_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE id = $1
+    AND used_at IS NULL
+    AND revoked_at IS NULL
+    AND expires_at > CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetRefreshToken :one
+SELECT *
+FROM refresh_tokens
+WHERE id = $1;
+
+-- the family of the tokens is the session, so it's revoked too
+-- name: RevokeRefreshTokenFamily :exec
+WITH revoked_session AS (
+    UPDATE sessions
+    SET revoked_at = CURRENT_TIMESTAMP
+    WHERE id = $1
+        AND revoked_at IS NULL
+)
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE family_id = $1
+    AND revoked_at IS NULL;
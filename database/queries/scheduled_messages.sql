@@ -0,0 +1,79 @@
+-- name: CreateScheduledMessage :one
+INSERT INTO scheduled_messages (
+    conversation_id,
+    sender_id,
+    content,
+    message_type,
+    reply_to_message_id,
+    send_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetScheduledMessageByID :one
+SELECT * FROM scheduled_messages
+WHERE id = $1;
+
+-- the conversation filter is optional, a NULL id returns the messages of all the conversations
+-- name: GetPendingScheduledMessages :many
+SELECT sm.*
+FROM scheduled_messages sm
+WHERE sm.sender_id = sqlc.arg(sender_id)
+    AND sm.status = 'PENDING'
+    AND (sqlc.narg(conversation_id)::uuid IS NULL OR sm.conversation_id = sqlc.narg(conversation_id)::uuid)
+ORDER BY sm.send_at ASC
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
+
+-- only a PENDING message can be cancelled, once the scheduler claims it it's too late
+-- name: CancelScheduledMessage :execrows
+UPDATE scheduled_messages
+SET
+    status = 'CANCELLED',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+    AND sender_id = $2
+    AND status = 'PENDING';
+
+-- SKIP LOCKED makes every due message be claimed by only one instance of the scheduler,
+-- the status change keeps the others from taking it once the transaction ends
+-- name: ClaimDueScheduledMessages :many
+UPDATE scheduled_messages
+SET
+    status = 'SENDING',
+    claimed_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT due.id
+    FROM scheduled_messages due
+    WHERE due.status = 'PENDING'
+        AND due.send_at <= CURRENT_TIMESTAMP
+    ORDER BY due.send_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkScheduledMessageSent :exec
+UPDATE scheduled_messages
+SET
+    status = 'SENT',
+    message_id = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: MarkScheduledMessageFailed :exec
+UPDATE scheduled_messages
+SET
+    status = 'FAILED',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- the instance that claimed them stopped before sending them. They are not sent again because
+-- we can't know if the message was created, a missing message is better than a duplicated one
+-- name: FailStaleScheduledMessages :execrows
+UPDATE scheduled_messages
+SET
+    status = 'FAILED',
+    updated_at = CURRENT_TIMESTAMP
+WHERE status = 'SENDING'
+    AND claimed_at < sqlc.arg(claimed_before)::timestamptz;
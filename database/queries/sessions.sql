@@ -0,0 +1,53 @@
+-- name: CreateSession :one
+INSERT INTO sessions (user_id, client_type, user_agent, ip_address)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetSession :one
+SELECT *
+FROM sessions
+WHERE id = $1;
+
+-- name: GetUserActiveSessions :many
+SELECT *
+FROM sessions
+WHERE user_id = $1
+    AND revoked_at IS NULL
+ORDER BY last_used_at DESC;
+
+-- name: TouchSession :exec
+UPDATE sessions
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- revokes the session of the user and its refresh tokens
+-- name: RevokeUserSession :execrows
+WITH revoked_tokens AS (
+    UPDATE refresh_tokens
+    SET revoked_at = CURRENT_TIMESTAMP
+    WHERE refresh_tokens.family_id = sqlc.arg(session_id)
+        AND refresh_tokens.user_id = sqlc.arg(user_id)
+        AND refresh_tokens.revoked_at IS NULL
+)
+UPDATE sessions
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE sessions.id = sqlc.arg(session_id)
+    AND sessions.user_id = sqlc.arg(user_id)
+    AND sessions.revoked_at IS NULL;
+
+-- name: RevokeOtherUserSessions :many
+WITH revoked_sessions AS (
+    UPDATE sessions
+    SET revoked_at = CURRENT_TIMESTAMP
+    WHERE sessions.user_id = sqlc.arg(user_id)
+        AND sessions.id <> sqlc.arg(current_session_id)
+        AND sessions.revoked_at IS NULL
+    RETURNING sessions.id
+), revoked_tokens AS (
+    UPDATE refresh_tokens
+    SET revoked_at = CURRENT_TIMESTAMP
+    WHERE refresh_tokens.family_id IN (SELECT id FROM revoked_sessions)
+        AND refresh_tokens.revoked_at IS NULL
+)
+SELECT id
+FROM revoked_sessions;
@@ -0,0 +1,26 @@
+-- name: StarMessage :exec
+INSERT INTO starred_messages (user_id, message_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id, message_id) DO NOTHING;
+
+-- name: UnstarMessage :execrows
+DELETE FROM starred_messages
+WHERE user_id = $1 AND message_id = $2;
+
+-- only the messages of the conversations where the user is still an active participant
+-- name: GetStarredMessages :many
+SELECT
+    sqlc.embed(m),
+    sqlc.embed(sender),
+    sm.starred_at
+FROM starred_messages sm
+JOIN messages m ON sm.message_id = m.id
+JOIN users sender ON m.sender_id = sender.id
+JOIN conversation_participants cp ON cp.conversation_id = m.conversation_id
+    AND cp.user_id = sm.user_id
+    AND cp.is_active = true
+WHERE sm.user_id = $1
+    AND m.is_deleted = false
+    AND (m.expires_at IS NULL OR m.expires_at > CURRENT_TIMESTAMP)
+ORDER BY sm.starred_at DESC
+LIMIT $2 OFFSET $3;
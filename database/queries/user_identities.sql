@@ -0,0 +1,14 @@
+-- name: GetUserByIdentity :one
+SELECT sqlc.embed(u)
+FROM user_identities ui
+JOIN users u ON u.id = ui.user_id
+WHERE ui.provider = sqlc.arg(provider)
+    AND ui.provider_user_id = sqlc.arg(provider_user_id);
+
+-- name: CreateUserIdentity :one
+INSERT INTO user_identities (user_id, provider, provider_user_id, email)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (provider, provider_user_id) DO UPDATE SET
+    email = EXCLUDED.email,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
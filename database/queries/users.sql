@@ -1,28 +1,96 @@
--- name: UpsertUserByGoogleAuthSafe :one
-INSERT INTO users (name, google_id, email, avatar_url, updated_at)
-VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+-- the name and the avatar edited by the user are kept
+-- name: UpsertUserByEmail :one
+INSERT INTO users (name, email, avatar_url, updated_at)
+VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
 ON CONFLICT (email) DO UPDATE SET
     name = CASE
+        WHEN users.name_edited_at IS NOT NULL THEN users.name
         WHEN users.name IS NULL OR users.name = '' THEN EXCLUDED.name
         ELSE COALESCE(EXCLUDED.name, users.name)
     END,
-    google_id = COALESCE(users.google_id, EXCLUDED.google_id),
-    avatar_url = COALESCE(EXCLUDED.avatar_url, users.avatar_url),
+    avatar_url = CASE
+        WHEN users.avatar_edited_at IS NOT NULL THEN users.avatar_url
+        ELSE COALESCE(EXCLUDED.avatar_url, users.avatar_url)
+    END,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- updates the profile with the data of the provider the user logged in with, the name and the
+-- avatar edited by the user are kept
+-- name: UpdateUserFromProvider :one
+UPDATE users
+SET
+    name = CASE
+        WHEN users.name_edited_at IS NOT NULL THEN users.name
+        WHEN users.name IS NULL OR users.name = '' THEN sqlc.narg(name)
+        ELSE COALESCE(sqlc.narg(name), users.name)
+    END,
+    avatar_url = CASE
+        WHEN users.avatar_edited_at IS NOT NULL THEN users.avatar_url
+        ELSE COALESCE(sqlc.narg(avatar_url), users.avatar_url)
+    END,
     updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
 RETURNING *;
 
 -- name: GetUserByEmail :one
-SELECT id, name, google_id, email, avatar_url, created_at, updated_at
+SELECT *
 FROM users
 WHERE email = $1;
 
--- name: GetUserByGoogleID :one
-SELECT id, name, google_id, email, avatar_url, created_at, updated_at
-FROM users
-WHERE google_id = $1;
 
-
--- delete all except the one with email ending in @gmail.com
+-- delete all except the one with email ending in @gmail.com and the sender of the deleted accounts
 -- name: RemoveAllUsers :exec
 DELETE FROM users
-WHERE email NOT LIKE '%@gmail.com';
\ No newline at end of file
+WHERE email NOT LIKE '%@gmail.com'
+    AND id <> '00000000-0000-0000-0000-000000000000';
+
+-- name: GetUsersByIDs :many
+SELECT *
+FROM users
+WHERE id = ANY(sqlc.arg(user_ids)::uuid[]);
+
+-- name: UpdateUserLastSeen :exec
+UPDATE users
+SET last_seen_at = sqlc.arg(last_seen_at)
+WHERE id = sqlc.arg(id);
+
+-- name: UpdateUserLastSeenPrivacy :one
+UPDATE users
+SET
+    last_seen_privacy = sqlc.arg(last_seen_privacy),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- the contacts of a user are the other participants of its direct conversations
+-- name: GetUserContactIDs :many
+SELECT DISTINCT other.user_id
+FROM conversation_participants mine
+JOIN conversations c ON c.id = mine.conversation_id AND c.type = 'DIRECT'
+JOIN conversation_participants other ON other.conversation_id = mine.conversation_id
+    AND other.user_id <> mine.user_id
+    AND other.is_active = true
+WHERE mine.user_id = sqlc.arg(user_id)
+    AND mine.is_active = true;
+
+-- only the fields with set_* are updated, so the concurrent edits of different fields don't
+-- overwrite each other
+-- name: UpdateUserProfile :one
+UPDATE users
+SET
+    name = CASE WHEN sqlc.arg(set_name)::bool THEN sqlc.narg(name) ELSE users.name END,
+    name_edited_at = CASE WHEN sqlc.arg(set_name)::bool THEN CURRENT_TIMESTAMP ELSE users.name_edited_at END,
+    about = CASE WHEN sqlc.arg(set_about)::bool THEN sqlc.narg(about) ELSE users.about END,
+    avatar_url = CASE WHEN sqlc.arg(set_avatar_url)::bool THEN sqlc.narg(avatar_url) ELSE users.avatar_url END,
+    avatar_edited_at = CASE WHEN sqlc.arg(set_avatar_url)::bool THEN CURRENT_TIMESTAMP ELSE users.avatar_edited_at END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- serializes the changes that check a limit of the user before writing, NO KEY doesn't block
+-- the rows that reference the user
+-- name: LockUser :exec
+SELECT id FROM users
+WHERE id = $1
+FOR NO KEY UPDATE;
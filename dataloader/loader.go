@@ -0,0 +1,126 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultWait     = 5 * time.Millisecond
+	DefaultMaxBatch = 100
+)
+
+// BatchFunc loads all the keys at once. The keys missing in the result get the zero value
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collects the keys requested during a short window and loads them with a single
+// call to the batch function. The results are cached, so a loader must live only during
+// a request, otherwise it would return stale data
+type Loader[K comparable, V any] struct {
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	batch   *batch[K, V]
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type batch[K comparable, V any] struct {
+	keys    []K
+	results map[K]*result[V]
+}
+
+func NewLoader[K comparable, V any](fetch BatchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		results:  map[K]*result[V]{},
+	}
+}
+
+// Load waits until the batch that includes the key is loaded
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+
+	res, ok := l.results[key]
+	if !ok {
+		res = &result[V]{done: make(chan struct{})}
+		l.results[key] = res
+		l.enqueue(ctx, key, res)
+	}
+
+	l.mu.Unlock()
+
+	select {
+	case <-res.done:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// enqueue must be called with the lock held
+func (l *Loader[K, V]) enqueue(ctx context.Context, key K, res *result[V]) {
+	if l.batch == nil {
+		l.batch = &batch[K, V]{results: map[K]*result[V]{}}
+		go l.dispatchAfterWait(ctx, l.batch)
+	}
+
+	l.batch.keys = append(l.batch.keys, key)
+	l.batch.results[key] = res
+
+	if len(l.batch.keys) >= l.maxBatch {
+		full := l.batch
+		l.batch = nil
+		go l.dispatch(ctx, full)
+	}
+}
+
+func (l *Loader[K, V]) dispatchAfterWait(ctx context.Context, b *batch[K, V]) {
+	time.Sleep(l.wait)
+
+	l.mu.Lock()
+	// the batch was already dispatched because it was full
+	if l.batch != b {
+		l.mu.Unlock()
+		return
+	}
+	l.batch = nil
+	l.mu.Unlock()
+
+	l.dispatch(ctx, b)
+}
+
+func (l *Loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	values, err := l.fetch(ctx, b.keys)
+
+	for key, res := range b.results {
+		if err != nil {
+			res.err = err
+		} else {
+			res.value = values[key]
+		}
+		close(res.done)
+	}
+
+	// failed keys are not cached, the next load tries again
+	if err != nil {
+		l.mu.Lock()
+		for key, res := range b.results {
+			if l.results[key] == res {
+				delete(l.results, key)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
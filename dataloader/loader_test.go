@@ -0,0 +1,124 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func loadConcurrently(loader *Loader[int, int], keys []int) ([]int, []error) {
+	values := make([]int, len(keys))
+	errs := make([]error, len(keys))
+
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], errs[i] = loader.Load(context.Background(), key)
+		}()
+	}
+	wg.Wait()
+
+	return values, errs
+}
+
+func TestLoaderBatchesAndCaches(t *testing.T) {
+	var calls atomic.Int32
+	loader := NewLoader(func(ctx context.Context, keys []int) (map[int]int, error) {
+		calls.Add(1)
+
+		values := map[int]int{}
+		for _, key := range keys {
+			// odd keys are missing and get the zero value
+			if key%2 == 0 {
+				values[key] = key * 10
+			}
+		}
+
+		return values, nil
+	}, 50*time.Millisecond, 100)
+
+	keys := []int{0, 1, 2, 3, 2, 0}
+	values, errs := loadConcurrently(loader, keys)
+
+	for i, key := range keys {
+		want := 0
+		if key%2 == 0 {
+			want = key * 10
+		}
+
+		if errs[i] != nil || values[i] != want {
+			t.Errorf("key %d: expected %d, got %d (%v)", key, want, values[i], errs[i])
+		}
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 batch, got %d", calls.Load())
+	}
+
+	// cached keys don't run a new batch
+	loadConcurrently(loader, []int{0, 1, 2})
+	if calls.Load() != 1 {
+		t.Errorf("expected the cached keys to be reused, got %d batches", calls.Load())
+	}
+}
+
+func TestLoaderSplitsFullBatches(t *testing.T) {
+	var mu sync.Mutex
+	sizes := []int{}
+	loader := NewLoader(func(ctx context.Context, keys []int) (map[int]int, error) {
+		mu.Lock()
+		sizes = append(sizes, len(keys))
+		mu.Unlock()
+
+		return map[int]int{}, nil
+	}, 50*time.Millisecond, 10)
+
+	keys := make([]int, 25)
+	for i := range keys {
+		keys[i] = i
+	}
+	loadConcurrently(loader, keys)
+
+	if len(sizes) != 3 {
+		t.Fatalf("expected 3 batches, got %v", sizes)
+	}
+
+	total := 0
+	for _, size := range sizes {
+		if size > 10 {
+			t.Errorf("batch of %d keys is bigger than the max", size)
+		}
+		total += size
+	}
+
+	if total != len(keys) {
+		t.Errorf("expected %d keys loaded, got %d", len(keys), total)
+	}
+}
+
+func TestLoaderDoesNotCacheErrors(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	var calls atomic.Int32
+	loader := NewLoader(func(ctx context.Context, keys []int) (map[int]int, error) {
+		if calls.Add(1) == 1 {
+			return nil, errFailed
+		}
+
+		return map[int]int{1: 1}, nil
+	}, time.Millisecond, 100)
+
+	if _, err := loader.Load(context.Background(), 1); !errors.Is(err, errFailed) {
+		t.Fatalf("expected the batch error, got %v", err)
+	}
+
+	value, err := loader.Load(context.Background(), 1)
+	if err != nil || value != 1 {
+		t.Errorf("expected the key to be loaded again, got %d (%v)", value, err)
+	}
+}
@@ -5,9 +5,52 @@ import "errors"
 var (
 	ErrInvalidUUIDValue = errors.New("invalid UUID value")
 	ErrResourceNotFound = errors.New("resource not found")
+	ErrForbidden        = errors.New("forbidden")
+	ErrInvalidReaction  = errors.New("invalid reaction emoji")
+
+	ErrTooManyForwardTargets       = errors.New("invalid number of conversations to forward to")
+	ErrPinLimitReached             = errors.New("pinned messages limit reached")
+	ErrConversationPinLimitReached = errors.New("pinned conversations limit reached")
+	ErrEmptyMessage                = errors.New("the message content is empty")
+	ErrMessageTooLong              = errors.New("the message content is too long")
+	ErrInvalidReply                = errors.New("the replied message is not in the conversation")
+
+	ErrInvalidDisappearingTimer = errors.New("invalid disappearing messages timer")
+
+	ErrInvalidScheduleTime        = errors.New("invalid time to send the scheduled message")
+	ErrScheduledMessageNotPending = errors.New("the scheduled message was already sent or cancelled")
+
+	ErrTooManyPresenceUsers  = errors.New("too many users to get the presence of")
+	ErrInvalidPrivacySetting = errors.New("invalid privacy setting")
+
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("the refresh token was already used")
+
+	ErrInvalidAccessToken = errors.New("invalid access token")
+	ErrSessionRevoked     = errors.New("the session was revoked")
+
+	ErrUnknownOAuthProvider = errors.New("unknown oauth provider")
+	ErrEmailNotVerified     = errors.New("the email of the account is not verified")
+	ErrInvalidOAuthState    = errors.New("invalid oauth state")
+	ErrInvalidCodeChallenge = errors.New("invalid pkce code challenge")
+	ErrInvalidAuthCode      = errors.New("invalid authorization code")
+
+	ErrInvalidEmail       = errors.New("invalid email address")
+	ErrInvalidLoginCode   = errors.New("invalid or expired login code")
+	ErrLoginCodeRequested = errors.New("a login code was requested recently, wait before requesting another one")
+
+	ErrInvalidProfileName = errors.New("invalid profile name")
+	ErrInvalidAbout       = errors.New("invalid about text")
+	ErrInvalidAvatarURL   = errors.New("invalid avatar url")
+
+	ErrInvalidAccountConfirmation = errors.New("the confirmation doesn't match the email of the account")
+	ErrAccountExportTooLarge      = errors.New("the account export is larger than the limit")
 )
 
 const (
 	CodeInternalError    = "INTERNAL_ERROR"
 	CodeResourceNotFound = "RESOURCE_NOT_FOUND"
+	CodeForbidden        = "FORBIDDEN"
+	CodeValidationError  = "VALIDATION_ERROR"
+	CodeRateLimited      = "RATE_LIMITED"
 )
@@ -0,0 +1,89 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph/model"
+)
+
+// RequestAccountExport is the resolver for the requestAccountExport field.
+func (r *mutationResolver) RequestAccountExport(ctx context.Context) (model.RequestAccountExportResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	export, err := r.AccountService.RequestExport(ctx, user.UserID)
+	if err != nil {
+		return model.ServerError{
+			ErrorMessage: "Error to request the export",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	return &model.RequestAccountExportSuccess{
+		Success: true,
+		Export:  toAccountExport(*export, r.AppConfig.BaseURL),
+	}, nil
+}
+
+// DeleteAccount is the resolver for the deleteAccount field.
+func (r *mutationResolver) DeleteAccount(ctx context.Context, input model.DeleteAccountInput) (model.DeleteAccountResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	err := r.AccountService.DeleteAccount(ctx, user.UserID, input.ConfirmEmail)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidAccountConfirmation) {
+			return model.ValidationError{
+				ErrorMessage: "the email doesn't match the email of the account",
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		r.Logger.Error().Msgf("error to delete the account of user %s: %v", user.UserID, err)
+
+		return model.ServerError{
+			ErrorMessage: "Error to delete the account",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	return &model.DeleteAccountSuccess{
+		Success: true,
+	}, nil
+}
+
+// AccountExport is the resolver for the accountExport field.
+func (r *queryResolver) AccountExport(ctx context.Context) (model.AccountExportQueryResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	export, err := r.AccountService.GetLatestExport(ctx, user.UserID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) {
+			return &model.AccountExportQuerySuccess{
+				Success: true,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to get the export",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	return &model.AccountExportQuerySuccess{
+		Success: true,
+		Export:  toAccountExport(*export, r.AppConfig.BaseURL),
+	}, nil
+}
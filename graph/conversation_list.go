@@ -0,0 +1,50 @@
+package graph
+
+import (
+	"context"
+
+	"golang-whatsapp-clone/graph/model"
+)
+
+// conversationLastMessage resolves the lastMessage field of both list item types. A failure
+// only hides the last message instead of failing the whole list
+func (r *Resolver) conversationLastMessage(ctx context.Context, conversationID string) *model.Message {
+	lastMessage, err := r.loaders(ctx).LastMessage.Load(ctx, conversationID)
+	if err != nil {
+		r.Logger.Error().Msgf("error to get the last message of the conversation %s: %v", conversationID, err)
+		return nil
+	}
+
+	if lastMessage == nil {
+		return nil
+	}
+
+	return toLastMessage(*lastMessage)
+}
+
+// directCounterpart returns the other participant of a direct conversation, or nil when they
+// are no longer part of it
+func (r *Resolver) directCounterpart(ctx context.Context, conversationID string, userID string) (*model.User, error) {
+	loaders := r.loaders(ctx)
+
+	participants, err := loaders.Participants.Load(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, participant := range participants {
+		participantID := participant.UserID.String()
+		if participantID == userID {
+			continue
+		}
+
+		counterpart, err := loaders.User.Load(ctx, participantID)
+		if err != nil || counterpart == nil {
+			return nil, err
+		}
+
+		return toUser(*counterpart), nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,167 @@
+package graph_test
+
+import (
+	"testing"
+	"time"
+
+	"golang-whatsapp-clone/auth"
+	db "golang-whatsapp-clone/database/gen"
+	"golang-whatsapp-clone/graph"
+	"golang-whatsapp-clone/graph/model"
+	"golang-whatsapp-clone/handler"
+	"golang-whatsapp-clone/repository"
+	"golang-whatsapp-clone/service"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+)
+
+func TestMyConversationsListItems(t *testing.T) {
+	counter := &queryCounter{}
+	me := db.User{ID: newUUID(), Email: "me@example.com", CreatedAt: timestamp(time.Now())}
+	ana := db.User{ID: newUUID(), Name: pgtype.Text{String: "Ana", Valid: true}, Email: "ana@example.com", CreatedAt: timestamp(time.Now())}
+
+	group := db.GetUserConversationsRow{
+		ID:               newUUID(),
+		Type:             string(model.ConversationTypeEnumGroup),
+		Name:             pgtype.Text{String: "Family", Valid: true},
+		ParticipantCount: 3,
+		CreatedAt:        timestamp(time.Now()),
+		UpdatedAt:        timestamp(time.Now()),
+	}
+	direct := db.GetUserConversationsRow{
+		ID:          newUUID(),
+		Type:        string(model.ConversationTypeEnumDirect),
+		UnreadCount: 2,
+		PinOrder:    pgtype.Int4{Int32: 1, Valid: true},
+		CreatedAt:   timestamp(time.Now()),
+		UpdatedAt:   timestamp(time.Now()),
+	}
+	// the other user left the conversation
+	abandoned := db.GetUserConversationsRow{
+		ID:        newUUID(),
+		Type:      string(model.ConversationTypeEnumDirect),
+		CreatedAt: timestamp(time.Now()),
+		UpdatedAt: timestamp(time.Now()),
+	}
+
+	// the group has no messages yet
+	conversationRepository := &fakeConversationRepository{
+		counter:       counter,
+		conversations: []db.GetUserConversationsRow{group, direct, abandoned},
+		lastMessages: map[string]db.GetLastMessageRow{
+			direct.ID.String(): {
+				ID:             newUUID(),
+				ConversationID: direct.ID,
+				SenderID:       ana.ID,
+				Content:        "Hi!",
+				MessageType:    repository.MESSAGE_TYPE_TEXT,
+				Status:         repository.MESSAGE_STATUS_SENT,
+				SenderEmail:    ana.Email,
+				CreatedAt:      timestamp(time.Now()),
+			},
+		},
+	}
+	participantRepository := &fakeParticipantRepository{counter: counter, participants: map[string][]db.ConversationParticipant{
+		group.ID.String():     {{ConversationID: group.ID, UserID: me.ID}, {ConversationID: group.ID, UserID: ana.ID}},
+		direct.ID.String():    {{ConversationID: direct.ID, UserID: me.ID}, {ConversationID: direct.ID, UserID: ana.ID}},
+		abandoned.ID.String(): {{ConversationID: abandoned.ID, UserID: me.ID}},
+	}}
+	userRepository := &fakeUserRepository{counter: counter, users: map[string]db.User{me.ID.String(): me, ana.ID.String(): ana}}
+
+	logger := zerolog.Nop()
+	resolver := &graph.Resolver{
+		Logger:              &logger,
+		ConversationService: service.NewConversationService(conversationRepository, participantRepository),
+		UserService:         service.NewUserService(userRepository),
+	}
+	gqlClient := client.New(handler.NewGraphqlHandler(&logger, resolver, auth.NewJWTService("access-secret", "refresh-secret"), nil))
+
+	type listItem struct {
+		Typename         string `json:"__typename"`
+		ID               string
+		Name             *string
+		ParticipantCount int32
+		UnreadCount      int32
+		Participant      *struct {
+			ID   string
+			Name *string
+		}
+		LastMessage *struct {
+			Content string
+		}
+		Settings struct {
+			IsPinned bool
+		}
+	}
+
+	var response struct {
+		MyConversations struct {
+			Conversations []listItem
+		}
+	}
+
+	gqlClient.MustPost(`
+		query {
+			myConversations {
+				... on MyConversationsQuerySuccess {
+					conversations {
+						__typename
+						... on ConversationListItemDirect {
+							id
+							unreadCount
+							participant { id name }
+							lastMessage { content }
+							settings { isPinned }
+						}
+						... on ConversationListItemGroup {
+							id
+							name
+							participantCount
+							lastMessage { content }
+							settings { isPinned }
+						}
+					}
+				}
+			}
+		}
+	`, &response, func(request *client.Request) {
+		request.HTTP = request.HTTP.WithContext(auth.WithUserContext(request.HTTP.Context(), me.ID.String(), me.Email, uuid.NewString()))
+	})
+
+	conversations := response.MyConversations.Conversations
+	if len(conversations) != 3 {
+		t.Fatalf("expected 3 conversations, got %d", len(conversations))
+	}
+
+	groupItem := conversations[0]
+	if groupItem.Typename != "ConversationListItemGroup" || groupItem.ID != group.ID.String() {
+		t.Fatalf("expected the group first, got %+v", groupItem)
+	}
+	if groupItem.LastMessage != nil {
+		t.Errorf("expected no last message in the group without messages, got %+v", groupItem.LastMessage)
+	}
+	if groupItem.Name == nil || *groupItem.Name != "Family" || groupItem.ParticipantCount != 3 {
+		t.Errorf("unexpected group %+v", groupItem)
+	}
+
+	directItem := conversations[1]
+	if directItem.Typename != "ConversationListItemDirect" || directItem.ID != direct.ID.String() {
+		t.Fatalf("expected the direct conversation second, got %+v", directItem)
+	}
+	if directItem.Participant == nil || directItem.Participant.ID != ana.ID.String() || *directItem.Participant.Name != "Ana" {
+		t.Errorf("expected the other user as the participant, got %+v", directItem.Participant)
+	}
+	if directItem.LastMessage == nil || directItem.LastMessage.Content != "Hi!" {
+		t.Errorf("unexpected last message %+v", directItem.LastMessage)
+	}
+	if directItem.UnreadCount != 2 || !directItem.Settings.IsPinned {
+		t.Errorf("unexpected direct conversation %+v", directItem)
+	}
+
+	if abandonedItem := conversations[2]; abandonedItem.Participant != nil || abandonedItem.LastMessage != nil {
+		t.Errorf("expected no participant and no last message, got %+v", abandonedItem)
+	}
+}
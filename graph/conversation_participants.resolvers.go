@@ -0,0 +1,56 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph/model"
+)
+
+// Conversation is the resolver for the conversation field.
+func (r *queryResolver) Conversation(ctx context.Context, input model.ConversationInput) (model.ConversationQueryResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	limit, offset := paginationValues(input.ParticipantsPagination)
+
+	conversation, rows, err := r.ConversationService.GetConversation(ctx, user.UserID, input.ConversationID, limit, offset)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.NotFoundError{
+				ErrorMessage: "the conversation was not found",
+				Code:         customerrors.CodeResourceNotFound,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrForbidden) {
+			return model.ForbiddenError{
+				ErrorMessage: "you are not a participant of this conversation",
+				Code:         customerrors.CodeForbidden,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to get the conversation",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	participants := make([]*model.ConversationParticipant, 0, len(*rows))
+
+	for _, row := range *rows {
+		participants = append(participants, toConversationParticipant(row.ConversationParticipant, row.User))
+	}
+
+	return &model.ConversationQuerySuccess{
+		Success:      true,
+		Conversation: toConversation(*conversation),
+		Participants: participants,
+	}, nil
+}
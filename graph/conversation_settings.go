@@ -0,0 +1,46 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph/model"
+	"golang-whatsapp-clone/service"
+	"time"
+)
+
+var muteDurations = map[model.MuteDurationEnum]time.Duration{
+	model.MuteDurationEnumEightHours: 8 * time.Hour,
+	model.MuteDurationEnumOneWeek:    7 * 24 * time.Hour,
+}
+
+// toUpdateConversationSettingsResult maps the result of the mutations that change the settings
+// of a conversation for the user, all of them fail in the same ways
+func toUpdateConversationSettingsResult(participant *db.ConversationParticipant, err error) model.UpdateConversationSettingsResult {
+	if err != nil {
+		if errors.Is(err, customerrors.ErrConversationPinLimitReached) {
+			return model.ValidationError{
+				ErrorMessage: fmt.Sprintf("you can only pin up to %d conversations", service.MaxPinnedConversations),
+				Code:         customerrors.CodeValidationError,
+			}
+		}
+
+		if errors.Is(err, customerrors.ErrForbidden) || errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.ForbiddenError{
+				ErrorMessage: "you are not a participant of this conversation",
+				Code:         customerrors.CodeForbidden,
+			}
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to update the conversation settings",
+			Code:         customerrors.CodeInternalError,
+		}
+	}
+
+	return &model.UpdateConversationSettingsSuccess{
+		Success:  true,
+		Settings: toParticipantSettings(*participant, time.Now()),
+	}
+}
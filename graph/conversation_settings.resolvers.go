@@ -0,0 +1,90 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"golang-whatsapp-clone/graph/model"
+	"time"
+)
+
+// ArchiveConversation is the resolver for the archiveConversation field.
+func (r *mutationResolver) ArchiveConversation(ctx context.Context, input model.ArchiveConversationInput) (model.UpdateConversationSettingsResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	participant, err := r.ConversationService.ArchiveConversation(ctx, user.UserID, input.ConversationID, true)
+
+	return toUpdateConversationSettingsResult(participant, err), nil
+}
+
+// UnarchiveConversation is the resolver for the unarchiveConversation field.
+func (r *mutationResolver) UnarchiveConversation(ctx context.Context, input model.UnarchiveConversationInput) (model.UpdateConversationSettingsResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	participant, err := r.ConversationService.ArchiveConversation(ctx, user.UserID, input.ConversationID, false)
+
+	return toUpdateConversationSettingsResult(participant, err), nil
+}
+
+// MuteConversation is the resolver for the muteConversation field.
+func (r *mutationResolver) MuteConversation(ctx context.Context, input model.MuteConversationInput) (model.UpdateConversationSettingsResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	// ALWAYS has no duration, the conversation stays muted until the user unmutes it
+	var until *time.Time
+	if duration, found := muteDurations[input.Duration]; found {
+		mutedUntil := time.Now().Add(duration)
+		until = &mutedUntil
+	}
+
+	participant, err := r.ConversationService.MuteConversation(ctx, user.UserID, input.ConversationID, until)
+
+	return toUpdateConversationSettingsResult(participant, err), nil
+}
+
+// UnmuteConversation is the resolver for the unmuteConversation field.
+func (r *mutationResolver) UnmuteConversation(ctx context.Context, input model.UnmuteConversationInput) (model.UpdateConversationSettingsResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	participant, err := r.ConversationService.UnmuteConversation(ctx, user.UserID, input.ConversationID)
+
+	return toUpdateConversationSettingsResult(participant, err), nil
+}
+
+// PinConversation is the resolver for the pinConversation field.
+func (r *mutationResolver) PinConversation(ctx context.Context, input model.PinConversationInput) (model.UpdateConversationSettingsResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	participant, err := r.ConversationService.PinConversation(ctx, user.UserID, input.ConversationID)
+
+	return toUpdateConversationSettingsResult(participant, err), nil
+}
+
+// UnpinConversation is the resolver for the unpinConversation field.
+func (r *mutationResolver) UnpinConversation(ctx context.Context, input model.UnpinConversationInput) (model.UpdateConversationSettingsResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	participant, err := r.ConversationService.UnpinConversation(ctx, user.UserID, input.ConversationID)
+
+	return toUpdateConversationSettingsResult(participant, err), nil
+}
@@ -0,0 +1,86 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph/model"
+)
+
+// SetDisappearingMessages is the resolver for the setDisappearingMessages field.
+func (r *mutationResolver) SetDisappearingMessages(ctx context.Context, input model.SetDisappearingMessagesInput) (model.SetDisappearingMessagesResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	duration, found := disappearingMessagesTimers[input.Timer]
+	if !found {
+		return model.ValidationError{
+			ErrorMessage: "invalid disappearing messages timer",
+			Code:         customerrors.CodeValidationError,
+		}, nil
+	}
+
+	conversation, err := r.ConversationService.SetDisappearingMessages(ctx, user.UserID, input.ConversationID, duration)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidDisappearingTimer) {
+			return model.ValidationError{
+				ErrorMessage: "invalid disappearing messages timer",
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.NotFoundError{
+				ErrorMessage: "the conversation was not found",
+				Code:         customerrors.CodeResourceNotFound,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrForbidden) {
+			return model.ForbiddenError{
+				ErrorMessage: "only the admins can change the disappearing messages of the group",
+				Code:         customerrors.CodeForbidden,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to change the disappearing messages timer",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	return &model.SetDisappearingMessagesSuccess{
+		Success:      true,
+		Conversation: toConversation(*conversation),
+	}, nil
+}
+
+// MessagesExpired is the resolver for the messagesExpired field.
+func (r *subscriptionResolver) MessagesExpired(ctx context.Context, input model.MessagesExpiredSubscriptionInput) (<-chan *model.MessagesExpiredEvent, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return nil, errors.New(graphqlError.ErrorMessage)
+	}
+
+	err := r.ConversationService.EnsureParticipant(ctx, input.ConversationID, user.UserID)
+	if err != nil {
+		return nil, errors.New("you are not a participant of this conversation")
+	}
+
+	expiredChannel := r.SubscriptionManager.SubscribeToExpiredMessages(input.ConversationID)
+
+	go func() {
+		<-ctx.Done()
+		r.SubscriptionManager.UnsubscribeFromExpiredMessages(input.ConversationID, expiredChannel)
+
+		r.Logger.Info().Msgf("Client disconnected from conversation %s expired messages subscription\n", input.ConversationID)
+	}()
+
+	return expiredChannel, nil
+}
@@ -0,0 +1,105 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph/model"
+	"golang-whatsapp-clone/service"
+	"time"
+)
+
+// SaveDraft is the resolver for the saveDraft field.
+func (r *mutationResolver) SaveDraft(ctx context.Context, input model.SaveDraftInput) (model.SaveDraftResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	draft, err := r.DraftService.SaveDraft(ctx, user.UserID, input.ConversationID, input.Content, input.ReplyToMessageID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrMessageTooLong) {
+			return model.ValidationError{
+				ErrorMessage: fmt.Sprintf("the message can't be longer than %d characters", service.MaxMessageLength),
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrForbidden) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.ForbiddenError{
+				ErrorMessage: "you are not a participant of this conversation",
+				Code:         customerrors.CodeForbidden,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to save the draft",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	var result *model.MessageDraft
+	if draft != nil {
+		result = toMessageDraft(*draft)
+	}
+
+	r.SubscriptionManager.BroadcastDraft(user.UserID, &model.DraftUpdatedEvent{
+		ConversationID: input.ConversationID,
+		Draft:          result,
+		Timestamp:      time.Now(),
+	})
+
+	return &model.SaveDraftSuccess{
+		Success: true,
+		Draft:   result,
+	}, nil
+}
+
+// ClearDraft is the resolver for the clearDraft field.
+func (r *mutationResolver) ClearDraft(ctx context.Context, input model.ClearDraftInput) (model.ClearDraftResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	cleared, err := r.DraftService.ClearDraft(ctx, user.UserID, input.ConversationID)
+	if err != nil && !errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+		return model.ServerError{
+			ErrorMessage: "Error to clear the draft",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	if cleared {
+		r.SubscriptionManager.BroadcastDraft(user.UserID, &model.DraftUpdatedEvent{
+			ConversationID: input.ConversationID,
+			Timestamp:      time.Now(),
+		})
+	}
+
+	return &model.ClearDraftSuccess{Success: true}, nil
+}
+
+// DraftUpdated is the resolver for the draftUpdated field.
+func (r *subscriptionResolver) DraftUpdated(ctx context.Context) (<-chan *model.DraftUpdatedEvent, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return nil, errors.New(graphqlError.ErrorMessage)
+	}
+
+	draftChannel := r.SubscriptionManager.SubscribeToDrafts(user.UserID)
+
+	go func() {
+		<-ctx.Done()
+		r.SubscriptionManager.UnsubscribeFromDrafts(user.UserID, draftChannel)
+
+		r.Logger.Info().Msgf("Client disconnected from user %s drafts subscription\n", user.UserID)
+	}()
+
+	return draftChannel, nil
+}
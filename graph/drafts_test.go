@@ -0,0 +1,206 @@
+package graph_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"golang-whatsapp-clone/auth"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph"
+	"golang-whatsapp-clone/graph/model"
+	"golang-whatsapp-clone/handler"
+	"golang-whatsapp-clone/repository"
+	"golang-whatsapp-clone/service"
+	"golang-whatsapp-clone/subscriptions"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+)
+
+type fakeDraftRepository struct {
+	repository.DraftRepository
+	drafts map[string]db.MessageDraft
+}
+
+func (r *fakeDraftRepository) SaveDraft(ctx context.Context, userID string, conversationID string, content string, replyToMessageID *string) (*db.MessageDraft, error) {
+	draft := db.MessageDraft{
+		ConversationID: pgtype.UUID{Bytes: uuid.MustParse(conversationID), Valid: true},
+		Content:        content,
+		UpdatedAt:      timestamp(time.Now()),
+	}
+	r.drafts[conversationID] = draft
+
+	return &draft, nil
+}
+
+func (r *fakeDraftRepository) DeleteDraft(ctx context.Context, userID string, conversationID string) (bool, error) {
+	_, ok := r.drafts[conversationID]
+	delete(r.drafts, conversationID)
+
+	return ok, nil
+}
+
+type fakeSendMessageRepository struct {
+	repository.MessageRepository
+}
+
+func (r *fakeSendMessageRepository) CreateMessage(ctx context.Context, conversationID string, senderID string, content string, messageType string, replyToMessageID *string) (*db.Message, error) {
+	return &db.Message{
+		ID:             newUUID(),
+		ConversationID: pgtype.UUID{Bytes: uuid.MustParse(conversationID), Valid: true},
+		SenderID:       pgtype.UUID{Bytes: uuid.MustParse(senderID), Valid: true},
+		Content:        content,
+		MessageType:    messageType,
+		CreatedAt:      timestamp(time.Now()),
+	}, nil
+}
+
+// fakeMemberParticipantRepository knows the conversations of a single user
+type fakeMemberParticipantRepository struct {
+	repository.ParticipantRepository
+	conversationIDs []string
+}
+
+func (r *fakeMemberParticipantRepository) GetParticipant(ctx context.Context, userID string, conversationID string) (*db.ConversationParticipant, error) {
+	if !slices.Contains(r.conversationIDs, conversationID) {
+		return nil, customerrors.ErrResourceNotFound
+	}
+
+	return &db.ConversationParticipant{}, nil
+}
+
+func receiveDraftEvent(t *testing.T, drafts <-chan *model.DraftUpdatedEvent) *model.DraftUpdatedEvent {
+	t.Helper()
+
+	select {
+	case event := <-drafts:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("expected a draft event")
+		return nil
+	}
+}
+
+func TestDraftIsClearedWhenTheMessageIsSent(t *testing.T) {
+	userID := uuid.NewString()
+	conversationID := uuid.NewString()
+
+	draftRepository := &fakeDraftRepository{drafts: map[string]db.MessageDraft{}}
+	participantRepository := &fakeMemberParticipantRepository{conversationIDs: []string{conversationID}}
+	subscriptionManager := subscriptions.NewSubscriptionManager()
+
+	logger := zerolog.Nop()
+	resolver := &graph.Resolver{
+		Logger:              &logger,
+		MessageService:      service.NewMessageService(&fakeSendMessageRepository{}, participantRepository, nil),
+		DraftService:        service.NewDraftService(draftRepository, participantRepository),
+		LinkPreviewService:  service.NewLinkPreviewService(nil, nil, &logger),
+		SubscriptionManager: subscriptionManager,
+	}
+	gqlClient := client.New(handler.NewGraphqlHandler(&logger, resolver, auth.NewJWTService("access-secret", "refresh-secret"), nil))
+	asUser := func(request *client.Request) {
+		request.HTTP = request.HTTP.WithContext(auth.WithUserContext(request.HTTP.Context(), userID, "ana@example.com", uuid.NewString()))
+	}
+
+	// the other devices of the user are subscribed to its drafts
+	drafts := subscriptionManager.SubscribeToDrafts(userID)
+	defer subscriptionManager.UnsubscribeFromDrafts(userID, drafts)
+
+	var saved struct {
+		SaveDraft struct {
+			Success bool
+		}
+	}
+	gqlClient.MustPost(`
+		mutation($conversationId: ID!) {
+			saveDraft(input: { conversationId: $conversationId, content: "Hello" }) {
+				... on SaveDraftSuccess { success }
+			}
+		}
+	`, &saved, asUser, client.Var("conversationId", conversationID))
+
+	if !saved.SaveDraft.Success {
+		t.Fatal("expected the draft to be saved")
+	}
+
+	event := receiveDraftEvent(t, drafts)
+	if event.ConversationID != conversationID || event.Draft == nil || event.Draft.Content != "Hello" {
+		t.Errorf("expected the saved draft to be broadcast, got %+v", event)
+	}
+
+	var sent struct {
+		SendMessage struct {
+			Success bool
+		}
+	}
+	gqlClient.MustPost(`
+		mutation($conversationId: ID!) {
+			sendMessage(input: { conversationId: $conversationId, content: "Hello", messageType: TEXT }) {
+				... on SendMessageSuccess { success }
+			}
+		}
+	`, &sent, asUser, client.Var("conversationId", conversationID))
+
+	if !sent.SendMessage.Success {
+		t.Fatal("expected the message to be sent")
+	}
+
+	if len(draftRepository.drafts) != 0 {
+		t.Errorf("expected the draft to be removed, got %v", draftRepository.drafts)
+	}
+
+	event = receiveDraftEvent(t, drafts)
+	if event.ConversationID != conversationID || event.Draft != nil {
+		t.Errorf("expected the cleared draft to be broadcast, got %+v", event)
+	}
+
+	// without a draft there is nothing to tell the other devices
+	gqlClient.MustPost(`
+		mutation($conversationId: ID!) {
+			sendMessage(input: { conversationId: $conversationId, content: "Again", messageType: TEXT }) {
+				... on SendMessageSuccess { success }
+			}
+		}
+	`, &sent, asUser, client.Var("conversationId", conversationID))
+
+	select {
+	case event := <-drafts:
+		t.Errorf("expected no draft event, got %+v", event)
+	default:
+	}
+}
+
+func TestSendMessageToAConversationOfOtherUsers(t *testing.T) {
+	logger := zerolog.Nop()
+	resolver := &graph.Resolver{
+		Logger:         &logger,
+		MessageService: service.NewMessageService(&fakeSendMessageRepository{}, &fakeMemberParticipantRepository{}, nil),
+	}
+	gqlClient := client.New(handler.NewGraphqlHandler(&logger, resolver, auth.NewJWTService("access-secret", "refresh-secret"), nil))
+
+	var sent struct {
+		SendMessage struct {
+			Typename string `json:"__typename"`
+			Code     string
+		}
+	}
+	gqlClient.MustPost(`
+		mutation($conversationId: ID!) {
+			sendMessage(input: { conversationId: $conversationId, content: "Hi @ana", messageType: TEXT }) {
+				__typename
+				... on ForbiddenError { code }
+			}
+		}
+	`, &sent, func(request *client.Request) {
+		request.HTTP = request.HTTP.WithContext(auth.WithUserContext(request.HTTP.Context(), uuid.NewString(), "ana@example.com", uuid.NewString()))
+	}, client.Var("conversationId", uuid.NewString()))
+
+	if sent.SendMessage.Typename != "ForbiddenError" || sent.SendMessage.Code != customerrors.CodeForbidden {
+		t.Errorf("expected a ForbiddenError, got %+v", sent.SendMessage)
+	}
+}
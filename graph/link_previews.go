@@ -0,0 +1,20 @@
+package graph
+
+import (
+	db "golang-whatsapp-clone/database/gen"
+	"golang-whatsapp-clone/graph/model"
+)
+
+// enqueueLinkPreview unfurls the link of the message in the background and notifies the
+// conversation when the preview is ready
+func (r *Resolver) enqueueLinkPreview(message *db.Message) {
+	conversationID := message.ConversationID.String()
+
+	r.LinkPreviewService.Enqueue(message, func(preview *db.MessageLinkPreview) {
+		r.SubscriptionManager.BroadcastLinkPreview(conversationID, &model.MessageLinkPreviewEvent{
+			ConversationID: conversationID,
+			MessageID:      preview.MessageID.String(),
+			Preview:        toLinkPreview(*preview),
+		})
+	})
+}
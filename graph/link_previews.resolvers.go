@@ -0,0 +1,50 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+	"golang-whatsapp-clone/graph/model"
+)
+
+// LinkPreview is the resolver for the linkPreview field.
+func (r *messageResolver) LinkPreview(ctx context.Context, obj *model.Message) (*model.LinkPreview, error) {
+	preview, err := r.loaders(ctx).LinkPreview.Load(ctx, obj.ID)
+	if err != nil {
+		r.Logger.Error().Msgf("error to get the link preview of the message %s: %v", obj.ID, err)
+		return nil, nil
+	}
+
+	if preview == nil {
+		return nil, nil
+	}
+
+	return toLinkPreview(*preview), nil
+}
+
+// MessageLinkPreviewReady is the resolver for the messageLinkPreviewReady field.
+func (r *subscriptionResolver) MessageLinkPreviewReady(ctx context.Context, input model.MessageLinkPreviewReadySubscriptionInput) (<-chan *model.MessageLinkPreviewEvent, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return nil, errors.New(graphqlError.ErrorMessage)
+	}
+
+	err := r.ConversationService.EnsureParticipant(ctx, input.ConversationID, user.UserID)
+	if err != nil {
+		return nil, errors.New("you are not a participant of this conversation")
+	}
+
+	previewChannel := r.SubscriptionManager.SubscribeToLinkPreviews(input.ConversationID)
+
+	go func() {
+		<-ctx.Done()
+		r.SubscriptionManager.UnsubscribeFromLinkPreviews(input.ConversationID, previewChannel)
+
+		r.Logger.Info().Msgf("Client disconnected from conversation %s link previews subscription\n", input.ConversationID)
+	}()
+
+	return previewChannel, nil
+}
@@ -0,0 +1,97 @@
+package graph
+
+import (
+	"context"
+
+	"golang-whatsapp-clone/auth"
+	db "golang-whatsapp-clone/database/gen"
+	"golang-whatsapp-clone/dataloader"
+)
+
+type loadersContextKey struct{}
+
+// Loaders batch the lookups made by the field resolvers of a list, so a list of conversations
+// loads all the last messages, participants and users with one query each, and a list of
+// messages loads all their reactions, reply counts, mentions and link previews the same way
+type Loaders struct {
+	LastMessage  *dataloader.Loader[string, *db.GetLastMessageRow]
+	Participants *dataloader.Loader[string, []db.ConversationParticipant]
+	User         *dataloader.Loader[string, *db.User]
+	Reactions    *dataloader.Loader[string, []db.GetMessageReactionSummariesRow]
+	ReplyCount   *dataloader.Loader[string, int32]
+	Mentions     *dataloader.Loader[string, []db.GetMessageMentionsRow]
+	LinkPreview  *dataloader.Loader[string, *db.MessageLinkPreview]
+}
+
+func (r *Resolver) newLoaders() *Loaders {
+	wait := r.LoaderWait
+	if wait == 0 {
+		wait = dataloader.DefaultWait
+	}
+
+	return &Loaders{
+		LastMessage: dataloader.NewLoader(func(ctx context.Context, conversationIDs []string) (map[string]*db.GetLastMessageRow, error) {
+			lastMessages, err := r.ConversationService.GetLastMessages(ctx, conversationIDs)
+			if err != nil {
+				return nil, err
+			}
+
+			return toPointerMap(lastMessages), nil
+		}, wait, dataloader.DefaultMaxBatch),
+		Participants: dataloader.NewLoader(r.ConversationService.GetActiveParticipants, wait, dataloader.DefaultMaxBatch),
+		User: dataloader.NewLoader(func(ctx context.Context, userIDs []string) (map[string]*db.User, error) {
+			users, err := r.UserService.GetUsersByIDs(ctx, userIDs)
+			if err != nil {
+				return nil, err
+			}
+
+			return toPointerMap(users), nil
+		}, wait, dataloader.DefaultMaxBatch),
+		// the loaders live during a request, so every message is seen by the same user
+		Reactions: dataloader.NewLoader(func(ctx context.Context, messageIDs []string) (map[string][]db.GetMessageReactionSummariesRow, error) {
+			user := auth.GetUserFromContext(ctx)
+			if user == nil {
+				return map[string][]db.GetMessageReactionSummariesRow{}, nil
+			}
+
+			return r.ReactionService.GetReactionSummaries(ctx, messageIDs, user.UserID)
+		}, wait, dataloader.DefaultMaxBatch),
+		ReplyCount: dataloader.NewLoader(r.MessageService.CountReplies, wait, dataloader.DefaultMaxBatch),
+		Mentions:   dataloader.NewLoader(r.MessageService.GetMentions, wait, dataloader.DefaultMaxBatch),
+		LinkPreview: dataloader.NewLoader(func(ctx context.Context, messageIDs []string) (map[string]*db.MessageLinkPreview, error) {
+			previews, err := r.LinkPreviewService.GetLinkPreviews(ctx, messageIDs)
+			if err != nil {
+				return nil, err
+			}
+
+			return toPointerMap(previews), nil
+		}, wait, dataloader.DefaultMaxBatch),
+	}
+}
+
+// WithLoaders attaches new loaders to the context. It must be called for every response, a
+// subscription sends many of them and the loaders cache the results
+func (r *Resolver) WithLoaders(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersContextKey{}, r.newLoaders())
+}
+
+// loaders returns the loaders of the request, or new ones that only batch the calls of the
+// current resolver when the request doesn't have them
+func (r *Resolver) loaders(ctx context.Context) *Loaders {
+	loaders, ok := ctx.Value(loadersContextKey{}).(*Loaders)
+	if !ok {
+		return r.newLoaders()
+	}
+
+	return loaders
+}
+
+func toPointerMap[V any](values map[string]V) map[string]*V {
+	result := make(map[string]*V, len(values))
+	for key := range values {
+		value := values[key]
+		result[key] = &value
+	}
+
+	return result
+}
@@ -0,0 +1,337 @@
+package graph_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-whatsapp-clone/auth"
+	db "golang-whatsapp-clone/database/gen"
+	"golang-whatsapp-clone/graph"
+	"golang-whatsapp-clone/graph/model"
+	"golang-whatsapp-clone/handler"
+	"golang-whatsapp-clone/repository"
+	"golang-whatsapp-clone/service"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+)
+
+// queryCounter counts the calls to the repository methods, every call is one SQL query
+type queryCounter struct {
+	userConversations atomic.Int32
+	lastMessage       atomic.Int32
+	lastMessages      atomic.Int32
+	participants      atomic.Int32
+	participantUsers  atomic.Int32
+	users             atomic.Int32
+	reactions         atomic.Int32
+	replyCounts       atomic.Int32
+	mentions          atomic.Int32
+	linkPreviews      atomic.Int32
+}
+
+type fakeConversationRepository struct {
+	repository.ConversationRepository
+	counter       *queryCounter
+	conversations []db.GetUserConversationsRow
+	lastMessages  map[string]db.GetLastMessageRow
+}
+
+func (r *fakeConversationRepository) GetUserConversations(ctx context.Context, userID string, includeArchived bool) (*[]db.GetUserConversationsRow, error) {
+	r.counter.userConversations.Add(1)
+	return &r.conversations, nil
+}
+
+func (r *fakeConversationRepository) GetLastMessageFromConversation(ctx context.Context, conversationID string) (*db.GetLastMessageRow, error) {
+	r.counter.lastMessage.Add(1)
+	lastMessage := r.lastMessages[conversationID]
+	return &lastMessage, nil
+}
+
+func (r *fakeConversationRepository) GetLastMessages(ctx context.Context, conversationIDs []string) (*[]db.GetLastMessageRow, error) {
+	r.counter.lastMessages.Add(1)
+
+	rows := []db.GetLastMessageRow{}
+	for _, conversationID := range conversationIDs {
+		if lastMessage, ok := r.lastMessages[conversationID]; ok {
+			rows = append(rows, lastMessage)
+		}
+	}
+
+	return &rows, nil
+}
+
+type fakeParticipantRepository struct {
+	repository.ParticipantRepository
+	counter      *queryCounter
+	participants map[string][]db.ConversationParticipant
+}
+
+func (r *fakeParticipantRepository) GetParticipantUsers(ctx context.Context, conversationID string) (*[]db.User, error) {
+	r.counter.participantUsers.Add(1)
+	return &[]db.User{}, nil
+}
+
+func (r *fakeParticipantRepository) GetActiveParticipants(ctx context.Context, conversationIDs []string) (*[]db.ConversationParticipant, error) {
+	r.counter.participants.Add(1)
+
+	rows := []db.ConversationParticipant{}
+	for _, conversationID := range conversationIDs {
+		rows = append(rows, r.participants[conversationID]...)
+	}
+
+	return &rows, nil
+}
+
+type fakeUserRepository struct {
+	repository.UserRepository
+	counter *queryCounter
+	users   map[string]db.User
+}
+
+func (r *fakeUserRepository) GetUsersByIDs(ctx context.Context, userIDs []string) (*[]db.User, error) {
+	r.counter.users.Add(1)
+
+	rows := []db.User{}
+	for _, userID := range userIDs {
+		if user, ok := r.users[userID]; ok {
+			rows = append(rows, user)
+		}
+	}
+
+	return &rows, nil
+}
+
+// fakeMessageFieldsRepository answers the queries of the fields of the messages, every message
+// has one reaction, one reply, one mention and one preview
+type fakeMessageFieldsRepository struct {
+	repository.ReactionRepository
+	repository.MessageRepository
+	repository.MentionRepository
+	repository.LinkPreviewRepository
+	counter *queryCounter
+	mention db.User
+}
+
+func (r *fakeMessageFieldsRepository) GetReactionSummaries(ctx context.Context, messageIDs []string, viewerID string) (*[]db.GetMessageReactionSummariesRow, error) {
+	r.counter.reactions.Add(1)
+
+	rows := []db.GetMessageReactionSummariesRow{}
+	for _, messageID := range messageIDs {
+		rows = append(rows, db.GetMessageReactionSummariesRow{MessageID: toUUID(messageID), Emoji: "👍", ReactionCount: 1})
+	}
+
+	return &rows, nil
+}
+
+func (r *fakeMessageFieldsRepository) CountMessageReplies(ctx context.Context, messageIDs []string) (*[]db.CountMessageRepliesRow, error) {
+	r.counter.replyCounts.Add(1)
+
+	rows := []db.CountMessageRepliesRow{}
+	for _, messageID := range messageIDs {
+		rows = append(rows, db.CountMessageRepliesRow{MessageID: toUUID(messageID), ReplyCount: 1})
+	}
+
+	return &rows, nil
+}
+
+func (r *fakeMessageFieldsRepository) GetMessageMentions(ctx context.Context, messageIDs []string) (*[]db.GetMessageMentionsRow, error) {
+	r.counter.mentions.Add(1)
+
+	rows := []db.GetMessageMentionsRow{}
+	for _, messageID := range messageIDs {
+		rows = append(rows, db.GetMessageMentionsRow{MessageID: toUUID(messageID), Length: 3, User: r.mention})
+	}
+
+	return &rows, nil
+}
+
+func (r *fakeMessageFieldsRepository) GetLinkPreviews(ctx context.Context, messageIDs []string) (*[]db.MessageLinkPreview, error) {
+	r.counter.linkPreviews.Add(1)
+
+	rows := []db.MessageLinkPreview{}
+	for _, messageID := range messageIDs {
+		rows = append(rows, db.MessageLinkPreview{MessageID: toUUID(messageID), Url: "https://example.com/" + messageID})
+	}
+
+	return &rows, nil
+}
+
+func toUUID(value string) pgtype.UUID {
+	return pgtype.UUID{Bytes: uuid.MustParse(value), Valid: true}
+}
+
+func newUUID() pgtype.UUID {
+	return pgtype.UUID{Bytes: uuid.New(), Valid: true}
+}
+
+func timestamp(value time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: value, Valid: true}
+}
+
+func TestMyConversationsQueryCount(t *testing.T) {
+	const conversationsCount = 100
+
+	counter := &queryCounter{}
+	me := db.User{ID: newUUID(), Email: "me@example.com", CreatedAt: timestamp(time.Now())}
+
+	conversationRepository := &fakeConversationRepository{counter: counter, lastMessages: map[string]db.GetLastMessageRow{}}
+	participantRepository := &fakeParticipantRepository{counter: counter, participants: map[string][]db.ConversationParticipant{}}
+	userRepository := &fakeUserRepository{counter: counter, users: map[string]db.User{me.ID.String(): me}}
+	messageFieldsRepository := &fakeMessageFieldsRepository{counter: counter, mention: me}
+
+	for i := range conversationsCount {
+		conversationID := newUUID()
+		conversationType := string(model.ConversationTypeEnumDirect)
+		if i%2 == 1 {
+			conversationType = string(model.ConversationTypeEnumGroup)
+		}
+
+		contact := db.User{
+			ID:        newUUID(),
+			Name:      pgtype.Text{String: fmt.Sprintf("contact %d", i), Valid: true},
+			Email:     fmt.Sprintf("contact%d@example.com", i),
+			CreatedAt: timestamp(time.Now()),
+		}
+		userRepository.users[contact.ID.String()] = contact
+
+		conversationRepository.conversations = append(conversationRepository.conversations, db.GetUserConversationsRow{
+			ID:               conversationID,
+			Type:             conversationType,
+			ParticipantCount: 2,
+			CreatedAt:        timestamp(time.Now()),
+			UpdatedAt:        timestamp(time.Now()),
+		})
+		conversationRepository.lastMessages[conversationID.String()] = db.GetLastMessageRow{
+			ID:             newUUID(),
+			ConversationID: conversationID,
+			SenderID:       contact.ID,
+			Content:        fmt.Sprintf("message %d", i),
+			MessageType:    repository.MESSAGE_TYPE_TEXT,
+			Status:         repository.MESSAGE_STATUS_SENT,
+			SenderEmail:    contact.Email,
+			CreatedAt:      timestamp(time.Now()),
+		}
+		participantRepository.participants[conversationID.String()] = []db.ConversationParticipant{
+			{ConversationID: conversationID, UserID: me.ID},
+			{ConversationID: conversationID, UserID: contact.ID},
+		}
+	}
+
+	logger := zerolog.Nop()
+	resolver := &graph.Resolver{
+		Logger:              &logger,
+		ConversationService: service.NewConversationService(conversationRepository, participantRepository),
+		UserService:         service.NewUserService(userRepository),
+		ReactionService:     service.NewReactionService(messageFieldsRepository, messageFieldsRepository, participantRepository),
+		MessageService:      service.NewMessageService(messageFieldsRepository, participantRepository, messageFieldsRepository),
+		LinkPreviewService:  service.NewLinkPreviewService(nil, messageFieldsRepository, &logger),
+		// a long window so a slow scheduler can't split the batches
+		LoaderWait: 100 * time.Millisecond,
+	}
+	gqlClient := client.New(handler.NewGraphqlHandler(&logger, resolver, auth.NewJWTService("access-secret", "refresh-secret"), nil))
+
+	var response struct {
+		MyConversations struct {
+			Conversations []struct {
+				ID          string
+				Participant *struct {
+					Email string
+				}
+				LastMessage *struct {
+					Content   string
+					Reactions []struct {
+						Emoji string
+					}
+					ReplyCount int32
+					Mentions   []struct {
+						User struct {
+							Email string
+						}
+					}
+					LinkPreview *struct {
+						URL string
+					}
+				}
+			}
+		}
+	}
+
+	gqlClient.MustPost(`
+		query {
+			myConversations {
+				... on MyConversationsQuerySuccess {
+					conversations {
+						... on ConversationListItemDirect {
+							id
+							participant { email }
+							lastMessage { ...messageFields }
+						}
+						... on ConversationListItemGroup {
+							id
+							lastMessage { ...messageFields }
+						}
+					}
+				}
+			}
+		}
+
+		fragment messageFields on Message {
+			content
+			reactions { emoji }
+			replyCount
+			mentions { user { email } }
+			linkPreview { url }
+		}
+	`, &response, func(request *client.Request) {
+		request.HTTP = request.HTTP.WithContext(auth.WithUserContext(request.HTTP.Context(), me.ID.String(), me.Email, uuid.NewString()))
+	})
+
+	conversations := response.MyConversations.Conversations
+	if len(conversations) != conversationsCount {
+		t.Fatalf("expected %d conversations, got %d", conversationsCount, len(conversations))
+	}
+
+	for i, conversation := range conversations {
+		if conversation.LastMessage == nil || conversation.LastMessage.Content != fmt.Sprintf("message %d", i) {
+			t.Errorf("conversation %d: unexpected last message %+v", i, conversation.LastMessage)
+		}
+
+		lastMessage := conversation.LastMessage
+		if lastMessage != nil && (len(lastMessage.Reactions) != 1 || lastMessage.ReplyCount != 1 || len(lastMessage.Mentions) != 1 || lastMessage.LinkPreview == nil) {
+			t.Errorf("conversation %d: unexpected message fields %+v", i, lastMessage)
+		}
+
+		if i%2 == 0 && (conversation.Participant == nil || conversation.Participant.Email != fmt.Sprintf("contact%d@example.com", i)) {
+			t.Errorf("conversation %d: unexpected participant %+v", i, conversation.Participant)
+		}
+	}
+
+	queries := []struct {
+		name  string
+		count int32
+		want  int32
+	}{
+		{"GetUserConversations", counter.userConversations.Load(), 1},
+		{"GetLastMessages", counter.lastMessages.Load(), 1},
+		{"GetActiveParticipants", counter.participants.Load(), 1},
+		{"GetUsersByIDs", counter.users.Load(), 1},
+		{"GetLastMessageFromConversation", counter.lastMessage.Load(), 0},
+		{"GetParticipantUsers", counter.participantUsers.Load(), 0},
+		{"GetReactionSummaries", counter.reactions.Load(), 1},
+		{"CountMessageReplies", counter.replyCounts.Load(), 1},
+		{"GetMessageMentions", counter.mentions.Load(), 1},
+		{"GetLinkPreviews", counter.linkPreviews.Load(), 1},
+	}
+
+	for _, query := range queries {
+		if query.count != query.want {
+			t.Errorf("%s: expected %d queries, got %d", query.name, query.want, query.count)
+		}
+	}
+}
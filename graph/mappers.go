@@ -0,0 +1,318 @@
+package graph
+
+import (
+	db "golang-whatsapp-clone/database/gen"
+	"golang-whatsapp-clone/graph/model"
+	"golang-whatsapp-clone/service"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func toMessageReactionSummaries(rows []db.GetMessageReactionSummariesRow) []*model.MessageReactionSummary {
+	summaries := make([]*model.MessageReactionSummary, 0, len(rows))
+
+	for _, row := range rows {
+		summaries = append(summaries, &model.MessageReactionSummary{
+			Emoji:       row.Emoji,
+			Count:       row.ReactionCount,
+			ReactedByMe: row.ReactedByMe,
+		})
+	}
+
+	return summaries
+}
+
+// toMessage converts a message row and its sender. The reply is not included because
+// only the conversation messages query joins it
+func toMessage(message db.Message, sender db.User) *model.Message {
+	return &model.Message{
+		ID:           message.ID.String(),
+		Content:      message.Content,
+		MessageType:  model.MessageTypeEnum(message.MessageType),
+		Status:       model.MessageStatusEnum(message.Status),
+		IsForwarded:  message.IsForwarded,
+		ForwardCount: message.ForwardCount,
+		ExpiresAt:    toTimePointer(message.ExpiresAt),
+		CreatedAt:    message.CreatedAt.Time,
+		EditedAt:     toTimePointer(message.EditedAt),
+		DeliveredAt:  toTimePointer(message.DeliveredAt),
+		ReadAt:       toTimePointer(message.ReadAt),
+		Sender:       toUser(sender),
+	}
+}
+
+// toLastMessage converts the last message of a conversation, the sender is joined in the row
+func toLastMessage(message db.GetLastMessageRow) *model.Message {
+	return &model.Message{
+		ID:           message.ID.String(),
+		Content:      message.Content,
+		MessageType:  model.MessageTypeEnum(message.MessageType),
+		Status:       model.MessageStatusEnum(message.Status),
+		IsForwarded:  message.IsForwarded,
+		ForwardCount: message.ForwardCount,
+		ExpiresAt:    toTimePointer(message.ExpiresAt),
+		CreatedAt:    message.CreatedAt.Time,
+		EditedAt:     toTimePointer(message.EditedAt),
+		DeliveredAt:  toTimePointer(message.DeliveredAt),
+		ReadAt:       toTimePointer(message.ReadAt),
+		Sender: &model.User{
+			ID:        message.SenderID.String(),
+			Name:      &message.SenderName.String,
+			Email:     message.SenderEmail,
+			AvatarURL: &message.SenderAvatarUrl.String,
+			CreatedAt: message.SenderCreatedAt.Time,
+			UpdatedAt: message.SenderUpdatedAt.Time,
+		},
+	}
+}
+
+// toConversationListItem builds the list item for the conversation type, the last message
+// and the direct participant are resolved by field resolvers
+func toConversationListItem(conversation db.GetUserConversationsRow, now time.Time) model.ConversationListItem {
+	if conversation.Type == string(model.ConversationTypeEnumGroup) {
+		return model.ConversationListItemGroup{
+			ID:               conversation.ID.String(),
+			Type:             model.ConversationTypeEnumGroup,
+			Name:             toStringPointer(conversation.Name),
+			AvatarURL:        toStringPointer(conversation.AvatarUrl),
+			ParticipantCount: conversation.ParticipantCount,
+			UnreadCount:      conversation.UnreadCount,
+			Draft:            toConversationDraft(conversation),
+			Settings:         toConversationSettings(conversation, now),
+			CreatedAt:        conversation.CreatedAt.Time,
+			UpdatedAt:        conversation.UpdatedAt.Time,
+		}
+	}
+
+	return model.ConversationListItemDirect{
+		ID:          conversation.ID.String(),
+		Type:        model.ConversationTypeEnumDirect,
+		UnreadCount: conversation.UnreadCount,
+		Draft:       toConversationDraft(conversation),
+		Settings:    toConversationSettings(conversation, now),
+		CreatedAt:   conversation.CreatedAt.Time,
+		UpdatedAt:   conversation.UpdatedAt.Time,
+	}
+}
+
+func toConversationParticipant(participant db.ConversationParticipant, user db.User) *model.ConversationParticipant {
+	return &model.ConversationParticipant{
+		ID:         participant.ID.String(),
+		User:       toUser(user),
+		Role:       model.ParticipantRoleEnum(strings.ToUpper(participant.Role)),
+		JoinedAt:   participant.JoinedAt.Time,
+		LastReadAt: toTimePointer(participant.LastReadAt),
+		IsActive:   participant.IsActive.Bool,
+	}
+}
+
+func toUser(user db.User) *model.User {
+	return &model.User{
+		ID:        user.ID.String(),
+		Name:      &user.Name.String,
+		Email:     user.Email,
+		AvatarURL: &user.AvatarUrl.String,
+		About:     &user.About.String,
+		CreatedAt: user.CreatedAt.Time,
+		UpdatedAt: user.UpdatedAt.Time,
+	}
+}
+
+func toTimePointer(value pgtype.Timestamptz) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.Time
+}
+
+func toLinkPreview(preview db.MessageLinkPreview) *model.LinkPreview {
+	return &model.LinkPreview{
+		URL:         preview.Url,
+		Title:       preview.Title,
+		Description: toStringPointer(preview.Description),
+		ImageURL:    toStringPointer(preview.ImageUrl),
+		SiteName:    toStringPointer(preview.SiteName),
+	}
+}
+
+func toStringPointer(value pgtype.Text) *string {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.String
+}
+
+func toTextSpans(spans []service.TextSpan) []*model.TextSpan {
+	result := make([]*model.TextSpan, 0, len(spans))
+
+	for _, span := range spans {
+		styles := make([]model.TextStyleEnum, 0, len(span.Styles))
+		for _, style := range span.Styles {
+			styles = append(styles, model.TextStyleEnum(style))
+		}
+
+		result = append(result, &model.TextSpan{
+			Text:   span.Text,
+			Styles: styles,
+		})
+	}
+
+	return result
+}
+
+func toConversation(conversation db.Conversation) *model.Conversation {
+	conversationType := model.ConversationTypeEnumDirect
+	if conversation.Type == model.ConversationTypeEnumGroup.String() {
+		conversationType = model.ConversationTypeEnumGroup
+	}
+
+	return &model.Conversation{
+		ID:                        conversation.ID.String(),
+		Type:                      conversationType,
+		Name:                      &conversation.Name.String,
+		AvatarURL:                 &conversation.AvatarUrl.String,
+		DisappearingMessagesTimer: toDisappearingMessagesTimer(conversation.DisappearingMessagesSeconds),
+		CreatedAt:                 conversation.CreatedAt.Time,
+		UpdatedAt:                 conversation.UpdatedAt.Time,
+	}
+}
+
+var disappearingMessagesTimers = map[model.DisappearingMessagesTimerEnum]time.Duration{
+	model.DisappearingMessagesTimerEnumOff:        0,
+	model.DisappearingMessagesTimerEnumOneDay:     24 * time.Hour,
+	model.DisappearingMessagesTimerEnumSevenDays:  7 * 24 * time.Hour,
+	model.DisappearingMessagesTimerEnumNinetyDays: 90 * 24 * time.Hour,
+}
+
+func toDisappearingMessagesTimer(seconds int32) model.DisappearingMessagesTimerEnum {
+	for timer, duration := range disappearingMessagesTimers {
+		if duration == time.Duration(seconds)*time.Second {
+			return timer
+		}
+	}
+
+	return model.DisappearingMessagesTimerEnumOff
+}
+
+func toScheduledMessage(scheduled db.ScheduledMessage) *model.ScheduledMessage {
+	var replyToMessageID *string
+	if scheduled.ReplyToMessageID.Valid {
+		replyID := scheduled.ReplyToMessageID.String()
+		replyToMessageID = &replyID
+	}
+
+	return &model.ScheduledMessage{
+		ID:               scheduled.ID.String(),
+		ConversationID:   scheduled.ConversationID.String(),
+		Content:          scheduled.Content,
+		MessageType:      model.MessageTypeEnum(scheduled.MessageType),
+		ReplyToMessageID: replyToMessageID,
+		SendAt:           scheduled.SendAt.Time,
+		CreatedAt:        scheduled.CreatedAt.Time,
+	}
+}
+
+func toMessageDraft(draft db.MessageDraft) *model.MessageDraft {
+	var replyToMessageID *string
+	if draft.ReplyToMessageID.Valid {
+		replyID := draft.ReplyToMessageID.String()
+		replyToMessageID = &replyID
+	}
+
+	return &model.MessageDraft{
+		ConversationID:   draft.ConversationID.String(),
+		Content:          draft.Content,
+		ReplyToMessageID: replyToMessageID,
+		UpdatedAt:        draft.UpdatedAt.Time,
+	}
+}
+
+// toConversationDraft returns the draft joined to the conversations list, nil when there is none
+func toConversationDraft(conversation db.GetUserConversationsRow) *model.MessageDraft {
+	if !conversation.DraftContent.Valid {
+		return nil
+	}
+
+	return toMessageDraft(db.MessageDraft{
+		ConversationID:   conversation.ID,
+		Content:          conversation.DraftContent.String,
+		ReplyToMessageID: conversation.DraftReplyToMessageID,
+		UpdatedAt:        conversation.DraftUpdatedAt,
+	})
+}
+
+func toConversationSettings(conversation db.GetUserConversationsRow, now time.Time) *model.ConversationSettings {
+	return toParticipantSettings(db.ConversationParticipant{
+		ConversationID: conversation.ID,
+		IsArchived:     conversation.IsArchived,
+		MutedUntil:     conversation.MutedUntil,
+		PinOrder:       conversation.PinOrder,
+	}, now)
+}
+
+func toParticipantSettings(participant db.ConversationParticipant, now time.Time) *model.ConversationSettings {
+	settings := &model.ConversationSettings{
+		ConversationID: participant.ConversationID.String(),
+		IsArchived:     participant.IsArchived,
+		IsMuted:        service.IsConversationMuted(participant.MutedUntil, now),
+		IsPinned:       participant.PinOrder.Valid,
+	}
+
+	// muted forever has no end time
+	if settings.IsMuted && participant.MutedUntil.InfinityModifier == pgtype.Finite {
+		settings.MutedUntil = &participant.MutedUntil.Time
+	}
+
+	return settings
+}
+
+func toUserPresence(presence service.Presence) *model.UserPresence {
+	return &model.UserPresence{
+		UserID:     presence.UserID,
+		IsOnline:   presence.IsOnline,
+		LastSeenAt: presence.LastSeenAt,
+	}
+}
+
+func toPrivacySettings(user db.User) *model.PrivacySettings {
+	return &model.PrivacySettings{
+		LastSeen: model.LastSeenPrivacyEnum(user.LastSeenPrivacy),
+	}
+}
+
+func toSession(session db.Session, currentSessionID string) *model.Session {
+	id := session.ID.String()
+
+	return &model.Session{
+		ID:         id,
+		ClientType: model.ClientTypeEnum(strings.ToUpper(session.ClientType)),
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IpAddress,
+		CreatedAt:  session.CreatedAt.Time,
+		LastUsedAt: session.LastUsedAt.Time,
+		IsCurrent:  id == currentSessionID,
+	}
+}
+
+func toAccountExport(export db.AccountExport, baseURL string) *model.AccountExport {
+	id := export.ID.String()
+
+	accountExport := &model.AccountExport{
+		ID:          id,
+		Status:      model.AccountExportStatusEnum(export.Status),
+		CreatedAt:   export.CreatedAt.Time,
+		CompletedAt: toTimePointer(export.CompletedAt),
+		ExpiresAt:   toTimePointer(export.ExpiresAt),
+	}
+
+	if export.Status == model.AccountExportStatusEnumReady.String() {
+		downloadURL := baseURL + "/api/v1/account/exports/" + id
+		accountExport.DownloadURL = &downloadURL
+	}
+
+	return accountExport
+}
@@ -0,0 +1,84 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph/model"
+)
+
+// Mentions is the resolver for the mentions field.
+func (r *messageResolver) Mentions(ctx context.Context, obj *model.Message) ([]*model.MessageMention, error) {
+	mentions, err := r.loaders(ctx).Mentions.Load(ctx, obj.ID)
+	if err != nil {
+		r.Logger.Error().Msgf("error to get the mentions of the message %s: %v", obj.ID, err)
+		return []*model.MessageMention{}, nil
+	}
+
+	result := make([]*model.MessageMention, 0, len(mentions))
+
+	for _, mention := range mentions {
+		result = append(result, &model.MessageMention{
+			User:   toUser(mention.User),
+			Offset: mention.OffsetStart,
+			Length: mention.Length,
+		})
+	}
+
+	return result, nil
+}
+
+// MyMentions is the resolver for the myMentions field.
+func (r *queryResolver) MyMentions(ctx context.Context, pagination *model.Pagination) (model.MyMentionsQueryResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	limit, offset := paginationValues(pagination)
+
+	rows, err := r.MessageService.GetUserMentions(ctx, user.UserID, limit, offset)
+	if err != nil {
+		return model.ServerError{
+			ErrorMessage: "Error to get the mentions",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	messages := make([]*model.MentionedMessage, 0, len(*rows))
+
+	for _, row := range *rows {
+		messages = append(messages, &model.MentionedMessage{
+			Message:        toMessage(row.Message, row.User),
+			ConversationID: row.Message.ConversationID.String(),
+		})
+	}
+
+	return &model.MyMentionsQuerySuccess{
+		Success:  true,
+		Messages: messages,
+	}, nil
+}
+
+// Mentioned is the resolver for the mentioned field.
+func (r *subscriptionResolver) Mentioned(ctx context.Context) (<-chan *model.MentionEvent, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return nil, errors.New(graphqlError.ErrorMessage)
+	}
+
+	mentionChannel := r.SubscriptionManager.SubscribeToMentions(user.UserID)
+
+	go func() {
+		<-ctx.Done()
+		r.SubscriptionManager.UnsubscribeFromMentions(user.UserID, mentionChannel)
+
+		r.Logger.Info().Msgf("Client disconnected from user %s mentions subscription\n", user.UserID)
+	}()
+
+	return mentionChannel, nil
+}
@@ -8,44 +8,37 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"golang-whatsapp-clone/auth"
 	customerrors "golang-whatsapp-clone/errors"
 	"golang-whatsapp-clone/graph/model"
+	"golang-whatsapp-clone/service"
+	"time"
 )
 
-// LastMessage is the resolver for the lastMessage field.
-func (r *conversationListItemDirectResolver) LastMessage(ctx context.Context, obj *model.ConversationListItemDirect) (*model.Message, error) {
-	lastMessage, err := r.ConversationService.GetLastMessageFromConversation(ctx, obj.ID)
-	if err != nil {
+// Participant is the resolver for the participant field.
+func (r *conversationListItemDirectResolver) Participant(ctx context.Context, obj *model.ConversationListItemDirect) (*model.User, error) {
+	user := auth.GetUserFromContext(ctx)
+	if user == nil {
 		return nil, nil
 	}
 
-	if lastMessage == nil {
+	counterpart, err := r.directCounterpart(ctx, obj.ID, user.UserID)
+	if err != nil {
+		r.Logger.Error().Msgf("error to get the participant of the conversation %s: %v", obj.ID, err)
 		return nil, nil
 	}
 
-	return &model.Message{
-		ID:          lastMessage.ID.String(),
-		Content:     lastMessage.Content,
-		MessageType: model.MessageTypeEnum(lastMessage.MessageType),
-		CreatedAt:   lastMessage.CreatedAt.Time,
-		EditedAt:    &lastMessage.EditedAt.Time,
-		Status:      model.MessageStatusEnum(lastMessage.Status),
-		DeliveredAt: &lastMessage.DeliveredAt.Time,
-		ReadAt:      &lastMessage.CreatedAt.Time,
-		Sender: &model.User{
-			ID:        lastMessage.SenderID.String(),
-			Name:      &lastMessage.SenderName.String,
-			Email:     lastMessage.SenderEmail,
-			AvatarURL: &lastMessage.SenderAvatarUrl.String,
-			CreatedAt: lastMessage.SenderCreatedAt.Time,
-			UpdatedAt: lastMessage.SenderUpdatedAt.Time,
-		},
-	}, nil
+	return counterpart, nil
+}
+
+// LastMessage is the resolver for the lastMessage field.
+func (r *conversationListItemDirectResolver) LastMessage(ctx context.Context, obj *model.ConversationListItemDirect) (*model.Message, error) {
+	return r.conversationLastMessage(ctx, obj.ID), nil
 }
 
 // LastMessage is the resolver for the lastMessage field.
 func (r *conversationListItemGroupResolver) LastMessage(ctx context.Context, obj *model.ConversationListItemGroup) (*model.Message, error) {
-	panic(fmt.Errorf("not implemented: LastMessage - lastMessage"))
+	return r.conversationLastMessage(ctx, obj.ID), nil
 }
 
 // SendMessage is the resolver for the sendMessage field.
@@ -55,35 +48,52 @@ func (r *mutationResolver) SendMessage(ctx context.Context, input model.SendMess
 		return graphqlError, nil
 	}
 
-	message, err := r.MessageService.CreateMessage(
+	message, mentions, err := r.MessageService.CreateMessage(
 		ctx,
 		input.ConversationID,
-		input.SenderID,
+		user.UserID,
 		input.Content,
 		string(input.MessageType),
 		input.ReplyToMessageID,
 	)
 
 	if err != nil {
+		if errors.Is(err, customerrors.ErrForbidden) {
+			return model.ForbiddenError{
+				ErrorMessage: "you are not a participant of this conversation",
+				Code:         customerrors.CodeForbidden,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrEmptyMessage) {
+			return model.ValidationError{
+				ErrorMessage: "the message can't be empty",
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrMessageTooLong) {
+			return model.ValidationError{
+				ErrorMessage: fmt.Sprintf("the message can't be longer than %d characters", service.MaxMessageLength),
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrInvalidReply) {
+			return model.ValidationError{
+				ErrorMessage: "the replied message is not in this conversation",
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
 		return model.ServerError{
 			ErrorMessage: "Error to send the message",
 			Code:         customerrors.CodeInternalError,
 		}, nil
 	}
 
-	// convert to graphql type
-	replyId := message.ReplyToMessageID.String()
-
-	m := &model.MessageAddedEvent{
-		ID:               message.ID.String(),
-		SenderUserID:     user.UserID,
-		Content:          message.Content,
-		ReplyToMessageID: &replyId,
-		MessageType:      model.MessageTypeEnum(message.MessageType),
-	}
-
-	// 🚀 BROADCAST the message to all subscribers!
-	r.SubscriptionManager.BroadcastMessage(input.ConversationID, m)
+	r.PublishMessage(message, mentions)
+	r.clearSentDraft(ctx, user.UserID, input.ConversationID)
 
 	return &model.SendMessageSuccess{Success: true}, nil
 }
@@ -115,76 +125,110 @@ func (r *mutationResolver) StartDirectConversation(ctx context.Context, input mo
 		}, nil
 	}
 
-	conversationType := model.ConversationTypeEnumDirect
-	if conversation.Type == model.ConversationTypeEnumGroup.String() {
-		conversationType = model.ConversationTypeEnumGroup
+	return model.StartDirectConversationSuccess{
+		Success:      true,
+		Conversation: toConversation(*conversation),
+	}, nil
+}
+
+// ForwardMessage is the resolver for the forwardMessage field.
+func (r *mutationResolver) ForwardMessage(ctx context.Context, input model.ForwardMessageInput) (model.ForwardMessageResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
 	}
 
-	return model.StartDirectConversationSuccess{
-		Success: true,
-		Conversation: &model.Conversation{
-			ID:        conversation.ID.String(),
-			Type:      conversationType,
-			Name:      &conversation.Name.String,
-			AvatarURL: &conversation.AvatarUrl.String,
-			CreatedAt: conversation.CreatedAt.Time,
-			UpdatedAt: conversation.UpdatedAt.Time,
-		},
+	messages, err := r.MessageService.ForwardMessage(ctx, user.UserID, input.MessageID, input.ConversationIds)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrTooManyForwardTargets) {
+			return model.ValidationError{
+				ErrorMessage: fmt.Sprintf("a message can be forwarded to between 1 and %d conversations", service.MaxForwardTargets),
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.NotFoundError{
+				ErrorMessage: "the message was not found",
+				Code:         customerrors.CodeResourceNotFound,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrForbidden) {
+			return model.ForbiddenError{
+				ErrorMessage: "you can't forward this message to the selected conversations",
+				Code:         customerrors.CodeForbidden,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to forward the message",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	forwarded := make([]*model.ForwardedMessage, 0, len(messages))
+
+	for _, message := range messages {
+		conversationID := message.ConversationID.String()
+
+		r.SubscriptionManager.BroadcastMessage(conversationID, &model.MessageAddedEvent{
+			ID:           message.ID.String(),
+			SenderUserID: user.UserID,
+			Content:      message.Content,
+			MessageType:  model.MessageTypeEnum(message.MessageType),
+			IsForwarded:  message.IsForwarded,
+			ForwardCount: message.ForwardCount,
+		})
+
+		r.enqueueLinkPreview(message)
+
+		forwarded = append(forwarded, &model.ForwardedMessage{
+			ConversationID: conversationID,
+			MessageID:      message.ID.String(),
+		})
+	}
+
+	return &model.ForwardMessageSuccess{
+		Success:  true,
+		Messages: forwarded,
 	}, nil
 }
 
 // MyConversations is the resolver for the myConversations field.
-func (r *queryResolver) MyConversations(ctx context.Context) (model.MyConversationsQueryResult, error) {
+func (r *queryResolver) MyConversations(ctx context.Context, input *model.MyConversationsInput) (model.MyConversationsQueryResult, error) {
 	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
 
 	if graphqlError != nil {
 		return graphqlError, nil
 	}
 
-	myConversations, err := r.ConversationService.GetUserConversations(ctx, user.UserID)
+	includeArchived := input != nil && input.IncludeArchived != nil && *input.IncludeArchived
+
+	myConversations, err := r.ConversationService.GetUserConversations(ctx, user.UserID, includeArchived)
 	if err != nil {
-		if errors.Is(err, customerrors.ErrInvalidUUIDValue) {
-			return model.ServerError{
-				ErrorMessage: "invalid user id",
-				Code:         customerrors.CodeInternalError,
-			}, nil
-		}
+		return model.ServerError{
+			ErrorMessage: "Error to get the conversations",
+			Code:         customerrors.CodeInternalError,
+		}, nil
 	}
 
 	conversations := []model.ConversationListItem{}
+	totalUnreadCount := int32(0)
+	now := time.Now()
 
 	for _, conversation := range *myConversations {
-
-		// var lastMessage *model.Message
-
-		// if conversation.LastMessageID.String() != "" {
-		// 	lastMessage = &model.Message{
-		// 		ID:        conversation.LastMessageID.String(),
-		// 		Content:   conversation.LastMessageContent.String,
-		// 		CreatedAt: conversation.LastMessageCreatedAt.Time,
-		// 		Sender: &model.User{
-		// 			ID:   conversation.LastMessageSenderID.String(),
-		// 			Name: &conversation.LastMessageSenderName.String,
-		// 		},
-		// 	}
-		// }
-
-		conversationItem := model.ConversationListItemDirect{
-			ID:   conversation.ID.String(),
-			Type: (model.ConversationTypeEnum)(conversation.Type),
-			// Participant: &model.ConversationParticipant{},
-			// LastMessage:   lastMessage,
-			UnreadCount: conversation.UnreadCount,
-			CreatedAt:   conversation.CreatedAt.Time,
-			UpdatedAt:   conversation.UpdatedAt.Time,
+		if !service.IsConversationMuted(conversation.MutedUntil, now) {
+			totalUnreadCount += conversation.UnreadCount
 		}
 
-		conversations = append(conversations, conversationItem)
+		conversations = append(conversations, toConversationListItem(conversation, now))
 	}
 
 	return model.MyConversationsQuerySuccess{
-		Success:       true,
-		Conversations: conversations,
+		Success:          true,
+		Conversations:    conversations,
+		TotalUnreadCount: totalUnreadCount,
 	}, nil
 }
 
@@ -236,6 +280,9 @@ func (r *queryResolver) ConversationMessages(ctx context.Context, input model.Co
 			ReadAt:         &value.ReadAt.Time,
 			DeliveredAt:    &value.DeletedAt.Time,
 			ReplyToMessage: replyMessage,
+			IsForwarded:    value.IsForwarded,
+			ForwardCount:   value.ForwardCount,
+			ExpiresAt:      toTimePointer(value.ExpiresAt),
 			Sender: &model.User{
 				ID:        value.SenderID.String(),
 				Name:      &value.SenderName.String,
@@ -312,5 +359,9 @@ func (r *Resolver) ConversationListItemGroup() ConversationListItemGroupResolver
 	return &conversationListItemGroupResolver{r}
 }
 
+// Message returns MessageResolver implementation.
+func (r *Resolver) Message() MessageResolver { return &messageResolver{r} }
+
 type conversationListItemDirectResolver struct{ *Resolver }
 type conversationListItemGroupResolver struct{ *Resolver }
+type messageResolver struct{ *Resolver }
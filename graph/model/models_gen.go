@@ -10,6 +10,18 @@ import (
 	"time"
 )
 
+type AccountExportQueryResult interface {
+	IsAccountExportQueryResult()
+}
+
+type CancelScheduledMessageResult interface {
+	IsCancelScheduledMessageResult()
+}
+
+type ClearDraftResult interface {
+	IsClearDraftResult()
+}
+
 type ConversationListItem interface {
 	IsConversationListItem()
 }
@@ -18,6 +30,14 @@ type ConversationMessagesQueryResult interface {
 	IsConversationMessagesQueryResult()
 }
 
+type ConversationQueryResult interface {
+	IsConversationQueryResult()
+}
+
+type DeleteAccountResult interface {
+	IsDeleteAccountResult()
+}
+
 type EditMessageResult interface {
 	IsEditMessageResult()
 }
@@ -28,6 +48,10 @@ type Error interface {
 	GetErrorMessage() string
 }
 
+type ForwardMessageResult interface {
+	IsForwardMessageResult()
+}
+
 type GetOrCreateDirectConversationResult interface {
 	IsGetOrCreateDirectConversationResult()
 }
@@ -36,14 +60,86 @@ type MarkConversationAsReadResult interface {
 	IsMarkConversationAsReadResult()
 }
 
+type MessageRepliesQueryResult interface {
+	IsMessageRepliesQueryResult()
+}
+
+type MessageThreadQueryResult interface {
+	IsMessageThreadQueryResult()
+}
+
 type MyConversationsQueryResult interface {
 	IsMyConversationsQueryResult()
 }
 
+type MyMentionsQueryResult interface {
+	IsMyMentionsQueryResult()
+}
+
+type MyPrivacySettingsQueryResult interface {
+	IsMyPrivacySettingsQueryResult()
+}
+
+type MyScheduledMessagesQueryResult interface {
+	IsMyScheduledMessagesQueryResult()
+}
+
+type MySessionsQueryResult interface {
+	IsMySessionsQueryResult()
+}
+
+type MyStarredMessagesQueryResult interface {
+	IsMyStarredMessagesQueryResult()
+}
+
+type PinMessageResult interface {
+	IsPinMessageResult()
+}
+
+type PinnedMessagesQueryResult interface {
+	IsPinnedMessagesQueryResult()
+}
+
+type ReactToMessageResult interface {
+	IsReactToMessageResult()
+}
+
+type RemoveReactionResult interface {
+	IsRemoveReactionResult()
+}
+
+type RequestAccountExportResult interface {
+	IsRequestAccountExportResult()
+}
+
+type RevokeAllOtherSessionsResult interface {
+	IsRevokeAllOtherSessionsResult()
+}
+
+type RevokeSessionResult interface {
+	IsRevokeSessionResult()
+}
+
+type SaveDraftResult interface {
+	IsSaveDraftResult()
+}
+
+type ScheduleMessageResult interface {
+	IsScheduleMessageResult()
+}
+
 type SendMessageResult interface {
 	IsSendMessageResult()
 }
 
+type SetDisappearingMessagesResult interface {
+	IsSetDisappearingMessagesResult()
+}
+
+type StarMessageResult interface {
+	IsStarMessageResult()
+}
+
 type StartDirectConversationResult interface {
 	IsStartDirectConversationResult()
 }
@@ -53,39 +149,126 @@ type Success interface {
 	GetSuccess() bool
 }
 
+type UnpinMessageResult interface {
+	IsUnpinMessageResult()
+}
+
+type UnstarMessageResult interface {
+	IsUnstarMessageResult()
+}
+
+type UpdateConversationSettingsResult interface {
+	IsUpdateConversationSettingsResult()
+}
+
+type UpdatePrivacySettingsResult interface {
+	IsUpdatePrivacySettingsResult()
+}
+
+type UpdateProfileResult interface {
+	IsUpdateProfileResult()
+}
+
+type UsersPresenceQueryResult interface {
+	IsUsersPresenceQueryResult()
+}
+
+type AccountExport struct {
+	ID          string                  `json:"id"`
+	Status      AccountExportStatusEnum `json:"status"`
+	CreatedAt   time.Time               `json:"createdAt"`
+	CompletedAt *time.Time              `json:"completedAt,omitempty"`
+	ExpiresAt   *time.Time              `json:"expiresAt,omitempty"`
+	DownloadURL *string                 `json:"downloadUrl,omitempty"`
+}
+
+type AccountExportQuerySuccess struct {
+	Success bool           `json:"success"`
+	Export  *AccountExport `json:"export,omitempty"`
+}
+
+func (AccountExportQuerySuccess) IsSuccess()            {}
+func (this AccountExportQuerySuccess) GetSuccess() bool { return this.Success }
+
+func (AccountExportQuerySuccess) IsAccountExportQueryResult() {}
+
 type AppTime struct {
 	UnixTime  int32  `json:"unixTime"`
 	TimeStamp string `json:"timeStamp"`
 }
 
+type ArchiveConversationInput struct {
+	ConversationID string `json:"conversationId"`
+}
+
+type CancelScheduledMessageInput struct {
+	ScheduledMessageID string `json:"scheduledMessageId"`
+}
+
+type CancelScheduledMessageSuccess struct {
+	Success bool `json:"success"`
+}
+
+func (CancelScheduledMessageSuccess) IsSuccess()            {}
+func (this CancelScheduledMessageSuccess) GetSuccess() bool { return this.Success }
+
+func (CancelScheduledMessageSuccess) IsCancelScheduledMessageResult() {}
+
+type ClearDraftInput struct {
+	ConversationID string `json:"conversationId"`
+}
+
+type ClearDraftSuccess struct {
+	Success bool `json:"success"`
+}
+
+func (ClearDraftSuccess) IsSuccess()            {}
+func (this ClearDraftSuccess) GetSuccess() bool { return this.Success }
+
+func (ClearDraftSuccess) IsClearDraftResult() {}
+
 type Conversation struct {
-	ID          string               `json:"id"`
-	Type        ConversationTypeEnum `json:"type"`
-	Name        *string              `json:"name,omitempty"`
-	Description *string              `json:"description,omitempty"`
-	AvatarURL   *string              `json:"avatarUrl,omitempty"`
-	CreatedAt   time.Time            `json:"createdAt"`
-	UpdatedAt   time.Time            `json:"updatedAt"`
+	ID                        string                        `json:"id"`
+	Type                      ConversationTypeEnum          `json:"type"`
+	Name                      *string                       `json:"name,omitempty"`
+	Description               *string                       `json:"description,omitempty"`
+	AvatarURL                 *string                       `json:"avatarUrl,omitempty"`
+	CreatedAt                 time.Time                     `json:"createdAt"`
+	UpdatedAt                 time.Time                     `json:"updatedAt"`
+	DisappearingMessagesTimer DisappearingMessagesTimerEnum `json:"disappearingMessagesTimer"`
+}
+
+type ConversationInput struct {
+	ConversationID         string      `json:"conversationId"`
+	ParticipantsPagination *Pagination `json:"participantsPagination,omitempty"`
 }
 
 type ConversationListItemDirect struct {
-	ID          string               `json:"id"`
-	Type        ConversationTypeEnum `json:"type"`
-	LastMessage *Message             `json:"lastMessage,omitempty"`
-	UnreadCount int32                `json:"unreadCount"`
-	CreatedAt   time.Time            `json:"createdAt"`
-	UpdatedAt   time.Time            `json:"updatedAt"`
+	ID          string                `json:"id"`
+	Type        ConversationTypeEnum  `json:"type"`
+	Participant *User                 `json:"participant,omitempty"`
+	LastMessage *Message              `json:"lastMessage,omitempty"`
+	UnreadCount int32                 `json:"unreadCount"`
+	CreatedAt   time.Time             `json:"createdAt"`
+	UpdatedAt   time.Time             `json:"updatedAt"`
+	Settings    *ConversationSettings `json:"settings"`
+	Draft       *MessageDraft         `json:"draft,omitempty"`
 }
 
 func (ConversationListItemDirect) IsConversationListItem() {}
 
 type ConversationListItemGroup struct {
-	ID          string               `json:"id"`
-	Type        ConversationTypeEnum `json:"type"`
-	LastMessage *Message             `json:"lastMessage,omitempty"`
-	UnreadCount int32                `json:"unreadCount"`
-	CreatedAt   time.Time            `json:"createdAt"`
-	UpdatedAt   time.Time            `json:"updatedAt"`
+	ID               string                `json:"id"`
+	Type             ConversationTypeEnum  `json:"type"`
+	Name             *string               `json:"name,omitempty"`
+	AvatarURL        *string               `json:"avatarUrl,omitempty"`
+	ParticipantCount int32                 `json:"participantCount"`
+	LastMessage      *Message              `json:"lastMessage,omitempty"`
+	UnreadCount      int32                 `json:"unreadCount"`
+	CreatedAt        time.Time             `json:"createdAt"`
+	UpdatedAt        time.Time             `json:"updatedAt"`
+	Settings         *ConversationSettings `json:"settings"`
+	Draft            *MessageDraft         `json:"draft,omitempty"`
 }
 
 func (ConversationListItemGroup) IsConversationListItem() {}
@@ -106,17 +289,56 @@ func (this ConversationMessagesQuerySuccess) GetSuccess() bool { return this.Suc
 func (ConversationMessagesQuerySuccess) IsConversationMessagesQueryResult() {}
 
 type ConversationParticipant struct {
-	ID         string     `json:"id"`
-	User       *User      `json:"user"`
-	JoinedAt   time.Time  `json:"joinedAt"`
-	LastReadAt *time.Time `json:"lastReadAt,omitempty"`
-	IsActive   bool       `json:"isActive"`
+	ID         string              `json:"id"`
+	User       *User               `json:"user"`
+	JoinedAt   time.Time           `json:"joinedAt"`
+	LastReadAt *time.Time          `json:"lastReadAt,omitempty"`
+	IsActive   bool                `json:"isActive"`
+	Role       ParticipantRoleEnum `json:"role"`
+}
+
+type ConversationQuerySuccess struct {
+	Success      bool                       `json:"success"`
+	Conversation *Conversation              `json:"conversation"`
+	Participants []*ConversationParticipant `json:"participants"`
+}
+
+func (ConversationQuerySuccess) IsSuccess()            {}
+func (this ConversationQuerySuccess) GetSuccess() bool { return this.Success }
+
+func (ConversationQuerySuccess) IsConversationQueryResult() {}
+
+type ConversationSettings struct {
+	ConversationID string     `json:"conversationId"`
+	IsArchived     bool       `json:"isArchived"`
+	IsMuted        bool       `json:"isMuted"`
+	MutedUntil     *time.Time `json:"mutedUntil,omitempty"`
+	IsPinned       bool       `json:"isPinned"`
 }
 
 type ConversationUpdatedSubscriptionInput struct {
 	ConversationID string `json:"conversationId"`
 }
 
+type DeleteAccountInput struct {
+	ConfirmEmail string `json:"confirmEmail"`
+}
+
+type DeleteAccountSuccess struct {
+	Success bool `json:"success"`
+}
+
+func (DeleteAccountSuccess) IsSuccess()            {}
+func (this DeleteAccountSuccess) GetSuccess() bool { return this.Success }
+
+func (DeleteAccountSuccess) IsDeleteAccountResult() {}
+
+type DraftUpdatedEvent struct {
+	ConversationID string        `json:"conversationId"`
+	Draft          *MessageDraft `json:"draft,omitempty"`
+	Timestamp      time.Time     `json:"timestamp"`
+}
+
 type EditMessageInput struct {
 	MessageID string `json:"messageId"`
 	Content   string `json:"content"`
@@ -137,10 +359,60 @@ type ForbiddenError struct {
 	Code         string `json:"code"`
 }
 
+func (ForbiddenError) IsConversationQueryResult() {}
+
+func (ForbiddenError) IsUpdateConversationSettingsResult() {}
+
+func (ForbiddenError) IsSetDisappearingMessagesResult() {}
+
+func (ForbiddenError) IsSaveDraftResult() {}
+
+func (ForbiddenError) IsSendMessageResult() {}
+
+func (ForbiddenError) IsForwardMessageResult() {}
+
+func (ForbiddenError) IsPinnedMessagesQueryResult() {}
+
+func (ForbiddenError) IsPinMessageResult() {}
+
+func (ForbiddenError) IsUnpinMessageResult() {}
+
+func (ForbiddenError) IsReactToMessageResult() {}
+
+func (ForbiddenError) IsRemoveReactionResult() {}
+
 func (ForbiddenError) IsError()                     {}
 func (this ForbiddenError) GetCode() string         { return this.Code }
 func (this ForbiddenError) GetErrorMessage() string { return this.ErrorMessage }
 
+func (ForbiddenError) IsScheduleMessageResult() {}
+
+func (ForbiddenError) IsStarMessageResult() {}
+
+func (ForbiddenError) IsMessageRepliesQueryResult() {}
+
+func (ForbiddenError) IsMessageThreadQueryResult() {}
+
+type ForwardMessageInput struct {
+	MessageID       string   `json:"messageId"`
+	ConversationIds []string `json:"conversationIds"`
+}
+
+type ForwardMessageSuccess struct {
+	Success  bool                `json:"success"`
+	Messages []*ForwardedMessage `json:"messages"`
+}
+
+func (ForwardMessageSuccess) IsSuccess()            {}
+func (this ForwardMessageSuccess) GetSuccess() bool { return this.Success }
+
+func (ForwardMessageSuccess) IsForwardMessageResult() {}
+
+type ForwardedMessage struct {
+	ConversationID string `json:"conversationId"`
+	MessageID      string `json:"messageId"`
+}
+
 type GetOrCreateDirectConversationInput struct {
 	UserID string `json:"userId"`
 }
@@ -155,6 +427,14 @@ func (this GetOrCreateDirectConversationSuccess) GetSuccess() bool { return this
 
 func (GetOrCreateDirectConversationSuccess) IsGetOrCreateDirectConversationResult() {}
 
+type LinkPreview struct {
+	URL         string  `json:"url"`
+	Title       string  `json:"title"`
+	Description *string `json:"description,omitempty"`
+	ImageURL    *string `json:"imageUrl,omitempty"`
+	SiteName    *string `json:"siteName,omitempty"`
+}
+
 type MarkConversationAsReadInput struct {
 	ConversationID string `json:"conversationId"`
 }
@@ -169,17 +449,38 @@ func (this MarkConversationAsReadSuccess) GetSuccess() bool { return this.Succes
 
 func (MarkConversationAsReadSuccess) IsMarkConversationAsReadResult() {}
 
+type MentionEvent struct {
+	ConversationID string    `json:"conversationId"`
+	MessageID      string    `json:"messageId"`
+	SenderUserID   string    `json:"senderUserId"`
+	Content        string    `json:"content"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+type MentionedMessage struct {
+	Message        *Message `json:"message"`
+	ConversationID string   `json:"conversationId"`
+}
+
 type Message struct {
-	ID             string            `json:"id"`
-	Sender         *User             `json:"sender"`
-	Content        string            `json:"content"`
-	MessageType    MessageTypeEnum   `json:"messageType"`
-	Status         MessageStatusEnum `json:"status"`
-	ReplyToMessage *ReplyMessage     `json:"replyToMessage,omitempty"`
-	CreatedAt      time.Time         `json:"createdAt"`
-	EditedAt       *time.Time        `json:"editedAt,omitempty"`
-	DeliveredAt    *time.Time        `json:"deliveredAt,omitempty"`
-	ReadAt         *time.Time        `json:"readAt,omitempty"`
+	ID               string                    `json:"id"`
+	Sender           *User                     `json:"sender"`
+	Content          string                    `json:"content"`
+	MessageType      MessageTypeEnum           `json:"messageType"`
+	Status           MessageStatusEnum         `json:"status"`
+	ReplyToMessage   *ReplyMessage             `json:"replyToMessage,omitempty"`
+	IsForwarded      bool                      `json:"isForwarded"`
+	ForwardCount     int32                     `json:"forwardCount"`
+	CreatedAt        time.Time                 `json:"createdAt"`
+	EditedAt         *time.Time                `json:"editedAt,omitempty"`
+	DeliveredAt      *time.Time                `json:"deliveredAt,omitempty"`
+	ReadAt           *time.Time                `json:"readAt,omitempty"`
+	ExpiresAt        *time.Time                `json:"expiresAt,omitempty"`
+	LinkPreview      *LinkPreview              `json:"linkPreview,omitempty"`
+	Mentions         []*MessageMention         `json:"mentions"`
+	Reactions        []*MessageReactionSummary `json:"reactions"`
+	FormattedContent []*TextSpan               `json:"formattedContent"`
+	ReplyCount       int32                     `json:"replyCount"`
 }
 
 type MessageAddedEvent struct {
@@ -188,12 +489,71 @@ type MessageAddedEvent struct {
 	Content          string          `json:"content"`
 	ReplyToMessageID *string         `json:"replyToMessageId,omitempty"`
 	MessageType      MessageTypeEnum `json:"messageType"`
+	IsForwarded      bool            `json:"isForwarded"`
+	ForwardCount     int32           `json:"forwardCount"`
 }
 
 type MessageAddedSubscriptionInput struct {
 	ConversationID string `json:"conversationId"`
 }
 
+type MessageDraft struct {
+	ConversationID   string    `json:"conversationId"`
+	Content          string    `json:"content"`
+	ReplyToMessageID *string   `json:"replyToMessageId,omitempty"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+type MessageLinkPreviewEvent struct {
+	ConversationID string       `json:"conversationId"`
+	MessageID      string       `json:"messageId"`
+	Preview        *LinkPreview `json:"preview"`
+}
+
+type MessageLinkPreviewReadySubscriptionInput struct {
+	ConversationID string `json:"conversationId"`
+}
+
+type MessageMention struct {
+	User   *User `json:"user"`
+	Offset int32 `json:"offset"`
+	Length int32 `json:"length"`
+}
+
+type MessageReactionEvent struct {
+	ConversationID string    `json:"conversationId"`
+	MessageID      string    `json:"messageId"`
+	UserID         string    `json:"userId"`
+	Emoji          *string   `json:"emoji,omitempty"`
+	Removed        bool      `json:"removed"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+type MessageReactionSummary struct {
+	Emoji       string `json:"emoji"`
+	Count       int32  `json:"count"`
+	ReactedByMe bool   `json:"reactedByMe"`
+}
+
+type MessageReactionUpdatedSubscriptionInput struct {
+	ConversationID string `json:"conversationId"`
+}
+
+type MessageRepliesInput struct {
+	MessageID  string      `json:"messageId"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+type MessageRepliesQuerySuccess struct {
+	Success bool       `json:"success"`
+	Replies []*Message `json:"replies"`
+}
+
+func (MessageRepliesQuerySuccess) IsSuccess()            {}
+func (this MessageRepliesQuerySuccess) GetSuccess() bool { return this.Success }
+
+func (MessageRepliesQuerySuccess) IsMessageRepliesQueryResult() {}
+
 type MessageStatusUpdatedEvent struct {
 	ConversationID string            `json:"conversationId"`
 	MessageID      string            `json:"messageId"`
@@ -204,12 +564,46 @@ type MessageStatusUpdatedSubscriptionInput struct {
 	ConversationID string `json:"conversationId"`
 }
 
+type MessageThreadInput struct {
+	MessageID  string      `json:"messageId"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+type MessageThreadQuerySuccess struct {
+	Success  bool             `json:"success"`
+	Messages []*ThreadMessage `json:"messages"`
+}
+
+func (MessageThreadQuerySuccess) IsSuccess()            {}
+func (this MessageThreadQuerySuccess) GetSuccess() bool { return this.Success }
+
+func (MessageThreadQuerySuccess) IsMessageThreadQueryResult() {}
+
+type MessagesExpiredEvent struct {
+	ConversationID string   `json:"conversationId"`
+	MessageIds     []string `json:"messageIds"`
+}
+
+type MessagesExpiredSubscriptionInput struct {
+	ConversationID string `json:"conversationId"`
+}
+
 type Mutation struct {
 }
 
+type MuteConversationInput struct {
+	ConversationID string           `json:"conversationId"`
+	Duration       MuteDurationEnum `json:"duration"`
+}
+
+type MyConversationsInput struct {
+	IncludeArchived *bool `json:"includeArchived,omitempty"`
+}
+
 type MyConversationsQuerySuccess struct {
-	Success       bool                   `json:"success"`
-	Conversations []ConversationListItem `json:"conversations"`
+	Success          bool                   `json:"success"`
+	Conversations    []ConversationListItem `json:"conversations"`
+	TotalUnreadCount int32                  `json:"totalUnreadCount"`
 }
 
 func (MyConversationsQuerySuccess) IsSuccess()            {}
@@ -217,11 +611,70 @@ func (this MyConversationsQuerySuccess) GetSuccess() bool { return this.Success
 
 func (MyConversationsQuerySuccess) IsMyConversationsQueryResult() {}
 
+type MyMentionsQuerySuccess struct {
+	Success  bool                `json:"success"`
+	Messages []*MentionedMessage `json:"messages"`
+}
+
+func (MyMentionsQuerySuccess) IsSuccess()            {}
+func (this MyMentionsQuerySuccess) GetSuccess() bool { return this.Success }
+
+func (MyMentionsQuerySuccess) IsMyMentionsQueryResult() {}
+
+type MyPrivacySettingsQuerySuccess struct {
+	Success  bool             `json:"success"`
+	Settings *PrivacySettings `json:"settings"`
+}
+
+func (MyPrivacySettingsQuerySuccess) IsSuccess()            {}
+func (this MyPrivacySettingsQuerySuccess) GetSuccess() bool { return this.Success }
+
+func (MyPrivacySettingsQuerySuccess) IsMyPrivacySettingsQueryResult() {}
+
+type MyScheduledMessagesInput struct {
+	ConversationID *string     `json:"conversationId,omitempty"`
+	Pagination     *Pagination `json:"pagination,omitempty"`
+}
+
+type MyScheduledMessagesQuerySuccess struct {
+	Success  bool                `json:"success"`
+	Messages []*ScheduledMessage `json:"messages"`
+}
+
+func (MyScheduledMessagesQuerySuccess) IsSuccess()            {}
+func (this MyScheduledMessagesQuerySuccess) GetSuccess() bool { return this.Success }
+
+func (MyScheduledMessagesQuerySuccess) IsMyScheduledMessagesQueryResult() {}
+
+type MySessionsQuerySuccess struct {
+	Success  bool       `json:"success"`
+	Sessions []*Session `json:"sessions"`
+}
+
+func (MySessionsQuerySuccess) IsSuccess()            {}
+func (this MySessionsQuerySuccess) GetSuccess() bool { return this.Success }
+
+func (MySessionsQuerySuccess) IsMySessionsQueryResult() {}
+
+type MyStarredMessagesQuerySuccess struct {
+	Success  bool              `json:"success"`
+	Messages []*StarredMessage `json:"messages"`
+}
+
+func (MyStarredMessagesQuerySuccess) IsSuccess()            {}
+func (this MyStarredMessagesQuerySuccess) GetSuccess() bool { return this.Success }
+
+func (MyStarredMessagesQuerySuccess) IsMyStarredMessagesQueryResult() {}
+
 type NotFoundError struct {
 	ErrorMessage string `json:"errorMessage"`
 	Code         string `json:"code"`
 }
 
+func (NotFoundError) IsConversationQueryResult() {}
+
+func (NotFoundError) IsSetDisappearingMessagesResult() {}
+
 func (NotFoundError) IsConversationMessagesQueryResult() {}
 
 func (NotFoundError) IsGetOrCreateDirectConversationResult() {}
@@ -234,68 +687,363 @@ func (NotFoundError) IsEditMessageResult() {}
 
 func (NotFoundError) IsStartDirectConversationResult() {}
 
+func (NotFoundError) IsForwardMessageResult() {}
+
+func (NotFoundError) IsPinMessageResult() {}
+
+func (NotFoundError) IsUnpinMessageResult() {}
+
+func (NotFoundError) IsMyPrivacySettingsQueryResult() {}
+
+func (NotFoundError) IsUpdatePrivacySettingsResult() {}
+
+func (NotFoundError) IsReactToMessageResult() {}
+
+func (NotFoundError) IsRemoveReactionResult() {}
+
 func (NotFoundError) IsError()                     {}
 func (this NotFoundError) GetCode() string         { return this.Code }
 func (this NotFoundError) GetErrorMessage() string { return this.ErrorMessage }
 
+func (NotFoundError) IsCancelScheduledMessageResult() {}
+
+func (NotFoundError) IsRevokeSessionResult() {}
+
+func (NotFoundError) IsStarMessageResult() {}
+
+func (NotFoundError) IsMessageRepliesQueryResult() {}
+
+func (NotFoundError) IsMessageThreadQueryResult() {}
+
+func (NotFoundError) IsUpdateProfileResult() {}
+
 type Pagination struct {
 	Offset int32 `json:"offset"`
 	Limit  int32 `json:"limit"`
 }
 
-type Query struct {
-}
-
-type ReplyMessage struct {
-	ID          string          `json:"id"`
-	SenderName  string          `json:"senderName"`
-	Content     string          `json:"content"`
-	MessageType MessageTypeEnum `json:"messageType"`
+type PinConversationInput struct {
+	ConversationID string `json:"conversationId"`
 }
 
-type SendMessageInput struct {
-	ConversationID   string          `json:"conversationId"`
-	SenderID         string          `json:"senderID"`
-	Content          string          `json:"content"`
-	MessageType      MessageTypeEnum `json:"messageType"`
-	ReplyToMessageID *string         `json:"replyToMessageId,omitempty"`
+type PinMessageInput struct {
+	MessageID string `json:"messageId"`
 }
 
-type SendMessageSuccess struct {
+type PinMessageSuccess struct {
 	Success bool `json:"success"`
 }
 
-func (SendMessageSuccess) IsSuccess()            {}
-func (this SendMessageSuccess) GetSuccess() bool { return this.Success }
+func (PinMessageSuccess) IsSuccess()            {}
+func (this PinMessageSuccess) GetSuccess() bool { return this.Success }
 
-func (SendMessageSuccess) IsSendMessageResult() {}
+func (PinMessageSuccess) IsPinMessageResult() {}
 
-type ServerError struct {
-	ErrorMessage string `json:"errorMessage"`
-	Code         string `json:"code"`
+type PinnedMessage struct {
+	Message  *Message  `json:"message"`
+	PinnedBy *string   `json:"pinnedBy,omitempty"`
+	PinnedAt time.Time `json:"pinnedAt"`
 }
 
-func (ServerError) IsMyConversationsQueryResult() {}
+type PinnedMessagesInput struct {
+	ConversationID string      `json:"conversationId"`
+	Pagination     *Pagination `json:"pagination,omitempty"`
+}
 
-func (ServerError) IsConversationMessagesQueryResult() {}
+type PinnedMessagesQuerySuccess struct {
+	Success  bool             `json:"success"`
+	Messages []*PinnedMessage `json:"messages"`
+}
 
-func (ServerError) IsGetOrCreateDirectConversationResult() {}
+func (PinnedMessagesQuerySuccess) IsSuccess()            {}
+func (this PinnedMessagesQuerySuccess) GetSuccess() bool { return this.Success }
 
-func (ServerError) IsSendMessageResult() {}
+func (PinnedMessagesQuerySuccess) IsPinnedMessagesQueryResult() {}
 
-func (ServerError) IsMarkConversationAsReadResult() {}
+type PrivacySettings struct {
+	LastSeen LastSeenPrivacyEnum `json:"lastSeen"`
+}
 
-func (ServerError) IsEditMessageResult() {}
+type Query struct {
+}
 
-func (ServerError) IsStartDirectConversationResult() {}
+type RateLimitError struct {
+	ErrorMessage      string `json:"errorMessage"`
+	Code              string `json:"code"`
+	RetryAfterSeconds int32  `json:"retryAfterSeconds"`
+}
 
-func (ServerError) IsError()                     {}
-func (this ServerError) GetCode() string         { return this.Code }
-func (this ServerError) GetErrorMessage() string { return this.ErrorMessage }
+func (RateLimitError) IsRequestAccountExportResult() {}
 
-type StartDirectConversationInput struct {
-	ParticipantID string `json:"participantId"`
-}
+func (RateLimitError) IsDeleteAccountResult() {}
+
+func (RateLimitError) IsSendMessageResult() {}
+
+func (RateLimitError) IsError()                     {}
+func (this RateLimitError) GetCode() string         { return this.Code }
+func (this RateLimitError) GetErrorMessage() string { return this.ErrorMessage }
+
+type ReactToMessageInput struct {
+	MessageID string `json:"messageId"`
+	Emoji     string `json:"emoji"`
+}
+
+type ReactToMessageSuccess struct {
+	Success   bool                      `json:"success"`
+	Reactions []*MessageReactionSummary `json:"reactions"`
+}
+
+func (ReactToMessageSuccess) IsSuccess()            {}
+func (this ReactToMessageSuccess) GetSuccess() bool { return this.Success }
+
+func (ReactToMessageSuccess) IsReactToMessageResult() {}
+
+type RemoveReactionInput struct {
+	MessageID string `json:"messageId"`
+}
+
+type RemoveReactionSuccess struct {
+	Success   bool                      `json:"success"`
+	Reactions []*MessageReactionSummary `json:"reactions"`
+}
+
+func (RemoveReactionSuccess) IsSuccess()            {}
+func (this RemoveReactionSuccess) GetSuccess() bool { return this.Success }
+
+func (RemoveReactionSuccess) IsRemoveReactionResult() {}
+
+type ReplyMessage struct {
+	ID          string          `json:"id"`
+	SenderName  string          `json:"senderName"`
+	Content     string          `json:"content"`
+	MessageType MessageTypeEnum `json:"messageType"`
+}
+
+type RequestAccountExportSuccess struct {
+	Success bool           `json:"success"`
+	Export  *AccountExport `json:"export"`
+}
+
+func (RequestAccountExportSuccess) IsSuccess()            {}
+func (this RequestAccountExportSuccess) GetSuccess() bool { return this.Success }
+
+func (RequestAccountExportSuccess) IsRequestAccountExportResult() {}
+
+type RevokeAllOtherSessionsSuccess struct {
+	Success      bool  `json:"success"`
+	RevokedCount int32 `json:"revokedCount"`
+}
+
+func (RevokeAllOtherSessionsSuccess) IsSuccess()            {}
+func (this RevokeAllOtherSessionsSuccess) GetSuccess() bool { return this.Success }
+
+func (RevokeAllOtherSessionsSuccess) IsRevokeAllOtherSessionsResult() {}
+
+type RevokeSessionInput struct {
+	SessionID string `json:"sessionId"`
+}
+
+type RevokeSessionSuccess struct {
+	Success   bool   `json:"success"`
+	SessionID string `json:"sessionId"`
+}
+
+func (RevokeSessionSuccess) IsSuccess()            {}
+func (this RevokeSessionSuccess) GetSuccess() bool { return this.Success }
+
+func (RevokeSessionSuccess) IsRevokeSessionResult() {}
+
+type SaveDraftInput struct {
+	ConversationID   string  `json:"conversationId"`
+	Content          string  `json:"content"`
+	ReplyToMessageID *string `json:"replyToMessageId,omitempty"`
+}
+
+type SaveDraftSuccess struct {
+	Success bool          `json:"success"`
+	Draft   *MessageDraft `json:"draft,omitempty"`
+}
+
+func (SaveDraftSuccess) IsSuccess()            {}
+func (this SaveDraftSuccess) GetSuccess() bool { return this.Success }
+
+func (SaveDraftSuccess) IsSaveDraftResult() {}
+
+type ScheduleMessageInput struct {
+	ConversationID   string          `json:"conversationId"`
+	Content          string          `json:"content"`
+	MessageType      MessageTypeEnum `json:"messageType"`
+	ReplyToMessageID *string         `json:"replyToMessageId,omitempty"`
+	SendAt           time.Time       `json:"sendAt"`
+}
+
+type ScheduleMessageSuccess struct {
+	Success          bool              `json:"success"`
+	ScheduledMessage *ScheduledMessage `json:"scheduledMessage"`
+}
+
+func (ScheduleMessageSuccess) IsSuccess()            {}
+func (this ScheduleMessageSuccess) GetSuccess() bool { return this.Success }
+
+func (ScheduleMessageSuccess) IsScheduleMessageResult() {}
+
+type ScheduledMessage struct {
+	ID               string          `json:"id"`
+	ConversationID   string          `json:"conversationId"`
+	Content          string          `json:"content"`
+	MessageType      MessageTypeEnum `json:"messageType"`
+	ReplyToMessageID *string         `json:"replyToMessageId,omitempty"`
+	SendAt           time.Time       `json:"sendAt"`
+	CreatedAt        time.Time       `json:"createdAt"`
+}
+
+type SendMessageInput struct {
+	ConversationID   string          `json:"conversationId"`
+	SenderID         *string         `json:"senderID,omitempty"`
+	Content          string          `json:"content"`
+	MessageType      MessageTypeEnum `json:"messageType"`
+	ReplyToMessageID *string         `json:"replyToMessageId,omitempty"`
+}
+
+type SendMessageSuccess struct {
+	Success bool `json:"success"`
+}
+
+func (SendMessageSuccess) IsSuccess()            {}
+func (this SendMessageSuccess) GetSuccess() bool { return this.Success }
+
+func (SendMessageSuccess) IsSendMessageResult() {}
+
+type ServerError struct {
+	ErrorMessage string `json:"errorMessage"`
+	Code         string `json:"code"`
+}
+
+func (ServerError) IsAccountExportQueryResult() {}
+
+func (ServerError) IsRequestAccountExportResult() {}
+
+func (ServerError) IsDeleteAccountResult() {}
+
+func (ServerError) IsConversationQueryResult() {}
+
+func (ServerError) IsUpdateConversationSettingsResult() {}
+
+func (ServerError) IsSetDisappearingMessagesResult() {}
+
+func (ServerError) IsSaveDraftResult() {}
+
+func (ServerError) IsClearDraftResult() {}
+
+func (ServerError) IsMyMentionsQueryResult() {}
+
+func (ServerError) IsMyConversationsQueryResult() {}
+
+func (ServerError) IsConversationMessagesQueryResult() {}
+
+func (ServerError) IsGetOrCreateDirectConversationResult() {}
+
+func (ServerError) IsSendMessageResult() {}
+
+func (ServerError) IsMarkConversationAsReadResult() {}
+
+func (ServerError) IsEditMessageResult() {}
+
+func (ServerError) IsStartDirectConversationResult() {}
+
+func (ServerError) IsForwardMessageResult() {}
+
+func (ServerError) IsPinnedMessagesQueryResult() {}
+
+func (ServerError) IsPinMessageResult() {}
+
+func (ServerError) IsUnpinMessageResult() {}
+
+func (ServerError) IsUsersPresenceQueryResult() {}
+
+func (ServerError) IsMyPrivacySettingsQueryResult() {}
+
+func (ServerError) IsUpdatePrivacySettingsResult() {}
+
+func (ServerError) IsReactToMessageResult() {}
+
+func (ServerError) IsRemoveReactionResult() {}
+
+func (ServerError) IsError()                     {}
+func (this ServerError) GetCode() string         { return this.Code }
+func (this ServerError) GetErrorMessage() string { return this.ErrorMessage }
+
+func (ServerError) IsMyScheduledMessagesQueryResult() {}
+
+func (ServerError) IsScheduleMessageResult() {}
+
+func (ServerError) IsCancelScheduledMessageResult() {}
+
+func (ServerError) IsMySessionsQueryResult() {}
+
+func (ServerError) IsRevokeSessionResult() {}
+
+func (ServerError) IsRevokeAllOtherSessionsResult() {}
+
+func (ServerError) IsMyStarredMessagesQueryResult() {}
+
+func (ServerError) IsStarMessageResult() {}
+
+func (ServerError) IsUnstarMessageResult() {}
+
+func (ServerError) IsMessageRepliesQueryResult() {}
+
+func (ServerError) IsMessageThreadQueryResult() {}
+
+func (ServerError) IsUpdateProfileResult() {}
+
+type Session struct {
+	ID         string         `json:"id"`
+	ClientType ClientTypeEnum `json:"clientType"`
+	UserAgent  string         `json:"userAgent"`
+	IPAddress  string         `json:"ipAddress"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	LastUsedAt time.Time      `json:"lastUsedAt"`
+	IsCurrent  bool           `json:"isCurrent"`
+}
+
+type SetDisappearingMessagesInput struct {
+	ConversationID string                        `json:"conversationId"`
+	Timer          DisappearingMessagesTimerEnum `json:"timer"`
+}
+
+type SetDisappearingMessagesSuccess struct {
+	Success      bool          `json:"success"`
+	Conversation *Conversation `json:"conversation"`
+}
+
+func (SetDisappearingMessagesSuccess) IsSuccess()            {}
+func (this SetDisappearingMessagesSuccess) GetSuccess() bool { return this.Success }
+
+func (SetDisappearingMessagesSuccess) IsSetDisappearingMessagesResult() {}
+
+type StarMessageInput struct {
+	MessageID string `json:"messageId"`
+}
+
+type StarMessageSuccess struct {
+	Success bool `json:"success"`
+}
+
+func (StarMessageSuccess) IsSuccess()            {}
+func (this StarMessageSuccess) GetSuccess() bool { return this.Success }
+
+func (StarMessageSuccess) IsStarMessageResult() {}
+
+type StarredMessage struct {
+	Message        *Message  `json:"message"`
+	ConversationID string    `json:"conversationId"`
+	StarredAt      time.Time `json:"starredAt"`
+}
+
+type StartDirectConversationInput struct {
+	ParticipantID string `json:"participantId"`
+}
 
 type StartDirectConversationSuccess struct {
 	Success      bool          `json:"success"`
@@ -310,6 +1058,17 @@ func (StartDirectConversationSuccess) IsStartDirectConversationResult() {}
 type Subscription struct {
 }
 
+type TextSpan struct {
+	Text   string          `json:"text"`
+	Styles []TextStyleEnum `json:"styles"`
+}
+
+type ThreadMessage struct {
+	Message         *Message `json:"message"`
+	ParentMessageID *string  `json:"parentMessageId,omitempty"`
+	Depth           int32    `json:"depth"`
+}
+
 type TypingEvent struct {
 	User           *User     `json:"user"`
 	IsTyping       bool      `json:"isTyping"`
@@ -317,50 +1076,351 @@ type TypingEvent struct {
 	Timestamp      time.Time `json:"timestamp"`
 }
 
+type UnarchiveConversationInput struct {
+	ConversationID string `json:"conversationId"`
+}
+
 type UnauthorizedError struct {
 	ErrorMessage string `json:"errorMessage"`
 	Code         string `json:"code"`
 }
 
+func (UnauthorizedError) IsAccountExportQueryResult() {}
+
+func (UnauthorizedError) IsRequestAccountExportResult() {}
+
+func (UnauthorizedError) IsDeleteAccountResult() {}
+
+func (UnauthorizedError) IsConversationQueryResult() {}
+
+func (UnauthorizedError) IsUpdateConversationSettingsResult() {}
+
+func (UnauthorizedError) IsSetDisappearingMessagesResult() {}
+
+func (UnauthorizedError) IsSaveDraftResult() {}
+
+func (UnauthorizedError) IsClearDraftResult() {}
+
+func (UnauthorizedError) IsMyMentionsQueryResult() {}
+
 func (UnauthorizedError) IsMyConversationsQueryResult() {}
 
-func (UnauthorizedError) IsConversationMessagesQueryResult() {}
+func (UnauthorizedError) IsConversationMessagesQueryResult() {}
+
+func (UnauthorizedError) IsGetOrCreateDirectConversationResult() {}
+
+func (UnauthorizedError) IsSendMessageResult() {}
+
+func (UnauthorizedError) IsMarkConversationAsReadResult() {}
+
+func (UnauthorizedError) IsEditMessageResult() {}
+
+func (UnauthorizedError) IsStartDirectConversationResult() {}
+
+func (UnauthorizedError) IsForwardMessageResult() {}
+
+func (UnauthorizedError) IsPinnedMessagesQueryResult() {}
+
+func (UnauthorizedError) IsPinMessageResult() {}
+
+func (UnauthorizedError) IsUnpinMessageResult() {}
+
+func (UnauthorizedError) IsUsersPresenceQueryResult() {}
+
+func (UnauthorizedError) IsMyPrivacySettingsQueryResult() {}
+
+func (UnauthorizedError) IsUpdatePrivacySettingsResult() {}
+
+func (UnauthorizedError) IsReactToMessageResult() {}
+
+func (UnauthorizedError) IsRemoveReactionResult() {}
+
+func (UnauthorizedError) IsError()                     {}
+func (this UnauthorizedError) GetCode() string         { return this.Code }
+func (this UnauthorizedError) GetErrorMessage() string { return this.ErrorMessage }
+
+func (UnauthorizedError) IsMyScheduledMessagesQueryResult() {}
+
+func (UnauthorizedError) IsScheduleMessageResult() {}
+
+func (UnauthorizedError) IsCancelScheduledMessageResult() {}
+
+func (UnauthorizedError) IsMySessionsQueryResult() {}
+
+func (UnauthorizedError) IsRevokeSessionResult() {}
+
+func (UnauthorizedError) IsRevokeAllOtherSessionsResult() {}
+
+func (UnauthorizedError) IsMyStarredMessagesQueryResult() {}
+
+func (UnauthorizedError) IsStarMessageResult() {}
+
+func (UnauthorizedError) IsUnstarMessageResult() {}
+
+func (UnauthorizedError) IsMessageRepliesQueryResult() {}
+
+func (UnauthorizedError) IsMessageThreadQueryResult() {}
+
+func (UnauthorizedError) IsUpdateProfileResult() {}
+
+type UnmuteConversationInput struct {
+	ConversationID string `json:"conversationId"`
+}
+
+type UnpinConversationInput struct {
+	ConversationID string `json:"conversationId"`
+}
+
+type UnpinMessageInput struct {
+	MessageID string `json:"messageId"`
+}
+
+type UnpinMessageSuccess struct {
+	Success bool `json:"success"`
+}
+
+func (UnpinMessageSuccess) IsSuccess()            {}
+func (this UnpinMessageSuccess) GetSuccess() bool { return this.Success }
+
+func (UnpinMessageSuccess) IsUnpinMessageResult() {}
+
+type UnstarMessageInput struct {
+	MessageID string `json:"messageId"`
+}
+
+type UnstarMessageSuccess struct {
+	Success bool `json:"success"`
+}
+
+func (UnstarMessageSuccess) IsSuccess()            {}
+func (this UnstarMessageSuccess) GetSuccess() bool { return this.Success }
+
+func (UnstarMessageSuccess) IsUnstarMessageResult() {}
+
+type UpdateConversationSettingsSuccess struct {
+	Success  bool                  `json:"success"`
+	Settings *ConversationSettings `json:"settings"`
+}
+
+func (UpdateConversationSettingsSuccess) IsSuccess()            {}
+func (this UpdateConversationSettingsSuccess) GetSuccess() bool { return this.Success }
+
+func (UpdateConversationSettingsSuccess) IsUpdateConversationSettingsResult() {}
+
+type UpdateLastSeenPrivacyInput struct {
+	LastSeen LastSeenPrivacyEnum `json:"lastSeen"`
+}
+
+type UpdatePrivacySettingsSuccess struct {
+	Success  bool             `json:"success"`
+	Settings *PrivacySettings `json:"settings"`
+}
+
+func (UpdatePrivacySettingsSuccess) IsSuccess()            {}
+func (this UpdatePrivacySettingsSuccess) GetSuccess() bool { return this.Success }
+
+func (UpdatePrivacySettingsSuccess) IsUpdatePrivacySettingsResult() {}
+
+type UpdateProfileInput struct {
+	Name      *string `json:"name,omitempty"`
+	About     *string `json:"about,omitempty"`
+	AvatarURL *string `json:"avatarUrl,omitempty"`
+}
+
+type UpdateProfileSuccess struct {
+	Success bool  `json:"success"`
+	User    *User `json:"user"`
+}
+
+func (UpdateProfileSuccess) IsSuccess()            {}
+func (this UpdateProfileSuccess) GetSuccess() bool { return this.Success }
+
+func (UpdateProfileSuccess) IsUpdateProfileResult() {}
+
+type User struct {
+	ID        string    `json:"id"`
+	Name      *string   `json:"name,omitempty"`
+	Email     string    `json:"email"`
+	AvatarURL *string   `json:"avatarUrl,omitempty"`
+	About     *string   `json:"about,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type UserPresence struct {
+	UserID     string     `json:"userId"`
+	IsOnline   bool       `json:"isOnline"`
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+}
+
+type UserTypingSubscriptionInput struct {
+	ConversationID string `json:"conversationId"`
+}
+
+type UsersPresenceInput struct {
+	UserIds []string `json:"userIds"`
+}
+
+type UsersPresenceQuerySuccess struct {
+	Success   bool            `json:"success"`
+	Presences []*UserPresence `json:"presences"`
+}
+
+func (UsersPresenceQuerySuccess) IsSuccess()            {}
+func (this UsersPresenceQuerySuccess) GetSuccess() bool { return this.Success }
+
+func (UsersPresenceQuerySuccess) IsUsersPresenceQueryResult() {}
+
+type ValidationError struct {
+	ErrorMessage string `json:"errorMessage"`
+	Code         string `json:"code"`
+}
+
+func (ValidationError) IsDeleteAccountResult() {}
+
+func (ValidationError) IsUpdateConversationSettingsResult() {}
+
+func (ValidationError) IsSetDisappearingMessagesResult() {}
+
+func (ValidationError) IsSaveDraftResult() {}
+
+func (ValidationError) IsSendMessageResult() {}
+
+func (ValidationError) IsForwardMessageResult() {}
+
+func (ValidationError) IsPinMessageResult() {}
+
+func (ValidationError) IsUsersPresenceQueryResult() {}
+
+func (ValidationError) IsUpdatePrivacySettingsResult() {}
+
+func (ValidationError) IsReactToMessageResult() {}
+
+func (ValidationError) IsError()                     {}
+func (this ValidationError) GetCode() string         { return this.Code }
+func (this ValidationError) GetErrorMessage() string { return this.ErrorMessage }
+
+func (ValidationError) IsScheduleMessageResult() {}
+
+func (ValidationError) IsCancelScheduledMessageResult() {}
+
+func (ValidationError) IsRevokeSessionResult() {}
+
+func (ValidationError) IsUpdateProfileResult() {}
+
+type AccountExportStatusEnum string
+
+const (
+	AccountExportStatusEnumPending    AccountExportStatusEnum = "PENDING"
+	AccountExportStatusEnumProcessing AccountExportStatusEnum = "PROCESSING"
+	AccountExportStatusEnumReady      AccountExportStatusEnum = "READY"
+	AccountExportStatusEnumFailed     AccountExportStatusEnum = "FAILED"
+)
+
+var AllAccountExportStatusEnum = []AccountExportStatusEnum{
+	AccountExportStatusEnumPending,
+	AccountExportStatusEnumProcessing,
+	AccountExportStatusEnumReady,
+	AccountExportStatusEnumFailed,
+}
+
+func (e AccountExportStatusEnum) IsValid() bool {
+	switch e {
+	case AccountExportStatusEnumPending, AccountExportStatusEnumProcessing, AccountExportStatusEnumReady, AccountExportStatusEnumFailed:
+		return true
+	}
+	return false
+}
+
+func (e AccountExportStatusEnum) String() string {
+	return string(e)
+}
+
+func (e *AccountExportStatusEnum) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AccountExportStatusEnum(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AccountExportStatusEnum", str)
+	}
+	return nil
+}
+
+func (e AccountExportStatusEnum) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *AccountExportStatusEnum) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e AccountExportStatusEnum) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
 
-func (UnauthorizedError) IsGetOrCreateDirectConversationResult() {}
+type ClientTypeEnum string
 
-func (UnauthorizedError) IsSendMessageResult() {}
+const (
+	ClientTypeEnumWeb    ClientTypeEnum = "WEB"
+	ClientTypeEnumMobile ClientTypeEnum = "MOBILE"
+)
 
-func (UnauthorizedError) IsMarkConversationAsReadResult() {}
+var AllClientTypeEnum = []ClientTypeEnum{
+	ClientTypeEnumWeb,
+	ClientTypeEnumMobile,
+}
 
-func (UnauthorizedError) IsEditMessageResult() {}
+func (e ClientTypeEnum) IsValid() bool {
+	switch e {
+	case ClientTypeEnumWeb, ClientTypeEnumMobile:
+		return true
+	}
+	return false
+}
 
-func (UnauthorizedError) IsStartDirectConversationResult() {}
+func (e ClientTypeEnum) String() string {
+	return string(e)
+}
 
-func (UnauthorizedError) IsError()                     {}
-func (this UnauthorizedError) GetCode() string         { return this.Code }
-func (this UnauthorizedError) GetErrorMessage() string { return this.ErrorMessage }
+func (e *ClientTypeEnum) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
 
-type User struct {
-	ID        string    `json:"id"`
-	Name      *string   `json:"name,omitempty"`
-	Email     string    `json:"email"`
-	AvatarURL *string   `json:"avatarUrl,omitempty"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	*e = ClientTypeEnum(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ClientTypeEnum", str)
+	}
+	return nil
 }
 
-type UserTypingSubscriptionInput struct {
-	ConversationID string `json:"conversationId"`
+func (e ClientTypeEnum) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-type ValidationError struct {
-	ErrorMessage string `json:"errorMessage"`
-	Code         string `json:"code"`
+func (e *ClientTypeEnum) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
 }
 
-func (ValidationError) IsError()                     {}
-func (this ValidationError) GetCode() string         { return this.Code }
-func (this ValidationError) GetErrorMessage() string { return this.ErrorMessage }
+func (e ClientTypeEnum) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
 
 type ConversationTypeEnum string
 
@@ -417,6 +1477,122 @@ func (e ConversationTypeEnum) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+type DisappearingMessagesTimerEnum string
+
+const (
+	DisappearingMessagesTimerEnumOff        DisappearingMessagesTimerEnum = "OFF"
+	DisappearingMessagesTimerEnumOneDay     DisappearingMessagesTimerEnum = "ONE_DAY"
+	DisappearingMessagesTimerEnumSevenDays  DisappearingMessagesTimerEnum = "SEVEN_DAYS"
+	DisappearingMessagesTimerEnumNinetyDays DisappearingMessagesTimerEnum = "NINETY_DAYS"
+)
+
+var AllDisappearingMessagesTimerEnum = []DisappearingMessagesTimerEnum{
+	DisappearingMessagesTimerEnumOff,
+	DisappearingMessagesTimerEnumOneDay,
+	DisappearingMessagesTimerEnumSevenDays,
+	DisappearingMessagesTimerEnumNinetyDays,
+}
+
+func (e DisappearingMessagesTimerEnum) IsValid() bool {
+	switch e {
+	case DisappearingMessagesTimerEnumOff, DisappearingMessagesTimerEnumOneDay, DisappearingMessagesTimerEnumSevenDays, DisappearingMessagesTimerEnumNinetyDays:
+		return true
+	}
+	return false
+}
+
+func (e DisappearingMessagesTimerEnum) String() string {
+	return string(e)
+}
+
+func (e *DisappearingMessagesTimerEnum) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DisappearingMessagesTimerEnum(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DisappearingMessagesTimerEnum", str)
+	}
+	return nil
+}
+
+func (e DisappearingMessagesTimerEnum) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *DisappearingMessagesTimerEnum) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e DisappearingMessagesTimerEnum) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type LastSeenPrivacyEnum string
+
+const (
+	LastSeenPrivacyEnumEveryone LastSeenPrivacyEnum = "EVERYONE"
+	LastSeenPrivacyEnumContacts LastSeenPrivacyEnum = "CONTACTS"
+	LastSeenPrivacyEnumNobody   LastSeenPrivacyEnum = "NOBODY"
+)
+
+var AllLastSeenPrivacyEnum = []LastSeenPrivacyEnum{
+	LastSeenPrivacyEnumEveryone,
+	LastSeenPrivacyEnumContacts,
+	LastSeenPrivacyEnumNobody,
+}
+
+func (e LastSeenPrivacyEnum) IsValid() bool {
+	switch e {
+	case LastSeenPrivacyEnumEveryone, LastSeenPrivacyEnumContacts, LastSeenPrivacyEnumNobody:
+		return true
+	}
+	return false
+}
+
+func (e LastSeenPrivacyEnum) String() string {
+	return string(e)
+}
+
+func (e *LastSeenPrivacyEnum) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LastSeenPrivacyEnum(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LastSeenPrivacyEnum", str)
+	}
+	return nil
+}
+
+func (e LastSeenPrivacyEnum) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *LastSeenPrivacyEnum) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e LastSeenPrivacyEnum) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type MessageStatusEnum string
 
 const (
@@ -532,3 +1708,176 @@ func (e MessageTypeEnum) MarshalJSON() ([]byte, error) {
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
+
+type MuteDurationEnum string
+
+const (
+	MuteDurationEnumEightHours MuteDurationEnum = "EIGHT_HOURS"
+	MuteDurationEnumOneWeek    MuteDurationEnum = "ONE_WEEK"
+	MuteDurationEnumAlways     MuteDurationEnum = "ALWAYS"
+)
+
+var AllMuteDurationEnum = []MuteDurationEnum{
+	MuteDurationEnumEightHours,
+	MuteDurationEnumOneWeek,
+	MuteDurationEnumAlways,
+}
+
+func (e MuteDurationEnum) IsValid() bool {
+	switch e {
+	case MuteDurationEnumEightHours, MuteDurationEnumOneWeek, MuteDurationEnumAlways:
+		return true
+	}
+	return false
+}
+
+func (e MuteDurationEnum) String() string {
+	return string(e)
+}
+
+func (e *MuteDurationEnum) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MuteDurationEnum(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MuteDurationEnum", str)
+	}
+	return nil
+}
+
+func (e MuteDurationEnum) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *MuteDurationEnum) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e MuteDurationEnum) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ParticipantRoleEnum string
+
+const (
+	ParticipantRoleEnumMember ParticipantRoleEnum = "MEMBER"
+	ParticipantRoleEnumAdmin  ParticipantRoleEnum = "ADMIN"
+	ParticipantRoleEnumOwner  ParticipantRoleEnum = "OWNER"
+)
+
+var AllParticipantRoleEnum = []ParticipantRoleEnum{
+	ParticipantRoleEnumMember,
+	ParticipantRoleEnumAdmin,
+	ParticipantRoleEnumOwner,
+}
+
+func (e ParticipantRoleEnum) IsValid() bool {
+	switch e {
+	case ParticipantRoleEnumMember, ParticipantRoleEnumAdmin, ParticipantRoleEnumOwner:
+		return true
+	}
+	return false
+}
+
+func (e ParticipantRoleEnum) String() string {
+	return string(e)
+}
+
+func (e *ParticipantRoleEnum) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ParticipantRoleEnum(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ParticipantRoleEnum", str)
+	}
+	return nil
+}
+
+func (e ParticipantRoleEnum) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ParticipantRoleEnum) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ParticipantRoleEnum) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type TextStyleEnum string
+
+const (
+	TextStyleEnumBold          TextStyleEnum = "BOLD"
+	TextStyleEnumItalic        TextStyleEnum = "ITALIC"
+	TextStyleEnumStrikethrough TextStyleEnum = "STRIKETHROUGH"
+	TextStyleEnumCode          TextStyleEnum = "CODE"
+)
+
+var AllTextStyleEnum = []TextStyleEnum{
+	TextStyleEnumBold,
+	TextStyleEnumItalic,
+	TextStyleEnumStrikethrough,
+	TextStyleEnumCode,
+}
+
+func (e TextStyleEnum) IsValid() bool {
+	switch e {
+	case TextStyleEnumBold, TextStyleEnumItalic, TextStyleEnumStrikethrough, TextStyleEnumCode:
+		return true
+	}
+	return false
+}
+
+func (e TextStyleEnum) String() string {
+	return string(e)
+}
+
+func (e *TextStyleEnum) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TextStyleEnum(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TextStyleEnum", str)
+	}
+	return nil
+}
+
+func (e TextStyleEnum) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *TextStyleEnum) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e TextStyleEnum) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,28 @@
+package graph
+
+import "golang-whatsapp-clone/graph/model"
+
+const (
+	defaultPageSize int32 = 20
+	maxPageSize     int32 = 100
+)
+
+// paginationValues returns the limit and offset to use in the queries, the pagination is optional
+// in most of the queries so we fallback to the first page
+func paginationValues(pagination *model.Pagination) (limit int32, offset int32) {
+	if pagination == nil {
+		return defaultPageSize, 0
+	}
+
+	limit = pagination.Limit
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	offset = max(pagination.Offset, 0)
+
+	return limit, offset
+}
@@ -0,0 +1,131 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph/model"
+	"golang-whatsapp-clone/service"
+)
+
+// PinMessage is the resolver for the pinMessage field.
+func (r *mutationResolver) PinMessage(ctx context.Context, input model.PinMessageInput) (model.PinMessageResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	_, err := r.PinService.PinMessage(ctx, user.UserID, input.MessageID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrPinLimitReached) {
+			return model.ValidationError{
+				ErrorMessage: fmt.Sprintf("a conversation can't have more than %d pinned messages", service.MaxPinnedMessages),
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.NotFoundError{
+				ErrorMessage: "the message was not found",
+				Code:         customerrors.CodeResourceNotFound,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrForbidden) {
+			return model.ForbiddenError{
+				ErrorMessage: "only the admins can pin messages in this conversation",
+				Code:         customerrors.CodeForbidden,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to pin the message",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	return &model.PinMessageSuccess{Success: true}, nil
+}
+
+// UnpinMessage is the resolver for the unpinMessage field.
+func (r *mutationResolver) UnpinMessage(ctx context.Context, input model.UnpinMessageInput) (model.UnpinMessageResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	_, err := r.PinService.UnpinMessage(ctx, user.UserID, input.MessageID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.NotFoundError{
+				ErrorMessage: "the message was not found",
+				Code:         customerrors.CodeResourceNotFound,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrForbidden) {
+			return model.ForbiddenError{
+				ErrorMessage: "only the admins can unpin messages in this conversation",
+				Code:         customerrors.CodeForbidden,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to unpin the message",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	return &model.UnpinMessageSuccess{Success: true}, nil
+}
+
+// PinnedMessages is the resolver for the pinnedMessages field.
+func (r *queryResolver) PinnedMessages(ctx context.Context, input model.PinnedMessagesInput) (model.PinnedMessagesQueryResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	limit, offset := paginationValues(input.Pagination)
+
+	rows, err := r.PinService.GetPinnedMessages(ctx, user.UserID, input.ConversationID, limit, offset)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrForbidden) {
+			return model.ForbiddenError{
+				ErrorMessage: "you are not a participant of this conversation",
+				Code:         customerrors.CodeForbidden,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to get the pinned messages",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	messages := make([]*model.PinnedMessage, 0, len(*rows))
+
+	for _, row := range *rows {
+		var pinnedBy *string
+		if row.PinnedBy.Valid {
+			id := row.PinnedBy.String()
+			pinnedBy = &id
+		}
+
+		messages = append(messages, &model.PinnedMessage{
+			Message:  toMessage(row.Message, row.User),
+			PinnedBy: pinnedBy,
+			PinnedAt: row.PinnedAt.Time,
+		})
+	}
+
+	return &model.PinnedMessagesQuerySuccess{
+		Success:  true,
+		Messages: messages,
+	}, nil
+}
@@ -0,0 +1,86 @@
+package graph_test
+
+import (
+	"context"
+	"testing"
+
+	"golang-whatsapp-clone/auth"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph"
+	"golang-whatsapp-clone/handler"
+	"golang-whatsapp-clone/repository"
+	"golang-whatsapp-clone/service"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+)
+
+type fakePinMessageRepository struct {
+	repository.MessageRepository
+	message *db.Message
+}
+
+func (r *fakePinMessageRepository) GetMessageByID(ctx context.Context, messageID string) (*db.Message, error) {
+	return r.message, nil
+}
+
+type fakePinConversationRepository struct {
+	repository.ConversationRepository
+}
+
+func (r *fakePinConversationRepository) GetConversationByID(ctx context.Context, conversationID string) (*db.Conversation, error) {
+	return &db.Conversation{Type: "DIRECT"}, nil
+}
+
+// fakeFullPinnedMessageRepository rejects every pin like a conversation at the limit
+type fakeFullPinnedMessageRepository struct {
+	repository.PinnedMessageRepository
+}
+
+func (r *fakeFullPinnedMessageRepository) PinMessage(ctx context.Context, conversationID string, messageID string, pinnedBy string, limit int32) error {
+	return customerrors.ErrPinLimitReached
+}
+
+func TestPinMessageLimitReached(t *testing.T) {
+	conversationID := uuid.NewString()
+	message := &db.Message{
+		ID:             newUUID(),
+		ConversationID: pgtype.UUID{Bytes: uuid.MustParse(conversationID), Valid: true},
+	}
+
+	logger := zerolog.Nop()
+	resolver := &graph.Resolver{
+		Logger: &logger,
+		PinService: service.NewPinService(
+			&fakeFullPinnedMessageRepository{},
+			&fakePinMessageRepository{message: message},
+			&fakePinConversationRepository{},
+			&fakeMemberParticipantRepository{conversationIDs: []string{conversationID}},
+		),
+	}
+	gqlClient := client.New(handler.NewGraphqlHandler(&logger, resolver, auth.NewJWTService("access-secret", "refresh-secret"), nil))
+
+	var pinned struct {
+		PinMessage struct {
+			Typename string `json:"__typename"`
+			Code     string
+		}
+	}
+	gqlClient.MustPost(`
+		mutation($messageId: ID!) {
+			pinMessage(input: { messageId: $messageId }) {
+				__typename
+				... on ValidationError { code }
+			}
+		}
+	`, &pinned, func(request *client.Request) {
+		request.HTTP = request.HTTP.WithContext(auth.WithUserContext(request.HTTP.Context(), uuid.NewString(), "ana@example.com", uuid.NewString()))
+	}, client.Var("messageId", message.ID.String()))
+
+	if pinned.PinMessage.Typename != "ValidationError" || pinned.PinMessage.Code != customerrors.CodeValidationError {
+		t.Errorf("expected a ValidationError, got %+v", pinned.PinMessage)
+	}
+}
@@ -0,0 +1,137 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph/model"
+	"golang-whatsapp-clone/service"
+)
+
+// UpdateLastSeenPrivacy is the resolver for the updateLastSeenPrivacy field.
+func (r *mutationResolver) UpdateLastSeenPrivacy(ctx context.Context, input model.UpdateLastSeenPrivacyInput) (model.UpdatePrivacySettingsResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	updated, err := r.PresenceService.SetLastSeenPrivacy(ctx, user.UserID, string(input.LastSeen))
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidPrivacySetting) {
+			return model.ValidationError{
+				ErrorMessage: "invalid last seen privacy",
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.NotFoundError{
+				ErrorMessage: "the user was not found",
+				Code:         customerrors.CodeResourceNotFound,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to update the privacy settings",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	return &model.UpdatePrivacySettingsSuccess{
+		Success:  true,
+		Settings: toPrivacySettings(*updated),
+	}, nil
+}
+
+// UsersPresence is the resolver for the usersPresence field.
+func (r *queryResolver) UsersPresence(ctx context.Context, input model.UsersPresenceInput) (model.UsersPresenceQueryResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	presences, err := r.PresenceService.GetPresences(ctx, user.UserID, input.UserIds)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrTooManyPresenceUsers) {
+			return model.ValidationError{
+				ErrorMessage: fmt.Sprintf("the presence of at most %d users can be requested at once", service.MaxPresenceUsers),
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.ValidationError{
+				ErrorMessage: "invalid user id",
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to get the presence of the users",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	result := make([]*model.UserPresence, 0, len(presences))
+
+	for _, presence := range presences {
+		result = append(result, toUserPresence(presence))
+	}
+
+	return &model.UsersPresenceQuerySuccess{
+		Success:   true,
+		Presences: result,
+	}, nil
+}
+
+// MyPrivacySettings is the resolver for the myPrivacySettings field.
+func (r *queryResolver) MyPrivacySettings(ctx context.Context) (model.MyPrivacySettingsQueryResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	me, err := r.UserService.GetUserByID(ctx, user.UserID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.NotFoundError{
+				ErrorMessage: "the user was not found",
+				Code:         customerrors.CodeResourceNotFound,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to get the privacy settings",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	return &model.MyPrivacySettingsQuerySuccess{
+		Success:  true,
+		Settings: toPrivacySettings(*me),
+	}, nil
+}
+
+// ContactPresenceChanged is the resolver for the contactPresenceChanged field.
+func (r *subscriptionResolver) ContactPresenceChanged(ctx context.Context) (<-chan *model.UserPresence, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return nil, errors.New(graphqlError.ErrorMessage)
+	}
+
+	presenceChannel := r.SubscriptionManager.SubscribeToContactsPresence(user.UserID)
+
+	go func() {
+		<-ctx.Done()
+		r.SubscriptionManager.UnsubscribeFromContactsPresence(user.UserID, presenceChannel)
+
+		r.Logger.Info().Msgf("Client disconnected from user %s contacts presence subscription\n", user.UserID)
+	}()
+
+	return presenceChannel, nil
+}
@@ -0,0 +1,82 @@
+package graph
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	"golang-whatsapp-clone/graph/model"
+	"golang-whatsapp-clone/service"
+	"time"
+)
+
+// PublishMessage notifies a new message to the subscribers of its conversation and to the
+// mentioned users, and starts its link preview. It's the path of every created message,
+// sent right away or by the scheduler
+func (r *Resolver) PublishMessage(message *db.Message, mentions []service.Mention) {
+	conversationID := message.ConversationID.String()
+
+	// convert to graphql type
+	replyId := message.ReplyToMessageID.String()
+
+	m := &model.MessageAddedEvent{
+		ID:               message.ID.String(),
+		SenderUserID:     message.SenderID.String(),
+		Content:          message.Content,
+		ReplyToMessageID: &replyId,
+		MessageType:      model.MessageTypeEnum(message.MessageType),
+		IsForwarded:      message.IsForwarded,
+		ForwardCount:     message.ForwardCount,
+	}
+
+	// 🚀 BROADCAST the message to all subscribers!
+	r.SubscriptionManager.BroadcastMessage(conversationID, m)
+
+	// the preview arrives later through the messageLinkPreviewReady subscription
+	r.enqueueLinkPreview(message)
+
+	// mentioned users get their own event (once per message), even if they are not looking at the conversation
+	notified := map[string]bool{}
+	for _, mention := range mentions {
+		if notified[mention.UserID] {
+			continue
+		}
+		notified[mention.UserID] = true
+
+		r.SubscriptionManager.BroadcastMention(mention.UserID, &model.MentionEvent{
+			ConversationID: conversationID,
+			MessageID:      message.ID.String(),
+			SenderUserID:   message.SenderID.String(),
+			Content:        message.Content,
+			Timestamp:      message.CreatedAt.Time,
+		})
+	}
+}
+
+// clearSentDraft removes the draft of the conversation once the user sends the message, and
+// tells the other devices of the user to clear their input too
+func (r *Resolver) clearSentDraft(ctx context.Context, userID string, conversationID string) {
+	cleared, err := r.DraftService.ClearDraft(ctx, userID, conversationID)
+	if err != nil {
+		r.Logger.Error().Msgf("error to clear the draft of user %s in conversation %s: %v", userID, conversationID, err)
+		return
+	}
+
+	if cleared {
+		r.SubscriptionManager.BroadcastDraft(userID, &model.DraftUpdatedEvent{
+			ConversationID: conversationID,
+			Timestamp:      time.Now(),
+		})
+	}
+}
+
+// publishProfile sends the updated profile to the devices of the user and of its contacts
+func (r *Resolver) publishProfile(ctx context.Context, userID string, profile *model.User) {
+	contactIDs, err := r.UserService.GetContactIDs(ctx, userID)
+	if err != nil {
+		r.Logger.Error().Msgf("error to get the contacts of user %s: %v", userID, err)
+	}
+
+	r.SubscriptionManager.BroadcastProfile(userID, profile)
+	for _, contactID := range contactIDs {
+		r.SubscriptionManager.BroadcastProfile(contactID, profile)
+	}
+}
@@ -0,0 +1,173 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+	"golang-whatsapp-clone/auth"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph/model"
+	"time"
+)
+
+// Reactions is the resolver for the reactions field.
+func (r *messageResolver) Reactions(ctx context.Context, obj *model.Message) ([]*model.MessageReactionSummary, error) {
+	user := auth.GetUserFromContext(ctx)
+	if user == nil {
+		return []*model.MessageReactionSummary{}, nil
+	}
+
+	summaries, err := r.loaders(ctx).Reactions.Load(ctx, obj.ID)
+	if err != nil {
+		r.Logger.Error().Msgf("error to get the reactions of the message %s: %v", obj.ID, err)
+		return []*model.MessageReactionSummary{}, nil
+	}
+
+	return toMessageReactionSummaries(summaries), nil
+}
+
+// ReactToMessage is the resolver for the reactToMessage field.
+func (r *mutationResolver) ReactToMessage(ctx context.Context, input model.ReactToMessageInput) (model.ReactToMessageResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	message, reaction, err := r.ReactionService.ReactToMessage(ctx, user.UserID, input.MessageID, input.Emoji)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidReaction) {
+			return model.ValidationError{
+				ErrorMessage: "the reaction must be a single emoji",
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.NotFoundError{
+				ErrorMessage: "the message was not found",
+				Code:         customerrors.CodeResourceNotFound,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrForbidden) {
+			return model.ForbiddenError{
+				ErrorMessage: "you are not a participant of this conversation",
+				Code:         customerrors.CodeForbidden,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to react to the message",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	messageID := message.ID.String()
+	conversationID := message.ConversationID.String()
+
+	r.SubscriptionManager.BroadcastReaction(conversationID, &model.MessageReactionEvent{
+		ConversationID: conversationID,
+		MessageID:      messageID,
+		UserID:         user.UserID,
+		Emoji:          &reaction.Emoji,
+		Removed:        false,
+		Timestamp:      reaction.UpdatedAt.Time,
+	})
+
+	summaries, err := r.ReactionService.GetReactionSummaries(ctx, []string{messageID}, user.UserID)
+	if err != nil {
+		return model.ServerError{
+			ErrorMessage: "Error to get the message reactions",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	return &model.ReactToMessageSuccess{
+		Success:   true,
+		Reactions: toMessageReactionSummaries(summaries[messageID]),
+	}, nil
+}
+
+// RemoveReaction is the resolver for the removeReaction field.
+func (r *mutationResolver) RemoveReaction(ctx context.Context, input model.RemoveReactionInput) (model.RemoveReactionResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	message, removed, err := r.ReactionService.RemoveReaction(ctx, user.UserID, input.MessageID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.NotFoundError{
+				ErrorMessage: "the message was not found",
+				Code:         customerrors.CodeResourceNotFound,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrForbidden) {
+			return model.ForbiddenError{
+				ErrorMessage: "you are not a participant of this conversation",
+				Code:         customerrors.CodeForbidden,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to remove the reaction",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	messageID := message.ID.String()
+	conversationID := message.ConversationID.String()
+
+	// nothing changed, so there is no need to notify the other participants
+	if removed {
+		r.SubscriptionManager.BroadcastReaction(conversationID, &model.MessageReactionEvent{
+			ConversationID: conversationID,
+			MessageID:      messageID,
+			UserID:         user.UserID,
+			Removed:        true,
+			Timestamp:      time.Now(),
+		})
+	}
+
+	summaries, err := r.ReactionService.GetReactionSummaries(ctx, []string{messageID}, user.UserID)
+	if err != nil {
+		return model.ServerError{
+			ErrorMessage: "Error to get the message reactions",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	return &model.RemoveReactionSuccess{
+		Success:   true,
+		Reactions: toMessageReactionSummaries(summaries[messageID]),
+	}, nil
+}
+
+// MessageReactionUpdated is the resolver for the messageReactionUpdated field.
+func (r *subscriptionResolver) MessageReactionUpdated(ctx context.Context, input model.MessageReactionUpdatedSubscriptionInput) (<-chan *model.MessageReactionEvent, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return nil, errors.New(graphqlError.ErrorMessage)
+	}
+
+	err := r.ConversationService.EnsureParticipant(ctx, input.ConversationID, user.UserID)
+	if err != nil {
+		return nil, errors.New("you are not a participant of this conversation")
+	}
+
+	reactionChannel := r.SubscriptionManager.SubscribeToReactions(input.ConversationID)
+
+	go func() {
+		<-ctx.Done()
+		r.SubscriptionManager.UnsubscribeFromReactions(input.ConversationID, reactionChannel)
+
+		r.Logger.Info().Msgf("Client disconnected from conversation %s reactions subscription\n", input.ConversationID)
+	}()
+
+	return reactionChannel, nil
+}
@@ -8,6 +8,7 @@ import (
 	"golang-whatsapp-clone/graph/model"
 	"golang-whatsapp-clone/service"
 	"golang-whatsapp-clone/subscriptions"
+	"time"
 
 	"github.com/rs/zerolog"
 )
@@ -19,12 +20,25 @@ import (
 //go:generate go run github.com/99designs/gqlgen generate
 
 type Resolver struct {
-	DBQueries           *db.Queries
-	AppConfig           *config.AppConfig
-	Logger              *zerolog.Logger
-	ConversationService *service.ConversationService
-	MessageService      *service.MessageService
-	SubscriptionManager *subscriptions.SubscriptionManager
+	DBQueries               *db.Queries
+	AppConfig               *config.AppConfig
+	Logger                  *zerolog.Logger
+	ConversationService     *service.ConversationService
+	MessageService          *service.MessageService
+	ReactionService         *service.ReactionService
+	PinService              *service.PinService
+	StarService             *service.StarService
+	LinkPreviewService      *service.LinkPreviewService
+	ScheduledMessageService *service.ScheduledMessageService
+	DraftService            *service.DraftService
+	UserService             *service.UserService
+	PresenceService         *service.PresenceService
+	SessionService          *service.SessionService
+	AccountService          *service.AccountService
+	SubscriptionManager     *subscriptions.SubscriptionManager
+	// LoaderWait is how long the loaders collect keys before running a batch, the default
+	// is used when it is zero
+	LoaderWait time.Duration
 }
 
 func (r *Resolver) mustGetAuthenticatedUser(ctx context.Context) (*auth.UserContext, *model.UnauthorizedError) {
@@ -0,0 +1,16 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"golang-whatsapp-clone/graph/model"
+	"golang-whatsapp-clone/service"
+)
+
+// FormattedContent is the resolver for the formattedContent field.
+func (r *messageResolver) FormattedContent(ctx context.Context, obj *model.Message) ([]*model.TextSpan, error) {
+	return toTextSpans(service.ParseRichText(obj.Content)), nil
+}
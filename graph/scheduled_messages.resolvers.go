@@ -0,0 +1,146 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph/model"
+	"golang-whatsapp-clone/service"
+)
+
+// ScheduleMessage is the resolver for the scheduleMessage field.
+func (r *mutationResolver) ScheduleMessage(ctx context.Context, input model.ScheduleMessageInput) (model.ScheduleMessageResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	scheduled, err := r.ScheduledMessageService.ScheduleMessage(
+		ctx,
+		user.UserID,
+		input.ConversationID,
+		input.Content,
+		string(input.MessageType),
+		input.ReplyToMessageID,
+		input.SendAt,
+	)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrEmptyMessage) {
+			return model.ValidationError{
+				ErrorMessage: "the message can't be empty",
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrMessageTooLong) {
+			return model.ValidationError{
+				ErrorMessage: fmt.Sprintf("the message can't be longer than %d characters", service.MaxMessageLength),
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrInvalidScheduleTime) {
+			return model.ValidationError{
+				ErrorMessage: "the message must be scheduled in the future and at most one year from now",
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrInvalidReply) {
+			return model.ValidationError{
+				ErrorMessage: "the replied message is not in this conversation",
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrForbidden) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.ForbiddenError{
+				ErrorMessage: "you are not a participant of this conversation",
+				Code:         customerrors.CodeForbidden,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to schedule the message",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	return &model.ScheduleMessageSuccess{
+		Success:          true,
+		ScheduledMessage: toScheduledMessage(*scheduled),
+	}, nil
+}
+
+// CancelScheduledMessage is the resolver for the cancelScheduledMessage field.
+func (r *mutationResolver) CancelScheduledMessage(ctx context.Context, input model.CancelScheduledMessageInput) (model.CancelScheduledMessageResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	err := r.ScheduledMessageService.CancelScheduledMessage(ctx, user.UserID, input.ScheduledMessageID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.NotFoundError{
+				ErrorMessage: "the scheduled message was not found",
+				Code:         customerrors.CodeResourceNotFound,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrScheduledMessageNotPending) {
+			return model.ValidationError{
+				ErrorMessage: "the message was already sent or cancelled",
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to cancel the scheduled message",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	return &model.CancelScheduledMessageSuccess{Success: true}, nil
+}
+
+// MyScheduledMessages is the resolver for the myScheduledMessages field.
+func (r *queryResolver) MyScheduledMessages(ctx context.Context, input *model.MyScheduledMessagesInput) (model.MyScheduledMessagesQueryResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	var conversationID *string
+	var pagination *model.Pagination
+	if input != nil {
+		conversationID = input.ConversationID
+		pagination = input.Pagination
+	}
+
+	limit, offset := paginationValues(pagination)
+
+	rows, err := r.ScheduledMessageService.GetScheduledMessages(ctx, user.UserID, conversationID, limit, offset)
+	if err != nil {
+		return model.ServerError{
+			ErrorMessage: "Error to get the scheduled messages",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	messages := make([]*model.ScheduledMessage, 0, len(*rows))
+
+	for _, row := range *rows {
+		messages = append(messages, toScheduledMessage(row))
+	}
+
+	return &model.MyScheduledMessagesQuerySuccess{
+		Success:  true,
+		Messages: messages,
+	}, nil
+}
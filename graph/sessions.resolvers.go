@@ -0,0 +1,94 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph/model"
+)
+
+// RevokeSession is the resolver for the revokeSession field.
+func (r *mutationResolver) RevokeSession(ctx context.Context, input model.RevokeSessionInput) (model.RevokeSessionResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	err := r.SessionService.RevokeSession(ctx, user.UserID, input.SessionID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.ValidationError{
+				ErrorMessage: "invalid session id",
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrResourceNotFound) {
+			return model.NotFoundError{
+				ErrorMessage: "the session was not found",
+				Code:         customerrors.CodeResourceNotFound,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to revoke the session",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	return &model.RevokeSessionSuccess{
+		Success:   true,
+		SessionID: input.SessionID,
+	}, nil
+}
+
+// RevokeAllOtherSessions is the resolver for the revokeAllOtherSessions field.
+func (r *mutationResolver) RevokeAllOtherSessions(ctx context.Context) (model.RevokeAllOtherSessionsResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	revokedCount, err := r.SessionService.RevokeOtherSessions(ctx, user.UserID, user.SessionID)
+	if err != nil {
+		return model.ServerError{
+			ErrorMessage: "Error to revoke the sessions",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	return &model.RevokeAllOtherSessionsSuccess{
+		Success:      true,
+		RevokedCount: int32(revokedCount),
+	}, nil
+}
+
+// MySessions is the resolver for the mySessions field.
+func (r *queryResolver) MySessions(ctx context.Context) (model.MySessionsQueryResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	sessions, err := r.SessionService.GetUserSessions(ctx, user.UserID)
+	if err != nil {
+		return model.ServerError{
+			ErrorMessage: "Error to get the sessions",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	result := make([]*model.Session, 0, len(*sessions))
+	for _, session := range *sessions {
+		result = append(result, toSession(session, user.SessionID))
+	}
+
+	return &model.MySessionsQuerySuccess{
+		Success:  true,
+		Sessions: result,
+	}, nil
+}
@@ -0,0 +1,95 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph/model"
+)
+
+// StarMessage is the resolver for the starMessage field.
+func (r *mutationResolver) StarMessage(ctx context.Context, input model.StarMessageInput) (model.StarMessageResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	err := r.StarService.StarMessage(ctx, user.UserID, input.MessageID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.NotFoundError{
+				ErrorMessage: "the message was not found",
+				Code:         customerrors.CodeResourceNotFound,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrForbidden) {
+			return model.ForbiddenError{
+				ErrorMessage: "you are not a participant of this conversation",
+				Code:         customerrors.CodeForbidden,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to star the message",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	return &model.StarMessageSuccess{Success: true}, nil
+}
+
+// UnstarMessage is the resolver for the unstarMessage field.
+func (r *mutationResolver) UnstarMessage(ctx context.Context, input model.UnstarMessageInput) (model.UnstarMessageResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	err := r.StarService.UnstarMessage(ctx, user.UserID, input.MessageID)
+	if err != nil {
+		return model.ServerError{
+			ErrorMessage: "Error to unstar the message",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	return &model.UnstarMessageSuccess{Success: true}, nil
+}
+
+// MyStarredMessages is the resolver for the myStarredMessages field.
+func (r *queryResolver) MyStarredMessages(ctx context.Context, pagination *model.Pagination) (model.MyStarredMessagesQueryResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	limit, offset := paginationValues(pagination)
+
+	rows, err := r.StarService.GetStarredMessages(ctx, user.UserID, limit, offset)
+	if err != nil {
+		return model.ServerError{
+			ErrorMessage: "Error to get the starred messages",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	messages := make([]*model.StarredMessage, 0, len(*rows))
+
+	for _, row := range *rows {
+		messages = append(messages, &model.StarredMessage{
+			Message:        toMessage(row.Message, row.User),
+			ConversationID: row.Message.ConversationID.String(),
+			StarredAt:      row.StarredAt.Time,
+		})
+	}
+
+	return &model.MyStarredMessagesQuerySuccess{
+		Success:  true,
+		Messages: messages,
+	}, nil
+}
@@ -0,0 +1,119 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph/model"
+)
+
+// ReplyCount is the resolver for the replyCount field.
+func (r *messageResolver) ReplyCount(ctx context.Context, obj *model.Message) (int32, error) {
+	count, err := r.loaders(ctx).ReplyCount.Load(ctx, obj.ID)
+	if err != nil {
+		r.Logger.Error().Msgf("error to count the replies of the message %s: %v", obj.ID, err)
+		return 0, nil
+	}
+
+	return count, nil
+}
+
+// MessageReplies is the resolver for the messageReplies field.
+func (r *queryResolver) MessageReplies(ctx context.Context, input model.MessageRepliesInput) (model.MessageRepliesQueryResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	limit, offset := paginationValues(input.Pagination)
+
+	rows, err := r.MessageService.GetMessageReplies(ctx, user.UserID, input.MessageID, limit, offset)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.NotFoundError{
+				ErrorMessage: "the message was not found",
+				Code:         customerrors.CodeResourceNotFound,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrForbidden) {
+			return model.ForbiddenError{
+				ErrorMessage: "you are not a participant of this conversation",
+				Code:         customerrors.CodeForbidden,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to get the message replies",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	replies := make([]*model.Message, 0, len(*rows))
+
+	for _, row := range *rows {
+		replies = append(replies, toMessage(row.Message, row.User))
+	}
+
+	return &model.MessageRepliesQuerySuccess{
+		Success: true,
+		Replies: replies,
+	}, nil
+}
+
+// MessageThread is the resolver for the messageThread field.
+func (r *queryResolver) MessageThread(ctx context.Context, input model.MessageThreadInput) (model.MessageThreadQueryResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	limit, offset := paginationValues(input.Pagination)
+
+	rows, err := r.MessageService.GetMessageThread(ctx, user.UserID, input.MessageID, limit, offset)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return model.NotFoundError{
+				ErrorMessage: "the message was not found",
+				Code:         customerrors.CodeResourceNotFound,
+			}, nil
+		}
+
+		if errors.Is(err, customerrors.ErrForbidden) {
+			return model.ForbiddenError{
+				ErrorMessage: "you are not a participant of this conversation",
+				Code:         customerrors.CodeForbidden,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to get the message thread",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	messages := make([]*model.ThreadMessage, 0, len(*rows))
+
+	for _, row := range *rows {
+		var parentMessageID *string
+		if row.Depth > 0 && row.Message.ReplyToMessageID.Valid {
+			id := row.Message.ReplyToMessageID.String()
+			parentMessageID = &id
+		}
+
+		messages = append(messages, &model.ThreadMessage{
+			Message:         toMessage(row.Message, row.User),
+			ParentMessageID: parentMessageID,
+			Depth:           row.Depth,
+		})
+	}
+
+	return &model.MessageThreadQuerySuccess{
+		Success:  true,
+		Messages: messages,
+	}, nil
+}
@@ -6,11 +6,66 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"golang-whatsapp-clone/auth"
+	customerrors "golang-whatsapp-clone/errors"
 	"golang-whatsapp-clone/graph/model"
+	"golang-whatsapp-clone/repository"
+	"golang-whatsapp-clone/service"
 )
 
+// UpdateProfile is the resolver for the updateProfile field.
+func (r *mutationResolver) UpdateProfile(ctx context.Context, input model.UpdateProfileInput) (model.UpdateProfileResult, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return graphqlError, nil
+	}
+
+	updated, err := r.UserService.UpdateProfile(ctx, user.UserID, repository.ProfileUpdate{
+		Name:      input.Name,
+		About:     input.About,
+		AvatarURL: input.AvatarURL,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, customerrors.ErrInvalidProfileName):
+			return model.ValidationError{
+				ErrorMessage: fmt.Sprintf("the name must have between 1 and %d characters", service.MaxProfileNameLength),
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		case errors.Is(err, customerrors.ErrInvalidAbout):
+			return model.ValidationError{
+				ErrorMessage: fmt.Sprintf("the about text can have at most %d characters", service.MaxAboutLength),
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		case errors.Is(err, customerrors.ErrInvalidAvatarURL):
+			return model.ValidationError{
+				ErrorMessage: "the avatar must be an http or https url",
+				Code:         customerrors.CodeValidationError,
+			}, nil
+		case errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue):
+			return model.NotFoundError{
+				ErrorMessage: "the user was not found",
+				Code:         customerrors.CodeResourceNotFound,
+			}, nil
+		}
+
+		return model.ServerError{
+			ErrorMessage: "Error to update the profile",
+			Code:         customerrors.CodeInternalError,
+		}, nil
+	}
+
+	profile := toUser(*updated)
+	r.publishProfile(ctx, user.UserID, profile)
+
+	return &model.UpdateProfileSuccess{
+		Success: true,
+		User:    profile,
+	}, nil
+}
+
 // Me is the resolver for the me field.
 func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
 	userCtx := auth.GetUserFromContext(ctx)
@@ -24,12 +79,24 @@ func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
-	return &model.User{
-		ID:        user.ID.String(),
-		Email:     user.Email,
-		Name:      &user.Name.String,
-		AvatarURL: &user.AvatarUrl.String,
-		CreatedAt: user.CreatedAt.Time,
-		UpdatedAt: user.UpdatedAt.Time,
-	}, nil
+	return toUser(user), nil
+}
+
+// ProfileUpdated is the resolver for the profileUpdated field.
+func (r *subscriptionResolver) ProfileUpdated(ctx context.Context) (<-chan *model.User, error) {
+	user, graphqlError := r.mustGetAuthenticatedUser(ctx)
+	if graphqlError != nil {
+		return nil, errors.New(graphqlError.ErrorMessage)
+	}
+
+	profileChannel := r.SubscriptionManager.SubscribeToProfiles(user.UserID)
+
+	go func() {
+		<-ctx.Done()
+		r.SubscriptionManager.UnsubscribeFromProfiles(user.UserID, profileChannel)
+
+		r.Logger.Info().Msgf("Client disconnected from user %s profiles subscription\n", user.UserID)
+	}()
+
+	return profileChannel, nil
 }
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"golang-whatsapp-clone/auth"
+	customerrors "golang-whatsapp-clone/errors"
+	"net/http"
+	"time"
+)
+
+const accountExportWriteTimeout = 10 * time.Minute
+
+// AccountExportDownloadHandler sends the zip file of an export of the logged in user, the
+// exports of the other users are not found
+func (h *Handler) AccountExportDownloadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		h.methodNotAllowedResponse(w, r)
+		return
+	}
+
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.errorResponse(w, r, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	archive, export, err := h.accountService.GetExportArchive(r.Context(), user.UserID, r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			h.errorResponse(w, r, http.StatusNotFound, "the export was not found or it expired")
+			return
+		}
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("account-export-%s.zip", export.CompletedAt.Time.Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	w.Header().Set("Cache-Control", "no-store")
+
+	// a big export doesn't fit in the write timeout of the server
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(accountExportWriteTimeout)); err != nil {
+		h.logger.Warn().Msgf("error to extend the write deadline of the export download: %v", err)
+	}
+
+	http.ServeContent(w, r, fileName, export.CompletedAt.Time, bytes.NewReader(archive))
+}
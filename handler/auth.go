@@ -2,200 +2,337 @@ package handler
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"golang-whatsapp-clone/auth"
 	db "golang-whatsapp-clone/database/gen"
-	"log"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"golang-whatsapp-clone/service"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
-
-	"github.com/jackc/pgx/v5/pgtype"
 )
 
 var oauthStateCookieName = "whatsappgio_oauth_state"
-var oauthClientTypeCookieName = "whatsappgio_oauth_client_type"
 
-func (h *Handler) GoogleLoginHandler(w http.ResponseWriter, r *http.Request) {
+// the refresh cookie is only sent to the auth routes, the rest of the api only needs the access token
+const refreshCookiePath = "/api/v1/auth"
+
+// OAuthLoginHandler redirects to the login page of the provider of the route /api/v1/auth/{provider}.
+// The mobile app sends the S256 pkce challenge of the one-time code it will get back, and
+// optionally its own state
+func (h *Handler) OAuthLoginHandler(w http.ResponseWriter, r *http.Request) {
+	provider, err := h.oauthService.Provider(r.PathValue("provider"))
+	if err != nil {
+		h.errorResponse(w, r, http.StatusNotFound, "unknown auth provider")
+		return
+	}
 
 	// Get client type (web or mobile)
 	queryParams := r.URL.Query()
-	// clientType := c.Query("client_type", "web")
 	clientType := queryParams.Get("client_type")
 	if clientType == "" {
 		clientType = "web"
 	}
 
-	h.logger.Info().Msgf("Initializing oauth google flow from url: %s", r.URL.String())
-	h.logger.Info().Msgf(">> Client type is: %s", clientType)
+	h.logger.Info().Msgf("Initializing oauth %s flow for client type %s", provider.Name(), clientType)
+
+	if method := queryParams.Get("code_challenge_method"); method != "" && method != "S256" {
+		h.errorResponse(w, r, http.StatusBadRequest, "only the S256 code challenge method is supported")
+		return
+	}
 
-	state := ""
 	// generate auth url and state
-	authURL, state, err := h.oauthService.GenerateAuthURL()
+	authURL, state, err := h.oauthService.StartLogin(r.Context(), provider, auth.LoginRequest{
+		ClientType:    clientType,
+		CodeChallenge: queryParams.Get("code_challenge"),
+		AppState:      queryParams.Get("state"),
+	})
 	if err != nil {
-		log.Printf("Failed to generate auth url: %v\n", err)
-		// return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-		// 	"error": "Failed to generate auth url",
-		// })
-
-		h.logger.Error().Msg("failed to generate auth url")
-		h.ServerErrorResponse(w, r, err)
+		switch {
+		case errors.Is(err, customerrors.ErrInvalidCodeChallenge):
+			h.errorResponse(w, r, http.StatusBadRequest, "invalid or missing code challenge")
+		case errors.Is(err, customerrors.ErrInvalidOAuthState):
+			h.errorResponse(w, r, http.StatusBadRequest, "invalid state parameter")
+		default:
+			h.logger.Error().Msgf("failed to generate auth url: %v", err)
+			h.ServerErrorResponse(w, r, err)
+		}
 		return
 	}
-	// mobile will send us a state as a query param
-	// stateForMobile := c.Query("state")
 
-	// if clientType == "web" {
-	// 	state = stateForWeb
-	// } else if clientType == "mobile" {
-	// 	state = stateForMobile
-	// }
+	// the state cookie binds the login of the web to the browser that started it
+	h.setStateCookie(w, state)
 
-	// if state == "" {
-	// 	log.Print("Failed to get the state value from url\n")
-	// 	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-	// 		"error": "Failed to get the state value",
-	// 	})
-
-	// }
-
-	// log.Printf("state value: %s", state)
-
-	// log.Printf("AuthURL: %s\n", authURL)
-
-	h.logger.Info().Msgf(">> State is: %s", state)
-
-	// store state and client type in cookies for validation
-	h.setStateCookies(w, r, state, clientType)
-
-	// redirect to google page
+	// redirect to the login page of the provider
 	// return c.Redirect(authURL, fiber.StatusTemporaryRedirect)
 	http.Redirect(w, r, authURL, http.StatusTemporaryRedirect)
 }
 
-func (h *Handler) GoogleCallbackHandler(w http.ResponseWriter, r *http.Request) {
-	h.logger.Info().Msgf("Callback run with url: %s", r.URL.String())
+func (h *Handler) OAuthCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	provider, err := h.oauthService.Provider(r.PathValue("provider"))
+	if err != nil {
+		h.errorResponse(w, r, http.StatusNotFound, "unknown auth provider")
+		return
+	}
 
 	queryParams := r.URL.Query()
-
-	// verify state parameter
 	state := queryParams.Get("state")
-	// storedState := c.Cookies(oauthStateCookieName)
+
 	storedState := ""
 	storedStateCookie, err := r.Cookie(oauthStateCookieName)
 	if err == nil {
 		storedState = storedStateCookie.Value
 	}
-	// clientType := c.Cookies(oauthClientTypeCookieName, "web")
-	clientType := "web"
-	clientTypeCookie, err := r.Cookie(oauthClientTypeCookieName)
-	if err == nil {
-		clientType = clientTypeCookie.Value
-	}
-
-	h.logger.Info().Msgf(">>> Callback - client type is %s and state is %s", clientType, state)
-
-	if state == "" || state != storedState {
-		log.Printf("state: %s and storedState: %s\n", state, storedState)
-		h.clearStateCookies(w, r)
-		// return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-		// 	"error": "invalid state parameter",
-		// })
-		h.errorResponse(w, r, http.StatusBadRequest, "invalid state parameter")
-		return
-	}
 
 	// clear state cookies
-	h.clearStateCookies(w, r)
+	h.clearStateCookie(w)
 
 	// exchange code for token
 	code := queryParams.Get("code")
 	if code == "" {
-		// return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-		// 	"error": "missing authorization code",
-		// })
 		h.errorResponse(w, r, http.StatusBadRequest, "missing authorization code")
 		return
 	}
 
-	ctx := context.Background()
-	token, err := h.oauthService.ExchangeCode(ctx, code)
+	ctx := r.Context()
+	// the state of a web login is only used by the browser that started it, so the state and the
+	// code are never used when the cookie doesn't match
+	oauthUser, login, err := h.oauthService.CompleteLogin(ctx, provider, state, storedState, code)
 	if err != nil {
-		log.Printf("Failed to exchange token: %v\n", err)
-		// return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-		// 	"error": "failed to exchange token",
-		// })
-		h.logger.Error().Msg("failed to exchange token")
+		if errors.Is(err, customerrors.ErrInvalidOAuthState) {
+			h.errorResponse(w, r, http.StatusBadRequest, "invalid state parameter")
+			return
+		}
+
+		h.logger.Error().Msgf("failed to authenticate with %s: %s", provider.Name(), err)
 		h.ServerErrorResponse(w, r, err)
 		return
 	}
 
-	// get user info from google
-	googleUser, err := h.oauthService.GetUserInfo(ctx, token)
+	user, err := h.identityService.Login(ctx, provider.Name(), oauthUser)
 	if err != nil {
-		// log.Printf("Failed to get user info: %v\n", err)
-		// return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-		// 	"error": "failed to get user information",
-		// })
-		h.logger.Error().Msgf("failed to get user information: %s", err)
+		if errors.Is(err, customerrors.ErrEmailNotVerified) {
+			h.errorResponse(w, r, http.StatusForbidden, "the email of the account is not verified")
+			return
+		}
+
+		h.logger.Error().Msg("failed to create/update the user")
 		h.ServerErrorResponse(w, r, err)
 		return
+	}
 
+	if login.ClientType == repository.CLIENT_TYPE_MOBILE {
+		h.redirectToMobileApp(w, r, user.ID.String(), login)
+		return
 	}
 
-	user, err := h.dbQueries.UpsertUserByGoogleAuthSafe(ctx, db.UpsertUserByGoogleAuthSafeParams{
-		Name:      pgtype.Text{String: googleUser.Name, Valid: true},
-		GoogleID:  pgtype.Text{String: googleUser.ID, Valid: true},
-		Email:     googleUser.Email,
-		AvatarUrl: pgtype.Text{String: googleUser.Picture, Valid: true},
-	})
+	tokens, err := h.startSession(r, user.ID.String(), user.Email, login.ClientType)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	h.logger.Info().Msg("Redirecting to web url after successfully oauth login...")
+
+	h.setAuthCookies(w, tokens)
+
+	// return c.Redirect("/chats", fiber.StatusTemporaryRedirect)
+	http.Redirect(w, r, "/chats", http.StatusTemporaryRedirect)
+}
+
+// redirectToMobileApp sends a one-time code to the mobile app, the app exchanges it for the
+// tokens in /api/v1/auth/token with the verifier of its code challenge
+func (h *Handler) redirectToMobileApp(w http.ResponseWriter, r *http.Request, userID string, login *db.OauthState) {
+	code, err := h.oauthService.IssueAuthCode(r.Context(), userID, login.AppCodeChallenge.String)
+	if err != nil {
+		h.logger.Error().Msgf("failed to issue the auth code: %v", err)
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	params := url.Values{"code": {code}}
+	if login.AppState.Valid {
+		params.Set("state", login.AppState.String)
+	}
+
+	h.logger.Info().Msg("Redirecting to mobile schema after successfully oauth login...")
+	http.Redirect(w, r, h.appConfig.MobileAppSchema+"auth/callback?"+params.Encode(), http.StatusTemporaryRedirect)
+}
+
+// ExchangeCodeHandler exchanges the one-time code of the mobile login for the tokens, the body has
+// the code and the pkce verifier of the challenge sent when the login started
+func (h *Handler) ExchangeCodeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.methodNotAllowedResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Code         string `json:"code"`
+		CodeVerifier string `json:"code_verifier"`
+	}
+
+	if err := h.readJson(w, r, &input); err != nil {
+		h.errorResponse(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	ctx := r.Context()
+	userID, email, err := h.oauthService.ExchangeAuthCode(ctx, input.Code, input.CodeVerifier)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidAuthCode) {
+			h.errorResponse(w, r, http.StatusBadRequest, "invalid authorization code")
+			return
+		}
+
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	tokens, err := h.startSession(r, userID, email, repository.CLIENT_TYPE_MOBILE)
 	if err != nil {
-		// log.Printf("failed to upsert user: %v", err)
-		// return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-		// 	"error": "failed to create/update user",
-		// })
-		h.logger.Error().Msg("failed to create/update the user")
 		h.ServerErrorResponse(w, r, err)
 		return
 	}
 
-	// generate jwt
-	jwtToken, err := h.jwtService.GenerateToken(user.ID.String(), user.Email)
+	if err := h.writeTokens(w, tokens); err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+// startSession creates the session of a login and issues its tokens, every login method ends here
+func (h *Handler) startSession(r *http.Request, userID string, email string, clientType string) (*auth.TokenPair, error) {
+	session, err := h.sessionService.CreateSession(r.Context(), userID, clientType, r.UserAgent(), clientIP(r))
+	if err != nil {
+		h.logger.Error().Msgf("failed to create the session: %v", err)
+		return nil, err
+	}
+
+	// generate the access token and start the family of refresh tokens of the session
+	tokens, err := h.tokenService.IssueTokens(r.Context(), userID, email, session.ID.String())
+	if err != nil {
+		h.logger.Error().Msgf("failed to generate jwt: %v", err)
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// writeTokens sends the tokens in the body, it's how the clients without cookies get them
+func (h *Handler) writeTokens(w http.ResponseWriter, tokens *auth.TokenPair) error {
+	return h.writeJson(w, http.StatusOK, envelop{
+		"access_token":             tokens.AccessToken,
+		"access_token_expires_at":  tokens.AccessTokenExpiresAt,
+		"refresh_token":            tokens.RefreshToken,
+		"refresh_token_expires_at": tokens.RefreshTokenExpiresAt,
+	}, nil)
+}
+
+// RefreshHandler rotates the refresh token. Web clients send it in the refresh cookie and get
+// the new tokens as cookies, the other clients send it in the body and get them in the response
+func (h *Handler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.methodNotAllowedResponse(w, r)
+		return
+	}
+
+	refreshToken, fromCookie, err := h.readRefreshToken(w, r)
+	if err != nil || refreshToken == "" {
+		h.errorResponse(w, r, http.StatusBadRequest, "missing refresh token")
+		return
+	}
+
+	tokens, err := h.tokenService.Refresh(r.Context(), refreshToken)
 	if err != nil {
-		log.Printf("failed to generate jwt: %v", err)
-		// return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-		// 	"error": "failed to generate token",
-		// })
+		if errors.Is(err, customerrors.ErrInvalidRefreshToken) || errors.Is(err, customerrors.ErrRefreshTokenReused) {
+			h.clearAuthCookies(w)
+			h.errorResponse(w, r, http.StatusUnauthorized, "invalid refresh token")
+			return
+		}
+
 		h.ServerErrorResponse(w, r, err)
 		return
 	}
 
-	h.handleAuthSuccess(w, r, jwtToken, clientType)
+	h.sessionService.TouchSession(r.Context(), tokens.SessionID)
+
+	if fromCookie {
+		h.setAuthCookies(w, tokens)
+		err = h.writeJson(w, http.StatusOK, envelop{"access_token_expires_at": tokens.AccessTokenExpiresAt}, nil)
+	} else {
+		err = h.writeTokens(w, tokens)
+	}
+
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
 }
 
 func (h *Handler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
-	expiredAuthCookie := h.createAuthCookie(w, r, "", -1, time.Now().Add(-1*time.Hour))
-	http.SetCookie(w, expiredAuthCookie)
+	// the refresh token is optional, without it only the cookies are removed
+	refreshToken, _, err := h.readRefreshToken(w, r)
+	if err == nil && refreshToken != "" {
+		sessionID, err := h.tokenService.Revoke(r.Context(), refreshToken)
+		if err != nil && !errors.Is(err, customerrors.ErrInvalidRefreshToken) {
+			h.ServerErrorResponse(w, r, err)
+			return
+		}
+
+		if err == nil {
+			h.sessionService.CloseConnections(sessionID)
+		}
+	}
+
+	h.clearAuthCookies(w)
 
 	// return c.JSON(fiber.Map{
 	// 	"message": "logged out successfully",
 	// })
-	err := h.writeJson(w, http.StatusOK, envelop{"message": "logged out successfully"}, nil)
+	err = h.writeJson(w, http.StatusOK, envelop{"message": "logged out successfully"}, nil)
 	if err != nil {
 		h.ServerErrorResponse(w, r, err)
 	}
 }
 
+// readRefreshToken reads the token from the refresh cookie or from the refresh_token field of the body
+func (h *Handler) readRefreshToken(w http.ResponseWriter, r *http.Request) (token string, fromCookie bool, err error) {
+	cookie, err := r.Cookie(h.refreshCookieName())
+	if err == nil && cookie.Value != "" {
+		return cookie.Value, true, nil
+	}
+
+	if r.Body == nil || r.ContentLength == 0 {
+		return "", false, nil
+	}
+
+	var input struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err := h.readJson(w, r, &input); err != nil {
+		return "", false, err
+	}
+
+	return input.RefreshToken, false, nil
+}
+
 // This middleware will try to attach the user data to the context request in case an authentication header exists
 func (h *Handler) AuthenticateUserMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
-			var tokenString string
+			// the auth routes don't need the user, and an expired access token must not
+			// prevent the client from refreshing it
+			if strings.HasPrefix(r.URL.Path, refreshCookiePath+"/") {
+				next.ServeHTTP(w, r)
+				return
+			}
 
 			// try to get token from Authorization header first (for mobile)
-			authHeader := r.Header.Get("Authorization")
-			if authHeader != "" && len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-				tokenString = authHeader[7:]
-			} else {
+			tokenString := bearerToken(r.Header.Get("Authorization"))
+			if tokenString == "" {
 				// try to get token from cookie (web)
 				tokenStringCookie, err := r.Cookie(h.appConfig.CookieName)
 				if err == nil {
@@ -211,31 +348,31 @@ func (h *Handler) AuthenticateUserMiddleware(next http.Handler) http.Handler {
 				return
 			}
 
-			// validate jwt
-			claims, err := h.jwtService.ValidateToken(tokenString)
-			if err != nil {
-				// return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				// 	"error": "invalid authentication token",
-				// })
-				h.errorResponse(w, r, http.StatusUnauthorized, "invalid authentication token")
-				return
-			}
-
 			// store user info in context for graphql resolvers
 			// c.Locals("user_id", claims.UserID)
 			// c.Locals("user_email", claims.Email)
-			ctx := r.Context()
-			if claims.UserID != "" && claims.Email != "" {
-				ctx = auth.WithUserContext(ctx, claims.UserID, claims.Email)
-				r = r.WithContext(ctx)
+			ctx, err := authenticateToken(r.Context(), h.jwtService, h.sessionService, tokenString)
+			if err != nil {
+				switch {
+				case errors.Is(err, customerrors.ErrInvalidAccessToken):
+					// return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					// 	"error": "invalid authentication token",
+					// })
+					h.errorResponse(w, r, http.StatusUnauthorized, "invalid authentication token")
+				case errors.Is(err, customerrors.ErrSessionRevoked):
+					h.errorResponse(w, r, http.StatusUnauthorized, "the session was revoked")
+				default:
+					h.ServerErrorResponse(w, r, err)
+				}
+				return
 			}
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		},
 	)
 }
 
-func (h *Handler) setStateCookies(w http.ResponseWriter, r *http.Request, state string, clientType string) {
+func (h *Handler) setStateCookie(w http.ResponseWriter, state string) {
 	isSecure := h.appConfig.AppEnv == "production"
 
 	oauthStateCookie := &http.Cookie{
@@ -244,84 +381,65 @@ func (h *Handler) setStateCookies(w http.ResponseWriter, r *http.Request, state
 		HttpOnly: true,
 		Secure:   isSecure,
 		SameSite: http.SameSiteLaxMode,
-		MaxAge:   300, // 5 minutes
+		MaxAge:   int(auth.OAuthStateDuration.Seconds()),
 		Path:     "/",
 	}
 
 	http.SetCookie(w, oauthStateCookie)
-
-	oauthClientTypeCookie := &http.Cookie{
-		Name:     oauthClientTypeCookieName,
-		Value:    clientType,
-		HttpOnly: true,
-		Secure:   isSecure,
-		SameSite: http.SameSiteLaxMode,
-		MaxAge:   300,
-		Path:     "/",
-	}
-	http.SetCookie(w, oauthClientTypeCookie)
 }
 
-func (h *Handler) clearStateCookies(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) clearStateCookie(w http.ResponseWriter) {
 	isSecure := h.appConfig.AppEnv == "production"
 
 	oauthStateCookie := &http.Cookie{
-		// Name:     h.appConfig.CookieName,
 		Name:     oauthStateCookieName,
 		Value:    "",
 		HttpOnly: true,
 		Secure:   isSecure,
 		SameSite: http.SameSiteLaxMode,
-		MaxAge:   -1, // 5 minutes
+		MaxAge:   -1,
 		Path:     "/",
 		Expires:  time.Now().Add(-1 * time.Hour), // set expiry in the past
 	}
 	http.SetCookie(w, oauthStateCookie)
-
-	oauthClientTypeCookie := &http.Cookie{
-		Name:     oauthClientTypeCookieName,
-		Value:    "",
-		HttpOnly: true,
-		Secure:   isSecure,
-		SameSite: http.SameSiteLaxMode,
-		MaxAge:   -1,
-		Path:     "/",
-		Expires:  time.Now().Add(-1 * time.Hour),
-	}
-	http.SetCookie(w, oauthClientTypeCookie)
 }
 
-func (h *Handler) handleAuthSuccess(
-	w http.ResponseWriter, r *http.Request,
-	jwtToken string, clientType string) {
-
-	h.logger.Info().Msgf("Handle success with client type: %s", clientType)
-
-	if clientType == "mobile" {
-		h.logger.Info().Msg("Redirecting to mobile schema after successfully google oauth login...")
-		// redirect to mobile app with token
-		redirectURL := fmt.Sprintf("%sauth/%s/", h.appConfig.MobileAppSchema, jwtToken)
-		// return c.Redirect(redirectURL, fiber.StatusTemporaryRedirect)
-		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
-		return
-	}
-
-	h.logger.Info().Msg("Redirecting to web url after successfully google oauth login...")
+func (h *Handler) refreshCookieName() string {
+	return h.appConfig.CookieName + "_refresh"
+}
 
+func (h *Handler) setAuthCookies(w http.ResponseWriter, tokens *auth.TokenPair) {
 	isSecure := h.appConfig.AppEnv == "production"
-	cookie := &http.Cookie{
+
+	http.SetCookie(w, &http.Cookie{
 		Name:     h.appConfig.CookieName,
-		Value:    jwtToken,
+		Value:    tokens.AccessToken,
 		HttpOnly: true,
 		Secure:   isSecure,
-		SameSite: http.SameSiteDefaultMode,
-		MaxAge:   7 * 24 * 60 * 60, // 7 days
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(auth.AccessTokenDuration.Seconds()),
 		Path:     "/",
-	}
-	http.SetCookie(w, cookie)
+	})
 
-	// return c.Redirect("/chats", fiber.StatusTemporaryRedirect)
-	http.Redirect(w, r, "/chats", http.StatusTemporaryRedirect)
+	http.SetCookie(w, &http.Cookie{
+		Name:     h.refreshCookieName(),
+		Value:    tokens.RefreshToken,
+		HttpOnly: true,
+		Secure:   isSecure,
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   int(auth.RefreshTokenDuration.Seconds()),
+		Path:     refreshCookiePath,
+	})
+}
+
+func (h *Handler) clearAuthCookies(w http.ResponseWriter) {
+	expiredAuthCookie := h.createAuthCookie(w, nil, "", -1, time.Now().Add(-1*time.Hour))
+	http.SetCookie(w, expiredAuthCookie)
+
+	expiredRefreshCookie := h.createAuthCookie(w, nil, "", -1, time.Now().Add(-1*time.Hour))
+	expiredRefreshCookie.Name = h.refreshCookieName()
+	expiredRefreshCookie.Path = refreshCookiePath
+	http.SetCookie(w, expiredRefreshCookie)
 }
 
 func (h *Handler) createAuthCookie(
@@ -343,3 +461,46 @@ func (h *Handler) createAuthCookie(
 	// http.SetCookie(w, cookie)
 	return cookie
 }
+
+// clientIP returns the ip of the client, behind the proxy it's the first address of X-Forwarded-For.
+// It's only informative, the header can be set by the client
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		ip, _, _ := strings.Cut(forwarded, ",")
+		return strings.TrimSpace(ip)
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// authenticateToken validates the access token and its session, the user is added to the context.
+// The access token of a revoked session is rejected before it expires
+func authenticateToken(ctx context.Context, jwtService *auth.JWTService, sessionService *service.SessionService, tokenString string) (context.Context, error) {
+	claims, err := jwtService.ValidateToken(tokenString)
+	if err != nil || claims.UserID == "" || claims.Email == "" {
+		return ctx, customerrors.ErrInvalidAccessToken
+	}
+
+	if sessionService != nil {
+		if err := sessionService.ValidateSession(ctx, claims.UserID, claims.ID); err != nil {
+			return ctx, err
+		}
+	}
+
+	return auth.WithTokenClaims(ctx, claims), nil
+}
+
+// bearerToken returns the token of an Authorization header value, it's empty for other schemes
+func bearerToken(authorization string) string {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
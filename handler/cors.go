@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	corsAllowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodOptions}
+	corsAllowedHeaders = []string{"Authorization", "Content-Type", "Apollo-Require-Preflight"}
+)
+
+// how long the browsers can cache a preflight response
+const corsMaxAge = 10 * 60
+
+// CORSMiddleware only lets the origins of AppConfig.AllowedOrigins call the api. The requests
+// without an Origin header come from the same origin or from clients that aren't browsers, like
+// the mobile app, so they are not checked
+func (h *Handler) CORSMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+
+			isPreflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			if !h.appConfig.IsAllowedOrigin(origin) {
+				h.logger.Warn().Msgf("request from origin %s rejected: %s %s", origin, r.Method, r.URL.Path)
+				h.errorResponse(w, r, http.StatusForbidden, "origin not allowed")
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			// the web client is authenticated with cookies
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+
+			if isPreflight {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(corsAllowedMethods, ", "))
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(corsAllowedHeaders, ", "))
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(corsMaxAge))
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		},
+	)
+}
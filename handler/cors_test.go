@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"golang-whatsapp-clone/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func newCORSTestHandler() http.Handler {
+	logger := zerolog.Nop()
+	h := &Handler{
+		logger:    &logger,
+		appConfig: &config.AppConfig{AllowedOrigins: []string{"https://app.example.com"}},
+	}
+
+	return h.CORSMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		origin      string
+		preflight   bool
+		status      int
+		allowOrigin string
+	}{
+		{name: "without origin", method: http.MethodPost, status: http.StatusOK},
+		{name: "allowed origin", method: http.MethodPost, origin: "https://app.example.com", status: http.StatusOK, allowOrigin: "https://app.example.com"},
+		{name: "origin with different case", method: http.MethodGet, origin: "HTTPS://APP.example.com", status: http.StatusOK, allowOrigin: "HTTPS://APP.example.com"},
+		{name: "rejected origin", method: http.MethodPost, origin: "https://evil.example.com", status: http.StatusForbidden},
+		{name: "allowed preflight", method: http.MethodOptions, origin: "https://app.example.com", preflight: true, status: http.StatusNoContent, allowOrigin: "https://app.example.com"},
+		{name: "rejected preflight", method: http.MethodOptions, origin: "https://evil.example.com", preflight: true, status: http.StatusForbidden},
+	}
+
+	corsHandler := newCORSTestHandler()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, "/graphql", nil)
+			if tt.origin != "" {
+				request.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+
+			recorder := httptest.NewRecorder()
+			corsHandler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, recorder.Code)
+			}
+
+			if got := recorder.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Errorf("expected allowed origin %q, got %q", tt.allowOrigin, got)
+			}
+
+			if tt.preflight && tt.status == http.StatusNoContent && recorder.Header().Get("Access-Control-Allow-Headers") == "" {
+				t.Error("expected the preflight response to list the allowed headers")
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"html/template"
+	"net/http"
+)
+
+// EmailLoginHandler sends a login code and link to the email of the body. The response is the
+// same when the email has no account, so it can't be used to find the registered emails.
+// The mobile app sends the S256 pkce challenge of the verifier it uses with the link
+func (h *Handler) EmailLoginHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.methodNotAllowedResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Email         string `json:"email"`
+		ClientType    string `json:"client_type"`
+		CodeChallenge string `json:"code_challenge"`
+	}
+
+	if err := h.readJson(w, r, &input); err != nil {
+		h.errorResponse(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err := h.emailLoginService.RequestLogin(r.Context(), input.Email, input.ClientType, input.CodeChallenge)
+	if err != nil {
+		switch {
+		case errors.Is(err, customerrors.ErrInvalidEmail):
+			h.errorResponse(w, r, http.StatusBadRequest, "invalid email address")
+		case errors.Is(err, customerrors.ErrInvalidCodeChallenge):
+			h.errorResponse(w, r, http.StatusBadRequest, "invalid or missing code challenge")
+		case errors.Is(err, customerrors.ErrLoginCodeRequested):
+			h.errorResponse(w, r, http.StatusTooManyRequests, err.Error())
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJson(w, http.StatusAccepted, envelop{"message": "a login code was sent to the email"}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+// EmailVerifyHandler logs in with the email and the code, or with the token of the link opened by
+// the mobile app and the verifier of its code challenge. Web clients get the tokens as cookies and
+// the others in the response
+func (h *Handler) EmailVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.methodNotAllowedResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Email        string `json:"email"`
+		Code         string `json:"code"`
+		Token        string `json:"token"`
+		CodeVerifier string `json:"code_verifier"`
+	}
+
+	if err := h.readJson(w, r, &input); err != nil {
+		h.errorResponse(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var user *db.User
+	var login *db.EmailLogin
+	var err error
+	if input.Token != "" {
+		user, login, err = h.emailLoginService.VerifyLink(r.Context(), input.Token, input.CodeVerifier)
+	} else {
+		user, login, err = h.emailLoginService.VerifyCode(r.Context(), input.Email, input.Code)
+	}
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidLoginCode) {
+			h.errorResponse(w, r, http.StatusBadRequest, "invalid or expired login code")
+			return
+		}
+
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	tokens, err := h.startSession(r, user.ID.String(), user.Email, login.ClientType)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if login.ClientType == repository.CLIENT_TYPE_WEB {
+		h.setAuthCookies(w, tokens)
+		err = h.writeJson(w, http.StatusOK, envelop{"access_token_expires_at": tokens.AccessTokenExpiresAt}, nil)
+	} else {
+		err = h.writeTokens(w, tokens)
+	}
+
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+// the link is confirmed with a form, the scanners of the email providers open the links of the
+// emails and would use the token before the user
+var emailCallbackPage = template.Must(template.New("email_callback").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Log in</title></head>
+<body>
+<form method="post">
+<input type="hidden" name="token" value="{{.}}">
+<button type="submit">Log in</button>
+</form>
+</body>
+</html>`))
+
+// EmailCallbackHandler is the link of the email for the web, the token is used when the page is
+// confirmed and it logs in the browser that opened it. The links of the mobile app have no verifier
+// here, so they are rejected
+func (h *Handler) EmailCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		if err := emailCallbackPage.Execute(w, r.URL.Query().Get("token")); err != nil {
+			h.logger.Error().Msgf("failed to render the email callback page: %v", err)
+		}
+		return
+	case http.MethodPost:
+	default:
+		h.methodNotAllowedResponse(w, r)
+		return
+	}
+
+	user, _, err := h.emailLoginService.VerifyLink(r.Context(), r.PostFormValue("token"), "")
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidLoginCode) {
+			h.errorResponse(w, r, http.StatusBadRequest, "invalid or expired login link")
+			return
+		}
+
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	tokens, err := h.startSession(r, user.ID.String(), user.Email, repository.CLIENT_TYPE_WEB)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	h.setAuthCookies(w, tokens)
+
+	http.Redirect(w, r, "/chats", http.StatusSeeOther)
+}
@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"context"
+	"golang-whatsapp-clone/auth"
+	customerrors "golang-whatsapp-clone/errors"
 	"golang-whatsapp-clone/graph"
+	"golang-whatsapp-clone/ratelimit"
 	"net/http"
 	"time"
 
+	"github.com/99designs/gqlgen/graphql"
 	gqlHandler "github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
 	"github.com/99designs/gqlgen/graphql/handler/lru"
@@ -15,7 +20,14 @@ import (
 	"github.com/vektah/gqlparser/v2/ast"
 )
 
-func NewGraphqlHandler(logger *zerolog.Logger, resolver *graph.Resolver) *gqlHandler.Server {
+// presenceUserKey marks the websocket connections counted by the PresenceService
+type presenceUserKey struct{}
+
+// sessionReleaseKey holds the function that removes the websocket connection from the SessionService
+// and releases its context
+type sessionReleaseKey struct{}
+
+func NewGraphqlHandler(logger *zerolog.Logger, resolver *graph.Resolver, jwtService *auth.JWTService, rateLimiter *ratelimit.Limiter) *gqlHandler.Server {
 	srv := gqlHandler.New(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
 
 	srv.AddTransport(transport.Websocket{
@@ -29,29 +41,88 @@ func NewGraphqlHandler(logger *zerolog.Logger, resolver *graph.Resolver) *gqlHan
 		// long since walked to the kitchen to make a sandwich instead.
 		KeepAlivePingInterval: 10 * time.Second,
 
+		InitFunc: func(ctx context.Context, initPayload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+			// the websocket clients that can't set headers on the upgrade request send the
+			// token in the connection_init payload, it replaces the user of the upgrade request
+			if authorization := initPayload.Authorization(); authorization != "" {
+				tokenString := bearerToken(authorization)
+				if tokenString == "" {
+					logger.Info().Msg("websocket connection rejected: the authorization of the payload is not a bearer token")
+					return ctx, nil, customerrors.ErrInvalidAccessToken
+				}
+
+				var err error
+				ctx, err = authenticateToken(ctx, jwtService, resolver.SessionService, tokenString)
+				if err != nil {
+					logger.Info().Msgf("websocket connection rejected: %v", err)
+					return ctx, nil, err
+				}
+			}
+
+			user := auth.GetUserFromContext(ctx)
+			if user == nil {
+				return ctx, &initPayload, nil
+			}
+
+			// cancelling the context of the connection closes it, so the connection is closed
+			// when its token expires or its session is revoked
+			if user.SessionID != "" {
+				ctx = transport.AppendCloseReason(ctx, "the token expired or the session was revoked, authenticate again")
+
+				var closeConnection context.CancelFunc
+				if user.ExpiresAt.IsZero() {
+					ctx, closeConnection = context.WithCancel(ctx)
+				} else {
+					ctx, closeConnection = context.WithDeadline(ctx, user.ExpiresAt)
+				}
+
+				untrack := func() {}
+				if resolver.SessionService != nil {
+					untrack = resolver.SessionService.TrackConnection(user.SessionID, closeConnection)
+				}
+				ctx = context.WithValue(ctx, sessionReleaseKey{}, func() {
+					untrack()
+					closeConnection()
+				})
+			}
+
+			// the user is online while it has an open websocket, see PresenceService
+			if resolver.PresenceService != nil {
+				resolver.PresenceService.Connect(ctx, user.UserID)
+				ctx = context.WithValue(ctx, presenceUserKey{}, user.UserID)
+			}
+
+			return ctx, &initPayload, nil
+		},
+		CloseFunc: func(ctx context.Context, closeCode int) {
+			if release, ok := ctx.Value(sessionReleaseKey{}).(func()); ok {
+				release()
+			}
+
+			userID, ok := ctx.Value(presenceUserKey{}).(string)
+			if !ok {
+				return
+			}
+
+			// the context of the connection is already cancelled when it's closed
+			resolver.PresenceService.Disconnect(context.WithoutCancel(ctx), userID)
+		},
+
 		// The `github.com/gorilla/websocket.Upgrader` is used to handle the transition
 		// from an HTTP connection to a WebSocket connection. Among other options, here
 		// you must check the origin of the request to prevent cross-site request forgery
 		// attacks.
 		Upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
-				// Allow exact match on host.
+				// the clients that aren't browsers, like the mobile app, don't send an origin
 				origin := r.Header.Get("Origin")
+				if origin == "" || resolver.AppConfig.IsAllowedOrigin(origin) {
+					return true
+				}
 
-				logger.Info().Msgf("origin: %s", origin)
-
-				return true
-				// if origin == "" || origin == r.Header.Get("Host") {
-				// 	return true
-				// }
+				logger.Warn().Msgf("websocket connection from origin %s rejected", origin)
 
-				// // Match on allow-listed origins.
-				// return slices.Contains(
-				// 	[]string{
-				// 		":5000",
-				// 		":4000",
-				// 		"https://sandbox.embed.apollographql.com",
-				// 	}, origin)
+				return false
 			},
 		},
 	})
@@ -59,6 +130,12 @@ func NewGraphqlHandler(logger *zerolog.Logger, resolver *graph.Resolver) *gqlHan
 	srv.AddTransport(transport.GET{})
 	srv.AddTransport(transport.POST{})
 
+	// new loaders for every response, so the field resolvers of a list are batched and a
+	// subscription never reads the results cached for a previous event
+	srv.AroundResponses(func(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+		return next(resolver.WithLoaders(ctx))
+	})
+
 	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
 
 	srv.Use(extension.Introspection{})
@@ -66,6 +143,9 @@ func NewGraphqlHandler(logger *zerolog.Logger, resolver *graph.Resolver) *gqlHan
 		Cache: lru.New[string](100),
 	})
 	srv.Use(extension.FixedComplexityLimit(50))
+	if rateLimiter != nil {
+		srv.Use(NewRateLimitExtension(rateLimiter))
+	}
 
 	return srv
 }
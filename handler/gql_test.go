@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"golang-whatsapp-clone/auth"
+	db "golang-whatsapp-clone/database/gen"
+	"golang-whatsapp-clone/graph"
+	"golang-whatsapp-clone/repository"
+	"golang-whatsapp-clone/service"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+)
+
+// fakeSessionRepository returns an active session of the user for every session id
+type fakeSessionRepository struct {
+	repository.SessionRepository
+	userID string
+}
+
+func (r *fakeSessionRepository) GetSession(ctx context.Context, sessionID string) (*db.Session, error) {
+	return &db.Session{
+		ID:     pgtype.UUID{Bytes: uuid.MustParse(sessionID), Valid: true},
+		UserID: pgtype.UUID{Bytes: uuid.MustParse(r.userID), Valid: true},
+	}, nil
+}
+
+func newWebsocketTestServer(t *testing.T, jwtService *auth.JWTService, sessionService *service.SessionService) string {
+	logger := zerolog.Nop()
+	resolver := &graph.Resolver{Logger: &logger, SessionService: sessionService}
+
+	server := httptest.NewServer(NewGraphqlHandler(&logger, resolver, jwtService, nil))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// connectWebsocket sends the connection_init with the authorization in its payload and
+// returns the connection and the type of the first message of the server
+func connectWebsocket(t *testing.T, url string, authorization string) (*websocket.Conn, string) {
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-transport-ws"}}
+	conn, _, err := dialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	init := map[string]any{"type": "connection_init", "payload": map[string]string{"Authorization": authorization}}
+	if err := conn.WriteJSON(init); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var message struct {
+		Type string `json:"type"`
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := conn.ReadJSON(&message); err != nil {
+		return conn, ""
+	}
+
+	return conn, message.Type
+}
+
+// waitForClose reads until the server closes the connection, it fails when it's still open after the timeout
+func waitForClose(t *testing.T, conn *websocket.Conn, timeout time.Duration) {
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+
+		if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			t.Fatalf("expected the server to close the connection, got %v", err)
+		}
+		return
+	}
+}
+
+func TestWebsocketPayloadAuthentication(t *testing.T) {
+	userID := uuid.NewString()
+	jwtService := auth.NewJWTService("access-secret", "refresh-secret")
+	logger := zerolog.Nop()
+	url := newWebsocketTestServer(t, jwtService, service.NewSessionService(&fakeSessionRepository{userID: userID}, &logger))
+
+	token, err := jwtService.GenerateToken(userID, "ana@example.com", uuid.NewString())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, messageType := connectWebsocket(t, url, "Bearer "+token); messageType != "connection_ack" {
+		t.Errorf("expected the connection with a valid token to be acknowledged, got %q", messageType)
+	}
+
+	for _, authorization := range []string{"Bearer not-a-token", "Basic " + token, token} {
+		conn, messageType := connectWebsocket(t, url, authorization)
+		if messageType == "connection_ack" {
+			t.Errorf("expected the connection with %q to be rejected", authorization)
+			continue
+		}
+		waitForClose(t, conn, 5*time.Second)
+	}
+}
+
+func TestWebsocketIsClosedWhenTheSessionIsRevoked(t *testing.T) {
+	userID := uuid.NewString()
+	sessionID := uuid.NewString()
+	jwtService := auth.NewJWTService("access-secret", "refresh-secret")
+	logger := zerolog.Nop()
+	sessionService := service.NewSessionService(&fakeSessionRepository{userID: userID}, &logger)
+	url := newWebsocketTestServer(t, jwtService, sessionService)
+
+	token, err := jwtService.GenerateToken(userID, "ana@example.com", sessionID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	conn, messageType := connectWebsocket(t, url, "Bearer "+token)
+	if messageType != "connection_ack" {
+		t.Fatalf("expected the connection to be acknowledged, got %q", messageType)
+	}
+
+	sessionService.CloseConnections(sessionID)
+
+	waitForClose(t, conn, 5*time.Second)
+}
+
+func TestWebsocketIsClosedWhenTheTokenExpires(t *testing.T) {
+	userID := uuid.NewString()
+	jwtService := auth.NewJWTService("access-secret", "refresh-secret")
+	logger := zerolog.Nop()
+	url := newWebsocketTestServer(t, jwtService, service.NewSessionService(&fakeSessionRepository{userID: userID}, &logger))
+
+	// a token that expires in a couple of seconds, signed like the ones of the JWTService
+	now := time.Now()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.JWTClaims{
+		UserID: userID,
+		Email:  "ana@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Audience:  jwt.ClaimStrings{"access"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(2 * time.Second)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}).SignedString([]byte("access-secret"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	conn, messageType := connectWebsocket(t, url, "Bearer "+token)
+	if messageType != "connection_ack" {
+		t.Fatalf("expected the connection to be acknowledged, got %q", messageType)
+	}
+
+	waitForClose(t, conn, 5*time.Second)
+}
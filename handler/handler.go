@@ -6,6 +6,8 @@ import (
 	"golang-whatsapp-clone/auth"
 	"golang-whatsapp-clone/config"
 	db "golang-whatsapp-clone/database/gen"
+	"golang-whatsapp-clone/ratelimit"
+	"golang-whatsapp-clone/service"
 	"maps"
 	"net/http"
 
@@ -13,22 +15,34 @@ import (
 )
 
 type Handler struct {
-	logger       *zerolog.Logger
-	appConfig    *config.AppConfig
-	dbQueries    *db.Queries
-	oauthService *auth.OAuthService
-	jwtService   *auth.JWTService
+	logger            *zerolog.Logger
+	appConfig         *config.AppConfig
+	dbQueries         *db.Queries
+	oauthService      *auth.OAuthService
+	jwtService        *auth.JWTService
+	tokenService      *auth.TokenService
+	sessionService    *service.SessionService
+	identityService   *service.IdentityService
+	emailLoginService *service.EmailLoginService
+	accountService    *service.AccountService
+	rateLimiter       *ratelimit.Limiter
 }
 
 type envelop map[string]any
 
-func NewHandler(logger *zerolog.Logger, appConfig *config.AppConfig, dbQueries *db.Queries, oauthService *auth.OAuthService, jwtService *auth.JWTService) *Handler {
+func NewHandler(logger *zerolog.Logger, appConfig *config.AppConfig, dbQueries *db.Queries, oauthService *auth.OAuthService, jwtService *auth.JWTService, tokenService *auth.TokenService, sessionService *service.SessionService, identityService *service.IdentityService, emailLoginService *service.EmailLoginService, accountService *service.AccountService, rateLimiter *ratelimit.Limiter) *Handler {
 	return &Handler{
-		logger:       logger,
-		appConfig:    appConfig,
-		dbQueries:    dbQueries,
-		oauthService: oauthService,
-		jwtService:   jwtService,
+		logger:            logger,
+		appConfig:         appConfig,
+		dbQueries:         dbQueries,
+		oauthService:      oauthService,
+		jwtService:        jwtService,
+		tokenService:      tokenService,
+		sessionService:    sessionService,
+		identityService:   identityService,
+		emailLoginService: emailLoginService,
+		accountService:    accountService,
+		rateLimiter:       rateLimiter,
 	}
 }
 
@@ -51,6 +65,16 @@ func (h *Handler) writeJson(w http.ResponseWriter, status int, data envelop, hea
 	return nil
 }
 
+// readJson decodes a small json body, the unknown fields are rejected
+func (h *Handler) readJson(w http.ResponseWriter, r *http.Request, dst any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, 1_048_576)
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(dst)
+}
+
 func (h *Handler) methodNotAllowedResponse(w http.ResponseWriter, r *http.Request) {
 	message := fmt.Sprintf("the %s is not supported for this resource", r.Method)
 	h.errorResponse(w, r, http.StatusMethodNotAllowed, message)
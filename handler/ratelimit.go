@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph/model"
+	"golang-whatsapp-clone/ratelimit"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const rateLimitedMessage = "too many requests, retry later"
+
+// IPRateLimitMiddleware limits the requests of every ip, it runs before AuthenticateUserMiddleware
+// so the requests with invalid tokens are limited before their tokens and sessions are checked
+func (h *Handler) IPRateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			ctx := ratelimit.WithClientIP(r.Context(), h.rateLimitIP(r))
+
+			result := h.rateLimiter.AllowIP(ctx)
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(result.RetryAfter)))
+				h.errorResponse(w, r, http.StatusTooManyRequests, rateLimitedMessage)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		},
+	)
+}
+
+// RateLimitMiddleware limits the requests of every route, it runs after AuthenticateUserMiddleware
+// so the logged in clients are limited by user and the others by ip
+func (h *Handler) RateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			ctx := ratelimit.WithClientIP(r.Context(), h.rateLimitIP(r))
+
+			result := h.rateLimiter.AllowRoute(ctx, r.URL.Path)
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(result.RetryAfter)))
+				h.errorResponse(w, r, http.StatusTooManyRequests, rateLimitedMessage)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		},
+	)
+}
+
+// rateLimitIP is the ip the clients without user are limited by, unlike clientIP the headers of
+// the proxy are only read when they're trusted, otherwise every request could use another ip
+func (h *Handler) rateLimitIP(r *http.Request) string {
+	if h.appConfig.TrustProxyHeaders {
+		if ip := r.Header.Get("X-Real-IP"); ip != "" {
+			return ip
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// RateLimitExtension limits the root fields of the graphql operations. The fields with a result
+// union that has a RateLimitError get it as their result and the others fail with a RATE_LIMITED
+// error
+type RateLimitExtension struct {
+	limiter *ratelimit.Limiter
+	schema  *ast.Schema
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = &RateLimitExtension{}
+
+func NewRateLimitExtension(limiter *ratelimit.Limiter) *RateLimitExtension {
+	return &RateLimitExtension{limiter: limiter}
+}
+
+func (e *RateLimitExtension) ExtensionName() string {
+	return "RateLimit"
+}
+
+func (e *RateLimitExtension) Validate(schema graphql.ExecutableSchema) error {
+	e.schema = schema.Schema()
+	return nil
+}
+
+func (e *RateLimitExtension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver || !e.isRootObject(fc.Object) {
+		return next(ctx)
+	}
+
+	result := e.limiter.AllowOperation(ctx, fc.Object, fc.Field.Name)
+	if result.Allowed {
+		return next(ctx)
+	}
+
+	retryAfter := retryAfterSeconds(result.RetryAfter)
+
+	if e.canReturnRateLimitError(fc.Field.Definition.Type) {
+		return &model.RateLimitError{
+			ErrorMessage:      rateLimitedMessage,
+			Code:              customerrors.CodeRateLimited,
+			RetryAfterSeconds: int32(retryAfter),
+		}, nil
+	}
+
+	return nil, &gqlerror.Error{
+		Message: rateLimitedMessage,
+		Path:    fc.Path(),
+		Extensions: map[string]any{
+			"code":              customerrors.CodeRateLimited,
+			"retryAfterSeconds": retryAfter,
+		},
+	}
+}
+
+func (e *RateLimitExtension) isRootObject(object string) bool {
+	for _, root := range []*ast.Definition{e.schema.Query, e.schema.Mutation, e.schema.Subscription} {
+		if root != nil && root.Name == object {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *RateLimitExtension) canReturnRateLimitError(fieldType *ast.Type) bool {
+	definition := e.schema.Types[fieldType.Name()]
+	if definition == nil || fieldType.Elem != nil {
+		return false
+	}
+
+	return slices.ContainsFunc(e.schema.GetPossibleTypes(definition), func(possibleType *ast.Definition) bool {
+		return possibleType.Name == "RateLimitError"
+	})
+}
+
+// retryAfterSeconds rounds up, a client that waits the seconds always gets a token
+func retryAfterSeconds(retryAfter time.Duration) int {
+	return max(1, int(math.Ceil(retryAfter.Seconds())))
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"golang-whatsapp-clone/auth"
+	"golang-whatsapp-clone/config"
+	"golang-whatsapp-clone/graph"
+	"golang-whatsapp-clone/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/rs/zerolog"
+)
+
+func newRateLimitTestHandler(trustProxyHeaders bool) http.Handler {
+	logger := zerolog.Nop()
+	h := &Handler{
+		logger:    &logger,
+		appConfig: &config.AppConfig{TrustProxyHeaders: trustProxyHeaders},
+		rateLimiter: ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Rules{
+			Routes: []ratelimit.RouteRule{{Prefix: "/api/v1/auth/", Limit: ratelimit.PerMinute(1, 1)}},
+		}, &logger),
+	}
+
+	return h.RateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func rateLimitTestRequest(handler http.Handler, path string, remoteAddr string, realIP string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	r.RemoteAddr = remoteAddr
+	if realIP != "" {
+		r.Header.Set("X-Real-IP", realIP)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	handler := newRateLimitTestHandler(false)
+
+	if w := rateLimitTestRequest(handler, "/api/v1/auth/google", "10.0.0.1:1234", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected the first request to be allowed, got %d", w.Code)
+	}
+
+	// the header of the proxy is ignored when it's not trusted, so it can't be used to get more tokens
+	w := rateLimitTestRequest(handler, "/api/v1/auth/github", "10.0.0.1:4321", "10.0.0.9")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("expected the second request to be limited with a Retry-After, got %d %v", w.Code, w.Header())
+	}
+
+	if w := rateLimitTestRequest(handler, "/api/v1/auth/google", "10.0.0.2:1234", ""); w.Code != http.StatusOK {
+		t.Errorf("expected another ip to be allowed, got %d", w.Code)
+	}
+
+	if w := rateLimitTestRequest(handler, "/health", "10.0.0.1:1234", ""); w.Code != http.StatusOK {
+		t.Errorf("expected the routes without limit to be allowed, got %d", w.Code)
+	}
+}
+
+func TestRateLimitMiddlewareTrustedProxy(t *testing.T) {
+	handler := newRateLimitTestHandler(true)
+
+	// every client comes from the address of the proxy
+	for _, realIP := range []string{"10.0.0.1", "10.0.0.2"} {
+		if w := rateLimitTestRequest(handler, "/api/v1/auth/google", "127.0.0.1:1234", realIP); w.Code != http.StatusOK {
+			t.Errorf("expected the client %s to be allowed, got %d", realIP, w.Code)
+		}
+	}
+}
+
+func TestIPRateLimitMiddlewareRunsBeforeTheAuthentication(t *testing.T) {
+	logger := zerolog.Nop()
+	h := &Handler{
+		logger:     &logger,
+		appConfig:  &config.AppConfig{CookieName: "access_token"},
+		jwtService: auth.NewJWTService("access-secret", "refresh-secret"),
+		rateLimiter: ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Rules{
+			IP: ratelimit.PerMinute(1, 1),
+		}, &logger),
+	}
+	handler := h.IPRateLimitMiddleware(h.AuthenticateUserMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("Authorization", "Bearer not-a-token")
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		return w
+	}
+
+	if w := request("10.0.0.1:1234"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected the invalid token to be rejected, got %d", w.Code)
+	}
+
+	// the invalid tokens take the tokens of the ip too
+	if w := request("10.0.0.1:1234"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the second invalid token to be limited, got %d", w.Code)
+	}
+
+	if w := request("10.0.0.2:1234"); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected another ip to reach the authentication, got %d", w.Code)
+	}
+}
+
+func TestRateLimitExtension(t *testing.T) {
+	logger := zerolog.Nop()
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Rules{
+		Operations: map[string]ratelimit.Limit{
+			"Mutation.sendMessage": ratelimit.PerMinute(1, 0),
+			"Query.*":              ratelimit.PerMinute(1, 0),
+		},
+	}, &logger)
+	gqlClient := client.New(NewGraphqlHandler(&logger, &graph.Resolver{Logger: &logger}, nil, limiter))
+
+	// the result union of sendMessage has a RateLimitError
+	var sendMessage struct {
+		SendMessage struct {
+			Typename          string `json:"__typename"`
+			Code              string
+			RetryAfterSeconds int
+		}
+	}
+	gqlClient.MustPost(`
+		mutation {
+			sendMessage(input: {conversationId: "1", senderID: "1", content: "hi", messageType: TEXT}) {
+				__typename
+				... on RateLimitError { code retryAfterSeconds }
+			}
+		}
+	`, &sendMessage)
+
+	if sendMessage.SendMessage.Typename != "RateLimitError" || sendMessage.SendMessage.Code != "RATE_LIMITED" || sendMessage.SendMessage.RetryAfterSeconds != 60 {
+		t.Errorf("expected a RateLimitError, got %+v", sendMessage.SendMessage)
+	}
+
+	// the other fields fail with the code in the extensions of the error
+	var me struct{ Me *struct{ ID string } }
+	err := gqlClient.Post(`query { me { id } }`, &me)
+	if err == nil || !strings.Contains(err.Error(), "RATE_LIMITED") {
+		t.Errorf("expected a RATE_LIMITED error, got %v", err)
+	}
+}
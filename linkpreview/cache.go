@@ -0,0 +1,76 @@
+package linkpreview
+
+import (
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	// nil when the url could not be unfurled, so we don't request it again until it expires
+	preview   *Preview
+	expiresAt time.Time
+}
+
+// cache is a small in memory cache of the previews by url, the same link is usually shared
+// many times in a short period (forwarded, pasted in several groups, etc)
+type cache struct {
+	entries    map[string]cacheEntry
+	ttl        time.Duration
+	maxEntries int
+	mutex      sync.Mutex
+}
+
+func newCache(ttl time.Duration, maxEntries int) *cache {
+	return &cache{
+		entries:    make(map[string]cacheEntry),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+	}
+}
+
+func (c *cache) get(key string, now time.Time) (*Preview, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if now.After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return entry.preview, true
+}
+
+func (c *cache) set(key string, preview *Preview, now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+
+	c.entries[key] = cacheEntry{
+		preview:   preview,
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+// evict removes the expired entries, if there are none it removes random ones to make space
+func (c *cache) evict(now time.Time) {
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+
+	for key := range c.entries {
+		if len(c.entries) < c.maxEntries {
+			return
+		}
+		delete(c.entries, key)
+	}
+}
@@ -0,0 +1,37 @@
+package linkpreview
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var urlPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"]+`)
+
+// ExtractURLs returns the http(s) urls found in the text, in order of appearance and without duplicates
+func ExtractURLs(text string) []string {
+	urls := []string{}
+	seen := map[string]bool{}
+
+	for _, match := range urlPattern.FindAllString(text, -1) {
+		// punctuation at the end is usually part of the sentence, not of the url: "look at https://go.dev."
+		match = strings.TrimRight(match, ".,;:!?'")
+		if strings.HasSuffix(match, ")") && !strings.Contains(match, "(") {
+			match = strings.TrimSuffix(match, ")")
+		}
+
+		parsed, err := url.Parse(match)
+		if err != nil || parsed.Host == "" {
+			continue
+		}
+
+		if seen[match] {
+			continue
+		}
+		seen[match] = true
+
+		urls = append(urls, match)
+	}
+
+	return urls
+}
@@ -0,0 +1,149 @@
+package linkpreview
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	maxTitleLength       = 300
+	maxDescriptionLength = 500
+)
+
+// Preview is the card shown below a message with a link
+type Preview struct {
+	URL         string
+	Title       string
+	Description string
+	ImageURL    string
+	SiteName    string
+}
+
+// ParseHTML reads the OpenGraph metadata of the page, falling back to the <title> and the
+// description meta tag. It only reads the <head> of the document
+func ParseHTML(body []byte, pageURL string) *Preview {
+	preview := &Preview{URL: pageURL}
+
+	var htmlTitle, metaDescription string
+	inTitle := false
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+
+		token := tokenizer.Token()
+
+		if tokenType == html.EndTagToken && token.DataAtom == atom.Head {
+			break
+		}
+
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch token.DataAtom {
+			case atom.Body:
+				// the metadata is always in the head, no need to keep reading
+				return finishPreview(preview, htmlTitle, metaDescription)
+			case atom.Title:
+				inTitle = tokenType == html.StartTagToken
+			case atom.Meta:
+				key, content := metaKeyAndContent(token)
+				switch key {
+				case "og:title":
+					preview.Title = content
+				case "og:description":
+					preview.Description = content
+				case "og:image", "og:image:url":
+					if preview.ImageURL == "" {
+						preview.ImageURL = resolveURL(pageURL, content)
+					}
+				case "og:site_name":
+					preview.SiteName = content
+				case "og:url":
+					preview.URL = resolveURL(pageURL, content)
+				case "description":
+					metaDescription = content
+				}
+			}
+		case html.TextToken:
+			if inTitle && htmlTitle == "" {
+				htmlTitle = strings.TrimSpace(token.Data)
+			}
+		case html.EndTagToken:
+			if token.DataAtom == atom.Title {
+				inTitle = false
+			}
+		}
+	}
+
+	return finishPreview(preview, htmlTitle, metaDescription)
+}
+
+func finishPreview(preview *Preview, htmlTitle string, metaDescription string) *Preview {
+	if preview.Title == "" {
+		preview.Title = htmlTitle
+	}
+	if preview.Description == "" {
+		preview.Description = metaDescription
+	}
+
+	preview.Title = truncate(strings.TrimSpace(preview.Title), maxTitleLength)
+	preview.Description = truncate(strings.TrimSpace(preview.Description), maxDescriptionLength)
+	preview.SiteName = truncate(strings.TrimSpace(preview.SiteName), maxTitleLength)
+
+	return preview
+}
+
+// metaKeyAndContent returns the property (OpenGraph) or name (standard) of the meta tag and its content
+func metaKeyAndContent(token html.Token) (string, string) {
+	var key, content string
+
+	for _, attr := range token.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "property", "name":
+			if key == "" {
+				key = strings.ToLower(strings.TrimSpace(attr.Val))
+			}
+		case "content":
+			content = attr.Val
+		}
+	}
+
+	return key, content
+}
+
+// resolveURL converts relative image urls ("/static/cover.png") to absolute ones, only http(s) urls are kept
+func resolveURL(pageURL string, value string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+
+	ref, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return ""
+	}
+
+	resolved := base.ResolveReference(ref)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return ""
+	}
+
+	return resolved.String()
+}
+
+func truncate(value string, maxLength int) string {
+	if utf8.RuneCountInString(value) <= maxLength {
+		return value
+	}
+
+	return string([]rune(value)[:maxLength-1]) + "…"
+}
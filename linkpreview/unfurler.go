@@ -0,0 +1,76 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"net/url"
+	"time"
+)
+
+var ErrNoPreview = errors.New("the page has no preview metadata")
+
+const maxCacheEntries = 5000
+
+// Unfurler builds the previews of the links, caching the results by url
+type Unfurler struct {
+	fetcher Fetcher
+	cache   *cache
+	now     func() time.Time
+}
+
+func NewUnfurler(fetcher Fetcher, cacheTTL time.Duration) *Unfurler {
+	return &Unfurler{
+		fetcher: fetcher,
+		cache:   newCache(cacheTTL, maxCacheEntries),
+		now:     time.Now,
+	}
+}
+
+// Unfurl returns the preview of the url. ErrNoPreview is returned when the page can't be
+// downloaded or it doesn't have a title, failures are cached as well
+func (u *Unfurler) Unfurl(ctx context.Context, rawURL string) (*Preview, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, ErrNoPreview
+	}
+
+	if preview, found := u.cache.get(rawURL, u.now()); found {
+		if preview == nil {
+			return nil, ErrNoPreview
+		}
+		return preview, nil
+	}
+
+	preview, err := u.unfurl(ctx, rawURL)
+	if err != nil {
+		// don't cache when we gave up because of the caller, the url may be fine
+		if ctx.Err() == nil {
+			u.cache.set(rawURL, nil, u.now())
+		}
+		return nil, err
+	}
+
+	u.cache.set(rawURL, preview, u.now())
+
+	return preview, nil
+}
+
+func (u *Unfurler) unfurl(ctx context.Context, rawURL string) (*Preview, error) {
+	page, err := u.fetcher.Fetch(ctx, rawURL)
+	if err != nil {
+		return nil, errors.Join(ErrNoPreview, err)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(page.ContentType)
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, errors.Join(ErrNoPreview, ErrUnsupportedContent)
+	}
+
+	preview := ParseHTML(page.Body, page.URL)
+	if preview.Title == "" {
+		return nil, ErrNoPreview
+	}
+
+	return preview, nil
+}
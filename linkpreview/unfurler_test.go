@@ -0,0 +1,143 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const articleHTML = `<!doctype html>
+<html>
+<head>
+  <title>Fallback title</title>
+  <meta property="og:title" content="Go 1.24 is released">
+  <meta property="og:description" content="The latest Go release brings generic type aliases.">
+  <meta property="og:image" content="/images/cover.png">
+  <meta property="og:site_name" content="The Go Blog">
+</head>
+<body><meta property="og:title" content="ignored"></body>
+</html>`
+
+func newTestServer(t *testing.T, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, articleHTML)
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><head><title> Just a title </title></head></html>")
+	})
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "image/png")
+		fmt.Fprint(w, "not really a png")
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestUnfurlReadsOpenGraphMetadata(t *testing.T) {
+	var hits atomic.Int32
+	server := newTestServer(t, &hits)
+
+	unfurler := NewUnfurler(NewHTTPFetcher(server.Client()), time.Minute)
+
+	preview, err := unfurler.Unfurl(context.Background(), server.URL+"/article")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := &Preview{
+		URL:         server.URL + "/article",
+		Title:       "Go 1.24 is released",
+		Description: "The latest Go release brings generic type aliases.",
+		ImageURL:    server.URL + "/images/cover.png",
+		SiteName:    "The Go Blog",
+	}
+	if !reflect.DeepEqual(preview, want) {
+		t.Errorf("got %+v, want %+v", preview, want)
+	}
+
+	plain, err := unfurler.Unfurl(context.Background(), server.URL+"/plain")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plain.Title != "Just a title" {
+		t.Errorf("expected the <title> as fallback, got %q", plain.Title)
+	}
+}
+
+func TestUnfurlCachesResults(t *testing.T) {
+	var hits atomic.Int32
+	server := newTestServer(t, &hits)
+
+	unfurler := NewUnfurler(NewHTTPFetcher(server.Client()), time.Minute)
+
+	for range 3 {
+		if _, err := unfurler.Unfurl(context.Background(), server.URL+"/article"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// failures are cached too
+	for range 2 {
+		if _, err := unfurler.Unfurl(context.Background(), server.URL+"/image.png"); !errors.Is(err, ErrNoPreview) {
+			t.Fatalf("expected ErrNoPreview for a non html page, got %v", err)
+		}
+	}
+
+	if hits.Load() != 2 {
+		t.Errorf("expected 2 requests to the server, got %d", hits.Load())
+	}
+}
+
+func TestSafeFetcherBlocksInternalAddresses(t *testing.T) {
+	var hits atomic.Int32
+	server := newTestServer(t, &hits)
+
+	unfurler := NewUnfurler(NewSafeHTTPFetcher(2*time.Second), time.Minute)
+
+	_, err := unfurler.Unfurl(context.Background(), server.URL+"/article")
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("expected ErrBlockedAddress for %s, got %v", server.URL, err)
+	}
+
+	if hits.Load() != 0 {
+		t.Errorf("the loopback server must not be reached, got %d requests", hits.Load())
+	}
+
+	blocked := []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "::1", "fe80::1", "::ffff:127.0.0.1", "0.0.0.0"}
+	for _, raw := range blocked {
+		if !IsBlockedAddress(netip.MustParseAddr(raw)) {
+			t.Errorf("expected %s to be blocked", raw)
+		}
+	}
+
+	if IsBlockedAddress(netip.MustParseAddr("142.250.80.46")) {
+		t.Error("expected a public address to be allowed")
+	}
+}
+
+func TestExtractURLs(t *testing.T) {
+	got := ExtractURLs("look at https://go.dev/blog. and (https://example.com/a?b=1) or https://go.dev/blog again, ftp://nope.com")
+	want := []string{"https://go.dev/blog", "https://example.com/a?b=1"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
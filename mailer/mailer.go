@@ -0,0 +1,91 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"golang-whatsapp-clone/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends the emails of the app, the log and file mailers are for development, they never
+// send anything
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// NewMailer returns the mailer configured in the AppConfig
+func NewMailer(appConfig *config.AppConfig, logger *zerolog.Logger) Mailer {
+	switch appConfig.Mailer {
+	case "smtp":
+		return NewSMTPMailer(appConfig.SMTPAddr, appConfig.MailFrom, appConfig.SMTPUsername, appConfig.SMTPPassword)
+	case "file":
+		return NewFileMailer(appConfig.MailerDir)
+	default:
+		return NewLogMailer(logger)
+	}
+}
+
+// LogMailer writes the emails in the log
+type LogMailer struct {
+	logger *zerolog.Logger
+}
+
+func NewLogMailer(logger *zerolog.Logger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+func (m *LogMailer) Send(ctx context.Context, message Message) error {
+	m.logger.Info().Msgf("LogMailer: email to %s\nSubject: %s\n\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
+
+// FileMailer writes every email in a file of the directory, it's created when it doesn't exist
+type FileMailer struct {
+	dir   string
+	count atomic.Int64
+}
+
+func NewFileMailer(dir string) *FileMailer {
+	return &FileMailer{dir: dir}
+}
+
+func (m *FileMailer) Send(ctx context.Context, message Message) error {
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create the mail directory: %w", err)
+	}
+
+	// the counter keeps the names unique when two emails are sent at the same time
+	name := fmt.Sprintf("%s-%d-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), m.count.Add(1), fileSafe(message.To))
+
+	content := fmt.Sprintf("To: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s", message.To, message.Subject, message.Body)
+	if err := os.WriteFile(filepath.Join(m.dir, name), []byte(content), 0o600); err != nil {
+		return fmt.Errorf("failed to write the email: %w", err)
+	}
+
+	return nil
+}
+
+// fileSafe keeps the letters, digits and a few symbols of an email address
+func fileSafe(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '@', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, value)
+}
@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFileMailerWritesEveryEmail(t *testing.T) {
+	dir := t.TempDir()
+	mailer := NewFileMailer(dir + "/mails")
+
+	for range 2 {
+		err := mailer.Send(context.Background(), Message{To: "ana@example.com", Subject: "Your code", Body: "123456"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir + "/mails")
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected 2 emails, got %d (%v)", len(entries), err)
+	}
+
+	content, _ := os.ReadFile(dir + "/mails/" + entries[0].Name())
+	if !strings.Contains(string(content), "To: ana@example.com") || !strings.HasSuffix(string(content), "123456") {
+		t.Errorf("unexpected email %q", content)
+	}
+}
+
+func TestFileSafe(t *testing.T) {
+	if name := fileSafe("../ana+test@example.com"); name != ".._ana_test@example.com" {
+		t.Errorf("expected the path separators to be replaced, got %s", name)
+	}
+}
@@ -13,6 +13,7 @@ import (
 func main() {
 
 	app, server, _ := server.NewServer()
+	stopBackgroundJobs := app.StartBackgroundJobs()
 
 	go func() {
 
@@ -33,6 +34,7 @@ func main() {
 	fmt.Println("Running cleanup tasks...")
 
 	// cleanup tasks
+	stopBackgroundJobs()
 	app.DBpool.Close()
 	fmt.Println("Server was successful shutdown.")
 }
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"golang-whatsapp-clone/repository"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	postgresSweepInterval = 10 * time.Minute
+	// the buckets of the default rules are full again long before, the limits that take longer
+	// to refill are reset after this time without requests
+	postgresBucketIdleTime = time.Hour
+)
+
+// PostgresStore keeps the buckets in the database, so every instance of the api uses the same
+// bucket for a client
+type PostgresStore struct {
+	rateLimitRepository repository.RateLimitRepository
+	logger              *zerolog.Logger
+}
+
+func NewPostgresStore(rateLimitRepository repository.RateLimitRepository, logger *zerolog.Logger) *PostgresStore {
+	return &PostgresStore{
+		rateLimitRepository: rateLimitRepository,
+		logger:              logger,
+	}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	tokens, allowed, err := s.rateLimitRepository.TakeToken(ctx, key, limit.Rate, limit.Burst)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if allowed {
+		return Result{Allowed: true}, nil
+	}
+
+	return Result{Allowed: false, RetryAfter: limit.retryAfter(tokens)}, nil
+}
+
+// Run removes the idle buckets every postgresSweepInterval until the context is cancelled
+func (s *PostgresStore) Run(ctx context.Context) {
+	ticker := time.NewTicker(postgresSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.rateLimitRepository.DeleteIdleBuckets(ctx, time.Now().Add(-postgresBucketIdleTime)); err != nil {
+			s.logger.Error().Msgf("PostgresStore: error to delete the idle buckets: %v", err)
+		}
+	}
+}
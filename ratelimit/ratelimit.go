@@ -0,0 +1,174 @@
+package ratelimit
+
+import (
+	"context"
+	"golang-whatsapp-clone/auth"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Limit is a token bucket, a request takes a token and Rate tokens are added every second up
+// to Burst
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func PerSecond(rate float64, burst int) Limit {
+	return Limit{Rate: rate, Burst: burst}
+}
+
+func PerMinute(rate float64, burst int) Limit {
+	return Limit{Rate: rate / 60, Burst: burst}
+}
+
+// retryAfter is how long the bucket takes to have a token again
+func (l Limit) retryAfter(tokens float64) time.Duration {
+	if tokens >= 1 || l.Rate <= 0 {
+		return 0
+	}
+
+	return time.Duration(math.Ceil((1 - tokens) / l.Rate * float64(time.Second)))
+}
+
+type Result struct {
+	Allowed bool
+	// RetryAfter is how long the client has to wait when the request is not allowed
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets, the memory store is enough for a single instance of the api and the
+// postgres one shares the buckets between instances
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// RouteRule limits the requests of the paths that start with the prefix
+type RouteRule struct {
+	Prefix string
+	Limit  Limit
+}
+
+type Rules struct {
+	// IP limits every request of an ip before its user is authenticated, so the requests with
+	// invalid tokens are limited too. The zero value doesn't limit them
+	IP Limit
+	// Routes are matched by the longest prefix
+	Routes []RouteRule
+	// Operations are the root fields of the graphql operations, like Mutation.sendMessage. The
+	// field * is every field of the type without its own limit
+	Operations map[string]Limit
+}
+
+// DefaultRules are the limits of the api, the limits of the auth routes are per ip because the
+// clients are not logged in yet
+func DefaultRules() Rules {
+	return Rules{
+		IP: PerSecond(50, 200),
+		Routes: []RouteRule{
+			{Prefix: "/api/v1/auth/", Limit: PerMinute(30, 20)},
+			{Prefix: "/api/v1/auth/email", Limit: PerMinute(5, 5)},
+			{Prefix: "/graphql", Limit: PerSecond(20, 100)},
+		},
+		Operations: map[string]Limit{
+			"Mutation.sendMessage":          PerMinute(60, 20),
+			"Mutation.requestAccountExport": PerMinute(5, 5),
+			"Mutation.deleteAccount":        PerMinute(5, 5),
+			"Mutation.*":                    PerMinute(120, 60),
+			"Subscription.*":                PerMinute(30, 30),
+		},
+	}
+}
+
+// Limiter applies the rules to the requests of every client, the clients are the users and
+// the ips of the requests without user. The requests are allowed when the store fails, an
+// outage of the store must not take down the api
+type Limiter struct {
+	store  Store
+	rules  Rules
+	logger *zerolog.Logger
+}
+
+func NewLimiter(store Store, rules Rules, logger *zerolog.Logger) *Limiter {
+	return &Limiter{
+		store:  store,
+		rules:  rules,
+		logger: logger,
+	}
+}
+
+// AllowIP takes a token of the bucket of the ip of the context, the user of the context is ignored
+func (l *Limiter) AllowIP(ctx context.Context) Result {
+	if l.rules.IP == (Limit{}) {
+		return Result{Allowed: true}
+	}
+
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return l.takeKey(ctx, "ip:"+ip, l.rules.IP)
+}
+
+// AllowRoute takes a token of the bucket of the route of the path
+func (l *Limiter) AllowRoute(ctx context.Context, path string) Result {
+	var rule *RouteRule
+	for i, route := range l.rules.Routes {
+		if strings.HasPrefix(path, route.Prefix) && (rule == nil || len(route.Prefix) > len(rule.Prefix)) {
+			rule = &l.rules.Routes[i]
+		}
+	}
+
+	if rule == nil {
+		return Result{Allowed: true}
+	}
+
+	return l.take(ctx, "route:"+rule.Prefix, rule.Limit)
+}
+
+// AllowOperation takes a token of the bucket of the root field of an operation
+func (l *Limiter) AllowOperation(ctx context.Context, object string, field string) Result {
+	name := object + "." + field
+	limit, ok := l.rules.Operations[name]
+	if !ok {
+		name = object + ".*"
+		limit, ok = l.rules.Operations[name]
+	}
+
+	if !ok {
+		return Result{Allowed: true}
+	}
+
+	return l.take(ctx, "operation:"+name, limit)
+}
+
+func (l *Limiter) take(ctx context.Context, name string, limit Limit) Result {
+	return l.takeKey(ctx, name+":"+ClientKey(ctx), limit)
+}
+
+func (l *Limiter) takeKey(ctx context.Context, key string, limit Limit) Result {
+	result, err := l.store.Take(ctx, key, limit)
+	if err != nil {
+		l.logger.Error().Msgf("Limiter: error to take a token of %s: %v", key, err)
+		return Result{Allowed: true}
+	}
+
+	return result
+}
+
+type clientIPKey struct{}
+
+// WithClientIP adds the ip of the request to the context, it identifies the clients without user
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientKey identifies the client of the context, the user when it's logged in or its ip
+func ClientKey(ctx context.Context) string {
+	if user := auth.GetUserFromContext(ctx); user != nil && user.UserID != "" {
+		return "user:" + user.UserID
+	}
+
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return "ip:" + ip
+}
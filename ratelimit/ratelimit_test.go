@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"golang-whatsapp-clone/auth"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestMemoryStoreRefillsTheBucket(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	limit := PerSecond(1, 2)
+	ctx := context.Background()
+
+	for i := range 2 {
+		if result, _ := store.Take(ctx, "key", limit); !result.Allowed {
+			t.Fatalf("expected the request %d of the burst to be allowed", i)
+		}
+	}
+
+	result, _ := store.Take(ctx, "key", limit)
+	if result.Allowed || result.RetryAfter != time.Second {
+		t.Fatalf("expected the empty bucket to wait a second, got %+v", result)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if result, _ := store.Take(ctx, "key", limit); result.Allowed || result.RetryAfter != 500*time.Millisecond {
+		t.Errorf("expected half a token, got %+v", result)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if result, _ := store.Take(ctx, "key", limit); !result.Allowed {
+		t.Error("expected the refilled token to be allowed")
+	}
+
+	if result, _ := store.Take(ctx, "another key", limit); !result.Allowed {
+		t.Error("expected every key to have its own bucket")
+	}
+
+	// the full buckets are removed
+	now = now.Add(time.Hour)
+	_, _ = store.Take(ctx, "key", limit)
+	if len(store.buckets) != 1 {
+		t.Errorf("expected the full buckets to be removed, got %d buckets", len(store.buckets))
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	return Result{}, errors.New("the store is down")
+}
+
+// recordingStore denies every request and keeps the keys
+type recordingStore struct {
+	keys []string
+}
+
+func (s *recordingStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.keys = append(s.keys, key)
+	return Result{Allowed: false, RetryAfter: time.Second}, nil
+}
+
+func TestLimiterRules(t *testing.T) {
+	logger := zerolog.Nop()
+	store := &recordingStore{}
+	limiter := NewLimiter(store, DefaultRules(), &logger)
+
+	ipCtx := WithClientIP(context.Background(), "10.0.0.1")
+	userCtx := auth.WithUserContext(ipCtx, "user-1", "ana@example.com", "session-1")
+
+	limiter.AllowIP(userCtx)
+	limiter.AllowRoute(ipCtx, "/api/v1/auth/email/verify")
+	limiter.AllowRoute(userCtx, "/graphql")
+	limiter.AllowOperation(userCtx, "Mutation", "sendMessage")
+	limiter.AllowOperation(userCtx, "Mutation", "deleteMessage")
+
+	expected := []string{
+		"ip:10.0.0.1",
+		"route:/api/v1/auth/email:ip:10.0.0.1",
+		"route:/graphql:user:user-1",
+		"operation:Mutation.sendMessage:user:user-1",
+		"operation:Mutation.*:user:user-1",
+	}
+	for i, key := range expected {
+		if i >= len(store.keys) || store.keys[i] != key {
+			t.Fatalf("expected the keys %v, got %v", expected, store.keys)
+		}
+	}
+
+	if result := limiter.AllowRoute(ipCtx, "/health"); !result.Allowed {
+		t.Error("expected the routes without rule to be allowed")
+	}
+
+	if result := limiter.AllowOperation(userCtx, "Query", "me"); !result.Allowed {
+		t.Error("expected the operations without rule to be allowed")
+	}
+
+	if result := NewLimiter(store, Rules{}, &logger).AllowIP(ipCtx); !result.Allowed {
+		t.Error("expected the ips to be allowed without a rule")
+	}
+
+	failing := NewLimiter(failingStore{}, DefaultRules(), &logger)
+	if result := failing.AllowOperation(userCtx, "Mutation", "sendMessage"); !result.Allowed {
+		t.Error("expected the requests to be allowed when the store fails")
+	}
+}
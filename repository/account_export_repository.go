@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type AccountExportRepository interface {
+	CreateAccountExport(ctx context.Context, userID string) (*db.AccountExport, error)
+	GetLatestAccountExport(ctx context.Context, userID string) (*db.AccountExport, error)
+	GetAccountExport(ctx context.Context, exportID string, userID string) (*db.AccountExport, error)
+	ClaimAccountExport(ctx context.Context) (*db.AccountExport, error)
+	MarkAccountExportReady(ctx context.Context, exportID string, archive []byte, expiresAt time.Time) error
+	GetAccountExportArchive(ctx context.Context, exportID string) ([]byte, error)
+	MarkAccountExportFailed(ctx context.Context, exportID string) error
+	FailStaleAccountExports(ctx context.Context, claimedBefore time.Time) (int64, error)
+	DeleteExpiredAccountExports(ctx context.Context) error
+	GetUserExportConversations(ctx context.Context, userID string) (*[]db.GetUserExportConversationsRow, error)
+	GetUserExportMessages(ctx context.Context, userID string, after *db.Message, limit int32) (*[]db.Message, error)
+}
+
+type AccountExportPostgresRepository struct {
+	DBQueries *db.Queries
+}
+
+func NewAccountExportRepository(dbQueries *db.Queries) *AccountExportPostgresRepository {
+	return &AccountExportPostgresRepository{
+		DBQueries: dbQueries,
+	}
+}
+
+func (r *AccountExportPostgresRepository) CreateAccountExport(ctx context.Context, userID string) (*db.AccountExport, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	export, err := r.DBQueries.CreateAccountExport(ctx, uId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+func (r *AccountExportPostgresRepository) GetLatestAccountExport(ctx context.Context, userID string) (*db.AccountExport, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	export, err := r.DBQueries.GetLatestAccountExport(ctx, uId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+func (r *AccountExportPostgresRepository) GetAccountExport(ctx context.Context, exportID string, userID string) (*db.AccountExport, error) {
+	id, err := fromStringToUUID(exportID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	export, err := r.DBQueries.GetAccountExport(ctx, db.GetAccountExportParams{
+		ID:     id,
+		UserID: uId,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// ClaimAccountExport returns customerrors.ErrResourceNotFound when there is no pending export
+func (r *AccountExportPostgresRepository) ClaimAccountExport(ctx context.Context) (*db.AccountExport, error) {
+	export, err := r.DBQueries.ClaimAccountExport(ctx)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// MarkAccountExportReady stores the zip file of the export, customerrors.ErrResourceNotFound is
+// returned when the export doesn't exist anymore, its user was deleted
+func (r *AccountExportPostgresRepository) MarkAccountExportReady(ctx context.Context, exportID string, archive []byte, expiresAt time.Time) error {
+	id, err := fromStringToUUID(exportID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	rows, err := r.DBQueries.MarkAccountExportReady(ctx, db.MarkAccountExportReadyParams{
+		ID:        id,
+		Data:      archive,
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return customerrors.ErrResourceNotFound
+	}
+
+	return nil
+}
+
+// GetAccountExportArchive returns the zip file of the export, customerrors.ErrResourceNotFound is
+// returned when it's not ready
+func (r *AccountExportPostgresRepository) GetAccountExportArchive(ctx context.Context, exportID string) ([]byte, error) {
+	id, err := fromStringToUUID(exportID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	archive, err := r.DBQueries.GetAccountExportArchive(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+func (r *AccountExportPostgresRepository) MarkAccountExportFailed(ctx context.Context, exportID string) error {
+	id, err := fromStringToUUID(exportID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	return r.DBQueries.MarkAccountExportFailed(ctx, id)
+}
+
+func (r *AccountExportPostgresRepository) FailStaleAccountExports(ctx context.Context, claimedBefore time.Time) (int64, error) {
+	return r.DBQueries.FailStaleAccountExports(ctx, pgtype.Timestamptz{Time: claimedBefore, Valid: true})
+}
+
+// DeleteExpiredAccountExports removes the expired exports with their archives
+func (r *AccountExportPostgresRepository) DeleteExpiredAccountExports(ctx context.Context) error {
+	return r.DBQueries.DeleteExpiredAccountExports(ctx)
+}
+
+func (r *AccountExportPostgresRepository) GetUserExportConversations(ctx context.Context, userID string) (*[]db.GetUserExportConversationsRow, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	conversations, err := r.DBQueries.GetUserExportConversations(ctx, uId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conversations, nil
+}
+
+// GetUserExportMessages returns the messages sent by the user after the given one, or from the
+// first one when it's nil
+func (r *AccountExportPostgresRepository) GetUserExportMessages(ctx context.Context, userID string, after *db.Message, limit int32) (*[]db.Message, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	params := db.GetUserExportMessagesParams{
+		SenderID:       uId,
+		AfterCreatedAt: pgtype.Timestamptz{Time: time.Time{}, Valid: true},
+		AfterID:        pgtype.UUID{Valid: true},
+		LimitCount:     limit,
+	}
+	if after != nil {
+		params.AfterCreatedAt = after.CreatedAt
+		params.AfterID = after.ID
+	}
+
+	messages, err := r.DBQueries.GetUserExportMessages(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &messages, nil
+}
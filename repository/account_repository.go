@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DeletedAccount has what was left outside of the database by a deleted account
+type DeletedAccount struct {
+	// the sessions that were active, their websocket connections must be closed
+	SessionIDs []string
+}
+
+type AccountRepository interface {
+	DeleteAccount(ctx context.Context, userID string, email string, keepDirectConversations bool) (*DeletedAccount, error)
+}
+
+// AccountPostgresRepository needs the pool besides the queries, the account is deleted in a
+// transaction so a failure never leaves it half removed
+type AccountPostgresRepository struct {
+	DBPool    *pgxpool.Pool
+	DBQueries *db.Queries
+}
+
+func NewAccountRepository(dbPool *pgxpool.Pool, dbQueries *db.Queries) *AccountPostgresRepository {
+	return &AccountPostgresRepository{
+		DBPool:    dbPool,
+		DBQueries: dbQueries,
+	}
+}
+
+// DeleteAccount removes the user and its data. Its messages in the groups are kept, sent by the
+// deleted account, and its direct conversations are removed unless keepDirectConversations is
+// set, then their messages are kept in the same way. customerrors.ErrResourceNotFound is returned
+// when the user doesn't exist
+func (r *AccountPostgresRepository) DeleteAccount(ctx context.Context, userID string, email string, keepDirectConversations bool) (*DeletedAccount, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	deletedUserID, err := fromStringToUUID(DELETED_USER_ID)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := &DeletedAccount{}
+
+	err = pgx.BeginFunc(ctx, r.DBPool, func(tx pgx.Tx) error {
+		queries := r.DBQueries.WithTx(tx)
+
+		sessions, err := queries.GetUserActiveSessions(ctx, uId)
+		if err != nil {
+			return err
+		}
+		for _, session := range sessions {
+			deleted.SessionIDs = append(deleted.SessionIDs, session.ID.String())
+		}
+
+		if !keepDirectConversations {
+			if err := queries.DeleteUserDirectConversations(ctx, uId); err != nil {
+				return err
+			}
+		}
+
+		err = queries.AnonymizeUserMessages(ctx, db.AnonymizeUserMessagesParams{
+			DeletedUserID: deletedUserID,
+			UserID:        uId,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := queries.TransferUserGroupOwnerships(ctx, uId); err != nil {
+			return err
+		}
+
+		// the pending email logins would create the account again
+		if err := queries.DeleteEmailLogins(ctx, email); err != nil {
+			return err
+		}
+
+		// the sessions, tokens, identities, participations, reactions and the rest go with it
+		rows, err := queries.DeleteUser(ctx, uId)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return customerrors.ErrResourceNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
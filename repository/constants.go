@@ -9,4 +9,26 @@ var (
 	MESSAGE_STATUS_DELIVERED = "DELIVERED"
 	MESSAGE_STATUS_READ      = "READ"
 	MESSAGE_STATUS_FAILED    = "FAILED"
+
+	PARTICIPANT_ROLE_MEMBER = "member"
+	PARTICIPANT_ROLE_ADMIN  = "admin"
+	PARTICIPANT_ROLE_OWNER  = "owner"
+
+	SCHEDULED_MESSAGE_STATUS_PENDING   = "PENDING"
+	SCHEDULED_MESSAGE_STATUS_SENDING   = "SENDING"
+	SCHEDULED_MESSAGE_STATUS_SENT      = "SENT"
+	SCHEDULED_MESSAGE_STATUS_CANCELLED = "CANCELLED"
+	SCHEDULED_MESSAGE_STATUS_FAILED    = "FAILED"
+
+	LAST_SEEN_PRIVACY_EVERYONE = "EVERYONE"
+	LAST_SEEN_PRIVACY_CONTACTS = "CONTACTS"
+	LAST_SEEN_PRIVACY_NOBODY   = "NOBODY"
+
+	// DELETED_USER_ID is the sender of the messages that are kept after their account is deleted
+	DELETED_USER_ID = "00000000-0000-0000-0000-000000000000"
+
+	ACCOUNT_EXPORT_STATUS_PENDING    = "PENDING"
+	ACCOUNT_EXPORT_STATUS_PROCESSING = "PROCESSING"
+	ACCOUNT_EXPORT_STATUS_READY      = "READY"
+	ACCOUNT_EXPORT_STATUS_FAILED     = "FAILED"
 )
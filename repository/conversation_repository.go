@@ -2,18 +2,25 @@ package repository
 
 import (
 	"context"
+	"errors"
 	db "golang-whatsapp-clone/database/gen"
 	customerrors "golang-whatsapp-clone/errors"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/rs/zerolog"
 )
 
 type ConversationRepository interface {
-	GetUserConversations(ctx context.Context, userID string) (*[]db.GetUserConversationsRow, error)
+	GetUserConversations(ctx context.Context, userID string, includeArchived bool) (*[]db.GetUserConversationsRow, error)
 	GetLastMessageFromConversation(ctx context.Context, conversationID string) (*db.GetLastMessageRow, error)
+	GetLastMessages(ctx context.Context, conversationIDs []string) (*[]db.GetLastMessageRow, error)
 	CreateConversation(ctx context.Context, conversationType string) (*db.Conversation, error)
 	FindDirectConversation(ctx context.Context, user1ID string, user2ID string) (*db.Conversation, error)
+	UpdateLastMessageAt(ctx context.Context, conversationID string, lastMessageAt time.Time) error
+	GetConversationByID(ctx context.Context, conversationID string) (*db.Conversation, error)
+	UpdateDisappearingMessages(ctx context.Context, conversationID string, seconds int32) (*db.Conversation, error)
 }
 
 type ConversationPostgresRepository struct {
@@ -28,13 +35,16 @@ func NewConversationRepository(dbQueries *db.Queries, logger *zerolog.Logger) *C
 	}
 }
 
-func (r *ConversationPostgresRepository) GetUserConversations(ctx context.Context, userID string) (*[]db.GetUserConversationsRow, error) {
+func (r *ConversationPostgresRepository) GetUserConversations(ctx context.Context, userID string, includeArchived bool) (*[]db.GetUserConversationsRow, error) {
 	uId, err := fromStringToUUID(userID)
 	if err != nil {
 		return nil, customerrors.ErrInvalidUUIDValue
 
 	}
-	result, err := r.DBQueries.GetUserConversations(ctx, uId)
+	result, err := r.DBQueries.GetUserConversations(ctx, db.GetUserConversationsParams{
+		UserID:          uId,
+		IncludeArchived: includeArchived,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -64,6 +74,21 @@ func (r *ConversationPostgresRepository) GetLastMessageFromConversation(ctx cont
 	return &lastMessage[0], nil
 }
 
+// GetLastMessages returns the last message of every conversation that has one
+func (r *ConversationPostgresRepository) GetLastMessages(ctx context.Context, conversationIDs []string) (*[]db.GetLastMessageRow, error) {
+	ids, err := fromStringsToUUIDs(conversationIDs)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	lastMessages, err := r.DBQueries.GetLastMessage(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lastMessages, nil
+}
+
 func (r *ConversationPostgresRepository) CreateConversation(ctx context.Context, conversationType string) (*db.Conversation, error) {
 	conversation, err := r.DBQueries.CreateConversation(ctx, conversationType)
 	if err != nil {
@@ -95,3 +120,52 @@ func (r *ConversationPostgresRepository) FindDirectConversation(ctx context.Cont
 
 	return &existing, nil
 }
+
+func (r *ConversationPostgresRepository) UpdateLastMessageAt(ctx context.Context, conversationID string, lastMessageAt time.Time) error {
+	cId, err := fromStringToUUID(conversationID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	return r.DBQueries.UpdateConversationLastMessageAt(ctx, db.UpdateConversationLastMessageAtParams{
+		ID:            cId,
+		LastMessageAt: pgtype.Timestamptz{Time: lastMessageAt, Valid: true},
+	})
+}
+
+func (r *ConversationPostgresRepository) GetConversationByID(ctx context.Context, conversationID string) (*db.Conversation, error) {
+	cId, err := fromStringToUUID(conversationID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	conversation, err := r.DBQueries.GetConversationByID(ctx, cId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &conversation, nil
+}
+
+func (r *ConversationPostgresRepository) UpdateDisappearingMessages(ctx context.Context, conversationID string, seconds int32) (*db.Conversation, error) {
+	cId, err := fromStringToUUID(conversationID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	conversation, err := r.DBQueries.UpdateConversationDisappearingMessages(ctx, db.UpdateConversationDisappearingMessagesParams{
+		ID:                          cId,
+		DisappearingMessagesSeconds: seconds,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &conversation, nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type DraftRepository interface {
+	SaveDraft(ctx context.Context, userID string, conversationID string, content string, replyToMessageID *string) (*db.MessageDraft, error)
+	DeleteDraft(ctx context.Context, userID string, conversationID string) (bool, error)
+}
+
+type DraftPostgresRepository struct {
+	DBQueries *db.Queries
+}
+
+func NewDraftRepository(dbQueries *db.Queries) *DraftPostgresRepository {
+	return &DraftPostgresRepository{
+		DBQueries: dbQueries,
+	}
+}
+
+func (r *DraftPostgresRepository) SaveDraft(ctx context.Context, userID string, conversationID string, content string, replyToMessageID *string) (*db.MessageDraft, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	cId, err := fromStringToUUID(conversationID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	var rId pgtype.UUID
+	if replyToMessageID != nil {
+		rId, err = fromStringToUUID(*replyToMessageID)
+		if err != nil {
+			return nil, customerrors.ErrInvalidUUIDValue
+		}
+	}
+
+	draft, err := r.DBQueries.UpsertMessageDraft(ctx, db.UpsertMessageDraftParams{
+		UserID:           uId,
+		ConversationID:   cId,
+		Content:          content,
+		ReplyToMessageID: rId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &draft, nil
+}
+
+// DeleteDraft returns false when there was no draft
+func (r *DraftPostgresRepository) DeleteDraft(ctx context.Context, userID string, conversationID string) (bool, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return false, customerrors.ErrInvalidUUIDValue
+	}
+
+	cId, err := fromStringToUUID(conversationID)
+	if err != nil {
+		return false, customerrors.ErrInvalidUUIDValue
+	}
+
+	rows, err := r.DBQueries.DeleteMessageDraft(ctx, db.DeleteMessageDraftParams{
+		UserID:         uId,
+		ConversationID: cId,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type EmailLoginRepository interface {
+	ReplaceEmailLogin(ctx context.Context, email string, codeHash string, linkTokenHash string, clientType string, codeChallenge string, expiresAt time.Time, resendInterval time.Duration) (*db.EmailLogin, error)
+	ClaimEmailLoginAttempt(ctx context.Context, email string, maxAttempts int) (*db.EmailLogin, error)
+	UseEmailLogin(ctx context.Context, loginID string) error
+	UseEmailLoginLink(ctx context.Context, linkTokenHash string) (*db.EmailLogin, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+// EmailLoginPostgresRepository needs the pool besides the queries, a login replaces the previous
+// ones of the email in a transaction
+type EmailLoginPostgresRepository struct {
+	DBPool    *pgxpool.Pool
+	DBQueries *db.Queries
+}
+
+func NewEmailLoginRepository(dbPool *pgxpool.Pool, dbQueries *db.Queries) *EmailLoginPostgresRepository {
+	return &EmailLoginPostgresRepository{
+		DBPool:    dbPool,
+		DBQueries: dbQueries,
+	}
+}
+
+// ReplaceEmailLogin creates a login of the email and deletes the previous ones, the code challenge
+// is only stored when it's not empty. customerrors.ErrLoginCodeRequested is returned when the last
+// login of the email was created less than resendInterval ago. The email is locked until the login
+// is created, so concurrent requests can't skip the interval
+func (r *EmailLoginPostgresRepository) ReplaceEmailLogin(ctx context.Context, email string, codeHash string, linkTokenHash string, clientType string, codeChallenge string, expiresAt time.Time, resendInterval time.Duration) (*db.EmailLogin, error) {
+	var login db.EmailLogin
+
+	err := pgx.BeginFunc(ctx, r.DBPool, func(tx pgx.Tx) error {
+		queries := r.DBQueries.WithTx(tx)
+
+		err := queries.LockEmailLogins(ctx, email)
+		if err != nil {
+			return err
+		}
+
+		latest, err := queries.GetLatestEmailLogin(ctx, email)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		if err == nil && time.Since(latest.CreatedAt.Time) < resendInterval {
+			return customerrors.ErrLoginCodeRequested
+		}
+
+		err = queries.DeleteEmailLogins(ctx, email)
+		if err != nil {
+			return err
+		}
+
+		login, err = queries.CreateEmailLogin(ctx, db.CreateEmailLoginParams{
+			Email:         email,
+			CodeHash:      codeHash,
+			LinkTokenHash: linkTokenHash,
+			ClientType:    clientType,
+			CodeChallenge: pgtype.Text{String: codeChallenge, Valid: codeChallenge != ""},
+			ExpiresAt:     pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		})
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &login, nil
+}
+
+// ClaimEmailLoginAttempt counts a guess of the code of the pending login of the email and returns
+// it, customerrors.ErrResourceNotFound is returned when there is no pending login or it's locked
+func (r *EmailLoginPostgresRepository) ClaimEmailLoginAttempt(ctx context.Context, email string, maxAttempts int) (*db.EmailLogin, error) {
+	login, err := r.DBQueries.ClaimEmailLoginAttempt(ctx, db.ClaimEmailLoginAttemptParams{
+		Email:       email,
+		MaxAttempts: int32(maxAttempts),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &login, nil
+}
+
+// UseEmailLogin returns customerrors.ErrResourceNotFound when the login was already used
+func (r *EmailLoginPostgresRepository) UseEmailLogin(ctx context.Context, loginID string) error {
+	lId, err := fromStringToUUID(loginID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	rows, err := r.DBQueries.UseEmailLogin(ctx, lId)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return customerrors.ErrResourceNotFound
+	}
+
+	return nil
+}
+
+// UseEmailLoginLink returns customerrors.ErrResourceNotFound when the link doesn't exist, it was
+// already used or it's expired
+func (r *EmailLoginPostgresRepository) UseEmailLoginLink(ctx context.Context, linkTokenHash string) (*db.EmailLogin, error) {
+	login, err := r.DBQueries.UseEmailLoginLink(ctx, linkTokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &login, nil
+}
+
+func (r *EmailLoginPostgresRepository) DeleteExpired(ctx context.Context) error {
+	return r.DBQueries.DeleteExpiredEmailLogins(ctx)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type IdentityRepository interface {
+	GetUserByIdentity(ctx context.Context, provider string, providerUserID string) (*db.User, error)
+	UpsertUserByEmail(ctx context.Context, name string, email string, avatarURL string) (*db.User, error)
+	UpdateUserFromProvider(ctx context.Context, userID string, name string, avatarURL string) (*db.User, error)
+	LinkIdentity(ctx context.Context, userID string, provider string, providerUserID string, email string) (*db.UserIdentity, error)
+}
+
+type IdentityPostgresRepository struct {
+	DBQueries *db.Queries
+}
+
+func NewIdentityRepository(dbQueries *db.Queries) *IdentityPostgresRepository {
+	return &IdentityPostgresRepository{
+		DBQueries: dbQueries,
+	}
+}
+
+func (r *IdentityPostgresRepository) GetUserByIdentity(ctx context.Context, provider string, providerUserID string) (*db.User, error) {
+	row, err := r.DBQueries.GetUserByIdentity(ctx, db.GetUserByIdentityParams{
+		Provider:       provider,
+		ProviderUserID: providerUserID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &row.User, nil
+}
+
+// UpsertUserByEmail creates the user or returns the one with the email, the empty name and
+// avatar keep the current values
+func (r *IdentityPostgresRepository) UpsertUserByEmail(ctx context.Context, name string, email string, avatarURL string) (*db.User, error) {
+	user, err := r.DBQueries.UpsertUserByEmail(ctx, db.UpsertUserByEmailParams{
+		Name:      fromStringToText(name),
+		Email:     email,
+		AvatarUrl: fromStringToText(avatarURL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *IdentityPostgresRepository) UpdateUserFromProvider(ctx context.Context, userID string, name string, avatarURL string) (*db.User, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	user, err := r.DBQueries.UpdateUserFromProvider(ctx, db.UpdateUserFromProviderParams{
+		ID:        uId,
+		Name:      fromStringToText(name),
+		AvatarUrl: fromStringToText(avatarURL),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// LinkIdentity links the account of the provider to the user, when it was already linked only
+// its email is updated
+func (r *IdentityPostgresRepository) LinkIdentity(ctx context.Context, userID string, provider string, providerUserID string, email string) (*db.UserIdentity, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	identity, err := r.DBQueries.CreateUserIdentity(ctx, db.CreateUserIdentityParams{
+		UserID:         uId,
+		Provider:       provider,
+		ProviderUserID: providerUserID,
+		Email:          email,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
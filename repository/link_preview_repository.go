@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/linkpreview"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type LinkPreviewRepository interface {
+	SaveLinkPreview(ctx context.Context, messageID string, preview *linkpreview.Preview) (*db.MessageLinkPreview, error)
+	GetLinkPreviews(ctx context.Context, messageIDs []string) (*[]db.MessageLinkPreview, error)
+}
+
+type LinkPreviewPostgresRepository struct {
+	DBQueries *db.Queries
+}
+
+func NewLinkPreviewRepository(dbQueries *db.Queries) *LinkPreviewPostgresRepository {
+	return &LinkPreviewPostgresRepository{
+		DBQueries: dbQueries,
+	}
+}
+
+func (r *LinkPreviewPostgresRepository) SaveLinkPreview(ctx context.Context, messageID string, preview *linkpreview.Preview) (*db.MessageLinkPreview, error) {
+	mId, err := fromStringToUUID(messageID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	saved, err := r.DBQueries.UpsertMessageLinkPreview(ctx, db.UpsertMessageLinkPreviewParams{
+		MessageID:   mId,
+		Url:         preview.URL,
+		Title:       preview.Title,
+		Description: fromStringToText(preview.Description),
+		ImageUrl:    fromStringToText(preview.ImageURL),
+		SiteName:    fromStringToText(preview.SiteName),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &saved, nil
+}
+
+func (r *LinkPreviewPostgresRepository) GetLinkPreviews(ctx context.Context, messageIDs []string) (*[]db.MessageLinkPreview, error) {
+	ids := make([]pgtype.UUID, 0, len(messageIDs))
+	for _, rawId := range messageIDs {
+		mId, err := fromStringToUUID(rawId)
+		if err != nil {
+			return nil, customerrors.ErrInvalidUUIDValue
+		}
+		ids = append(ids, mId)
+	}
+
+	previews, err := r.DBQueries.GetMessageLinkPreviews(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return &previews, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// NewMention is a parsed mention waiting to be stored
+type NewMention struct {
+	UserID string
+	Offset int32
+	Length int32
+}
+
+type MentionRepository interface {
+	CreateMentions(ctx context.Context, messageID string, mentions []NewMention) error
+	GetMessageMentions(ctx context.Context, messageIDs []string) (*[]db.GetMessageMentionsRow, error)
+	GetUserMentions(ctx context.Context, userID string, limit int32, offset int32) (*[]db.GetUserMentionsRow, error)
+}
+
+type MentionPostgresRepository struct {
+	DBQueries *db.Queries
+}
+
+func NewMentionRepository(dbQueries *db.Queries) *MentionPostgresRepository {
+	return &MentionPostgresRepository{
+		DBQueries: dbQueries,
+	}
+}
+
+func (r *MentionPostgresRepository) CreateMentions(ctx context.Context, messageID string, mentions []NewMention) error {
+	mId, err := fromStringToUUID(messageID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	rows := make([]db.CreateMessageMentionsParams, 0, len(mentions))
+	for _, mention := range mentions {
+		uId, err := fromStringToUUID(mention.UserID)
+		if err != nil {
+			return customerrors.ErrInvalidUUIDValue
+		}
+
+		rows = append(rows, db.CreateMessageMentionsParams{
+			MessageID:       mId,
+			MentionedUserID: uId,
+			OffsetStart:     mention.Offset,
+			Length:          mention.Length,
+		})
+	}
+
+	_, err = r.DBQueries.CreateMessageMentions(ctx, rows)
+
+	return err
+}
+
+func (r *MentionPostgresRepository) GetMessageMentions(ctx context.Context, messageIDs []string) (*[]db.GetMessageMentionsRow, error) {
+	ids := make([]pgtype.UUID, 0, len(messageIDs))
+	for _, rawId := range messageIDs {
+		mId, err := fromStringToUUID(rawId)
+		if err != nil {
+			return nil, customerrors.ErrInvalidUUIDValue
+		}
+		ids = append(ids, mId)
+	}
+
+	mentions, err := r.DBQueries.GetMessageMentions(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mentions, nil
+}
+
+func (r *MentionPostgresRepository) GetUserMentions(ctx context.Context, userID string, limit int32, offset int32) (*[]db.GetUserMentionsRow, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	messages, err := r.DBQueries.GetUserMentions(ctx, db.GetUserMentionsParams{
+		MentionedUserID: uId,
+		Limit:           limit,
+		Offset:          offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &messages, nil
+}
@@ -2,23 +2,36 @@ package repository
 
 import (
 	"context"
+	"errors"
 	db "golang-whatsapp-clone/database/gen"
 	customerrors "golang-whatsapp-clone/errors"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type MessageRepository interface {
 	CreateMessage(ctx context.Context, conversationID string, senderID string, content string, messageType string, replyToMessageID *string) (*db.Message, error)
 	GetMessages(ctx context.Context, conversationID string, limit int32, offset int32) (*[]db.GetConversationMessagesRow, error)
+	GetMessageByID(ctx context.Context, messageID string) (*db.Message, error)
+	ForwardMessage(ctx context.Context, sourceMessageID string, conversationIDs []string, senderID string) ([]*db.Message, error)
+	GetMessageReplies(ctx context.Context, messageID string, limit int32, offset int32) (*[]db.GetMessageRepliesRow, error)
+	CountMessageReplies(ctx context.Context, messageIDs []string) (*[]db.CountMessageRepliesRow, error)
+	GetMessageThread(ctx context.Context, messageID string, maxDepth int32, limit int32, offset int32) (*[]db.GetMessageThreadRow, error)
+	DeleteExpiredMessages(ctx context.Context, limit int32) (*[]db.DeleteExpiredMessagesRow, error)
 }
 
+// MessagePostgresRepository needs the pool besides the queries, a forward to several conversations
+// is stored in a transaction
 type MessagePostgresRepository struct {
+	DBPool    *pgxpool.Pool
 	DBQueries *db.Queries
 }
 
-func NewMessageRepository(dbQueries *db.Queries) *MessagePostgresRepository {
+func NewMessageRepository(dbPool *pgxpool.Pool, dbQueries *db.Queries) *MessagePostgresRepository {
 	return &MessagePostgresRepository{
+		DBPool:    dbPool,
 		DBQueries: dbQueries,
 	}
 }
@@ -78,3 +91,147 @@ func (r *MessagePostgresRepository) GetMessages(ctx context.Context, conversatio
 
 	return &messages, nil
 }
+
+func (r *MessagePostgresRepository) GetMessageByID(ctx context.Context, messageID string) (*db.Message, error) {
+	mId, err := fromStringToUUID(messageID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	message, err := r.DBQueries.GetMessageByID(ctx, mId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &message, nil
+}
+
+// ForwardMessage copies the content and media of the source message into every conversation as a
+// new forwarded message, and updates the last message time of the conversations. It's done in a
+// transaction, either every conversation gets the message or none of them
+func (r *MessagePostgresRepository) ForwardMessage(ctx context.Context, sourceMessageID string, conversationIDs []string, senderID string) ([]*db.Message, error) {
+	mId, err := fromStringToUUID(sourceMessageID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	sId, err := fromStringToUUID(senderID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	cIds := make([]pgtype.UUID, 0, len(conversationIDs))
+	for _, conversationID := range conversationIDs {
+		cId, err := fromStringToUUID(conversationID)
+		if err != nil {
+			return nil, customerrors.ErrInvalidUUIDValue
+		}
+		cIds = append(cIds, cId)
+	}
+
+	forwarded := make([]*db.Message, 0, len(cIds))
+
+	err = pgx.BeginFunc(ctx, r.DBPool, func(tx pgx.Tx) error {
+		queries := r.DBQueries.WithTx(tx)
+
+		for _, cId := range cIds {
+			message, err := queries.ForwardMessage(ctx, db.ForwardMessageParams{
+				ConversationID:  cId,
+				SenderID:        sId,
+				Status:          MESSAGE_STATUS_SENT,
+				SourceMessageID: mId,
+			})
+			if err != nil {
+				return err
+			}
+
+			err = queries.UpdateConversationLastMessageAt(ctx, db.UpdateConversationLastMessageAtParams{
+				ID:            cId,
+				LastMessageAt: message.CreatedAt,
+			})
+			if err != nil {
+				return err
+			}
+
+			forwarded = append(forwarded, &message)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return forwarded, nil
+}
+
+func (r *MessagePostgresRepository) GetMessageReplies(ctx context.Context, messageID string, limit int32, offset int32) (*[]db.GetMessageRepliesRow, error) {
+	mId, err := fromStringToUUID(messageID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	replies, err := r.DBQueries.GetMessageReplies(ctx, db.GetMessageRepliesParams{
+		ReplyToMessageID: mId,
+		Limit:            limit,
+		Offset:           offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &replies, nil
+}
+
+func (r *MessagePostgresRepository) CountMessageReplies(ctx context.Context, messageIDs []string) (*[]db.CountMessageRepliesRow, error) {
+	ids := make([]pgtype.UUID, 0, len(messageIDs))
+	for _, rawId := range messageIDs {
+		mId, err := fromStringToUUID(rawId)
+		if err != nil {
+			return nil, customerrors.ErrInvalidUUIDValue
+		}
+		ids = append(ids, mId)
+	}
+
+	counts, err := r.DBQueries.CountMessageReplies(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
+func (r *MessagePostgresRepository) GetMessageThread(ctx context.Context, messageID string, maxDepth int32, limit int32, offset int32) (*[]db.GetMessageThreadRow, error) {
+	mId, err := fromStringToUUID(messageID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	thread, err := r.DBQueries.GetMessageThread(ctx, db.GetMessageThreadParams{
+		MessageID:   mId,
+		MaxDepth:    maxDepth,
+		LimitCount:  limit,
+		OffsetCount: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &thread, nil
+}
+
+// DeleteExpiredMessages removes up to limit messages whose expires_at already passed
+func (r *MessagePostgresRepository) DeleteExpiredMessages(ctx context.Context, limit int32) (*[]db.DeleteExpiredMessagesRow, error) {
+	deleted, err := r.DBQueries.DeleteExpiredMessages(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deleted, nil
+}
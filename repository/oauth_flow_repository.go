@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type OAuthFlowRepository interface {
+	CreateOAuthState(ctx context.Context, state db.OauthState) error
+	ConsumeOAuthState(ctx context.Context, stateHash string, browserStateHash string) (*db.OauthState, error)
+	CreateAuthCode(ctx context.Context, codeHash string, userID string, codeChallenge string, expiresAt time.Time) error
+	ConsumeAuthCode(ctx context.Context, codeHash string) (*db.ConsumeAuthCodeRow, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+type OAuthFlowPostgresRepository struct {
+	DBQueries *db.Queries
+}
+
+func NewOAuthFlowRepository(dbQueries *db.Queries) *OAuthFlowPostgresRepository {
+	return &OAuthFlowPostgresRepository{
+		DBQueries: dbQueries,
+	}
+}
+
+func (r *OAuthFlowPostgresRepository) CreateOAuthState(ctx context.Context, state db.OauthState) error {
+	return r.DBQueries.CreateOAuthState(ctx, db.CreateOAuthStateParams{
+		ID:               state.ID,
+		Provider:         state.Provider,
+		ClientType:       state.ClientType,
+		CodeVerifier:     state.CodeVerifier,
+		AppCodeChallenge: state.AppCodeChallenge,
+		AppState:         state.AppState,
+		ExpiresAt:        state.ExpiresAt,
+	})
+}
+
+// ConsumeOAuthState deletes the state and returns it, customerrors.ErrResourceNotFound is
+// returned when it doesn't exist, it was already used, it's expired or it's the state of a web
+// login and the browser sent another one
+func (r *OAuthFlowPostgresRepository) ConsumeOAuthState(ctx context.Context, stateHash string, browserStateHash string) (*db.OauthState, error) {
+	state, err := r.DBQueries.ConsumeOAuthState(ctx, db.ConsumeOAuthStateParams{
+		ID:             stateHash,
+		BrowserStateID: browserStateHash,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+func (r *OAuthFlowPostgresRepository) CreateAuthCode(ctx context.Context, codeHash string, userID string, codeChallenge string, expiresAt time.Time) error {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	return r.DBQueries.CreateAuthCode(ctx, db.CreateAuthCodeParams{
+		ID:            codeHash,
+		UserID:        uId,
+		CodeChallenge: codeChallenge,
+		ExpiresAt:     pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+}
+
+// ConsumeAuthCode deletes the code and returns its user, customerrors.ErrResourceNotFound is
+// returned when it doesn't exist, it was already used or it's expired
+func (r *OAuthFlowPostgresRepository) ConsumeAuthCode(ctx context.Context, codeHash string) (*db.ConsumeAuthCodeRow, error) {
+	code, err := r.DBQueries.ConsumeAuthCode(ctx, codeHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+func (r *OAuthFlowPostgresRepository) DeleteExpired(ctx context.Context) error {
+	return r.DBQueries.DeleteExpiredOAuthFlows(ctx)
+}
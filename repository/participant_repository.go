@@ -2,23 +2,39 @@ package repository
 
 import (
 	"context"
+	"errors"
 	db "golang-whatsapp-clone/database/gen"
 	customerrors "golang-whatsapp-clone/errors"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
 )
 
 type ParticipantRepository interface {
 	CreateParticipants(ctx context.Context, conversationID string, userIDs []string) error
+	GetParticipant(ctx context.Context, userID string, conversationID string) (*db.ConversationParticipant, error)
+	GetParticipantUsers(ctx context.Context, conversationID string) (*[]db.User, error)
+	GetActiveParticipants(ctx context.Context, conversationIDs []string) (*[]db.ConversationParticipant, error)
+	GetConversationParticipants(ctx context.Context, conversationID string, limit int32, offset int32) (*[]db.GetConversationParticipantsRow, error)
+	SetArchived(ctx context.Context, userID string, conversationID string, archived bool) (*db.ConversationParticipant, error)
+	SetMutedUntil(ctx context.Context, userID string, conversationID string, mutedUntil pgtype.Timestamptz) (*db.ConversationParticipant, error)
+	Pin(ctx context.Context, userID string, conversationID string, limit int32) (*db.ConversationParticipant, error)
+	Unpin(ctx context.Context, userID string, conversationID string) (*db.ConversationParticipant, error)
 }
 
+// ParticipantPostgresRepository needs the pool besides the queries, the limit of pinned
+// conversations is checked in the same transaction as the pin
 type ParticipantPostgresRepository struct {
+	dbPool    *pgxpool.Pool
 	dbQueries *db.Queries
 	logger    *zerolog.Logger
 }
 
-func NewParticipantRepository(dbQueries *db.Queries, logger *zerolog.Logger) *ParticipantPostgresRepository {
+func NewParticipantRepository(dbPool *pgxpool.Pool, dbQueries *db.Queries, logger *zerolog.Logger) *ParticipantPostgresRepository {
 	return &ParticipantPostgresRepository{
+		dbPool:    dbPool,
 		dbQueries: dbQueries,
 		logger:    logger,
 	}
@@ -42,7 +58,7 @@ func (r *ParticipantPostgresRepository) CreateParticipants(ctx context.Context,
 			ConversationID: cId,
 			UserID:         uId,
 			// TODO: add support for admin, owner, member
-			Role: "member",
+			Role: PARTICIPANT_ROLE_MEMBER,
 		})
 	}
 
@@ -55,3 +71,211 @@ func (r *ParticipantPostgresRepository) CreateParticipants(ctx context.Context,
 
 	return nil
 }
+
+// GetParticipant returns the active participant row of the user in the conversation
+func (r *ParticipantPostgresRepository) GetParticipant(ctx context.Context, userID string, conversationID string) (*db.ConversationParticipant, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	cId, err := fromStringToUUID(conversationID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	participant, err := r.dbQueries.GetParticipantByUserAndConversation(ctx, db.GetParticipantByUserAndConversationParams{
+		UserID:         uId,
+		ConversationID: cId,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		r.logger.Error().Msgf("Repo:GetParticipant: error to get the participant, %v", err)
+		return nil, err
+	}
+
+	return &participant, nil
+}
+
+// GetParticipantUsers returns the users that are active participants of the conversation
+func (r *ParticipantPostgresRepository) GetParticipantUsers(ctx context.Context, conversationID string) (*[]db.User, error) {
+	cId, err := fromStringToUUID(conversationID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	rows, err := r.dbQueries.GetConversationParticipantUsers(ctx, cId)
+	if err != nil {
+		r.logger.Error().Msgf("Repo:GetParticipantUsers: error to get the participants, %v", err)
+		return nil, err
+	}
+
+	users := make([]db.User, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, row.User)
+	}
+
+	return &users, nil
+}
+
+// GetActiveParticipants returns the active participants of all the conversations in one query
+func (r *ParticipantPostgresRepository) GetActiveParticipants(ctx context.Context, conversationIDs []string) (*[]db.ConversationParticipant, error) {
+	ids, err := fromStringsToUUIDs(conversationIDs)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	participants, err := r.dbQueries.GetActiveParticipantsByConversationIDs(ctx, ids)
+	if err != nil {
+		r.logger.Error().Msgf("Repo:GetActiveParticipants: error to get the participants, %v", err)
+		return nil, err
+	}
+
+	return &participants, nil
+}
+
+func (r *ParticipantPostgresRepository) GetConversationParticipants(ctx context.Context, conversationID string, limit int32, offset int32) (*[]db.GetConversationParticipantsRow, error) {
+	cId, err := fromStringToUUID(conversationID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	participants, err := r.dbQueries.GetConversationParticipants(ctx, db.GetConversationParticipantsParams{
+		ConversationID: cId,
+		LimitCount:     limit,
+		OffsetCount:    offset,
+	})
+	if err != nil {
+		r.logger.Error().Msgf("Repo:GetConversationParticipants: error to get the participants, %v", err)
+		return nil, err
+	}
+
+	return &participants, nil
+}
+
+func (r *ParticipantPostgresRepository) SetArchived(ctx context.Context, userID string, conversationID string, archived bool) (*db.ConversationParticipant, error) {
+	uId, cId, err := participantKey(userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := r.dbQueries.UpdateParticipantArchived(ctx, db.UpdateParticipantArchivedParams{
+		IsArchived:     archived,
+		UserID:         uId,
+		ConversationID: cId,
+	})
+
+	return participantOrNotFound(participant, err)
+}
+
+// SetMutedUntil mutes the conversation for the user, a NULL mutedUntil unmutes it
+func (r *ParticipantPostgresRepository) SetMutedUntil(ctx context.Context, userID string, conversationID string, mutedUntil pgtype.Timestamptz) (*db.ConversationParticipant, error) {
+	uId, cId, err := participantKey(userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := r.dbQueries.UpdateParticipantMutedUntil(ctx, db.UpdateParticipantMutedUntilParams{
+		UserID:         uId,
+		ConversationID: cId,
+		MutedUntil:     mutedUntil,
+	})
+
+	return participantOrNotFound(participant, err)
+}
+
+// Pin puts the conversation on top of the pinned ones of the user, unless the user already has
+// limit pinned conversations, then customerrors.ErrConversationPinLimitReached is returned.
+// Pinning an already pinned conversation does nothing. The user is locked while the pins are
+// counted, so concurrent pins can't exceed the limit or get the same order
+func (r *ParticipantPostgresRepository) Pin(ctx context.Context, userID string, conversationID string, limit int32) (*db.ConversationParticipant, error) {
+	uId, cId, err := participantKey(userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var participant db.ConversationParticipant
+
+	err = pgx.BeginFunc(ctx, r.dbPool, func(tx pgx.Tx) error {
+		queries := r.dbQueries.WithTx(tx)
+
+		err := queries.LockUser(ctx, uId)
+		if err != nil {
+			return err
+		}
+
+		participant, err = queries.GetParticipantByUserAndConversation(ctx, db.GetParticipantByUserAndConversationParams{
+			UserID:         uId,
+			ConversationID: cId,
+		})
+		if err != nil {
+			return err
+		}
+
+		if participant.PinOrder.Valid {
+			return nil
+		}
+
+		pinned, err := queries.CountPinnedConversations(ctx, uId)
+		if err != nil {
+			return err
+		}
+
+		if pinned >= limit {
+			return customerrors.ErrConversationPinLimitReached
+		}
+
+		participant, err = queries.PinParticipantConversation(ctx, db.PinParticipantConversationParams{
+			UserID:         uId,
+			ConversationID: cId,
+		})
+
+		return err
+	})
+	if errors.Is(err, customerrors.ErrConversationPinLimitReached) {
+		return nil, err
+	}
+
+	return participantOrNotFound(participant, err)
+}
+
+func (r *ParticipantPostgresRepository) Unpin(ctx context.Context, userID string, conversationID string) (*db.ConversationParticipant, error) {
+	uId, cId, err := participantKey(userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := r.dbQueries.UnpinParticipantConversation(ctx, db.UnpinParticipantConversationParams{
+		UserID:         uId,
+		ConversationID: cId,
+	})
+
+	return participantOrNotFound(participant, err)
+}
+
+func participantKey(userID string, conversationID string) (pgtype.UUID, pgtype.UUID, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, customerrors.ErrInvalidUUIDValue
+	}
+
+	cId, err := fromStringToUUID(conversationID)
+	if err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, customerrors.ErrInvalidUUIDValue
+	}
+
+	return uId, cId, nil
+}
+
+func participantOrNotFound(participant db.ConversationParticipant, err error) (*db.ConversationParticipant, error) {
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &participant, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PinnedMessageRepository interface {
+	PinMessage(ctx context.Context, conversationID string, messageID string, pinnedBy string, limit int32) error
+	UnpinMessage(ctx context.Context, conversationID string, messageID string) (bool, error)
+	GetPinnedMessages(ctx context.Context, conversationID string, limit int32, offset int32) (*[]db.GetPinnedMessagesRow, error)
+}
+
+// PinnedMessagePostgresRepository needs the pool besides the queries, the limit of pins is checked
+// in the same transaction as the pin
+type PinnedMessagePostgresRepository struct {
+	DBPool    *pgxpool.Pool
+	DBQueries *db.Queries
+}
+
+func NewPinnedMessageRepository(dbPool *pgxpool.Pool, dbQueries *db.Queries) *PinnedMessagePostgresRepository {
+	return &PinnedMessagePostgresRepository{
+		DBPool:    dbPool,
+		DBQueries: dbQueries,
+	}
+}
+
+// PinMessage pins the message unless the conversation already has limit pins, then
+// customerrors.ErrPinLimitReached is returned. Pinning an already pinned message does nothing.
+// The conversation is locked while the pins are counted, so concurrent pins can't exceed the limit
+func (r *PinnedMessagePostgresRepository) PinMessage(ctx context.Context, conversationID string, messageID string, pinnedBy string, limit int32) error {
+	cId, err := fromStringToUUID(conversationID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	mId, err := fromStringToUUID(messageID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	uId, err := fromStringToUUID(pinnedBy)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	return pgx.BeginFunc(ctx, r.DBPool, func(tx pgx.Tx) error {
+		queries := r.DBQueries.WithTx(tx)
+
+		err := queries.LockConversation(ctx, cId)
+		if err != nil {
+			return err
+		}
+
+		alreadyPinned, err := queries.IsMessagePinned(ctx, db.IsMessagePinnedParams{
+			ConversationID: cId,
+			MessageID:      mId,
+		})
+		if err != nil {
+			return err
+		}
+
+		if alreadyPinned {
+			return nil
+		}
+
+		count, err := queries.CountPinnedMessages(ctx, cId)
+		if err != nil {
+			return err
+		}
+
+		if count >= limit {
+			return customerrors.ErrPinLimitReached
+		}
+
+		return queries.PinMessage(ctx, db.PinMessageParams{
+			ConversationID: cId,
+			MessageID:      mId,
+			PinnedBy:       uId,
+		})
+	})
+}
+
+// UnpinMessage returns false when the message was not pinned
+func (r *PinnedMessagePostgresRepository) UnpinMessage(ctx context.Context, conversationID string, messageID string) (bool, error) {
+	cId, err := fromStringToUUID(conversationID)
+	if err != nil {
+		return false, customerrors.ErrInvalidUUIDValue
+	}
+
+	mId, err := fromStringToUUID(messageID)
+	if err != nil {
+		return false, customerrors.ErrInvalidUUIDValue
+	}
+
+	deleted, err := r.DBQueries.UnpinMessage(ctx, db.UnpinMessageParams{
+		ConversationID: cId,
+		MessageID:      mId,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return deleted > 0, nil
+}
+
+func (r *PinnedMessagePostgresRepository) GetPinnedMessages(ctx context.Context, conversationID string, limit int32, offset int32) (*[]db.GetPinnedMessagesRow, error) {
+	cId, err := fromStringToUUID(conversationID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	messages, err := r.DBQueries.GetPinnedMessages(ctx, db.GetPinnedMessagesParams{
+		ConversationID: cId,
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &messages, nil
+}
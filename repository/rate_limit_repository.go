@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type RateLimitRepository interface {
+	TakeToken(ctx context.Context, key string, rate float64, burst int) (tokens float64, allowed bool, err error)
+	DeleteIdleBuckets(ctx context.Context, idleSince time.Time) error
+}
+
+type RateLimitPostgresRepository struct {
+	DBQueries *db.Queries
+}
+
+func NewRateLimitRepository(dbQueries *db.Queries) *RateLimitPostgresRepository {
+	return &RateLimitPostgresRepository{
+		DBQueries: dbQueries,
+	}
+}
+
+// TakeToken takes a token of the bucket of the key, the tokens are the ones left in the bucket
+func (r *RateLimitPostgresRepository) TakeToken(ctx context.Context, key string, rate float64, burst int) (float64, bool, error) {
+	bucket, err := r.DBQueries.TakeRateLimitToken(ctx, db.TakeRateLimitTokenParams{
+		Key:   key,
+		Burst: float64(burst),
+		Rate:  rate,
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	return bucket.Tokens, bucket.Allowed, nil
+}
+
+func (r *RateLimitPostgresRepository) DeleteIdleBuckets(ctx context.Context, idleSince time.Time) error {
+	return r.DBQueries.DeleteIdleRateLimitBuckets(ctx, pgtype.Timestamptz{Time: idleSince, Valid: true})
+}
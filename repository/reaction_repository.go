@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ReactionRepository interface {
+	UpsertReaction(ctx context.Context, messageID string, userID string, emoji string) (*db.MessageReaction, error)
+	DeleteReaction(ctx context.Context, messageID string, userID string) (bool, error)
+	GetReactionSummaries(ctx context.Context, messageIDs []string, viewerID string) (*[]db.GetMessageReactionSummariesRow, error)
+}
+
+type ReactionPostgresRepository struct {
+	DBQueries *db.Queries
+}
+
+func NewReactionRepository(dbQueries *db.Queries) *ReactionPostgresRepository {
+	return &ReactionPostgresRepository{
+		DBQueries: dbQueries,
+	}
+}
+
+func (r *ReactionPostgresRepository) UpsertReaction(ctx context.Context, messageID string, userID string, emoji string) (*db.MessageReaction, error) {
+	mId, err := fromStringToUUID(messageID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	reaction, err := r.DBQueries.UpsertMessageReaction(ctx, db.UpsertMessageReactionParams{
+		MessageID: mId,
+		UserID:    uId,
+		Emoji:     emoji,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &reaction, nil
+}
+
+// DeleteReaction returns false when the user had no reaction on the message
+func (r *ReactionPostgresRepository) DeleteReaction(ctx context.Context, messageID string, userID string) (bool, error) {
+	mId, err := fromStringToUUID(messageID)
+	if err != nil {
+		return false, customerrors.ErrInvalidUUIDValue
+	}
+
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return false, customerrors.ErrInvalidUUIDValue
+	}
+
+	deleted, err := r.DBQueries.DeleteMessageReaction(ctx, db.DeleteMessageReactionParams{
+		MessageID: mId,
+		UserID:    uId,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return deleted > 0, nil
+}
+
+func (r *ReactionPostgresRepository) GetReactionSummaries(ctx context.Context, messageIDs []string, viewerID string) (*[]db.GetMessageReactionSummariesRow, error) {
+	vId, err := fromStringToUUID(viewerID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	ids := make([]pgtype.UUID, 0, len(messageIDs))
+	for _, rawId := range messageIDs {
+		mId, err := fromStringToUUID(rawId)
+		if err != nil {
+			return nil, customerrors.ErrInvalidUUIDValue
+		}
+		ids = append(ids, mId)
+	}
+
+	summaries, err := r.DBQueries.GetMessageReactionSummaries(ctx, db.GetMessageReactionSummariesParams{
+		ViewerID:   vId,
+		MessageIds: ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &summaries, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, tokenID string, userID string, familyID string, expiresAt time.Time) (*db.RefreshToken, error)
+	UseRefreshToken(ctx context.Context, tokenID string) (*db.RefreshToken, error)
+	GetRefreshToken(ctx context.Context, tokenID string) (*db.RefreshToken, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+}
+
+type RefreshTokenPostgresRepository struct {
+	DBQueries *db.Queries
+}
+
+func NewRefreshTokenRepository(dbQueries *db.Queries) *RefreshTokenPostgresRepository {
+	return &RefreshTokenPostgresRepository{
+		DBQueries: dbQueries,
+	}
+}
+
+func (r *RefreshTokenPostgresRepository) CreateRefreshToken(ctx context.Context, tokenID string, userID string, familyID string, expiresAt time.Time) (*db.RefreshToken, error) {
+	tId, err := fromStringToUUID(tokenID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	fId, err := fromStringToUUID(familyID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	token, err := r.DBQueries.CreateRefreshToken(ctx, db.CreateRefreshTokenParams{
+		ID:        tId,
+		UserID:    uId,
+		FamilyID:  fId,
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// UseRefreshToken marks the token as used, customerrors.ErrResourceNotFound is returned when the
+// token doesn't exist or it was already used, revoked or it's expired
+func (r *RefreshTokenPostgresRepository) UseRefreshToken(ctx context.Context, tokenID string) (*db.RefreshToken, error) {
+	tId, err := fromStringToUUID(tokenID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	token, err := r.DBQueries.UseRefreshToken(ctx, tId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *RefreshTokenPostgresRepository) GetRefreshToken(ctx context.Context, tokenID string) (*db.RefreshToken, error) {
+	tId, err := fromStringToUUID(tokenID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	token, err := r.DBQueries.GetRefreshToken(ctx, tId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *RefreshTokenPostgresRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	fId, err := fromStringToUUID(familyID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	return r.DBQueries.RevokeRefreshTokenFamily(ctx, fId)
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ScheduledMessageRepository interface {
+	CreateScheduledMessage(ctx context.Context, conversationID string, senderID string, content string, messageType string, replyToMessageID *string, sendAt time.Time) (*db.ScheduledMessage, error)
+	GetScheduledMessageByID(ctx context.Context, scheduledMessageID string) (*db.ScheduledMessage, error)
+	GetPendingScheduledMessages(ctx context.Context, senderID string, conversationID *string, limit int32, offset int32) (*[]db.ScheduledMessage, error)
+	CancelScheduledMessage(ctx context.Context, scheduledMessageID string, senderID string) (bool, error)
+	ClaimDueScheduledMessages(ctx context.Context, limit int32) (*[]db.ScheduledMessage, error)
+	MarkScheduledMessageSent(ctx context.Context, scheduledMessageID string, messageID string) error
+	MarkScheduledMessageFailed(ctx context.Context, scheduledMessageID string) error
+	FailStaleScheduledMessages(ctx context.Context, claimedBefore time.Time) (int64, error)
+}
+
+type ScheduledMessagePostgresRepository struct {
+	DBQueries *db.Queries
+}
+
+func NewScheduledMessageRepository(dbQueries *db.Queries) *ScheduledMessagePostgresRepository {
+	return &ScheduledMessagePostgresRepository{
+		DBQueries: dbQueries,
+	}
+}
+
+func (r *ScheduledMessagePostgresRepository) CreateScheduledMessage(ctx context.Context, conversationID string, senderID string, content string, messageType string, replyToMessageID *string, sendAt time.Time) (*db.ScheduledMessage, error) {
+	cId, err := fromStringToUUID(conversationID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	sId, err := fromStringToUUID(senderID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	var rId pgtype.UUID
+	if replyToMessageID != nil {
+		rId, err = fromStringToUUID(*replyToMessageID)
+		if err != nil {
+			return nil, customerrors.ErrInvalidUUIDValue
+		}
+	}
+
+	scheduled, err := r.DBQueries.CreateScheduledMessage(ctx, db.CreateScheduledMessageParams{
+		ConversationID:   cId,
+		SenderID:         sId,
+		Content:          content,
+		MessageType:      messageType,
+		ReplyToMessageID: rId,
+		SendAt:           pgtype.Timestamptz{Time: sendAt, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &scheduled, nil
+}
+
+func (r *ScheduledMessagePostgresRepository) GetScheduledMessageByID(ctx context.Context, scheduledMessageID string) (*db.ScheduledMessage, error) {
+	id, err := fromStringToUUID(scheduledMessageID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	scheduled, err := r.DBQueries.GetScheduledMessageByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &scheduled, nil
+}
+
+func (r *ScheduledMessagePostgresRepository) GetPendingScheduledMessages(ctx context.Context, senderID string, conversationID *string, limit int32, offset int32) (*[]db.ScheduledMessage, error) {
+	sId, err := fromStringToUUID(senderID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	var cId pgtype.UUID
+	if conversationID != nil {
+		cId, err = fromStringToUUID(*conversationID)
+		if err != nil {
+			return nil, customerrors.ErrInvalidUUIDValue
+		}
+	}
+
+	scheduled, err := r.DBQueries.GetPendingScheduledMessages(ctx, db.GetPendingScheduledMessagesParams{
+		SenderID:       sId,
+		ConversationID: cId,
+		LimitCount:     limit,
+		OffsetCount:    offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &scheduled, nil
+}
+
+// CancelScheduledMessage returns false when the message doesn't belong to the sender or it's not pending anymore
+func (r *ScheduledMessagePostgresRepository) CancelScheduledMessage(ctx context.Context, scheduledMessageID string, senderID string) (bool, error) {
+	id, err := fromStringToUUID(scheduledMessageID)
+	if err != nil {
+		return false, customerrors.ErrInvalidUUIDValue
+	}
+
+	sId, err := fromStringToUUID(senderID)
+	if err != nil {
+		return false, customerrors.ErrInvalidUUIDValue
+	}
+
+	rows, err := r.DBQueries.CancelScheduledMessage(ctx, db.CancelScheduledMessageParams{
+		ID:       id,
+		SenderID: sId,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// ClaimDueScheduledMessages marks up to limit due messages as SENDING and returns them,
+// the same message is never returned to two callers
+func (r *ScheduledMessagePostgresRepository) ClaimDueScheduledMessages(ctx context.Context, limit int32) (*[]db.ScheduledMessage, error) {
+	claimed, err := r.DBQueries.ClaimDueScheduledMessages(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &claimed, nil
+}
+
+func (r *ScheduledMessagePostgresRepository) MarkScheduledMessageSent(ctx context.Context, scheduledMessageID string, messageID string) error {
+	id, err := fromStringToUUID(scheduledMessageID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	mId, err := fromStringToUUID(messageID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	return r.DBQueries.MarkScheduledMessageSent(ctx, db.MarkScheduledMessageSentParams{
+		ID:        id,
+		MessageID: mId,
+	})
+}
+
+func (r *ScheduledMessagePostgresRepository) MarkScheduledMessageFailed(ctx context.Context, scheduledMessageID string) error {
+	id, err := fromStringToUUID(scheduledMessageID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	return r.DBQueries.MarkScheduledMessageFailed(ctx, id)
+}
+
+func (r *ScheduledMessagePostgresRepository) FailStaleScheduledMessages(ctx context.Context, claimedBefore time.Time) (int64, error) {
+	return r.DBQueries.FailStaleScheduledMessages(ctx, pgtype.Timestamptz{Time: claimedBefore, Valid: true})
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	CLIENT_TYPE_WEB    = "web"
+	CLIENT_TYPE_MOBILE = "mobile"
+)
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, userID string, clientType string, userAgent string, ipAddress string) (*db.Session, error)
+	GetSession(ctx context.Context, sessionID string) (*db.Session, error)
+	GetUserActiveSessions(ctx context.Context, userID string) (*[]db.Session, error)
+	TouchSession(ctx context.Context, sessionID string) error
+	RevokeUserSession(ctx context.Context, userID string, sessionID string) error
+	RevokeOtherUserSessions(ctx context.Context, userID string, currentSessionID string) ([]string, error)
+}
+
+type SessionPostgresRepository struct {
+	DBQueries *db.Queries
+}
+
+func NewSessionRepository(dbQueries *db.Queries) *SessionPostgresRepository {
+	return &SessionPostgresRepository{
+		DBQueries: dbQueries,
+	}
+}
+
+func (r *SessionPostgresRepository) CreateSession(ctx context.Context, userID string, clientType string, userAgent string, ipAddress string) (*db.Session, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	session, err := r.DBQueries.CreateSession(ctx, db.CreateSessionParams{
+		UserID:     uId,
+		ClientType: clientType,
+		UserAgent:  userAgent,
+		IpAddress:  ipAddress,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *SessionPostgresRepository) GetSession(ctx context.Context, sessionID string) (*db.Session, error) {
+	sId, err := fromStringToUUID(sessionID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	session, err := r.DBQueries.GetSession(ctx, sId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *SessionPostgresRepository) GetUserActiveSessions(ctx context.Context, userID string) (*[]db.Session, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	sessions, err := r.DBQueries.GetUserActiveSessions(ctx, uId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sessions, nil
+}
+
+func (r *SessionPostgresRepository) TouchSession(ctx context.Context, sessionID string) error {
+	sId, err := fromStringToUUID(sessionID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	return r.DBQueries.TouchSession(ctx, sId)
+}
+
+// RevokeUserSession revokes the session and its refresh tokens, customerrors.ErrResourceNotFound
+// is returned when the session doesn't belong to the user or it was already revoked
+func (r *SessionPostgresRepository) RevokeUserSession(ctx context.Context, userID string, sessionID string) error {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	sId, err := fromStringToUUID(sessionID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	rows, err := r.DBQueries.RevokeUserSession(ctx, db.RevokeUserSessionParams{
+		SessionID: sId,
+		UserID:    uId,
+	})
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return customerrors.ErrResourceNotFound
+	}
+
+	return nil
+}
+
+// RevokeOtherUserSessions revokes every active session of the user except the current one and
+// returns the ids of the revoked sessions
+func (r *SessionPostgresRepository) RevokeOtherUserSessions(ctx context.Context, userID string, currentSessionID string) ([]string, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	sId, err := fromStringToUUID(currentSessionID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	ids, err := r.DBQueries.RevokeOtherUserSessions(ctx, db.RevokeOtherUserSessionsParams{
+		UserID:           uId,
+		CurrentSessionID: sId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sessionIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		sessionIDs = append(sessionIDs, id.String())
+	}
+
+	return sessionIDs, nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+)
+
+type StarredMessageRepository interface {
+	StarMessage(ctx context.Context, userID string, messageID string) error
+	UnstarMessage(ctx context.Context, userID string, messageID string) (bool, error)
+	GetStarredMessages(ctx context.Context, userID string, limit int32, offset int32) (*[]db.GetStarredMessagesRow, error)
+}
+
+type StarredMessagePostgresRepository struct {
+	DBQueries *db.Queries
+}
+
+func NewStarredMessageRepository(dbQueries *db.Queries) *StarredMessagePostgresRepository {
+	return &StarredMessagePostgresRepository{
+		DBQueries: dbQueries,
+	}
+}
+
+func (r *StarredMessagePostgresRepository) StarMessage(ctx context.Context, userID string, messageID string) error {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	mId, err := fromStringToUUID(messageID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	return r.DBQueries.StarMessage(ctx, db.StarMessageParams{
+		UserID:    uId,
+		MessageID: mId,
+	})
+}
+
+// UnstarMessage returns false when the message was not starred by the user
+func (r *StarredMessagePostgresRepository) UnstarMessage(ctx context.Context, userID string, messageID string) (bool, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return false, customerrors.ErrInvalidUUIDValue
+	}
+
+	mId, err := fromStringToUUID(messageID)
+	if err != nil {
+		return false, customerrors.ErrInvalidUUIDValue
+	}
+
+	deleted, err := r.DBQueries.UnstarMessage(ctx, db.UnstarMessageParams{
+		UserID:    uId,
+		MessageID: mId,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return deleted > 0, nil
+}
+
+func (r *StarredMessagePostgresRepository) GetStarredMessages(ctx context.Context, userID string, limit int32, offset int32) (*[]db.GetStarredMessagesRow, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	messages, err := r.DBQueries.GetStarredMessages(ctx, db.GetStarredMessagesParams{
+		UserID: uId,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &messages, nil
+}
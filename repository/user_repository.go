@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ProfileUpdate has the fields edited by the user, the nil ones are not changed and the empty
+// ones are removed
+type ProfileUpdate struct {
+	Name      *string
+	About     *string
+	AvatarURL *string
+}
+
+type UserRepository interface {
+	GetUsersByIDs(ctx context.Context, userIDs []string) (*[]db.User, error)
+	UpdateLastSeen(ctx context.Context, userID string, lastSeenAt time.Time) error
+	UpdateLastSeenPrivacy(ctx context.Context, userID string, privacy string) (*db.User, error)
+	GetContactIDs(ctx context.Context, userID string) ([]string, error)
+	UpdateProfile(ctx context.Context, userID string, update ProfileUpdate) (*db.User, error)
+}
+
+type UserPostgresRepository struct {
+	DBQueries *db.Queries
+}
+
+func NewUserRepository(dbQueries *db.Queries) *UserPostgresRepository {
+	return &UserPostgresRepository{
+		DBQueries: dbQueries,
+	}
+}
+
+func (r *UserPostgresRepository) GetUsersByIDs(ctx context.Context, userIDs []string) (*[]db.User, error) {
+	ids, err := fromStringsToUUIDs(userIDs)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	users, err := r.DBQueries.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return &users, nil
+}
+
+func (r *UserPostgresRepository) UpdateLastSeen(ctx context.Context, userID string, lastSeenAt time.Time) error {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return customerrors.ErrInvalidUUIDValue
+	}
+
+	return r.DBQueries.UpdateUserLastSeen(ctx, db.UpdateUserLastSeenParams{
+		ID:         uId,
+		LastSeenAt: pgtype.Timestamptz{Time: lastSeenAt, Valid: true},
+	})
+}
+
+func (r *UserPostgresRepository) UpdateLastSeenPrivacy(ctx context.Context, userID string, privacy string) (*db.User, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	user, err := r.DBQueries.UpdateUserLastSeenPrivacy(ctx, db.UpdateUserLastSeenPrivacyParams{
+		ID:              uId,
+		LastSeenPrivacy: privacy,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *UserPostgresRepository) UpdateProfile(ctx context.Context, userID string, update ProfileUpdate) (*db.User, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	params := db.UpdateUserProfileParams{ID: uId}
+	if update.Name != nil {
+		params.SetName = true
+		params.Name = fromStringToText(*update.Name)
+	}
+	if update.About != nil {
+		params.SetAbout = true
+		params.About = fromStringToText(*update.About)
+	}
+	if update.AvatarURL != nil {
+		params.SetAvatarUrl = true
+		params.AvatarUrl = fromStringToText(*update.AvatarURL)
+	}
+
+	user, err := r.DBQueries.UpdateUserProfile(ctx, params)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customerrors.ErrResourceNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// GetContactIDs returns the ids of the users that have an active direct conversation with the user
+func (r *UserPostgresRepository) GetContactIDs(ctx context.Context, userID string) ([]string, error) {
+	uId, err := fromStringToUUID(userID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidUUIDValue
+	}
+
+	rows, err := r.DBQueries.GetUserContactIDs(ctx, uId)
+	if err != nil {
+		return nil, err
+	}
+
+	contactIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		contactIDs = append(contactIDs, row.String())
+	}
+
+	return contactIDs, nil
+}
@@ -18,3 +18,24 @@ func fromStringToUUID(value string) (pgtype.UUID, error) {
 
 	return pgUUID, nil
 }
+
+func fromStringsToUUIDs(values []string) ([]pgtype.UUID, error) {
+	ids := make([]pgtype.UUID, 0, len(values))
+	for _, value := range values {
+		id, err := fromStringToUUID(value)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// fromStringToText maps the empty string to NULL
+func fromStringToText(value string) pgtype.Text {
+	return pgtype.Text{
+		String: value,
+		Valid:  value != "",
+	}
+}
@@ -1,19 +1,25 @@
 package server
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"golang-whatsapp-clone/auth"
 	"golang-whatsapp-clone/config"
 	db "golang-whatsapp-clone/database/gen"
 	"golang-whatsapp-clone/graph"
+	"golang-whatsapp-clone/graph/model"
 	"golang-whatsapp-clone/handler"
+	"golang-whatsapp-clone/linkpreview"
 	"golang-whatsapp-clone/logger"
+	"golang-whatsapp-clone/mailer"
+	"golang-whatsapp-clone/ratelimit"
 	"golang-whatsapp-clone/repository"
 	"golang-whatsapp-clone/service"
 	"golang-whatsapp-clone/subscriptions"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"golang-whatsapp-clone/database"
@@ -27,6 +33,31 @@ type App struct {
 	DBpool    *pgxpool.Pool
 	Logger    *zerolog.Logger
 	Handler   *handler.Handler
+
+	// the loops that run beside the requests, see StartBackgroundJobs
+	backgroundJobs []func(ctx context.Context)
+}
+
+// StartBackgroundJobs runs the reaper of the disappearing messages, the scheduler, the exporter of
+// the accounts and the sweep of the rate limits. The returned function stops them and waits until
+// they return, it must be called before the pool is closed.
+// The serverless entry doesn't start them, its instances only live while they serve a request
+func (app *App) StartBackgroundJobs() func() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	for _, job := range app.backgroundJobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job(ctx)
+		}()
+	}
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
 }
 
 func NewServer() (*App, *http.Server, http.Handler) {
@@ -39,24 +70,94 @@ func NewServer() (*App, *http.Server, http.Handler) {
 
 	// repositories
 	conversationRepository := repository.NewConversationRepository(dbQueries, log)
-	participantRepository := repository.NewParticipantRepository(dbQueries, log)
-	messageRepository := repository.NewMessageRepository(dbQueries)
+	participantRepository := repository.NewParticipantRepository(dbpool, dbQueries, log)
+	messageRepository := repository.NewMessageRepository(dbpool, dbQueries)
+	reactionRepository := repository.NewReactionRepository(dbQueries)
+	pinnedMessageRepository := repository.NewPinnedMessageRepository(dbpool, dbQueries)
+	starredMessageRepository := repository.NewStarredMessageRepository(dbQueries)
+	mentionRepository := repository.NewMentionRepository(dbQueries)
+	linkPreviewRepository := repository.NewLinkPreviewRepository(dbQueries)
+	scheduledMessageRepository := repository.NewScheduledMessageRepository(dbQueries)
+	draftRepository := repository.NewDraftRepository(dbQueries)
+	userRepository := repository.NewUserRepository(dbQueries)
+	refreshTokenRepository := repository.NewRefreshTokenRepository(dbQueries)
+	sessionRepository := repository.NewSessionRepository(dbQueries)
+	identityRepository := repository.NewIdentityRepository(dbQueries)
+	oauthFlowRepository := repository.NewOAuthFlowRepository(dbQueries)
+	emailLoginRepository := repository.NewEmailLoginRepository(dbpool, dbQueries)
+	rateLimitRepository := repository.NewRateLimitRepository(dbQueries)
+	accountRepository := repository.NewAccountRepository(dbpool, dbQueries)
+	accountExportRepository := repository.NewAccountExportRepository(dbQueries)
+
+	appMailer := mailer.NewMailer(appConfig, log)
 
 	// services
-	jwtService := auth.NewJWTService(appConfig.JWTSecret)
-	oauthService := auth.NewOAuthService(appConfig, jwtService)
+	jwtService := auth.NewJWTService(appConfig.JWTSecret, appConfig.JWTRefreshSecret)
+	sessionService := service.NewSessionService(sessionRepository, log)
+	tokenService := auth.NewTokenService(jwtService, refreshTokenRepository, log, sessionService.CloseConnections)
+	identityService := service.NewIdentityService(identityRepository, log)
+	emailLoginService := service.NewEmailLoginService(emailLoginRepository, identityService, appMailer, appConfig, log)
+	oauthService := auth.NewOAuthService(appConfig, jwtService, oauthFlowRepository, log, auth.NewOAuthProviders(appConfig)...)
 	conversationService := service.NewConversationService(conversationRepository, participantRepository)
-	messageService := service.NewMessageService(messageRepository)
+	messageService := service.NewMessageService(messageRepository, participantRepository, mentionRepository)
+	reactionService := service.NewReactionService(reactionRepository, messageRepository, participantRepository)
+	pinService := service.NewPinService(pinnedMessageRepository, messageRepository, conversationRepository, participantRepository)
+	starService := service.NewStarService(starredMessageRepository, messageRepository, participantRepository)
+	scheduledMessageService := service.NewScheduledMessageService(scheduledMessageRepository, messageRepository, participantRepository)
+	draftService := service.NewDraftService(draftRepository, participantRepository)
+	userService := service.NewUserService(userRepository)
+	accountService := service.NewAccountService(accountRepository, accountExportRepository, userService, sessionService, appConfig, log)
+	linkPreviewService := service.NewLinkPreviewService(
+		linkpreview.NewUnfurler(linkpreview.NewSafeHTTPFetcher(5*time.Second), 6*time.Hour),
+		linkPreviewRepository,
+		log,
+	)
 
 	// subscriptions
 	subscriptionManager := subscriptions.NewSubscriptionManager()
 
+	// background jobs
+	messageReaper := service.NewMessageReaper(messageRepository, log, func(conversationID string, messageIDs []string) {
+		subscriptionManager.BroadcastExpiredMessages(conversationID, &model.MessagesExpiredEvent{
+			ConversationID: conversationID,
+			MessageIds:     messageIDs,
+		})
+	})
+
+	accountExporter := service.NewAccountExporter(accountExportRepository, userService, appMailer, appConfig, log)
+
+	backgroundJobs := []func(ctx context.Context){messageReaper.Run, accountExporter.Run}
+
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if appConfig.RateLimitStore == "postgres" {
+		postgresStore := ratelimit.NewPostgresStore(rateLimitRepository, log)
+		backgroundJobs = append(backgroundJobs, postgresStore.Run)
+		rateLimitStore = postgresStore
+	}
+	rateLimiter := ratelimit.NewLimiter(rateLimitStore, ratelimit.DefaultRules(), log)
+
+	presenceService := service.NewPresenceService(userRepository, log, func(contactIDs []string, presence service.Presence) {
+		for _, contactID := range contactIDs {
+			subscriptionManager.BroadcastPresence(contactID, &model.UserPresence{
+				UserID:     presence.UserID,
+				IsOnline:   presence.IsOnline,
+				LastSeenAt: presence.LastSeenAt,
+			})
+		}
+	})
+
 	handlers := handler.NewHandler(
 		log,
 		appConfig,
 		dbQueries,
 		oauthService,
 		jwtService,
+		tokenService,
+		sessionService,
+		identityService,
+		emailLoginService,
+		accountService,
+		rateLimiter,
 	)
 
 	app := &App{
@@ -70,9 +171,16 @@ func NewServer() (*App, *http.Server, http.Handler) {
 
 	mux.HandleFunc("/health", handlers.HealthCheckHandler)
 	mux.HandleFunc("/api/v1/health", handlers.HealthCheckHandler)
-	mux.HandleFunc("/api/v1/auth/google", handlers.GoogleLoginHandler)
-	mux.HandleFunc("/api/v1/auth/google/callback", handlers.GoogleCallbackHandler)
+	// the literal auth routes take precedence over the provider ones
+	mux.HandleFunc("/api/v1/auth/refresh", handlers.RefreshHandler)
 	mux.HandleFunc("/api/v1/auth/logout", handlers.LogoutHandler)
+	mux.HandleFunc("/api/v1/auth/token", handlers.ExchangeCodeHandler)
+	mux.HandleFunc("/api/v1/auth/email", handlers.EmailLoginHandler)
+	mux.HandleFunc("/api/v1/auth/email/verify", handlers.EmailVerifyHandler)
+	mux.HandleFunc("/api/v1/auth/email/callback", handlers.EmailCallbackHandler)
+	mux.HandleFunc("/api/v1/auth/{provider}", handlers.OAuthLoginHandler)
+	mux.HandleFunc("/api/v1/auth/{provider}/callback", handlers.OAuthCallbackHandler)
+	mux.HandleFunc("/api/v1/account/exports/{id}", handlers.AccountExportDownloadHandler)
 	mux.HandleFunc("/chats", func(w http.ResponseWriter, r *http.Request) {
 		user := auth.GetUserFromContext(r.Context())
 		log.Info().Msgf("user is: %+v", user)
@@ -84,21 +192,43 @@ func NewServer() (*App, *http.Server, http.Handler) {
 
 	// ------------------------- GQLGen setup handlers ---------------------- //
 	gqlResolver := &graph.Resolver{
-		DBQueries:           dbQueries,
-		AppConfig:           appConfig,
-		Logger:              log,
-		ConversationService: conversationService,
-		MessageService:      messageService,
-		SubscriptionManager: subscriptionManager,
+		DBQueries:               dbQueries,
+		AppConfig:               appConfig,
+		Logger:                  log,
+		ConversationService:     conversationService,
+		MessageService:          messageService,
+		ReactionService:         reactionService,
+		PinService:              pinService,
+		StarService:             starService,
+		LinkPreviewService:      linkPreviewService,
+		ScheduledMessageService: scheduledMessageService,
+		DraftService:            draftService,
+		UserService:             userService,
+		PresenceService:         presenceService,
+		SessionService:          sessionService,
+		AccountService:          accountService,
+		SubscriptionManager:     subscriptionManager,
 	}
-	graphqlHandler := handler.NewGraphqlHandler(log, gqlResolver)
+
+	// scheduled messages are published exactly like the ones sent from the sendMessage mutation
+	messageScheduler := service.NewMessageScheduler(
+		scheduledMessageRepository,
+		messageService,
+		log,
+		gqlResolver.PublishMessage,
+	)
+	app.backgroundJobs = append(backgroundJobs, messageScheduler.Run)
+
+	graphqlHandler := handler.NewGraphqlHandler(log, gqlResolver, jwtService, rateLimiter)
 	graphqlPlaygroundHandler := handler.NewGraphqlPlaygroundHandler()
 
 	mux.HandleFunc("/graphql", graphqlHandler.ServeHTTP)
 	mux.HandleFunc("/playground", graphqlPlaygroundHandler.ServeHTTP)
 
 	// ----------------------- Middlewares ----------------------- //
-	rootHandler := handlers.AuthenticateUserMiddleware(mux)
+	// the preflight requests are answered before the authentication, the ips are limited before
+	// the tokens are checked and the users after
+	rootHandler := handlers.CORSMiddleware(handlers.IPRateLimitMiddleware(handlers.AuthenticateUserMiddleware(handlers.RateLimitMiddleware(mux))))
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%s", appConfig.Port),
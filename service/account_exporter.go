@@ -0,0 +1,364 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang-whatsapp-clone/config"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/mailer"
+	"golang-whatsapp-clone/repository"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// AccountExportDuration is how long the zip file of an export can be downloaded
+	AccountExportDuration = 7 * 24 * time.Hour
+
+	accountExporterInterval = 10 * time.Second
+	// a claimed export not ready after this time belongs to an instance that stopped
+	accountExportClaimTimeout = 15 * time.Minute
+	// the messages are read and written in pages, an account can have a lot of them
+	accountExportMessagesPage = 500
+	// the zip is built in memory and stored in a BYTEA column, a bigger export fails
+	// instead of exhausting the memory of the server
+	accountExportMaxBytes = 256 * 1024 * 1024
+)
+
+// AccountExporter builds the zip files of the exports requested by the users. Several instances
+// of the server can run it at the same time, every export is claimed by only one of them and its
+// zip is stored in the database, so any instance can serve it.
+// The zip has the profile, the conversations and the messages sent by the user as JSON, the
+// messages of the other participants are their data and are not included
+type AccountExporter struct {
+	accountExportRepository repository.AccountExportRepository
+	userService             *UserService
+	mailer                  mailer.Mailer
+	appConfig               *config.AppConfig
+	logger                  *zerolog.Logger
+	maxArchiveBytes         int
+}
+
+func NewAccountExporter(
+	accountExportRepository repository.AccountExportRepository,
+	userService *UserService,
+	mailer mailer.Mailer,
+	appConfig *config.AppConfig,
+	logger *zerolog.Logger,
+) *AccountExporter {
+	return &AccountExporter{
+		accountExportRepository: accountExportRepository,
+		userService:             userService,
+		mailer:                  mailer,
+		appConfig:               appConfig,
+		logger:                  logger,
+		maxArchiveBytes:         accountExportMaxBytes,
+	}
+}
+
+// Run builds the pending exports every accountExporterInterval until the context is cancelled,
+// the expired ones are removed with their zip files
+func (e *AccountExporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(accountExporterInterval)
+	defer ticker.Stop()
+
+	for {
+		e.failStaleClaims(ctx)
+		e.deleteExpiredExports(ctx)
+		e.buildPendingExports(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *AccountExporter) failStaleClaims(ctx context.Context) {
+	failed, err := e.accountExportRepository.FailStaleAccountExports(ctx, time.Now().Add(-accountExportClaimTimeout))
+	if err != nil {
+		e.logger.Error().Msgf("AccountExporter: error to fail the stale exports: %v", err)
+		return
+	}
+
+	if failed > 0 {
+		e.logger.Warn().Msgf("AccountExporter: %d exports were claimed but never finished, they were marked as failed", failed)
+	}
+}
+
+func (e *AccountExporter) deleteExpiredExports(ctx context.Context) {
+	err := e.accountExportRepository.DeleteExpiredAccountExports(ctx)
+	if err != nil {
+		e.logger.Error().Msgf("AccountExporter: error to delete the expired exports: %v", err)
+	}
+}
+
+func (e *AccountExporter) buildPendingExports(ctx context.Context) {
+	for ctx.Err() == nil {
+		export, err := e.accountExportRepository.ClaimAccountExport(ctx)
+		if err != nil {
+			if !errors.Is(err, customerrors.ErrResourceNotFound) {
+				e.logger.Error().Msgf("AccountExporter: error to claim a pending export: %v", err)
+			}
+			return
+		}
+
+		e.build(ctx, export)
+	}
+}
+
+func (e *AccountExporter) build(ctx context.Context, export *db.AccountExport) {
+	exportID := export.ID.String()
+	userID := export.UserID.String()
+
+	user, err := e.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		e.logger.Error().Msgf("AccountExporter: error to get the user %s of the export %s: %v", userID, exportID, err)
+		e.markFailed(ctx, exportID)
+		return
+	}
+
+	// the zip is built in memory, a failure never stores a truncated one
+	var archive bytes.Buffer
+	if err := e.writeZip(ctx, &cappedWriter{w: &archive, remaining: e.maxArchiveBytes}, user); err != nil {
+		e.logger.Error().Msgf("AccountExporter: error to write the export %s: %v", exportID, err)
+		e.markFailed(ctx, exportID)
+		return
+	}
+
+	expiresAt := time.Now().Add(AccountExportDuration)
+	err = e.accountExportRepository.MarkAccountExportReady(ctx, exportID, archive.Bytes(), expiresAt)
+	if err != nil {
+		// the user was deleted meanwhile
+		e.logger.Error().Msgf("AccountExporter: error to mark the export %s as ready: %v", exportID, err)
+		return
+	}
+
+	e.notify(ctx, user, exportID, expiresAt)
+}
+
+func (e *AccountExporter) writeZip(ctx context.Context, w io.Writer, user *db.User) error {
+	archive := zip.NewWriter(w)
+	userID := user.ID.String()
+
+	err := writeZipJSON(archive, "profile.json", toExportedProfile(user))
+	if err != nil {
+		return err
+	}
+
+	conversations, err := e.accountExportRepository.GetUserExportConversations(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	exportedConversations := make([]exportedConversation, 0, len(*conversations))
+	for _, conversation := range *conversations {
+		exportedConversations = append(exportedConversations, toExportedConversation(conversation))
+	}
+
+	err = writeZipJSON(archive, "conversations.json", exportedConversations)
+	if err != nil {
+		return err
+	}
+
+	if err := e.writeMessages(ctx, archive, userID); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// writeMessages writes the JSON array of the messages page by page, only a page of rows is
+// read at a time but the zip that receives them is limited by maxArchiveBytes
+func (e *AccountExporter) writeMessages(ctx context.Context, archive *zip.Writer, userID string) error {
+	w, err := archive.Create("messages.json")
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	var last *db.Message
+	first := true
+	for {
+		messages, err := e.accountExportRepository.GetUserExportMessages(ctx, userID, last, accountExportMessagesPage)
+		if err != nil {
+			return err
+		}
+
+		for _, message := range *messages {
+			data, err := json.MarshalIndent(toExportedMessage(message), "  ", "  ")
+			if err != nil {
+				return err
+			}
+
+			separator := ",\n  "
+			if first {
+				separator = "\n  "
+				first = false
+			}
+
+			if _, err := io.WriteString(w, separator); err != nil {
+				return err
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+		}
+
+		if len(*messages) < accountExportMessagesPage {
+			break
+		}
+		last = &(*messages)[len(*messages)-1]
+	}
+
+	_, err = io.WriteString(w, "\n]\n")
+
+	return err
+}
+
+func (e *AccountExporter) notify(ctx context.Context, user *db.User, exportID string, expiresAt time.Time) {
+	err := e.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Your data export is ready",
+		Body: fmt.Sprintf(
+			"The export of your data is ready.\n\nDownload it from the app, or from this link while you're logged in:\n%s\n\nIt's available until %s.\n",
+			e.appConfig.BaseURL+"/api/v1/account/exports/"+exportID,
+			expiresAt.UTC().Format("January 2, 2006 15:04 MST"),
+		),
+	})
+	if err != nil {
+		// the export can still be found in the app
+		e.logger.Error().Msgf("AccountExporter: error to send the email of the export %s: %v", exportID, err)
+	}
+}
+
+func (e *AccountExporter) markFailed(ctx context.Context, exportID string) {
+	err := e.accountExportRepository.MarkAccountExportFailed(ctx, exportID)
+	if err != nil {
+		e.logger.Error().Msgf("AccountExporter: error to mark the export %s as failed: %v", exportID, err)
+	}
+}
+
+// cappedWriter fails with customerrors.ErrAccountExportTooLarge once more than remaining bytes are written
+type cappedWriter struct {
+	w         io.Writer
+	remaining int
+}
+
+func (c *cappedWriter) Write(p []byte) (int, error) {
+	if len(p) > c.remaining {
+		return 0, customerrors.ErrAccountExportTooLarge
+	}
+	c.remaining -= len(p)
+
+	return c.w.Write(p)
+}
+
+func writeZipJSON(archive *zip.Writer, name string, value any) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
+
+// the files of the export have their own types, so a new column is never exported by mistake
+type exportedProfile struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	About           string     `json:"about"`
+	AvatarURL       string     `json:"avatarUrl"`
+	LastSeenPrivacy string     `json:"lastSeenPrivacy"`
+	LastSeenAt      *time.Time `json:"lastSeenAt"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+type exportedConversation struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Role        string    `json:"role"`
+	IsActive    bool      `json:"isActive"`
+	JoinedAt    time.Time `json:"joinedAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type exportedMessage struct {
+	ID               string     `json:"id"`
+	ConversationID   string     `json:"conversationId"`
+	Content          string     `json:"content"`
+	MessageType      string     `json:"messageType"`
+	ReplyToMessageID *string    `json:"replyToMessageId"`
+	IsForwarded      bool       `json:"isForwarded"`
+	IsDeleted        bool       `json:"isDeleted"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	EditedAt         *time.Time `json:"editedAt"`
+}
+
+func toExportedProfile(user *db.User) exportedProfile {
+	profile := exportedProfile{
+		ID:              user.ID.String(),
+		Name:            user.Name.String,
+		Email:           user.Email,
+		About:           user.About.String,
+		AvatarURL:       user.AvatarUrl.String,
+		LastSeenPrivacy: user.LastSeenPrivacy,
+		CreatedAt:       user.CreatedAt.Time,
+	}
+	if user.LastSeenAt.Valid {
+		profile.LastSeenAt = &user.LastSeenAt.Time
+	}
+
+	return profile
+}
+
+func toExportedConversation(row db.GetUserExportConversationsRow) exportedConversation {
+	return exportedConversation{
+		ID:          row.Conversation.ID.String(),
+		Type:        row.Conversation.Type,
+		Name:        row.Conversation.Name.String,
+		Description: row.Conversation.Description.String,
+		Role:        row.Role,
+		IsActive:    row.IsActive.Bool,
+		JoinedAt:    row.JoinedAt.Time,
+		CreatedAt:   row.Conversation.CreatedAt.Time,
+	}
+}
+
+func toExportedMessage(message db.Message) exportedMessage {
+	exported := exportedMessage{
+		ID:             message.ID.String(),
+		ConversationID: message.ConversationID.String(),
+		Content:        message.Content,
+		MessageType:    message.MessageType,
+		IsForwarded:    message.IsForwarded,
+		IsDeleted:      message.IsDeleted.Bool,
+		CreatedAt:      message.CreatedAt.Time,
+	}
+	if message.ReplyToMessageID.Valid {
+		replyID := message.ReplyToMessageID.String()
+		exported.ReplyToMessageID = &replyID
+	}
+	if message.EditedAt.Valid {
+		exported.EditedAt = &message.EditedAt.Time
+	}
+
+	return exported
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang-whatsapp-clone/config"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// AccountExportInterval is how long a ready export is returned instead of starting another one
+const AccountExportInterval = 24 * time.Hour
+
+// AccountService handles the requests of the users about their whole account, the exports of
+// their data and the deletion of the account
+type AccountService struct {
+	accountRepository       repository.AccountRepository
+	accountExportRepository repository.AccountExportRepository
+	userService             *UserService
+	sessionService          *SessionService
+	appConfig               *config.AppConfig
+	logger                  *zerolog.Logger
+}
+
+func NewAccountService(
+	accountRepository repository.AccountRepository,
+	accountExportRepository repository.AccountExportRepository,
+	userService *UserService,
+	sessionService *SessionService,
+	appConfig *config.AppConfig,
+	logger *zerolog.Logger,
+) *AccountService {
+	return &AccountService{
+		accountRepository:       accountRepository,
+		accountExportRepository: accountExportRepository,
+		userService:             userService,
+		sessionService:          sessionService,
+		appConfig:               appConfig,
+		logger:                  logger,
+	}
+}
+
+// RequestExport starts an export of the data of the user, the AccountExporter builds it in the
+// background. The export in progress, or the one that got ready in the last
+// AccountExportInterval, is returned instead of starting another one
+func (s *AccountService) RequestExport(ctx context.Context, userID string) (*db.AccountExport, error) {
+	latest, err := s.accountExportRepository.GetLatestAccountExport(ctx, userID)
+	if err != nil && !errors.Is(err, customerrors.ErrResourceNotFound) {
+		return nil, err
+	}
+
+	if latest != nil {
+		switch latest.Status {
+		case repository.ACCOUNT_EXPORT_STATUS_PENDING, repository.ACCOUNT_EXPORT_STATUS_PROCESSING:
+			return latest, nil
+		case repository.ACCOUNT_EXPORT_STATUS_READY:
+			if time.Since(latest.CreatedAt.Time) < AccountExportInterval && latest.ExpiresAt.Time.After(time.Now()) {
+				return latest, nil
+			}
+		}
+	}
+
+	return s.accountExportRepository.CreateAccountExport(ctx, userID)
+}
+
+// GetLatestExport returns customerrors.ErrResourceNotFound when the user never requested one
+func (s *AccountService) GetLatestExport(ctx context.Context, userID string) (*db.AccountExport, error) {
+	return s.accountExportRepository.GetLatestAccountExport(ctx, userID)
+}
+
+// GetExportArchive returns the zip file of an export of the user, customerrors.ErrResourceNotFound
+// is returned when the export is not ready or it expired
+func (s *AccountService) GetExportArchive(ctx context.Context, userID string, exportID string) ([]byte, *db.AccountExport, error) {
+	export, err := s.accountExportRepository.GetAccountExport(ctx, exportID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if export.Status != repository.ACCOUNT_EXPORT_STATUS_READY || export.ExpiresAt.Time.Before(time.Now()) {
+		return nil, nil, customerrors.ErrResourceNotFound
+	}
+
+	archive, err := s.accountExportRepository.GetAccountExportArchive(ctx, exportID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return archive, export, nil
+}
+
+// DeleteAccount removes the account of the user, the confirmation must be its email.
+// The sessions are removed with the account, so its tokens are rejected from now on, and the
+// websocket connections of the sessions are closed
+func (s *AccountService) DeleteAccount(ctx context.Context, userID string, confirmation string) error {
+	if userID == repository.DELETED_USER_ID {
+		return customerrors.ErrForbidden
+	}
+
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(strings.TrimSpace(confirmation), user.Email) {
+		return customerrors.ErrInvalidAccountConfirmation
+	}
+
+	keepDirectConversations := s.appConfig.DeletedAccountDirectConversations == "keep"
+
+	deleted, err := s.accountRepository.DeleteAccount(ctx, userID, user.Email, keepDirectConversations)
+	if err != nil {
+		return err
+	}
+
+	for _, sessionID := range deleted.SessionIDs {
+		s.sessionService.CloseConnections(sessionID)
+	}
+
+	s.logger.Info().Msgf("AccountService: the account of user %s was deleted", userID)
+
+	return nil
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"golang-whatsapp-clone/config"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+)
+
+// fakeAccountExportRepository keeps the exports in memory, the messages are returned in pages
+// like the query
+type fakeAccountExportRepository struct {
+	repository.AccountExportRepository
+	exports  []*db.AccountExport
+	archives map[string][]byte
+	messages []db.Message
+}
+
+func (r *fakeAccountExportRepository) CreateAccountExport(ctx context.Context, userID string) (*db.AccountExport, error) {
+	export := &db.AccountExport{
+		ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		UserID:    pgtype.UUID{Bytes: uuid.MustParse(userID), Valid: true},
+		Status:    repository.ACCOUNT_EXPORT_STATUS_PENDING,
+		CreatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	r.exports = append(r.exports, export)
+
+	return export, nil
+}
+
+func (r *fakeAccountExportRepository) GetLatestAccountExport(ctx context.Context, userID string) (*db.AccountExport, error) {
+	for i := len(r.exports) - 1; i >= 0; i-- {
+		if r.exports[i].UserID.String() == userID {
+			return r.exports[i], nil
+		}
+	}
+
+	return nil, customerrors.ErrResourceNotFound
+}
+
+func (r *fakeAccountExportRepository) GetAccountExport(ctx context.Context, exportID string, userID string) (*db.AccountExport, error) {
+	for _, export := range r.exports {
+		if export.ID.String() == exportID && export.UserID.String() == userID {
+			return export, nil
+		}
+	}
+
+	return nil, customerrors.ErrResourceNotFound
+}
+
+func (r *fakeAccountExportRepository) MarkAccountExportReady(ctx context.Context, exportID string, archive []byte, expiresAt time.Time) error {
+	for _, export := range r.exports {
+		if export.ID.String() == exportID {
+			export.Status = repository.ACCOUNT_EXPORT_STATUS_READY
+			export.CompletedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+			export.ExpiresAt = pgtype.Timestamptz{Time: expiresAt, Valid: true}
+			r.archives[exportID] = archive
+			return nil
+		}
+	}
+
+	return customerrors.ErrResourceNotFound
+}
+
+func (r *fakeAccountExportRepository) MarkAccountExportFailed(ctx context.Context, exportID string) error {
+	for _, export := range r.exports {
+		if export.ID.String() == exportID {
+			export.Status = repository.ACCOUNT_EXPORT_STATUS_FAILED
+		}
+	}
+
+	return nil
+}
+
+func (r *fakeAccountExportRepository) GetAccountExportArchive(ctx context.Context, exportID string) ([]byte, error) {
+	archive, ok := r.archives[exportID]
+	if !ok {
+		return nil, customerrors.ErrResourceNotFound
+	}
+
+	return archive, nil
+}
+
+func (r *fakeAccountExportRepository) GetUserExportConversations(ctx context.Context, userID string) (*[]db.GetUserExportConversationsRow, error) {
+	return &[]db.GetUserExportConversationsRow{{
+		Conversation: db.Conversation{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Type: "GROUP", Name: pgtype.Text{String: "Family", Valid: true}},
+		Role:         repository.PARTICIPANT_ROLE_OWNER,
+		IsActive:     pgtype.Bool{Bool: true, Valid: true},
+	}}, nil
+}
+
+func (r *fakeAccountExportRepository) GetUserExportMessages(ctx context.Context, userID string, after *db.Message, limit int32) (*[]db.Message, error) {
+	start := 0
+	if after != nil {
+		for i, message := range r.messages {
+			if message.ID == after.ID {
+				start = i + 1
+			}
+		}
+	}
+
+	end := min(start+int(limit), len(r.messages))
+	page := r.messages[start:end]
+
+	return &page, nil
+}
+
+type fakeAccountRepository struct {
+	deleted                 []string
+	keepDirectConversations bool
+	result                  *repository.DeletedAccount
+}
+
+func (r *fakeAccountRepository) DeleteAccount(ctx context.Context, userID string, email string, keepDirectConversations bool) (*repository.DeletedAccount, error) {
+	r.deleted = append(r.deleted, userID)
+	r.keepDirectConversations = keepDirectConversations
+
+	return r.result, nil
+}
+
+func newAccountTestService(policy string) (*AccountService, *fakeAccountRepository, *fakeAccountExportRepository, *SessionService, string) {
+	ana := db.User{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Email: "ana@example.com"}
+	userRepository := &fakePresenceUserRepository{users: map[string]*db.User{ana.ID.String(): &ana}}
+	accountRepository := &fakeAccountRepository{result: &repository.DeletedAccount{}}
+	accountExportRepository := &fakeAccountExportRepository{archives: map[string][]byte{}}
+	logger := zerolog.Nop()
+	sessionService := NewSessionService(nil, &logger)
+	appConfig := &config.AppConfig{DeletedAccountDirectConversations: policy}
+
+	accountService := NewAccountService(accountRepository, accountExportRepository, NewUserService(userRepository), sessionService, appConfig, &logger)
+
+	return accountService, accountRepository, accountExportRepository, sessionService, ana.ID.String()
+}
+
+func TestRequestExportReusesRecentExports(t *testing.T) {
+	accountService, _, accountExportRepository, _, anaID := newAccountTestService("delete")
+	ctx := context.Background()
+
+	first, err := accountService.RequestExport(ctx, anaID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the pending export is returned while it's built
+	if second, _ := accountService.RequestExport(ctx, anaID); second.ID != first.ID {
+		t.Errorf("expected the pending export to be returned, got a new one")
+	}
+
+	first.Status = repository.ACCOUNT_EXPORT_STATUS_READY
+	first.ExpiresAt = pgtype.Timestamptz{Time: time.Now().Add(AccountExportDuration), Valid: true}
+	if second, _ := accountService.RequestExport(ctx, anaID); second.ID != first.ID {
+		t.Errorf("expected the recent export to be returned, got a new one")
+	}
+
+	// an old or failed export starts another one
+	first.CreatedAt.Time = time.Now().Add(-AccountExportInterval)
+	if second, _ := accountService.RequestExport(ctx, anaID); second.ID == first.ID {
+		t.Errorf("expected a new export after %s", AccountExportInterval)
+	}
+
+	accountExportRepository.exports[1].Status = repository.ACCOUNT_EXPORT_STATUS_FAILED
+	if _, _ = accountService.RequestExport(ctx, anaID); len(accountExportRepository.exports) != 3 {
+		t.Errorf("expected a new export after the failed one, got %d exports", len(accountExportRepository.exports))
+	}
+}
+
+func TestAccountExportArchive(t *testing.T) {
+	accountService, _, accountExportRepository, _, anaID := newAccountTestService("delete")
+	ctx := context.Background()
+
+	export, _ := accountService.RequestExport(ctx, anaID)
+	if _, _, err := accountService.GetExportArchive(ctx, anaID, export.ID.String()); !errors.Is(err, customerrors.ErrResourceNotFound) {
+		t.Errorf("expected the pending export to have no archive, got %v", err)
+	}
+
+	// the exporter stores the zip with the export, so any instance can serve it
+	logger := zerolog.Nop()
+	exporter := NewAccountExporter(accountExportRepository, accountService.userService, &fakeMailer{}, &config.AppConfig{}, &logger)
+	exporter.build(ctx, export)
+
+	archive, ready, err := accountService.GetExportArchive(ctx, anaID, export.ID.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ready.Status != repository.ACCOUNT_EXPORT_STATUS_READY {
+		t.Errorf("expected the export to be ready, got %s", ready.Status)
+	}
+	if _, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive))); err != nil {
+		t.Errorf("expected the archive to be a zip, got %v", err)
+	}
+
+	if _, _, err := accountService.GetExportArchive(ctx, uuid.NewString(), export.ID.String()); !errors.Is(err, customerrors.ErrResourceNotFound) {
+		t.Errorf("expected the export of another user to be not found, got %v", err)
+	}
+
+	export.ExpiresAt.Time = time.Now().Add(-time.Second)
+	if _, _, err := accountService.GetExportArchive(ctx, anaID, export.ID.String()); !errors.Is(err, customerrors.ErrResourceNotFound) {
+		t.Errorf("expected the expired export to be not found, got %v", err)
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	accountService, accountRepository, _, sessionService, anaID := newAccountTestService("keep")
+	ctx := context.Background()
+
+	if err := accountService.DeleteAccount(ctx, anaID, "bob@example.com"); !errors.Is(err, customerrors.ErrInvalidAccountConfirmation) {
+		t.Fatalf("expected the wrong email to be rejected, got %v", err)
+	}
+	if len(accountRepository.deleted) != 0 {
+		t.Fatal("expected the account to be kept")
+	}
+
+	if err := accountService.DeleteAccount(ctx, repository.DELETED_USER_ID, ""); !errors.Is(err, customerrors.ErrForbidden) {
+		t.Errorf("expected the deleted account user to be forbidden, got %v", err)
+	}
+
+	closed := false
+	sessionService.TrackConnection("session-1", func() { closed = true })
+	accountRepository.result = &repository.DeletedAccount{SessionIDs: []string{"session-1"}}
+
+	if err := accountService.DeleteAccount(ctx, anaID, " Ana@Example.com "); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(accountRepository.deleted) != 1 || !accountRepository.keepDirectConversations {
+		t.Errorf("expected the account to be deleted keeping the direct conversations, got %+v", accountRepository)
+	}
+
+	if !closed {
+		t.Error("expected the connections of the sessions to be closed")
+	}
+}
+
+func TestAccountExportZip(t *testing.T) {
+	accountExportRepository := &fakeAccountExportRepository{}
+	for i := range accountExportMessagesPage + 2 {
+		accountExportRepository.messages = append(accountExportRepository.messages, db.Message{
+			ID:      pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Content: "message",
+			CreatedAt: pgtype.Timestamptz{
+				Time:  time.Now().Add(time.Duration(i) * time.Second),
+				Valid: true,
+			},
+		})
+	}
+
+	logger := zerolog.Nop()
+	exporter := NewAccountExporter(accountExportRepository, nil, nil, &config.AppConfig{}, &logger)
+	user := &db.User{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Email: "ana@example.com", Name: pgtype.Text{String: "Ana", Valid: true}}
+
+	var buf bytes.Buffer
+	if err := exporter.writeZip(context.Background(), &buf, user); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+
+	var profile exportedProfile
+	var conversations []exportedConversation
+	var messages []exportedMessage
+	files := map[string]any{"profile.json": &profile, "conversations.json": &conversations, "messages.json": &messages}
+
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("error to open %s: %v", file.Name, err)
+		}
+		data, _ := io.ReadAll(reader)
+		reader.Close()
+
+		if err := json.Unmarshal(data, files[file.Name]); err != nil {
+			t.Fatalf("invalid json in %s: %v", file.Name, err)
+		}
+	}
+
+	if profile.Email != "ana@example.com" || profile.Name != "Ana" {
+		t.Errorf("unexpected profile %+v", profile)
+	}
+
+	if len(conversations) != 1 || conversations[0].Name != "Family" {
+		t.Errorf("unexpected conversations %+v", conversations)
+	}
+
+	// the messages of every page are written
+	if len(messages) != accountExportMessagesPage+2 {
+		t.Errorf("expected %d messages, got %d", accountExportMessagesPage+2, len(messages))
+	}
+}
+
+func TestAccountExportLargerThanTheLimit(t *testing.T) {
+	accountService, _, accountExportRepository, _, anaID := newAccountTestService("delete")
+	for range accountExportMessagesPage + 2 {
+		accountExportRepository.messages = append(accountExportRepository.messages, db.Message{
+			ID:      pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Content: strings.Repeat("a long message ", 20),
+		})
+	}
+	ctx := context.Background()
+
+	export, _ := accountService.RequestExport(ctx, anaID)
+
+	logger := zerolog.Nop()
+	exporter := NewAccountExporter(accountExportRepository, accountService.userService, &fakeMailer{}, &config.AppConfig{}, &logger)
+	exporter.maxArchiveBytes = 4 * 1024
+	exporter.build(ctx, export)
+
+	if export.Status != repository.ACCOUNT_EXPORT_STATUS_FAILED {
+		t.Errorf("expected the export larger than the limit to fail, got %s", export.Status)
+	}
+
+	if _, _, err := accountService.GetExportArchive(ctx, anaID, export.ID.String()); !errors.Is(err, customerrors.ErrResourceNotFound) {
+		t.Errorf("expected no archive for the failed export, got %v", err)
+	}
+}
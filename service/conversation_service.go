@@ -4,10 +4,26 @@ import (
 	"context"
 	"errors"
 	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
 	"golang-whatsapp-clone/graph/model"
 	"golang-whatsapp-clone/repository"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// same limit WhatsApp uses for the pinned chats
+const MaxPinnedConversations = 3
+
+// DisappearingMessagesDurations are the timers a conversation can use, 0 turns them off
+var DisappearingMessagesDurations = []time.Duration{
+	0,
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+	90 * 24 * time.Hour,
+}
+
 type ConversationService struct {
 	conversationRepository repository.ConversationRepository
 	participantRepository  repository.ParticipantRepository
@@ -23,8 +39,9 @@ func NewConversationService(
 	}
 }
 
-func (s *ConversationService) GetUserConversations(ctx context.Context, userID string) (*[]db.GetUserConversationsRow, error) {
-	result, err := s.conversationRepository.GetUserConversations(ctx, userID)
+// GetUserConversations returns the conversations of the user, the pinned ones first
+func (s *ConversationService) GetUserConversations(ctx context.Context, userID string, includeArchived bool) (*[]db.GetUserConversationsRow, error) {
+	result, err := s.conversationRepository.GetUserConversations(ctx, userID, includeArchived)
 	if err != nil {
 		return nil, errors.New("invalid user id value")
 	}
@@ -41,7 +58,73 @@ func (s *ConversationService) GetLastMessageFromConversation(ctx context.Context
 	return result, nil
 }
 
+// GetLastMessages returns the last message of the conversations grouped by conversation id,
+// the conversations without messages are not included
+func (s *ConversationService) GetLastMessages(ctx context.Context, conversationIDs []string) (map[string]db.GetLastMessageRow, error) {
+	result := make(map[string]db.GetLastMessageRow, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return result, nil
+	}
+
+	lastMessages, err := s.conversationRepository.GetLastMessages(ctx, conversationIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lastMessage := range *lastMessages {
+		result[lastMessage.ConversationID.String()] = lastMessage
+	}
+
+	return result, nil
+}
+
+// GetActiveParticipants returns the active participants grouped by conversation id
+func (s *ConversationService) GetActiveParticipants(ctx context.Context, conversationIDs []string) (map[string][]db.ConversationParticipant, error) {
+	result := make(map[string][]db.ConversationParticipant, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return result, nil
+	}
+
+	participants, err := s.participantRepository.GetActiveParticipants(ctx, conversationIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, participant := range *participants {
+		conversationID := participant.ConversationID.String()
+		result[conversationID] = append(result[conversationID], participant)
+	}
+
+	return result, nil
+}
+
+// GetConversation returns the conversation and a page of its participants, only its active
+// participants can see it
+func (s *ConversationService) GetConversation(ctx context.Context, userID string, conversationID string, limit int32, offset int32) (*db.Conversation, *[]db.GetConversationParticipantsRow, error) {
+	_, err := ensureParticipant(ctx, s.participantRepository, userID, conversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conversation, err := s.conversationRepository.GetConversationByID(ctx, conversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	participants, err := s.participantRepository.GetConversationParticipants(ctx, conversationID, limit, offset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conversation, participants, nil
+}
+
 func (s *ConversationService) GetOrCreateDirectConversation(ctx context.Context, user1ID string, user2ID string) (*db.Conversation, error) {
+	// the sender of the messages of the deleted accounts is not a real user
+	if user1ID == repository.DELETED_USER_ID || user2ID == repository.DELETED_USER_ID {
+		return nil, customerrors.ErrForbidden
+	}
+
 	// first try to find existing conversation
 	existing, err := s.conversationRepository.FindDirectConversation(ctx, user1ID, user2ID)
 	if err == nil {
@@ -63,3 +146,103 @@ func (s *ConversationService) GetOrCreateDirectConversation(ctx context.Context,
 
 	return conversation, nil
 }
+
+// EnsureParticipant returns customerrors.ErrForbidden when the user is not an active participant of the conversation
+func (s *ConversationService) EnsureParticipant(ctx context.Context, conversationID string, userID string) error {
+	_, err := ensureParticipant(ctx, s.participantRepository, userID, conversationID)
+
+	return err
+}
+
+// SetDisappearingMessages changes the timer of the new messages of the conversation.
+// In groups only the admins can change it
+func (s *ConversationService) SetDisappearingMessages(ctx context.Context, userID string, conversationID string, duration time.Duration) (*db.Conversation, error) {
+	if !slices.Contains(DisappearingMessagesDurations, duration) {
+		return nil, customerrors.ErrInvalidDisappearingTimer
+	}
+
+	participant, err := ensureParticipant(ctx, s.participantRepository, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation, err := s.conversationRepository.GetConversationByID(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canManageConversation(conversation.Type, participant.Role) {
+		return nil, customerrors.ErrForbidden
+	}
+
+	return s.conversationRepository.UpdateDisappearingMessages(ctx, conversationID, int32(duration.Seconds()))
+}
+
+// ArchiveConversation hides the conversation from the main list of the user, archiving also unpins it
+func (s *ConversationService) ArchiveConversation(ctx context.Context, userID string, conversationID string, archived bool) (*db.ConversationParticipant, error) {
+	_, err := ensureParticipant(ctx, s.participantRepository, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.participantRepository.SetArchived(ctx, userID, conversationID, archived)
+}
+
+// MuteConversation mutes the conversation for the user until the given time, or until
+// the user unmutes it when until is nil. The mentions of the user are never muted
+func (s *ConversationService) MuteConversation(ctx context.Context, userID string, conversationID string, until *time.Time) (*db.ConversationParticipant, error) {
+	_, err := ensureParticipant(ctx, s.participantRepository, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	mutedUntil := pgtype.Timestamptz{InfinityModifier: pgtype.Infinity, Valid: true}
+	if until != nil {
+		mutedUntil = pgtype.Timestamptz{Time: *until, Valid: true}
+	}
+
+	return s.participantRepository.SetMutedUntil(ctx, userID, conversationID, mutedUntil)
+}
+
+func (s *ConversationService) UnmuteConversation(ctx context.Context, userID string, conversationID string) (*db.ConversationParticipant, error) {
+	_, err := ensureParticipant(ctx, s.participantRepository, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.participantRepository.SetMutedUntil(ctx, userID, conversationID, pgtype.Timestamptz{})
+}
+
+// PinConversation puts the conversation on top of the list of the user,
+// customerrors.ErrConversationPinLimitReached is returned when MaxPinnedConversations are already
+// pinned. Pinning a conversation that is already pinned does nothing
+func (s *ConversationService) PinConversation(ctx context.Context, userID string, conversationID string) (*db.ConversationParticipant, error) {
+	_, err := ensureParticipant(ctx, s.participantRepository, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.participantRepository.Pin(ctx, userID, conversationID, MaxPinnedConversations)
+}
+
+func (s *ConversationService) UnpinConversation(ctx context.Context, userID string, conversationID string) (*db.ConversationParticipant, error) {
+	_, err := ensureParticipant(ctx, s.participantRepository, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.participantRepository.Unpin(ctx, userID, conversationID)
+}
+
+// IsConversationMuted tells if a muted_until value is still muting the conversation
+func IsConversationMuted(mutedUntil pgtype.Timestamptz, now time.Time) bool {
+	if !mutedUntil.Valid {
+		return false
+	}
+
+	if mutedUntil.InfinityModifier == pgtype.Infinity {
+		return true
+	}
+
+	return mutedUntil.Time.After(now)
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeSettingsParticipantRepository keeps the participants of a single user by conversation id,
+// the pins are checked and written under a lock like the transaction that locks the user row
+type fakeSettingsParticipantRepository struct {
+	repository.ParticipantRepository
+	mu           sync.Mutex
+	participants map[string]*db.ConversationParticipant
+	limits       []int32
+}
+
+func newFakeSettingsParticipantRepository(conversationIDs ...string) *fakeSettingsParticipantRepository {
+	r := &fakeSettingsParticipantRepository{participants: map[string]*db.ConversationParticipant{}}
+	for _, conversationID := range conversationIDs {
+		r.participants[conversationID] = &db.ConversationParticipant{
+			ConversationID: pgtype.UUID{Bytes: uuid.MustParse(conversationID), Valid: true},
+		}
+	}
+
+	return r
+}
+
+func (r *fakeSettingsParticipantRepository) GetParticipant(ctx context.Context, userID string, conversationID string) (*db.ConversationParticipant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	participant, ok := r.participants[conversationID]
+	if !ok {
+		return nil, customerrors.ErrResourceNotFound
+	}
+
+	copied := *participant
+	return &copied, nil
+}
+
+func (r *fakeSettingsParticipantRepository) SetArchived(ctx context.Context, userID string, conversationID string, archived bool) (*db.ConversationParticipant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	participant := r.participants[conversationID]
+	participant.IsArchived = archived
+	if archived {
+		participant.PinOrder = pgtype.Int4{}
+	}
+
+	return participant, nil
+}
+
+func (r *fakeSettingsParticipantRepository) SetMutedUntil(ctx context.Context, userID string, conversationID string, mutedUntil pgtype.Timestamptz) (*db.ConversationParticipant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	participant := r.participants[conversationID]
+	participant.MutedUntil = mutedUntil
+
+	return participant, nil
+}
+
+func (r *fakeSettingsParticipantRepository) Pin(ctx context.Context, userID string, conversationID string, limit int32) (*db.ConversationParticipant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.limits = append(r.limits, limit)
+
+	participant := r.participants[conversationID]
+	if participant.PinOrder.Valid {
+		return participant, nil
+	}
+
+	pinned := int32(0)
+	for _, other := range r.participants {
+		if other.PinOrder.Valid {
+			pinned++
+		}
+	}
+
+	if pinned >= limit {
+		return nil, customerrors.ErrConversationPinLimitReached
+	}
+
+	participant.PinOrder = pgtype.Int4{Int32: pinned + 1, Valid: true}
+	participant.IsArchived = false
+
+	return participant, nil
+}
+
+func (r *fakeSettingsParticipantRepository) Unpin(ctx context.Context, userID string, conversationID string) (*db.ConversationParticipant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	participant := r.participants[conversationID]
+	participant.PinOrder = pgtype.Int4{}
+
+	return participant, nil
+}
+
+// fakeListConversationRepository lists the conversations of the participants repository,
+// filtered and sorted like the GetUserConversations query
+type fakeListConversationRepository struct {
+	repository.ConversationRepository
+	participants  *fakeSettingsParticipantRepository
+	lastMessageAt map[string]time.Time
+}
+
+func (r *fakeListConversationRepository) GetUserConversations(ctx context.Context, userID string, includeArchived bool) (*[]db.GetUserConversationsRow, error) {
+	r.participants.mu.Lock()
+	defer r.participants.mu.Unlock()
+
+	rows := []db.GetUserConversationsRow{}
+	for conversationID, participant := range r.participants.participants {
+		if participant.IsArchived && !includeArchived {
+			continue
+		}
+
+		rows = append(rows, db.GetUserConversationsRow{
+			ID:            participant.ConversationID,
+			LastMessageAt: pgtype.Timestamptz{Time: r.lastMessageAt[conversationID], Valid: true},
+			IsArchived:    participant.IsArchived,
+			PinOrder:      participant.PinOrder,
+		})
+	}
+
+	// ORDER BY cp.pin_order DESC NULLS LAST, c.last_message_at DESC
+	slices.SortFunc(rows, func(a db.GetUserConversationsRow, b db.GetUserConversationsRow) int {
+		if a.PinOrder.Valid != b.PinOrder.Valid {
+			if a.PinOrder.Valid {
+				return -1
+			}
+			return 1
+		}
+		if a.PinOrder.Int32 != b.PinOrder.Int32 {
+			return int(b.PinOrder.Int32 - a.PinOrder.Int32)
+		}
+		return b.LastMessageAt.Time.Compare(a.LastMessageAt.Time)
+	})
+
+	return &rows, nil
+}
+
+func TestGetUserConversations(t *testing.T) {
+	conversationIDs := []string{uuid.NewString(), uuid.NewString(), uuid.NewString(), uuid.NewString()}
+	participantRepository := newFakeSettingsParticipantRepository(conversationIDs...)
+	conversationRepository := &fakeListConversationRepository{participants: participantRepository, lastMessageAt: map[string]time.Time{}}
+	conversationService := NewConversationService(conversationRepository, participantRepository)
+	ctx := context.Background()
+
+	// the first conversation has the newest message
+	now := time.Now()
+	for i, conversationID := range conversationIDs {
+		conversationRepository.lastMessageAt[conversationID] = now.Add(-time.Duration(i) * time.Hour)
+	}
+
+	listConversations := func(includeArchived bool) []string {
+		t.Helper()
+
+		conversations, err := conversationService.GetUserConversations(ctx, "user-id", includeArchived)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ids := []string{}
+		for _, conversation := range *conversations {
+			ids = append(ids, conversation.ID.String())
+		}
+
+		return ids
+	}
+
+	if ids := listConversations(false); !slices.Equal(ids, conversationIDs) {
+		t.Fatalf("expected the newest conversations first, got %v", ids)
+	}
+
+	// the pinned conversations go first, the last pinned at the top
+	for _, conversationID := range []string{conversationIDs[2], conversationIDs[3]} {
+		if _, err := conversationService.PinConversation(ctx, "user-id", conversationID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := conversationService.ArchiveConversation(ctx, "user-id", conversationIDs[1], true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{conversationIDs[3], conversationIDs[2], conversationIDs[0]}
+	if ids := listConversations(false); !slices.Equal(ids, expected) {
+		t.Errorf("expected the pinned conversations first and no archived ones %v, got %v", expected, ids)
+	}
+
+	expected = []string{conversationIDs[3], conversationIDs[2], conversationIDs[0], conversationIDs[1]}
+	if ids := listConversations(true); !slices.Equal(ids, expected) {
+		t.Errorf("expected the archived conversation to be included %v, got %v", expected, ids)
+	}
+
+	// pinning the archived conversation takes it out of the archive and to the top
+	if _, err := conversationService.PinConversation(ctx, "user-id", conversationIDs[1]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected = []string{conversationIDs[1], conversationIDs[3], conversationIDs[2], conversationIDs[0]}
+	if ids := listConversations(false); !slices.Equal(ids, expected) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+}
+
+func TestArchiveConversation(t *testing.T) {
+	conversationID := uuid.NewString()
+	participantRepository := newFakeSettingsParticipantRepository(conversationID)
+	conversationService := NewConversationService(nil, participantRepository)
+	ctx := context.Background()
+
+	if _, err := conversationService.PinConversation(ctx, "user-id", conversationID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// archiving unpins the conversation
+	participant, err := conversationService.ArchiveConversation(ctx, "user-id", conversationID, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !participant.IsArchived || participant.PinOrder.Valid {
+		t.Errorf("expected the conversation to be archived and unpinned, got %+v", participant)
+	}
+
+	// pinning takes it out of the archive
+	participant, _ = conversationService.PinConversation(ctx, "user-id", conversationID)
+	if participant.IsArchived || !participant.PinOrder.Valid {
+		t.Errorf("expected the conversation to be pinned and unarchived, got %+v", participant)
+	}
+
+	if _, err := conversationService.ArchiveConversation(ctx, "user-id", uuid.NewString(), true); !errors.Is(err, customerrors.ErrForbidden) {
+		t.Errorf("expected %v for a conversation of other users, got %v", customerrors.ErrForbidden, err)
+	}
+}
+
+func TestMuteConversation(t *testing.T) {
+	conversationID := uuid.NewString()
+	participantRepository := newFakeSettingsParticipantRepository(conversationID)
+	conversationService := NewConversationService(nil, participantRepository)
+	ctx := context.Background()
+	now := time.Now()
+
+	// without a time it's muted until the user unmutes it
+	participant, err := conversationService.MuteConversation(ctx, "user-id", conversationID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if participant.MutedUntil.InfinityModifier != pgtype.Infinity || !IsConversationMuted(participant.MutedUntil, now.AddDate(10, 0, 0)) {
+		t.Errorf("expected the conversation to be muted forever, got %+v", participant.MutedUntil)
+	}
+
+	until := now.Add(8 * time.Hour)
+	participant, _ = conversationService.MuteConversation(ctx, "user-id", conversationID, &until)
+	if !IsConversationMuted(participant.MutedUntil, now) || IsConversationMuted(participant.MutedUntil, until.Add(time.Second)) {
+		t.Errorf("expected the conversation to be muted for 8 hours, got %+v", participant.MutedUntil)
+	}
+
+	participant, _ = conversationService.UnmuteConversation(ctx, "user-id", conversationID)
+	if participant.MutedUntil.Valid || IsConversationMuted(participant.MutedUntil, now) {
+		t.Errorf("expected the conversation to be unmuted, got %+v", participant.MutedUntil)
+	}
+
+	if _, err := conversationService.MuteConversation(ctx, "user-id", uuid.NewString(), nil); !errors.Is(err, customerrors.ErrForbidden) {
+		t.Errorf("expected %v for a conversation of other users, got %v", customerrors.ErrForbidden, err)
+	}
+}
+
+func TestPinConversationLimit(t *testing.T) {
+	conversationIDs := []string{}
+	for range MaxPinnedConversations + 2 {
+		conversationIDs = append(conversationIDs, uuid.NewString())
+	}
+	participantRepository := newFakeSettingsParticipantRepository(conversationIDs...)
+	conversationService := NewConversationService(nil, participantRepository)
+	ctx := context.Background()
+
+	// the conversations are pinned at the same time, only MaxPinnedConversations of them can be
+	errs := make([]error, len(conversationIDs))
+	var wg sync.WaitGroup
+	for i, conversationID := range conversationIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = conversationService.PinConversation(ctx, "user-id", conversationID)
+		}()
+	}
+	wg.Wait()
+
+	var pinned, rejected []string
+	orders := map[int32]bool{}
+	for i, err := range errs {
+		if errors.Is(err, customerrors.ErrConversationPinLimitReached) {
+			rejected = append(rejected, conversationIDs[i])
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		pinned = append(pinned, conversationIDs[i])
+		orders[participantRepository.participants[conversationIDs[i]].PinOrder.Int32] = true
+	}
+
+	if len(orders) != MaxPinnedConversations || len(rejected) != 2 {
+		t.Fatalf("expected %d pins with different orders and 2 rejected, got the orders %v and %d rejected", MaxPinnedConversations, orders, len(rejected))
+	}
+
+	for _, limit := range participantRepository.limits {
+		if limit != MaxPinnedConversations {
+			t.Errorf("expected the limit %d to reach the repository, got %d", MaxPinnedConversations, limit)
+		}
+	}
+
+	// after an unpin there is room for another one
+	if _, err := conversationService.UnpinConversation(ctx, "user-id", pinned[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := conversationService.PinConversation(ctx, "user-id", rejected[0]); err != nil {
+		t.Errorf("expected the conversation to be pinned after the unpin, got %v", err)
+	}
+}
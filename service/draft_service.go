@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"strings"
+)
+
+type DraftService struct {
+	draftRepository       repository.DraftRepository
+	participantRepository repository.ParticipantRepository
+}
+
+func NewDraftService(
+	draftRepository repository.DraftRepository,
+	participantRepository repository.ParticipantRepository,
+) *DraftService {
+	return &DraftService{
+		draftRepository:       draftRepository,
+		participantRepository: participantRepository,
+	}
+}
+
+// SaveDraft stores the half typed message of the user, replacing the previous draft of the
+// conversation. The spaces are kept because the user is still writing, but a draft with only
+// spaces is removed and nil is returned
+func (s *DraftService) SaveDraft(ctx context.Context, userID string, conversationID string, content string, replyToMessageID *string) (*db.MessageDraft, error) {
+	content = removeControlCharacters(content)
+
+	if isMessageTooLong(content) {
+		return nil, customerrors.ErrMessageTooLong
+	}
+
+	_, err := ensureParticipant(ctx, s.participantRepository, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(content) == "" && replyToMessageID == nil {
+		_, err = s.draftRepository.DeleteDraft(ctx, userID, conversationID)
+		return nil, err
+	}
+
+	return s.draftRepository.SaveDraft(ctx, userID, conversationID, content, replyToMessageID)
+}
+
+// ClearDraft removes the draft of the conversation, the returned bool is false when there was none
+func (s *DraftService) ClearDraft(ctx context.Context, userID string, conversationID string) (bool, error) {
+	return s.draftRepository.DeleteDraft(ctx, userID, conversationID)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// fakeDraftRepository keeps the drafts by user and conversation
+type fakeDraftRepository struct {
+	repository.DraftRepository
+	drafts map[string]db.MessageDraft
+}
+
+func (r *fakeDraftRepository) SaveDraft(ctx context.Context, userID string, conversationID string, content string, replyToMessageID *string) (*db.MessageDraft, error) {
+	draft := db.MessageDraft{Content: content}
+	r.drafts[userID+conversationID] = draft
+
+	return &draft, nil
+}
+
+func (r *fakeDraftRepository) DeleteDraft(ctx context.Context, userID string, conversationID string) (bool, error) {
+	_, ok := r.drafts[userID+conversationID]
+	delete(r.drafts, userID+conversationID)
+
+	return ok, nil
+}
+
+func TestSaveDraft(t *testing.T) {
+	conversationID := uuid.NewString()
+	draftRepository := &fakeDraftRepository{drafts: map[string]db.MessageDraft{}}
+	participantRepository := &fakeMembershipParticipantRepository{conversationIDs: []string{conversationID}}
+	draftService := NewDraftService(draftRepository, participantRepository)
+	ctx := context.Background()
+
+	// the spaces are kept while the user types, the control characters are not
+	draft, err := draftService.SaveDraft(ctx, "user-id", conversationID, "Hello \x00world ", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if draft.Content != "Hello world " {
+		t.Errorf("expected the content %q, got %q", "Hello world ", draft.Content)
+	}
+
+	if _, err := draftService.SaveDraft(ctx, "user-id", conversationID, strings.Repeat("a", MaxMessageLength+1), nil); !errors.Is(err, customerrors.ErrMessageTooLong) {
+		t.Errorf("expected %v, got %v", customerrors.ErrMessageTooLong, err)
+	}
+
+	if _, err := draftService.SaveDraft(ctx, "user-id", uuid.NewString(), "Hello", nil); !errors.Is(err, customerrors.ErrForbidden) {
+		t.Errorf("expected %v for a conversation of other users, got %v", customerrors.ErrForbidden, err)
+	}
+
+	// a draft with only spaces removes the saved one
+	draft, err = draftService.SaveDraft(ctx, "user-id", conversationID, "   ", nil)
+	if err != nil || draft != nil {
+		t.Fatalf("expected the draft to be removed, got %v and %v", draft, err)
+	}
+	if len(draftRepository.drafts) != 0 {
+		t.Errorf("expected no drafts, got %v", draftRepository.drafts)
+	}
+
+	// a reply keeps the draft even without content
+	replyToMessageID := uuid.NewString()
+	if draft, _ := draftService.SaveDraft(ctx, "user-id", conversationID, "", &replyToMessageID); draft == nil {
+		t.Error("expected the draft of the reply to be saved")
+	}
+
+	cleared, err := draftService.ClearDraft(ctx, "user-id", conversationID)
+	if err != nil || !cleared {
+		t.Errorf("expected the draft to be cleared, got %v and %v", cleared, err)
+	}
+
+	if cleared, _ := draftService.ClearDraft(ctx, "user-id", conversationID); cleared {
+		t.Error("expected nothing to clear")
+	}
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"golang-whatsapp-clone/auth"
+	"golang-whatsapp-clone/config"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/mailer"
+	"golang-whatsapp-clone/repository"
+	"math/big"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// EmailLoginProvider is the provider of the identities of the users that logged in by email
+	EmailLoginProvider = "email"
+	// EmailLoginDuration is how long the code and the link of a login are valid
+	EmailLoginDuration = 15 * time.Minute
+	// EmailLoginMaxAttempts is how many wrong codes lock a login
+	EmailLoginMaxAttempts = 5
+	// EmailLoginResendInterval is the minimum time between two logins of the same email
+	EmailLoginResendInterval = time.Minute
+)
+
+const emailLoginCodeDigits = 6
+
+// EmailLoginService logs in the users without password, a short code and a link are sent to
+// the email and either of them can be used once. The code only has a few digits, so it's
+// stored with a keyed hash and the login is locked after a few wrong guesses. The link of the
+// mobile app is bound to the app by its pkce challenge, like the logins with a provider
+type EmailLoginService struct {
+	emailLoginRepository repository.EmailLoginRepository
+	identityService      *IdentityService
+	mailer               mailer.Mailer
+	appConfig            *config.AppConfig
+	// the key of the hashes of the codes, without it a leak of the table is enough to find them.
+	// It's derived from the JWT secret, so a code hash never works as a signature of a token
+	secret []byte
+	logger *zerolog.Logger
+}
+
+func NewEmailLoginService(
+	emailLoginRepository repository.EmailLoginRepository,
+	identityService *IdentityService,
+	mailer mailer.Mailer,
+	appConfig *config.AppConfig,
+	logger *zerolog.Logger,
+) *EmailLoginService {
+	return &EmailLoginService{
+		emailLoginRepository: emailLoginRepository,
+		identityService:      identityService,
+		mailer:               mailer,
+		appConfig:            appConfig,
+		secret:               deriveKey(appConfig.JWTSecret, "email-login-code"),
+		logger:               logger,
+	}
+}
+
+// RequestLogin sends a new code and link to the email, the previous ones of the email stop
+// working. The mobile app sends the S256 pkce challenge of the verifier it uses with the link,
+// customerrors.ErrInvalidCodeChallenge is returned when it's missing.
+// customerrors.ErrLoginCodeRequested is returned when the last one was sent less than
+// EmailLoginResendInterval ago
+func (s *EmailLoginService) RequestLogin(ctx context.Context, email string, clientType string, codeChallenge string) error {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return err
+	}
+
+	if clientType != repository.CLIENT_TYPE_MOBILE {
+		clientType = repository.CLIENT_TYPE_WEB
+		codeChallenge = ""
+	}
+
+	if clientType == repository.CLIENT_TYPE_MOBILE && !auth.IsS256Challenge(codeChallenge) {
+		return customerrors.ErrInvalidCodeChallenge
+	}
+
+	// the abandoned logins are removed from time to time by the new ones
+	if err := s.emailLoginRepository.DeleteExpired(ctx); err != nil {
+		s.logger.Error().Msgf("EmailLoginService: error to delete the expired logins: %v", err)
+	}
+
+	code, err := generateLoginCode()
+	if err != nil {
+		return err
+	}
+
+	linkToken, err := generateLinkToken()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.emailLoginRepository.ReplaceEmailLogin(ctx, email, s.hashCode(email, code), hashLinkToken(linkToken), clientType, codeChallenge, time.Now().Add(EmailLoginDuration), EmailLoginResendInterval)
+	if err != nil {
+		return err
+	}
+
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: fmt.Sprintf("%s is your login code", code),
+		Body: fmt.Sprintf(
+			"Your login code is %s\n\nOr open this link to log in:\n%s\n\nThe code and the link expire in %d minutes. If you didn't try to log in, ignore this email.\n",
+			code,
+			s.loginLink(linkToken, clientType),
+			int(EmailLoginDuration.Minutes()),
+		),
+	})
+	if err != nil {
+		s.logger.Error().Msgf("EmailLoginService: error to send the login email: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// VerifyCode logs in with the code sent to the email, every call counts as an attempt.
+// customerrors.ErrInvalidLoginCode is returned when the code is wrong, used, expired or the
+// login is locked
+func (s *EmailLoginService) VerifyCode(ctx context.Context, email string, code string) (*db.User, *db.EmailLogin, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, nil, customerrors.ErrInvalidLoginCode
+	}
+
+	login, err := s.emailLoginRepository.ClaimEmailLoginAttempt(ctx, email, EmailLoginMaxAttempts)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) {
+			return nil, nil, customerrors.ErrInvalidLoginCode
+		}
+		return nil, nil, err
+	}
+
+	if !hmac.Equal([]byte(s.hashCode(email, strings.TrimSpace(code))), []byte(login.CodeHash)) {
+		return nil, nil, customerrors.ErrInvalidLoginCode
+	}
+
+	if err := s.emailLoginRepository.UseEmailLogin(ctx, login.ID.String()); err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) {
+			return nil, nil, customerrors.ErrInvalidLoginCode
+		}
+		return nil, nil, err
+	}
+
+	return s.login(ctx, login)
+}
+
+// VerifyLink logs in with the token of the link sent to the email, the link of the mobile app also
+// needs the verifier of its code challenge. customerrors.ErrInvalidLoginCode is returned when the
+// token is wrong, used or expired, or the verifier is wrong. A wrong verifier burns the link
+func (s *EmailLoginService) VerifyLink(ctx context.Context, linkToken string, codeVerifier string) (*db.User, *db.EmailLogin, error) {
+	if linkToken == "" {
+		return nil, nil, customerrors.ErrInvalidLoginCode
+	}
+
+	login, err := s.emailLoginRepository.UseEmailLoginLink(ctx, hashLinkToken(linkToken))
+	if err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) {
+			return nil, nil, customerrors.ErrInvalidLoginCode
+		}
+		return nil, nil, err
+	}
+
+	if login.CodeChallenge.Valid && !auth.VerifyCodeChallenge(codeVerifier, login.CodeChallenge.String) {
+		return nil, nil, customerrors.ErrInvalidLoginCode
+	}
+
+	return s.login(ctx, login)
+}
+
+// login returns the user of the email, receiving the code proves the email is verified
+func (s *EmailLoginService) login(ctx context.Context, login *db.EmailLogin) (*db.User, *db.EmailLogin, error) {
+	user, err := s.identityService.Login(ctx, EmailLoginProvider, &auth.OAuthUser{
+		Subject:       login.Email,
+		Email:         login.Email,
+		EmailVerified: true,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, login, nil
+}
+
+// loginLink is the url of the link, the mobile app opens its own and exchanges the token with
+// the verifier of its code challenge
+func (s *EmailLoginService) loginLink(linkToken string, clientType string) string {
+	query := url.Values{"token": {linkToken}}.Encode()
+
+	if clientType == repository.CLIENT_TYPE_MOBILE {
+		return s.appConfig.MobileAppSchema + "auth/email?" + query
+	}
+
+	return strings.TrimSuffix(s.appConfig.BaseURL, "/") + "/api/v1/auth/email/callback?" + query
+}
+
+func (s *EmailLoginService) hashCode(email string, code string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(email + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// deriveKey returns a key for a single purpose from the secret of the app
+func deriveKey(secret string, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+func hashLinkToken(linkToken string) string {
+	sum := sha256.Sum256([]byte(linkToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeEmail returns the lowercase address, customerrors.ErrInvalidEmail is returned when it's
+// not a bare address
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || len(email) > 255 {
+		return "", customerrors.ErrInvalidEmail
+	}
+
+	return email, nil
+}
+
+func generateLoginCode() (string, error) {
+	max := big.NewInt(1)
+	for range emailLoginCodeDigits {
+		max.Mul(max, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", emailLoginCodeDigits, n), nil
+}
+
+func generateLinkToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"golang-whatsapp-clone/config"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/mailer"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+	"golang.org/x/oauth2"
+)
+
+// fakeEmailLoginRepository keeps the logins in memory with the same rules as the queries, a login
+// replaces the previous ones under a lock like the transaction that locks the email
+type fakeEmailLoginRepository struct {
+	mutex  sync.Mutex
+	logins []*db.EmailLogin
+}
+
+func (r *fakeEmailLoginRepository) ReplaceEmailLogin(ctx context.Context, email string, codeHash string, linkTokenHash string, clientType string, codeChallenge string, expiresAt time.Time, resendInterval time.Duration) (*db.EmailLogin, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if latest := r.latestEmailLogin(email); latest != nil && time.Since(latest.CreatedAt.Time) < resendInterval {
+		return nil, customerrors.ErrLoginCodeRequested
+	}
+
+	logins := []*db.EmailLogin{}
+	for _, login := range r.logins {
+		if login.Email != email {
+			logins = append(logins, login)
+		}
+	}
+
+	login := &db.EmailLogin{
+		ID:            pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Email:         email,
+		CodeHash:      codeHash,
+		LinkTokenHash: linkTokenHash,
+		ClientType:    clientType,
+		CodeChallenge: pgtype.Text{String: codeChallenge, Valid: codeChallenge != ""},
+		ExpiresAt:     pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		CreatedAt:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	r.logins = append(logins, login)
+
+	return login, nil
+}
+
+func (r *fakeEmailLoginRepository) latestEmailLogin(email string) *db.EmailLogin {
+	for i := len(r.logins) - 1; i >= 0; i-- {
+		if r.logins[i].Email == email {
+			return r.logins[i]
+		}
+	}
+
+	return nil
+}
+
+func (r *fakeEmailLoginRepository) ClaimEmailLoginAttempt(ctx context.Context, email string, maxAttempts int) (*db.EmailLogin, error) {
+	login := r.latestEmailLogin(email)
+	if login == nil || login.UsedAt.Valid || login.ExpiresAt.Time.Before(time.Now()) || int(login.Attempts) >= maxAttempts {
+		return nil, customerrors.ErrResourceNotFound
+	}
+	login.Attempts++
+
+	return login, nil
+}
+
+func (r *fakeEmailLoginRepository) UseEmailLogin(ctx context.Context, loginID string) error {
+	for _, login := range r.logins {
+		if login.ID.String() == loginID && !login.UsedAt.Valid {
+			login.UsedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+			return nil
+		}
+	}
+
+	return customerrors.ErrResourceNotFound
+}
+
+func (r *fakeEmailLoginRepository) UseEmailLoginLink(ctx context.Context, linkTokenHash string) (*db.EmailLogin, error) {
+	for _, login := range r.logins {
+		if login.LinkTokenHash == linkTokenHash && !login.UsedAt.Valid && login.ExpiresAt.Time.After(time.Now()) {
+			login.UsedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+			return login, nil
+		}
+	}
+
+	return nil, customerrors.ErrResourceNotFound
+}
+
+func (r *fakeEmailLoginRepository) DeleteExpired(ctx context.Context) error {
+	return nil
+}
+
+// fakeMailer keeps the sent emails
+type fakeMailer struct {
+	mutex    sync.Mutex
+	messages []mailer.Message
+}
+
+func (m *fakeMailer) Send(ctx context.Context, message mailer.Message) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+var loginCodeRegexp = regexp.MustCompile(`code is (\d{6})`)
+
+// lastLogin returns the code and the link token of the last email
+func (m *fakeMailer) lastLogin(t *testing.T) (code string, linkToken string) {
+	t.Helper()
+
+	body := m.messages[len(m.messages)-1].Body
+	match := loginCodeRegexp.FindStringSubmatch(body)
+	if match == nil {
+		t.Fatalf("the email has no code: %s", body)
+	}
+
+	for line := range strings.SplitSeq(body, "\n") {
+		if link, err := url.Parse(line); err == nil && link.Query().Has("token") {
+			return match[1], link.Query().Get("token")
+		}
+	}
+
+	t.Fatalf("the email has no link: %s", body)
+	return "", ""
+}
+
+func newEmailLoginTestService() (*EmailLoginService, *fakeEmailLoginRepository, *fakeMailer) {
+	emailLoginRepository := &fakeEmailLoginRepository{}
+	identityService, _ := newIdentityTestService()
+	fakeMailer := &fakeMailer{}
+	logger := zerolog.Nop()
+	appConfig := &config.AppConfig{BaseURL: "http://localhost:4000", MobileAppSchema: "whatsapp://", JWTSecret: "secret"}
+
+	return NewEmailLoginService(emailLoginRepository, identityService, fakeMailer, appConfig, &logger), emailLoginRepository, fakeMailer
+}
+
+func TestEmailLoginWithCode(t *testing.T) {
+	emailLoginService, emailLoginRepository, fakeMailer := newEmailLoginTestService()
+	ctx := context.Background()
+
+	if err := emailLoginService.RequestLogin(ctx, " Ana@Example.com", "web", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	code, _ := fakeMailer.lastLogin(t)
+	if fakeMailer.messages[0].To != "ana@example.com" || strings.Contains(emailLoginRepository.logins[0].CodeHash, code) {
+		t.Errorf("expected the code to be sent to the normalized email and stored hashed, got %+v", emailLoginRepository.logins[0])
+	}
+
+	// the key of the hash is not the secret of the tokens
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("ana@example.com:" + code))
+	if emailLoginRepository.logins[0].CodeHash == hex.EncodeToString(mac.Sum(nil)) {
+		t.Error("expected the code to be hashed with a key derived from the secret")
+	}
+
+	user, login, err := emailLoginService.VerifyCode(ctx, "ana@example.com", code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if user.Email != "ana@example.com" || login.ClientType != "web" {
+		t.Errorf("unexpected login %+v of %+v", login, user)
+	}
+
+	if _, _, err := emailLoginService.VerifyCode(ctx, "ana@example.com", code); !errors.Is(err, customerrors.ErrInvalidLoginCode) {
+		t.Errorf("expected the code to be used once, got %v", err)
+	}
+}
+
+func TestEmailLoginLocksAfterMaxAttempts(t *testing.T) {
+	emailLoginService, _, fakeMailer := newEmailLoginTestService()
+	ctx := context.Background()
+
+	_ = emailLoginService.RequestLogin(ctx, "ana@example.com", "web", "")
+	code, _ := fakeMailer.lastLogin(t)
+
+	wrongCode := "000000"
+	if code == wrongCode {
+		wrongCode = "111111"
+	}
+
+	for range EmailLoginMaxAttempts {
+		if _, _, err := emailLoginService.VerifyCode(ctx, "ana@example.com", wrongCode); !errors.Is(err, customerrors.ErrInvalidLoginCode) {
+			t.Fatalf("expected the wrong code to be rejected, got %v", err)
+		}
+	}
+
+	if _, _, err := emailLoginService.VerifyCode(ctx, "ana@example.com", code); !errors.Is(err, customerrors.ErrInvalidLoginCode) {
+		t.Errorf("expected the login to be locked, got %v", err)
+	}
+}
+
+func TestEmailLoginWithLink(t *testing.T) {
+	emailLoginService, emailLoginRepository, fakeMailer := newEmailLoginTestService()
+	ctx := context.Background()
+	verifier := oauth2.GenerateVerifier()
+
+	for _, challenge := range []string{"", "plain-verifier"} {
+		if err := emailLoginService.RequestLogin(ctx, "ana@example.com", "mobile", challenge); !errors.Is(err, customerrors.ErrInvalidCodeChallenge) {
+			t.Errorf("expected the challenge %q to be rejected, got %v", challenge, err)
+		}
+	}
+
+	_ = emailLoginService.RequestLogin(ctx, "ana@example.com", "mobile", oauth2.S256ChallengeFromVerifier(verifier))
+	_, linkToken := fakeMailer.lastLogin(t)
+
+	if !strings.Contains(fakeMailer.messages[0].Body, "whatsapp://auth/email?token=") {
+		t.Errorf("expected the link of the mobile app, got %s", fakeMailer.messages[0].Body)
+	}
+
+	// an intercepted link is useless without the verifier, and a wrong guess burns it
+	if _, _, err := emailLoginService.VerifyLink(ctx, linkToken, oauth2.GenerateVerifier()); !errors.Is(err, customerrors.ErrInvalidLoginCode) {
+		t.Errorf("expected another verifier to be rejected, got %v", err)
+	}
+
+	if _, _, err := emailLoginService.VerifyLink(ctx, linkToken, verifier); !errors.Is(err, customerrors.ErrInvalidLoginCode) {
+		t.Errorf("expected the link to be burnt by the wrong verifier, got %v", err)
+	}
+
+	emailLoginRepository.logins[0].CreatedAt.Time = time.Now().Add(-EmailLoginResendInterval)
+	_ = emailLoginService.RequestLogin(ctx, "ana@example.com", "mobile", oauth2.S256ChallengeFromVerifier(verifier))
+	code, linkToken := fakeMailer.lastLogin(t)
+
+	user, login, err := emailLoginService.VerifyLink(ctx, linkToken, verifier)
+	if err != nil || user.Email != "ana@example.com" || login.ClientType != "mobile" {
+		t.Fatalf("expected the login of the link, got %+v %+v (%v)", user, login, err)
+	}
+
+	// the code and the link belong to the same login, using one uses the other
+	if _, _, err := emailLoginService.VerifyCode(ctx, "ana@example.com", code); !errors.Is(err, customerrors.ErrInvalidLoginCode) {
+		t.Errorf("expected the code of the used link to be rejected, got %v", err)
+	}
+}
+
+func TestEmailLoginResendInterval(t *testing.T) {
+	emailLoginService, emailLoginRepository, fakeMailer := newEmailLoginTestService()
+	ctx := context.Background()
+
+	if err := emailLoginService.RequestLogin(ctx, "not an email", "web", ""); !errors.Is(err, customerrors.ErrInvalidEmail) {
+		t.Errorf("expected the invalid email to be rejected, got %v", err)
+	}
+
+	_ = emailLoginService.RequestLogin(ctx, "ana@example.com", "web", "")
+	firstCode, _ := fakeMailer.lastLogin(t)
+
+	if err := emailLoginService.RequestLogin(ctx, "ana@example.com", "web", ""); !errors.Is(err, customerrors.ErrLoginCodeRequested) {
+		t.Fatalf("expected the second request to be too soon, got %v", err)
+	}
+
+	emailLoginRepository.logins[0].CreatedAt.Time = time.Now().Add(-EmailLoginResendInterval)
+	if err := emailLoginService.RequestLogin(ctx, "ana@example.com", "web", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the new login replaces the previous one
+	secondCode, _ := fakeMailer.lastLogin(t)
+	if len(emailLoginRepository.logins) != 1 || len(fakeMailer.messages) != 2 {
+		t.Fatalf("expected 1 login and 2 emails, got %d logins and %d emails", len(emailLoginRepository.logins), len(fakeMailer.messages))
+	}
+
+	if firstCode != secondCode {
+		if _, _, err := emailLoginService.VerifyCode(ctx, "ana@example.com", firstCode); !errors.Is(err, customerrors.ErrInvalidLoginCode) {
+			t.Errorf("expected the previous code to be rejected, got %v", err)
+		}
+	}
+}
+
+func TestEmailLoginConcurrentRequests(t *testing.T) {
+	emailLoginService, emailLoginRepository, fakeMailer := newEmailLoginTestService()
+	ctx := context.Background()
+
+	// the requests are sent at the same time, only one of them sends an email
+	errs := make([]error, 10)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = emailLoginService.RequestLogin(ctx, "ana@example.com", "web", "")
+		}()
+	}
+	wg.Wait()
+
+	sent := 0
+	for _, err := range errs {
+		if err == nil {
+			sent++
+			continue
+		}
+		if !errors.Is(err, customerrors.ErrLoginCodeRequested) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if sent != 1 || len(fakeMailer.messages) != 1 || len(emailLoginRepository.logins) != 1 {
+		t.Errorf("expected 1 request to send 1 email, got %d requests, %d emails and %d logins", sent, len(fakeMailer.messages), len(emailLoginRepository.logins))
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang-whatsapp-clone/auth"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// IdentityService finds the user of an account of an oauth provider. The accounts are linked by
+// email, so logging in with github after google returns the same user, but only when the
+// provider verified the email, otherwise anyone could take over an account by using its email
+type IdentityService struct {
+	identityRepository repository.IdentityRepository
+	logger             *zerolog.Logger
+}
+
+func NewIdentityService(
+	identityRepository repository.IdentityRepository,
+	logger *zerolog.Logger,
+) *IdentityService {
+	return &IdentityService{
+		identityRepository: identityRepository,
+		logger:             logger,
+	}
+}
+
+// Login returns the user linked to the account of the provider, the user is created or linked
+// by email on the first login. customerrors.ErrEmailNotVerified is returned when the account
+// is not linked yet and its email is not verified
+func (s *IdentityService) Login(ctx context.Context, provider string, oauthUser *auth.OAuthUser) (*db.User, error) {
+	user, err := s.identityRepository.GetUserByIdentity(ctx, provider, oauthUser.Subject)
+	if err == nil {
+		return s.identityRepository.UpdateUserFromProvider(ctx, user.ID.String(), oauthUser.Name, oauthUser.AvatarURL)
+	}
+
+	if !errors.Is(err, customerrors.ErrResourceNotFound) {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(oauthUser.Email))
+	if email == "" || !oauthUser.EmailVerified {
+		return nil, customerrors.ErrEmailNotVerified
+	}
+
+	user, err = s.identityRepository.UpsertUserByEmail(ctx, oauthUser.Name, email, oauthUser.AvatarURL)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.identityRepository.LinkIdentity(ctx, user.ID.String(), provider, oauthUser.Subject, email)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().Msgf("IdentityService: %s account %s linked to the user %s", provider, oauthUser.Subject, user.ID.String())
+
+	return user, nil
+}
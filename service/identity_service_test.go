@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang-whatsapp-clone/auth"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+)
+
+type fakeIdentityRepository struct {
+	// users by email
+	users map[string]*db.User
+	// user ids by provider and provider user id
+	identities map[string]string
+}
+
+func (r *fakeIdentityRepository) GetUserByIdentity(ctx context.Context, provider string, providerUserID string) (*db.User, error) {
+	userID, ok := r.identities[provider+"/"+providerUserID]
+	if !ok {
+		return nil, customerrors.ErrResourceNotFound
+	}
+
+	return r.getUser(userID)
+}
+
+func (r *fakeIdentityRepository) getUser(userID string) (*db.User, error) {
+	for _, user := range r.users {
+		if user.ID.String() == userID {
+			return user, nil
+		}
+	}
+
+	return nil, customerrors.ErrResourceNotFound
+}
+
+func (r *fakeIdentityRepository) UpsertUserByEmail(ctx context.Context, name string, email string, avatarURL string) (*db.User, error) {
+	if user, ok := r.users[email]; ok {
+		return user, nil
+	}
+
+	user := &db.User{
+		ID:    pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Name:  pgtype.Text{String: name, Valid: true},
+		Email: email,
+	}
+	r.users[email] = user
+
+	return user, nil
+}
+
+func (r *fakeIdentityRepository) UpdateUserFromProvider(ctx context.Context, userID string, name string, avatarURL string) (*db.User, error) {
+	return r.getUser(userID)
+}
+
+func (r *fakeIdentityRepository) LinkIdentity(ctx context.Context, userID string, provider string, providerUserID string, email string) (*db.UserIdentity, error) {
+	r.identities[provider+"/"+providerUserID] = userID
+	return &db.UserIdentity{Provider: provider, ProviderUserID: providerUserID, Email: email}, nil
+}
+
+func newIdentityTestService() (*IdentityService, *fakeIdentityRepository) {
+	identityRepository := &fakeIdentityRepository{users: map[string]*db.User{}, identities: map[string]string{}}
+	logger := zerolog.Nop()
+
+	return NewIdentityService(identityRepository, &logger), identityRepository
+}
+
+func TestLoginLinksAccountsByVerifiedEmail(t *testing.T) {
+	identityService, identityRepository := newIdentityTestService()
+	ctx := context.Background()
+
+	googleUser, err := identityService.Login(ctx, "google", &auth.OAuthUser{Subject: "g-1", Email: "Ana@Example.com", EmailVerified: true, Name: "Ana"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if googleUser.Email != "ana@example.com" {
+		t.Errorf("expected the email to be normalized, got %s", googleUser.Email)
+	}
+
+	gitHubUser, err := identityService.Login(ctx, "github", &auth.OAuthUser{Subject: "42", Email: "ana@example.com", EmailVerified: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gitHubUser.ID != googleUser.ID {
+		t.Error("expected the github account to be linked to the same user")
+	}
+
+	if len(identityRepository.identities) != 2 || len(identityRepository.users) != 1 {
+		t.Errorf("expected 2 identities of 1 user, got %d identities and %d users", len(identityRepository.identities), len(identityRepository.users))
+	}
+
+	// the next logins find the user by the identity, even when the email changed in the provider
+	again, err := identityService.Login(ctx, "github", &auth.OAuthUser{Subject: "42", Email: "ana@another.com"})
+	if err != nil || again.ID != googleUser.ID {
+		t.Errorf("expected the linked user, got %+v (%v)", again, err)
+	}
+}
+
+func TestLoginRejectsUnverifiedEmails(t *testing.T) {
+	identityService, identityRepository := newIdentityTestService()
+	ctx := context.Background()
+
+	// someone registers a provider account with the email of another user without verifying it
+	_, err := identityService.Login(ctx, "oidc", &auth.OAuthUser{Subject: "evil", Email: "ana@example.com"})
+	if !errors.Is(err, customerrors.ErrEmailNotVerified) {
+		t.Fatalf("expected the unverified email to be rejected, got %v", err)
+	}
+
+	if len(identityRepository.users) != 0 || len(identityRepository.identities) != 0 {
+		t.Error("expected no user or identity to be created")
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	"golang-whatsapp-clone/linkpreview"
+	"golang-whatsapp-clone/repository"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	linkPreviewWorkers   = 4
+	linkPreviewQueueSize = 256
+	linkPreviewTimeout   = 10 * time.Second
+)
+
+type linkPreviewJob struct {
+	messageID string
+	url       string
+	onReady   func(*db.MessageLinkPreview)
+}
+
+// LinkPreviewService unfurls the links of the messages in the background, so sending a
+// message never waits for a third party website
+type LinkPreviewService struct {
+	unfurler              *linkpreview.Unfurler
+	linkPreviewRepository repository.LinkPreviewRepository
+	logger                *zerolog.Logger
+	jobs                  chan linkPreviewJob
+}
+
+func NewLinkPreviewService(
+	unfurler *linkpreview.Unfurler,
+	linkPreviewRepository repository.LinkPreviewRepository,
+	logger *zerolog.Logger,
+) *LinkPreviewService {
+	s := &LinkPreviewService{
+		unfurler:              unfurler,
+		linkPreviewRepository: linkPreviewRepository,
+		logger:                logger,
+		jobs:                  make(chan linkPreviewJob, linkPreviewQueueSize),
+	}
+
+	for range linkPreviewWorkers {
+		go s.work()
+	}
+
+	return s
+}
+
+// Enqueue schedules the preview of the first link of a text message. onReady is called from
+// a worker goroutine once the preview is stored, it is not called when the link has no preview
+func (s *LinkPreviewService) Enqueue(message *db.Message, onReady func(*db.MessageLinkPreview)) {
+	if message.MessageType != repository.MESSAGE_TYPE_TEXT {
+		return
+	}
+
+	urls := linkpreview.ExtractURLs(message.Content)
+	if len(urls) == 0 {
+		return
+	}
+
+	job := linkPreviewJob{
+		messageID: message.ID.String(),
+		url:       urls[0],
+		onReady:   onReady,
+	}
+
+	// the preview is a nice to have, when the queue is full the message goes without it
+	select {
+	case s.jobs <- job:
+	default:
+		s.logger.Warn().Msgf("link preview queue is full, skipping the preview of message %s", job.messageID)
+	}
+}
+
+// GetLinkPreviews returns the stored previews by message id
+func (s *LinkPreviewService) GetLinkPreviews(ctx context.Context, messageIDs []string) (map[string]db.MessageLinkPreview, error) {
+	result := make(map[string]db.MessageLinkPreview, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return result, nil
+	}
+
+	previews, err := s.linkPreviewRepository.GetLinkPreviews(ctx, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, preview := range *previews {
+		result[preview.MessageID.String()] = preview
+	}
+
+	return result, nil
+}
+
+func (s *LinkPreviewService) work() {
+	for job := range s.jobs {
+		s.process(job)
+	}
+}
+
+func (s *LinkPreviewService) process(job linkPreviewJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), linkPreviewTimeout)
+	defer cancel()
+
+	preview, err := s.unfurler.Unfurl(ctx, job.url)
+	if err != nil {
+		s.logger.Debug().Msgf("no link preview for %s: %v", job.url, err)
+		return
+	}
+
+	saved, err := s.linkPreviewRepository.SaveLinkPreview(ctx, job.messageID, preview)
+	if err != nil {
+		s.logger.Error().Msgf("error to save the link preview of message %s: %v", job.messageID, err)
+		return
+	}
+
+	if job.onReady != nil {
+		job.onReady(saved)
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	"golang-whatsapp-clone/linkpreview"
+	"golang-whatsapp-clone/repository"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+)
+
+// fakePageFetcher serves a page with a title for every url and keeps the fetched urls
+type fakePageFetcher struct {
+	mu      sync.Mutex
+	fetched []string
+}
+
+func (f *fakePageFetcher) Fetch(ctx context.Context, rawURL string) (*linkpreview.Page, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.fetched = append(f.fetched, rawURL)
+
+	return &linkpreview.Page{
+		URL:         rawURL,
+		ContentType: "text/html",
+		Body:        []byte("<html><head><title>" + rawURL + "</title></head></html>"),
+	}, nil
+}
+
+type fakeLinkPreviewRepository struct {
+	repository.LinkPreviewRepository
+	mu       sync.Mutex
+	previews map[string]db.MessageLinkPreview
+}
+
+func (r *fakeLinkPreviewRepository) SaveLinkPreview(ctx context.Context, messageID string, preview *linkpreview.Preview) (*db.MessageLinkPreview, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := db.MessageLinkPreview{
+		MessageID: pgtype.UUID{Bytes: uuid.MustParse(messageID), Valid: true},
+		Url:       preview.URL,
+		Title:     preview.Title,
+	}
+	r.previews[messageID] = saved
+
+	return &saved, nil
+}
+
+func (r *fakeLinkPreviewRepository) GetLinkPreviews(ctx context.Context, messageIDs []string) (*[]db.MessageLinkPreview, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previews := []db.MessageLinkPreview{}
+	for _, messageID := range messageIDs {
+		if preview, ok := r.previews[messageID]; ok {
+			previews = append(previews, preview)
+		}
+	}
+
+	return &previews, nil
+}
+
+func TestLinkPreviewService(t *testing.T) {
+	fetcher := &fakePageFetcher{}
+	linkPreviewRepository := &fakeLinkPreviewRepository{previews: map[string]db.MessageLinkPreview{}}
+	logger := zerolog.Nop()
+	linkPreviewService := NewLinkPreviewService(linkpreview.NewUnfurler(fetcher, time.Hour), linkPreviewRepository, &logger)
+
+	newMessage := func(messageType string, content string) *db.Message {
+		return &db.Message{
+			ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			MessageType: messageType,
+			Content:     content,
+		}
+	}
+
+	ready := make(chan *db.MessageLinkPreview, 4)
+	onReady := func(preview *db.MessageLinkPreview) { ready <- preview }
+
+	// only the text messages with links are unfurled
+	linkPreviewService.Enqueue(newMessage(repository.MESSAGE_TYPE_IMAGE, "https://caption.example.com"), onReady)
+	linkPreviewService.Enqueue(newMessage(repository.MESSAGE_TYPE_TEXT, "no links here"), onReady)
+
+	first := newMessage(repository.MESSAGE_TYPE_TEXT, "look at https://go.dev/blog, https://pkg.go.dev and https://go.dev/blog again")
+	linkPreviewService.Enqueue(first, onReady)
+
+	// the preview is ready before the second message is sent, so its link comes from the cache
+	receiveLinkPreview(t, ready)
+
+	second := newMessage(repository.MESSAGE_TYPE_TEXT, "https://go.dev/blog")
+	linkPreviewService.Enqueue(second, onReady)
+	receiveLinkPreview(t, ready)
+
+	select {
+	case preview := <-ready:
+		t.Fatalf("expected only the previews of the two messages, got %+v", preview)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	fetcher.mu.Lock()
+	fetched := slices.Clone(fetcher.fetched)
+	fetcher.mu.Unlock()
+
+	if !slices.Equal(fetched, []string{"https://go.dev/blog"}) {
+		t.Errorf("expected only the first link to be fetched once, got %v", fetched)
+	}
+
+	previews, err := linkPreviewService.GetLinkPreviews(context.Background(), []string{first.ID.String(), second.ID.String(), uuid.NewString()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(previews) != 2 || previews[first.ID.String()].Url != "https://go.dev/blog" || previews[second.ID.String()].Title != "https://go.dev/blog" {
+		t.Errorf("expected the previews of both messages by message id, got %+v", previews)
+	}
+}
+
+func receiveLinkPreview(t *testing.T, ready <-chan *db.MessageLinkPreview) *db.MessageLinkPreview {
+	t.Helper()
+
+	select {
+	case preview := <-ready:
+		return preview
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the link preview to be ready")
+		return nil
+	}
+}
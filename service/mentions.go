@@ -0,0 +1,92 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// MentionCandidate is a user that can be mentioned in a conversation, tokens are the texts
+// that can follow the @ to reference the user (the display name, the email username, etc)
+type MentionCandidate struct {
+	UserID string
+	Tokens []string
+}
+
+// Mention is a parsed @mention. Offset and Length are counted in unicode code points
+// and include the @ character
+type Mention struct {
+	UserID string
+	Offset int
+	Length int
+}
+
+type mentionToken struct {
+	userID string
+	runes  []rune
+}
+
+// ParseMentions finds the @mentions in the content that reference one of the candidates.
+// The matching is case insensitive and prefers the longest token, so "@Ana Maria" mentions
+// "Ana Maria" instead of "Ana" when both are candidates. A token only matches when it is
+// followed by the end of the text or by a character that can't be part of a name.
+func ParseMentions(content string, candidates []MentionCandidate) []Mention {
+	tokens := []mentionToken{}
+	for _, candidate := range candidates {
+		for _, token := range candidate.Tokens {
+			token = strings.TrimSpace(token)
+			if token == "" {
+				continue
+			}
+			tokens = append(tokens, mentionToken{userID: candidate.UserID, runes: []rune(token)})
+		}
+	}
+
+	slices.SortStableFunc(tokens, func(a, b mentionToken) int {
+		return len(b.runes) - len(a.runes)
+	})
+
+	mentions := []Mention{}
+	if len(tokens) == 0 {
+		return mentions
+	}
+
+	text := []rune(content)
+
+	for i := 0; i < len(text); i++ {
+		if text[i] != '@' || (i > 0 && isMentionNameRune(text[i-1])) {
+			continue
+		}
+
+		for _, token := range tokens {
+			end := i + 1 + len(token.runes)
+			if end > len(text) || !equalFoldRunes(text[i+1:end], token.runes) {
+				continue
+			}
+
+			if end < len(text) && isMentionNameRune(text[end]) {
+				continue
+			}
+
+			mentions = append(mentions, Mention{
+				UserID: token.userID,
+				Offset: i,
+				Length: end - i,
+			})
+
+			// continue after the mention, -1 because the loop increments it
+			i = end - 1
+			break
+		}
+	}
+
+	return mentions
+}
+
+func isMentionNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func equalFoldRunes(a []rune, b []rune) bool {
+	return strings.EqualFold(string(a), string(b))
+}
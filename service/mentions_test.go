@@ -0,0 +1,63 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	candidates := []MentionCandidate{
+		{UserID: "user-ana", Tokens: []string{"Ana", "ana.lopez"}},
+		{UserID: "user-ana-maria", Tokens: []string{"Ana Maria"}},
+		{UserID: "user-bob", Tokens: []string{"Bob"}},
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    []Mention
+	}{
+		{
+			name:    "single mention",
+			content: "hi @Bob!",
+			want:    []Mention{{UserID: "user-bob", Offset: 3, Length: 4}},
+		},
+		{
+			name:    "prefers the longest name",
+			content: "@ana maria and @Ana",
+			want: []Mention{
+				{UserID: "user-ana-maria", Offset: 0, Length: 10},
+				{UserID: "user-ana", Offset: 15, Length: 4},
+			},
+		},
+		{
+			name:    "email username",
+			content: "ping @ana.lopez.",
+			want:    []Mention{{UserID: "user-ana", Offset: 5, Length: 10}},
+		},
+		{
+			name:    "name must end at a word boundary",
+			content: "@Bobby is not bob",
+			want:    []Mention{},
+		},
+		{
+			name:    "emails are not mentions",
+			content: "write to me@Bob",
+			want:    []Mention{},
+		},
+		{
+			name:    "offsets are counted in code points",
+			content: "👋 @Bob",
+			want:    []Mention{{UserID: "user-bob", Offset: 2, Length: 4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseMentions(tt.content, candidates)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMentions(%q) = %+v, want %+v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"golang-whatsapp-clone/repository"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	messageReaperInterval  = 30 * time.Second
+	messageReaperBatchSize = 500
+)
+
+// MessageReaper removes the disappearing messages once they expire
+type MessageReaper struct {
+	messageRepository repository.MessageRepository
+	logger            *zerolog.Logger
+	// called after every batch with the ids of the messages removed from each conversation
+	onExpired func(conversationID string, messageIDs []string)
+}
+
+func NewMessageReaper(
+	messageRepository repository.MessageRepository,
+	logger *zerolog.Logger,
+	onExpired func(conversationID string, messageIDs []string),
+) *MessageReaper {
+	return &MessageReaper{
+		messageRepository: messageRepository,
+		logger:            logger,
+		onExpired:         onExpired,
+	}
+}
+
+// Run removes the expired messages every messageReaperInterval until the context is cancelled
+func (r *MessageReaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(messageReaperInterval)
+	defer ticker.Stop()
+
+	for {
+		r.reap(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reap deletes batches until there is nothing left, so a backlog doesn't wait for the next tick
+func (r *MessageReaper) reap(ctx context.Context) {
+	for ctx.Err() == nil {
+		deleted, err := r.messageRepository.DeleteExpiredMessages(ctx, messageReaperBatchSize)
+		if err != nil {
+			r.logger.Error().Msgf("error to delete the expired messages: %v", err)
+			return
+		}
+
+		byConversation := map[string][]string{}
+		for _, message := range *deleted {
+			conversationID := message.ConversationID.String()
+			byConversation[conversationID] = append(byConversation[conversationID], message.ID.String())
+		}
+
+		for conversationID, messageIDs := range byConversation {
+			r.onExpired(conversationID, messageIDs)
+		}
+
+		if len(*deleted) < messageReaperBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	"golang-whatsapp-clone/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+)
+
+// fakeExpiredMessageRepository returns the batches in order, then empty ones
+type fakeExpiredMessageRepository struct {
+	repository.MessageRepository
+	batches [][]db.DeleteExpiredMessagesRow
+	calls   int
+	err     error
+}
+
+func (r *fakeExpiredMessageRepository) DeleteExpiredMessages(ctx context.Context, limit int32) (*[]db.DeleteExpiredMessagesRow, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	batch := []db.DeleteExpiredMessagesRow{}
+	if len(r.batches) > 0 {
+		batch, r.batches = r.batches[0], r.batches[1:]
+	}
+
+	return &batch, nil
+}
+
+func expiredMessages(conversationID string, count int) []db.DeleteExpiredMessagesRow {
+	rows := make([]db.DeleteExpiredMessagesRow, 0, count)
+	for range count {
+		rows = append(rows, db.DeleteExpiredMessagesRow{
+			ID:             pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			ConversationID: pgtype.UUID{Bytes: uuid.MustParse(conversationID), Valid: true},
+		})
+	}
+
+	return rows
+}
+
+func TestMessageReaperReap(t *testing.T) {
+	first := uuid.NewString()
+	second := uuid.NewString()
+
+	// a full batch is followed by another one right away, the partial one ends the round
+	fullBatch := append(expiredMessages(first, messageReaperBatchSize-1), expiredMessages(second, 1)...)
+	messageRepository := &fakeExpiredMessageRepository{
+		batches: [][]db.DeleteExpiredMessagesRow{fullBatch, expiredMessages(first, 2)},
+	}
+
+	expired := map[string]int{}
+	notifications := 0
+	logger := zerolog.Nop()
+	reaper := NewMessageReaper(messageRepository, &logger, func(conversationID string, messageIDs []string) {
+		notifications++
+		expired[conversationID] += len(messageIDs)
+	})
+
+	reaper.reap(context.Background())
+
+	if messageRepository.calls != 2 {
+		t.Errorf("expected 2 batches, got %d", messageRepository.calls)
+	}
+
+	// one notification per conversation and batch
+	if notifications != 3 {
+		t.Errorf("expected 3 notifications, got %d", notifications)
+	}
+
+	if expired[first] != messageReaperBatchSize+1 || expired[second] != 1 {
+		t.Errorf("unexpected expired messages by conversation %v", expired)
+	}
+}
+
+func TestMessageReaperReapError(t *testing.T) {
+	messageRepository := &fakeExpiredMessageRepository{err: errors.New("connection lost")}
+	logger := zerolog.Nop()
+	reaper := NewMessageReaper(messageRepository, &logger, func(conversationID string, messageIDs []string) {
+		t.Error("expected no notification when the delete fails")
+	})
+
+	reaper.reap(context.Background())
+
+	if messageRepository.calls != 1 {
+		t.Errorf("expected the round to stop after the error, got %d calls", messageRepository.calls)
+	}
+}
+
+func TestMessageReaperRunStops(t *testing.T) {
+	messageRepository := &fakeExpiredMessageRepository{}
+	logger := zerolog.Nop()
+	reaper := NewMessageReaper(messageRepository, &logger, func(conversationID string, messageIDs []string) {})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		reaper.Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected Run to return once the context is cancelled")
+	}
+}
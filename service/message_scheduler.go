@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	messageSchedulerInterval  = 5 * time.Second
+	messageSchedulerBatchSize = 50
+
+	// a claimed message not sent after this time belongs to an instance that stopped
+	scheduledMessageClaimTimeout = 5 * time.Minute
+	// how long a claimed batch can take to be sent once the scheduler is stopped, well under
+	// the claim timeout
+	scheduledMessageBatchTimeout = time.Minute
+)
+
+// MessageScheduler sends the scheduled messages when they are due. Several instances of the
+// server can run it at the same time, every message is claimed by only one of them
+type MessageScheduler struct {
+	scheduledMessageRepository repository.ScheduledMessageRepository
+	messageService             *MessageService
+	logger                     *zerolog.Logger
+	// called after a message is created, to broadcast it like any other message
+	onSent func(message *db.Message, mentions []Mention)
+}
+
+func NewMessageScheduler(
+	scheduledMessageRepository repository.ScheduledMessageRepository,
+	messageService *MessageService,
+	logger *zerolog.Logger,
+	onSent func(message *db.Message, mentions []Mention),
+) *MessageScheduler {
+	return &MessageScheduler{
+		scheduledMessageRepository: scheduledMessageRepository,
+		messageService:             messageService,
+		logger:                     logger,
+		onSent:                     onSent,
+	}
+}
+
+// Run sends the due messages every messageSchedulerInterval until the context is cancelled
+func (s *MessageScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(messageSchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		s.failStaleClaims(ctx)
+		s.sendDueMessages(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *MessageScheduler) failStaleClaims(ctx context.Context) {
+	failed, err := s.scheduledMessageRepository.FailStaleScheduledMessages(ctx, time.Now().Add(-scheduledMessageClaimTimeout))
+	if err != nil {
+		s.logger.Error().Msgf("error to fail the stale scheduled messages: %v", err)
+		return
+	}
+
+	if failed > 0 {
+		s.logger.Warn().Msgf("%d scheduled messages were claimed but never sent, they were marked as failed", failed)
+	}
+}
+
+func (s *MessageScheduler) sendDueMessages(ctx context.Context) {
+	for ctx.Err() == nil {
+		claimed, err := s.scheduledMessageRepository.ClaimDueScheduledMessages(ctx, messageSchedulerBatchSize)
+		if err != nil {
+			s.logger.Error().Msgf("error to claim the due scheduled messages: %v", err)
+			return
+		}
+
+		// the claimed messages are sent even when the scheduler is stopped meanwhile, otherwise
+		// they would stay claimed until they're marked as failed
+		batchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), scheduledMessageBatchTimeout)
+		for _, scheduled := range *claimed {
+			s.send(batchCtx, scheduled)
+		}
+		cancel()
+
+		if len(*claimed) < messageSchedulerBatchSize {
+			return
+		}
+	}
+}
+
+func (s *MessageScheduler) send(ctx context.Context, scheduled db.ScheduledMessage) {
+	scheduledID := scheduled.ID.String()
+	conversationID := scheduled.ConversationID.String()
+	senderID := scheduled.SenderID.String()
+
+	var replyToMessageID *string
+	if scheduled.ReplyToMessageID.Valid {
+		replyID := scheduled.ReplyToMessageID.String()
+		replyToMessageID = &replyID
+	}
+
+	message, mentions, err := s.messageService.CreateMessage(ctx, conversationID, senderID, scheduled.Content, scheduled.MessageType, replyToMessageID)
+	if errors.Is(err, customerrors.ErrForbidden) {
+		// the sender left the conversation after scheduling it
+		s.logger.Warn().Msgf("scheduled message %s not sent, the sender is not a participant anymore", scheduledID)
+		s.markFailed(ctx, scheduledID)
+		return
+	}
+	if errors.Is(err, customerrors.ErrInvalidReply) {
+		s.logger.Warn().Msgf("scheduled message %s not sent, the replied message was deleted", scheduledID)
+		s.markFailed(ctx, scheduledID)
+		return
+	}
+	if err != nil {
+		s.logger.Error().Msgf("error to send the scheduled message %s: %v", scheduledID, err)
+		s.markFailed(ctx, scheduledID)
+		return
+	}
+
+	err = s.scheduledMessageRepository.MarkScheduledMessageSent(ctx, scheduledID, message.ID.String())
+	if err != nil {
+		// it stays in SENDING, so it's marked as failed later but never sent twice
+		s.logger.Error().Msgf("error to mark the scheduled message %s as sent: %v", scheduledID, err)
+	}
+
+	s.onSent(message, mentions)
+}
+
+func (s *MessageScheduler) markFailed(ctx context.Context, scheduledID string) {
+	err := s.scheduledMessageRepository.MarkScheduledMessageFailed(ctx, scheduledID)
+	if err != nil {
+		s.logger.Error().Msgf("error to mark the scheduled message %s as failed: %v", scheduledID, err)
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	"golang-whatsapp-clone/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+)
+
+// fakeSchedulerRepository returns the claimed batches in order and keeps what happened to every
+// scheduled message
+type fakeSchedulerRepository struct {
+	repository.ScheduledMessageRepository
+	batches       [][]db.ScheduledMessage
+	claims        int
+	sent          map[string]string
+	failed        []string
+	claimedBefore time.Time
+}
+
+func (r *fakeSchedulerRepository) ClaimDueScheduledMessages(ctx context.Context, limit int32) (*[]db.ScheduledMessage, error) {
+	r.claims++
+
+	batch := []db.ScheduledMessage{}
+	if len(r.batches) > 0 {
+		batch, r.batches = r.batches[0], r.batches[1:]
+	}
+
+	return &batch, nil
+}
+
+func (r *fakeSchedulerRepository) MarkScheduledMessageSent(ctx context.Context, scheduledMessageID string, messageID string) error {
+	r.sent[scheduledMessageID] = messageID
+	return nil
+}
+
+func (r *fakeSchedulerRepository) MarkScheduledMessageFailed(ctx context.Context, scheduledMessageID string) error {
+	r.failed = append(r.failed, scheduledMessageID)
+	return nil
+}
+
+func (r *fakeSchedulerRepository) FailStaleScheduledMessages(ctx context.Context, claimedBefore time.Time) (int64, error) {
+	r.claimedBefore = claimedBefore
+	return 0, nil
+}
+
+// fakeCreateMessageRepository fails the messages with the content "fail"
+type fakeCreateMessageRepository struct {
+	repository.MessageRepository
+	created int
+}
+
+func (r *fakeCreateMessageRepository) CreateMessage(ctx context.Context, conversationID string, senderID string, content string, messageType string, replyToMessageID *string) (*db.Message, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if content == "fail" {
+		return nil, errors.New("connection lost")
+	}
+
+	r.created++
+
+	return &db.Message{
+		ID:             pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		ConversationID: pgtype.UUID{Bytes: uuid.MustParse(conversationID), Valid: true},
+		Content:        content,
+	}, nil
+}
+
+func scheduledMessage(conversationID string, content string) db.ScheduledMessage {
+	return db.ScheduledMessage{
+		ID:             pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		ConversationID: pgtype.UUID{Bytes: uuid.MustParse(conversationID), Valid: true},
+		SenderID:       pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Content:        content,
+		MessageType:    repository.MESSAGE_TYPE_TEXT,
+	}
+}
+
+func newTestMessageScheduler(conversationIDs []string, batches [][]db.ScheduledMessage) (*MessageScheduler, *fakeSchedulerRepository, *[]*db.Message) {
+	scheduledMessageRepository := &fakeSchedulerRepository{batches: batches, sent: map[string]string{}}
+	participantRepository := &fakeMembershipParticipantRepository{conversationIDs: conversationIDs}
+	messageService := NewMessageService(&fakeCreateMessageRepository{}, participantRepository, nil)
+	logger := zerolog.Nop()
+
+	published := &[]*db.Message{}
+	scheduler := NewMessageScheduler(scheduledMessageRepository, messageService, &logger, func(message *db.Message, mentions []Mention) {
+		*published = append(*published, message)
+	})
+
+	return scheduler, scheduledMessageRepository, published
+}
+
+func TestMessageSchedulerSendDueMessages(t *testing.T) {
+	conversationID := uuid.NewString()
+	leftConversationID := uuid.NewString()
+
+	// a full batch is followed by another claim right away, the partial one ends the round
+	fullBatch := []db.ScheduledMessage{scheduledMessage(leftConversationID, "hi")}
+	for range messageSchedulerBatchSize - 1 {
+		fullBatch = append(fullBatch, scheduledMessage(conversationID, "hi"))
+	}
+	failing := scheduledMessage(conversationID, "fail")
+
+	scheduler, scheduledMessageRepository, published := newTestMessageScheduler(
+		[]string{conversationID},
+		[][]db.ScheduledMessage{fullBatch, {failing}},
+	)
+
+	scheduler.sendDueMessages(context.Background())
+
+	if scheduledMessageRepository.claims != 2 {
+		t.Errorf("expected 2 claims, got %d", scheduledMessageRepository.claims)
+	}
+
+	if len(scheduledMessageRepository.sent) != messageSchedulerBatchSize-1 || len(*published) != messageSchedulerBatchSize-1 {
+		t.Errorf("expected %d messages sent and published, got %d sent and %d published", messageSchedulerBatchSize-1, len(scheduledMessageRepository.sent), len(*published))
+	}
+
+	sentMessageIDs := map[string]bool{}
+	for _, messageID := range scheduledMessageRepository.sent {
+		sentMessageIDs[messageID] = true
+	}
+	for _, message := range *published {
+		if !sentMessageIDs[message.ID.String()] {
+			t.Errorf("expected the published message %s to be marked as sent", message.ID.String())
+		}
+	}
+
+	// the sender left the conversation of the first one, the last one couldn't be created
+	failed := scheduledMessageRepository.failed
+	if len(failed) != 2 || failed[0] != fullBatch[0].ID.String() || failed[1] != failing.ID.String() {
+		t.Errorf("expected the first and the last scheduled messages to fail, got %v", failed)
+	}
+}
+
+func TestMessageSchedulerStopsAfterTheClaimedBatch(t *testing.T) {
+	conversationID := uuid.NewString()
+	batch := []db.ScheduledMessage{}
+	for range messageSchedulerBatchSize {
+		batch = append(batch, scheduledMessage(conversationID, "hi"))
+	}
+
+	scheduler, scheduledMessageRepository, _ := newTestMessageScheduler([]string{conversationID}, [][]db.ScheduledMessage{batch, batch})
+
+	// the scheduler is stopped after the first message of the batch is sent
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	onSent := scheduler.onSent
+	scheduler.onSent = func(message *db.Message, mentions []Mention) {
+		onSent(message, mentions)
+		cancel()
+	}
+
+	scheduler.sendDueMessages(ctx)
+
+	if len(scheduledMessageRepository.failed) != 0 {
+		t.Errorf("expected no claimed message to fail, got %v", scheduledMessageRepository.failed)
+	}
+
+	if len(scheduledMessageRepository.sent) != messageSchedulerBatchSize {
+		t.Errorf("expected the %d claimed messages to be sent, got %d", messageSchedulerBatchSize, len(scheduledMessageRepository.sent))
+	}
+
+	// nothing else is claimed once it's stopped
+	if scheduledMessageRepository.claims != 1 {
+		t.Errorf("expected 1 claim, got %d", scheduledMessageRepository.claims)
+	}
+}
+
+func TestMessageSchedulerRun(t *testing.T) {
+	scheduler, scheduledMessageRepository, _ := newTestMessageScheduler(nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected Run to return once the context is cancelled")
+	}
+
+	// the claims older than the timeout are failed before sending
+	staleAt := time.Now().Add(-scheduledMessageClaimTimeout)
+	if scheduledMessageRepository.claimedBefore.Sub(staleAt).Abs() > time.Second {
+		t.Errorf("expected the claims before %v to be failed, got %v", staleAt, scheduledMessageRepository.claimedBefore)
+	}
+}
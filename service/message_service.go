@@ -4,27 +4,106 @@ import (
 	"context"
 	"errors"
 	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
 	"golang-whatsapp-clone/repository"
+	"slices"
+	"strings"
+)
+
+const (
+	// same limit WhatsApp uses to slow down the spread of chain messages
+	MaxForwardTargets = 5
+
+	// how deep the thread query follows the replies of replies
+	maxThreadDepth = 50
 )
 
 type MessageService struct {
-	MessageRepository repository.MessageRepository
+	MessageRepository     repository.MessageRepository
+	participantRepository repository.ParticipantRepository
+	mentionRepository     repository.MentionRepository
 }
 
-func NewMessageService(messageRepository repository.MessageRepository) *MessageService {
+func NewMessageService(
+	messageRepository repository.MessageRepository,
+	participantRepository repository.ParticipantRepository,
+	mentionRepository repository.MentionRepository,
+) *MessageService {
 	return &MessageService{
-		MessageRepository: messageRepository,
+		MessageRepository:     messageRepository,
+		participantRepository: participantRepository,
+		mentionRepository:     mentionRepository,
 	}
 }
 
-func (s *MessageService) CreateMessage(ctx context.Context, conversationID string, senderID string, content string, messageType string, replyToMessageID *string) (*db.Message, error) {
+// CreateMessage sanitizes and stores the message and the @mentions found in its content.
+// Text messages can't be empty, the other types can because the content is the caption.
+// Only the active participants of the conversation can send messages to it, and only reply to its messages
+func (s *MessageService) CreateMessage(ctx context.Context, conversationID string, senderID string, content string, messageType string, replyToMessageID *string) (*db.Message, []Mention, error) {
+	_, err := ensureParticipant(ctx, s.participantRepository, senderID, conversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err = prepareMessageContent(content, messageType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = ensureReplyTarget(ctx, s.MessageRepository, s.participantRepository, senderID, conversationID, replyToMessageID)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	message, err := s.MessageRepository.CreateMessage(ctx, conversationID, senderID, content, messageType, replyToMessageID)
 
 	if err != nil {
-		return nil, errors.New("here was an error when creating the message")
+		return nil, nil, errors.New("here was an error when creating the message")
+	}
+
+	mentions := s.saveMentions(ctx, message)
+
+	return message, mentions, nil
+}
+
+// prepareMessageContent returns the sanitized content or an error when it can't be sent
+func prepareMessageContent(content string, messageType string) (string, error) {
+	content = SanitizeMessageContent(content)
+
+	if content == "" && messageType == repository.MESSAGE_TYPE_TEXT {
+		return "", customerrors.ErrEmptyMessage
+	}
+
+	if isMessageTooLong(content) {
+		return "", customerrors.ErrMessageTooLong
+	}
+
+	return content, nil
+}
+
+// GetMentions returns the mentions grouped by message id
+func (s *MessageService) GetMentions(ctx context.Context, messageIDs []string) (map[string][]db.GetMessageMentionsRow, error) {
+	result := make(map[string][]db.GetMessageMentionsRow, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return result, nil
+	}
+
+	mentions, err := s.mentionRepository.GetMessageMentions(ctx, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, mention := range *mentions {
+		messageID := mention.MessageID.String()
+		result[messageID] = append(result[messageID], mention)
 	}
 
-	return message, nil
+	return result, nil
+}
+
+// GetUserMentions returns the messages where the user was mentioned, newest first
+func (s *MessageService) GetUserMentions(ctx context.Context, userID string, limit int32, offset int32) (*[]db.GetUserMentionsRow, error) {
+	return s.mentionRepository.GetUserMentions(ctx, userID, limit, offset)
 }
 
 func (s *MessageService) GetMessages(ctx context.Context, conversationID string, limit int32, offset int32) (*[]db.GetConversationMessagesRow, error) {
@@ -36,3 +115,129 @@ func (s *MessageService) GetMessages(ctx context.Context, conversationID string,
 
 	return result, nil
 }
+
+// ForwardMessage copies the message into every target conversation on behalf of the user.
+// The user must be able to read the source message and be a participant of all the targets,
+// otherwise nothing is forwarded
+func (s *MessageService) ForwardMessage(ctx context.Context, userID string, messageID string, targetConversationIDs []string) ([]*db.Message, error) {
+	targets := slices.Compact(slices.Sorted(slices.Values(targetConversationIDs)))
+	if len(targets) == 0 || len(targets) > MaxForwardTargets {
+		return nil, customerrors.ErrTooManyForwardTargets
+	}
+
+	source, err := s.MessageRepository.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if source.IsDeleted.Bool {
+		return nil, customerrors.ErrResourceNotFound
+	}
+
+	_, err = ensureParticipant(ctx, s.participantRepository, userID, source.ConversationID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, conversationID := range targets {
+		_, err = ensureParticipant(ctx, s.participantRepository, userID, conversationID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.MessageRepository.ForwardMessage(ctx, source.ID.String(), targets, userID)
+}
+
+// GetMessageReplies returns the direct replies of the message, oldest first
+func (s *MessageService) GetMessageReplies(ctx context.Context, userID string, messageID string, limit int32, offset int32) (*[]db.GetMessageRepliesRow, error) {
+	_, _, err := getReadableMessage(ctx, s.MessageRepository, s.participantRepository, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.MessageRepository.GetMessageReplies(ctx, messageID, limit, offset)
+}
+
+// GetMessageThread returns the message and all its nested replies, oldest first
+func (s *MessageService) GetMessageThread(ctx context.Context, userID string, messageID string, limit int32, offset int32) (*[]db.GetMessageThreadRow, error) {
+	_, _, err := getReadableMessage(ctx, s.MessageRepository, s.participantRepository, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.MessageRepository.GetMessageThread(ctx, messageID, maxThreadDepth, limit, offset)
+}
+
+// CountReplies returns the number of direct replies by message id, messages without replies are not included
+func (s *MessageService) CountReplies(ctx context.Context, messageIDs []string) (map[string]int32, error) {
+	result := make(map[string]int32, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return result, nil
+	}
+
+	counts, err := s.MessageRepository.CountMessageReplies(ctx, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, count := range *counts {
+		result[count.MessageID.String()] = count.ReplyCount
+	}
+
+	return result, nil
+}
+
+// saveMentions parses the @mentions of text messages against the other participants of the conversation
+// and stores them. Mentions are a best effort feature: if something fails the message is still sent
+func (s *MessageService) saveMentions(ctx context.Context, message *db.Message) []Mention {
+	if message.MessageType != repository.MESSAGE_TYPE_TEXT || !strings.Contains(message.Content, "@") {
+		return nil
+	}
+
+	users, err := s.participantRepository.GetParticipantUsers(ctx, message.ConversationID.String())
+	if err != nil {
+		return nil
+	}
+
+	senderID := message.SenderID.String()
+	candidates := make([]MentionCandidate, 0, len(*users))
+
+	for _, user := range *users {
+		userID := user.ID.String()
+		if userID == senderID {
+			continue
+		}
+
+		tokens := []string{}
+		if user.Name.Valid {
+			tokens = append(tokens, user.Name.String)
+		}
+		if username, _, found := strings.Cut(user.Email, "@"); found {
+			tokens = append(tokens, username)
+		}
+
+		candidates = append(candidates, MentionCandidate{UserID: userID, Tokens: tokens})
+	}
+
+	mentions := ParseMentions(message.Content, candidates)
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	newMentions := make([]repository.NewMention, 0, len(mentions))
+	for _, mention := range mentions {
+		newMentions = append(newMentions, repository.NewMention{
+			UserID: mention.UserID,
+			Offset: int32(mention.Offset),
+			Length: int32(mention.Length),
+		})
+	}
+
+	err = s.mentionRepository.CreateMentions(ctx, message.ID.String(), newMentions)
+	if err != nil {
+		return nil
+	}
+
+	return mentions
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeForwardMessageRepository keeps the targets of every forward, the repository stores them
+// in one transaction so a failure forwards nothing
+type fakeForwardMessageRepository struct {
+	repository.MessageRepository
+	source   *db.Message
+	forwards [][]string
+	err      error
+}
+
+func (r *fakeForwardMessageRepository) GetMessageByID(ctx context.Context, messageID string) (*db.Message, error) {
+	if r.source.ID.String() != messageID {
+		return nil, customerrors.ErrResourceNotFound
+	}
+
+	return r.source, nil
+}
+
+func (r *fakeForwardMessageRepository) ForwardMessage(ctx context.Context, sourceMessageID string, conversationIDs []string, senderID string) ([]*db.Message, error) {
+	r.forwards = append(r.forwards, conversationIDs)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	forwarded := make([]*db.Message, 0, len(conversationIDs))
+	for _, conversationID := range conversationIDs {
+		forwarded = append(forwarded, &db.Message{
+			ID:             pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			ConversationID: pgtype.UUID{Bytes: uuid.MustParse(conversationID), Valid: true},
+			IsForwarded:    true,
+		})
+	}
+
+	return forwarded, nil
+}
+
+// fakeMembershipParticipantRepository knows the conversations of a single user
+type fakeMembershipParticipantRepository struct {
+	repository.ParticipantRepository
+	conversationIDs []string
+}
+
+func (r *fakeMembershipParticipantRepository) GetParticipant(ctx context.Context, userID string, conversationID string) (*db.ConversationParticipant, error) {
+	if !slices.Contains(r.conversationIDs, conversationID) {
+		return nil, customerrors.ErrResourceNotFound
+	}
+
+	return &db.ConversationParticipant{}, nil
+}
+
+func TestForwardMessage(t *testing.T) {
+	source := uuid.NewString()
+	first := uuid.NewString()
+	second := uuid.NewString()
+	foreign := uuid.NewString()
+
+	newService := func() (*MessageService, *fakeForwardMessageRepository) {
+		messageRepository := &fakeForwardMessageRepository{
+			source: &db.Message{
+				ID:             pgtype.UUID{Bytes: uuid.New(), Valid: true},
+				ConversationID: pgtype.UUID{Bytes: uuid.MustParse(source), Valid: true},
+			},
+		}
+		participantRepository := &fakeMembershipParticipantRepository{conversationIDs: []string{source, first, second}}
+
+		return NewMessageService(messageRepository, participantRepository, nil), messageRepository
+	}
+	ctx := context.Background()
+
+	t.Run("forwards once to every target", func(t *testing.T) {
+		messageService, messageRepository := newService()
+
+		messages, err := messageService.ForwardMessage(ctx, "user-id", messageRepository.source.ID.String(), []string{second, first, second})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(messageRepository.forwards) != 1 || len(messageRepository.forwards[0]) != 2 {
+			t.Fatalf("expected a single forward to the 2 conversations, got %v", messageRepository.forwards)
+		}
+		if len(messages) != 2 {
+			t.Errorf("expected 2 messages, got %d", len(messages))
+		}
+	})
+
+	t.Run("nothing is forwarded when a target is not allowed", func(t *testing.T) {
+		messageService, messageRepository := newService()
+
+		_, err := messageService.ForwardMessage(ctx, "user-id", messageRepository.source.ID.String(), []string{first, foreign})
+		if !errors.Is(err, customerrors.ErrForbidden) {
+			t.Fatalf("expected %v, got %v", customerrors.ErrForbidden, err)
+		}
+
+		if len(messageRepository.forwards) != 0 {
+			t.Errorf("expected nothing to be forwarded, got %v", messageRepository.forwards)
+		}
+	})
+
+	t.Run("the error of the forward is returned", func(t *testing.T) {
+		messageService, messageRepository := newService()
+		messageRepository.err = errors.New("connection lost")
+
+		messages, err := messageService.ForwardMessage(ctx, "user-id", messageRepository.source.ID.String(), []string{first, second})
+		if !errors.Is(err, messageRepository.err) {
+			t.Fatalf("expected %v, got %v", messageRepository.err, err)
+		}
+
+		if messages != nil {
+			t.Errorf("expected no messages, got %v", messages)
+		}
+	})
+
+	t.Run("too many targets", func(t *testing.T) {
+		messageService, messageRepository := newService()
+
+		targets := make([]string, 0, MaxForwardTargets+1)
+		for range MaxForwardTargets + 1 {
+			targets = append(targets, uuid.NewString())
+		}
+
+		_, err := messageService.ForwardMessage(ctx, "user-id", messageRepository.source.ID.String(), targets)
+		if !errors.Is(err, customerrors.ErrTooManyForwardTargets) {
+			t.Fatalf("expected %v, got %v", customerrors.ErrTooManyForwardTargets, err)
+		}
+	})
+}
+
+func TestCreateMessageRequiresAParticipant(t *testing.T) {
+	conversationID := uuid.NewString()
+	messageRepository := &fakeCreateMessageRepository{}
+	messageService := NewMessageService(messageRepository, &fakeMembershipParticipantRepository{conversationIDs: []string{conversationID}}, nil)
+	ctx := context.Background()
+
+	if _, _, err := messageService.CreateMessage(ctx, uuid.NewString(), "user-id", "Hi @ana", repository.MESSAGE_TYPE_TEXT, nil); !errors.Is(err, customerrors.ErrForbidden) {
+		t.Errorf("expected %v for a conversation of other users, got %v", customerrors.ErrForbidden, err)
+	}
+
+	if messageRepository.created != 0 {
+		t.Fatalf("expected nothing to be stored for a non participant, got %d messages", messageRepository.created)
+	}
+
+	if _, _, err := messageService.CreateMessage(ctx, conversationID, "user-id", "Hi", repository.MESSAGE_TYPE_TEXT, nil); err != nil || messageRepository.created != 1 {
+		t.Errorf("expected the participant to send the message, got %v", err)
+	}
+}
+
+// fakeReplyMessageRepository stores the messages and knows the ones that can be replied to
+type fakeReplyMessageRepository struct {
+	fakeCreateMessageRepository
+	messages map[string]*db.Message
+}
+
+func (r *fakeReplyMessageRepository) GetMessageByID(ctx context.Context, messageID string) (*db.Message, error) {
+	message, ok := r.messages[messageID]
+	if !ok {
+		return nil, customerrors.ErrResourceNotFound
+	}
+
+	return message, nil
+}
+
+func TestCreateMessageReply(t *testing.T) {
+	conversationID := uuid.NewString()
+	otherConversationID := uuid.NewString()
+	foreignConversationID := uuid.NewString()
+
+	newMessage := func(conversationID string, deleted bool) *db.Message {
+		return &db.Message{
+			ID:             pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			ConversationID: pgtype.UUID{Bytes: uuid.MustParse(conversationID), Valid: true},
+			IsDeleted:      pgtype.Bool{Bool: deleted, Valid: true},
+		}
+	}
+
+	sameConversation := newMessage(conversationID, false)
+	otherConversation := newMessage(otherConversationID, false)
+	foreignConversation := newMessage(foreignConversationID, false)
+	deleted := newMessage(conversationID, true)
+
+	messageRepository := &fakeReplyMessageRepository{messages: map[string]*db.Message{}}
+	for _, message := range []*db.Message{sameConversation, otherConversation, foreignConversation, deleted} {
+		messageRepository.messages[message.ID.String()] = message
+	}
+	participantRepository := &fakeMembershipParticipantRepository{conversationIDs: []string{conversationID, otherConversationID}}
+	messageService := NewMessageService(messageRepository, participantRepository, nil)
+
+	tests := []struct {
+		name             string
+		replyToMessageID *string
+		expected         error
+	}{
+		{name: "no reply", replyToMessageID: nil, expected: nil},
+		{name: "same conversation", replyToMessageID: ptr(sameConversation.ID.String()), expected: nil},
+		{name: "another conversation of the user", replyToMessageID: ptr(otherConversation.ID.String()), expected: customerrors.ErrInvalidReply},
+		{name: "conversation of other users", replyToMessageID: ptr(foreignConversation.ID.String()), expected: customerrors.ErrInvalidReply},
+		{name: "deleted message", replyToMessageID: ptr(deleted.ID.String()), expected: customerrors.ErrInvalidReply},
+		{name: "unknown message", replyToMessageID: ptr(uuid.NewString()), expected: customerrors.ErrInvalidReply},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			created := messageRepository.created
+
+			_, _, err := messageService.CreateMessage(context.Background(), conversationID, "user-id", "Hi", repository.MESSAGE_TYPE_TEXT, test.replyToMessageID)
+			if !errors.Is(err, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, err)
+			}
+
+			if test.expected != nil && messageRepository.created != created {
+				t.Errorf("expected the reply not to be stored")
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/graph/model"
+	"golang-whatsapp-clone/repository"
+)
+
+// ensureParticipant returns customerrors.ErrForbidden when the user is not an active participant of the conversation
+func ensureParticipant(ctx context.Context, participantRepository repository.ParticipantRepository, userID string, conversationID string) (*db.ConversationParticipant, error) {
+	participant, err := participantRepository.GetParticipant(ctx, userID, conversationID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return nil, customerrors.ErrForbidden
+		}
+		return nil, err
+	}
+
+	return participant, nil
+}
+
+// getReadableMessage returns the message only when the user is an active participant of its conversation
+func getReadableMessage(
+	ctx context.Context,
+	messageRepository repository.MessageRepository,
+	participantRepository repository.ParticipantRepository,
+	userID string,
+	messageID string,
+) (*db.Message, *db.ConversationParticipant, error) {
+	message, err := messageRepository.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if message.IsDeleted.Bool {
+		return nil, nil, customerrors.ErrResourceNotFound
+	}
+
+	participant, err := ensureParticipant(ctx, participantRepository, userID, message.ConversationID.String())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return message, participant, nil
+}
+
+// ensureReplyTarget returns customerrors.ErrInvalidReply when the replied message is not a readable
+// message of the same conversation, so a reply never points to a message of another conversation
+func ensureReplyTarget(
+	ctx context.Context,
+	messageRepository repository.MessageRepository,
+	participantRepository repository.ParticipantRepository,
+	userID string,
+	conversationID string,
+	replyToMessageID *string,
+) error {
+	if replyToMessageID == nil {
+		return nil
+	}
+
+	message, _, err := getReadableMessage(ctx, messageRepository, participantRepository, userID, *replyToMessageID)
+	if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) || errors.Is(err, customerrors.ErrForbidden) {
+		return customerrors.ErrInvalidReply
+	}
+	if err != nil {
+		return err
+	}
+
+	if message.ConversationID.String() != conversationID {
+		return customerrors.ErrInvalidReply
+	}
+
+	return nil
+}
+
+// canManageConversation tells if the participant can change the shared settings of the conversation.
+// In direct conversations both users can, in groups only the admins and the owner
+func canManageConversation(conversationType string, role string) bool {
+	if conversationType != model.ConversationTypeEnumGroup.String() {
+		return true
+	}
+
+	return role == repository.PARTICIPANT_ROLE_ADMIN || role == repository.PARTICIPANT_ROLE_OWNER
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+)
+
+// WhatsApp keeps at most 3 pinned messages per conversation
+const MaxPinnedMessages = 3
+
+type PinService struct {
+	pinnedMessageRepository repository.PinnedMessageRepository
+	messageRepository       repository.MessageRepository
+	conversationRepository  repository.ConversationRepository
+	participantRepository   repository.ParticipantRepository
+}
+
+func NewPinService(
+	pinnedMessageRepository repository.PinnedMessageRepository,
+	messageRepository repository.MessageRepository,
+	conversationRepository repository.ConversationRepository,
+	participantRepository repository.ParticipantRepository,
+) *PinService {
+	return &PinService{
+		pinnedMessageRepository: pinnedMessageRepository,
+		messageRepository:       messageRepository,
+		conversationRepository:  conversationRepository,
+		participantRepository:   participantRepository,
+	}
+}
+
+// PinMessage pins the message in its conversation, customerrors.ErrPinLimitReached is returned when
+// it already has MaxPinnedMessages. Pinning an already pinned message does nothing
+func (s *PinService) PinMessage(ctx context.Context, userID string, messageID string) (*db.Message, error) {
+	message, err := s.getPinnableMessage(ctx, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.pinnedMessageRepository.PinMessage(ctx, message.ConversationID.String(), messageID, userID, MaxPinnedMessages)
+	if err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+func (s *PinService) UnpinMessage(ctx context.Context, userID string, messageID string) (*db.Message, error) {
+	message, err := s.getPinnableMessage(ctx, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.pinnedMessageRepository.UnpinMessage(ctx, message.ConversationID.String(), messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+func (s *PinService) GetPinnedMessages(ctx context.Context, userID string, conversationID string, limit int32, offset int32) (*[]db.GetPinnedMessagesRow, error) {
+	_, err := ensureParticipant(ctx, s.participantRepository, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.pinnedMessageRepository.GetPinnedMessages(ctx, conversationID, limit, offset)
+}
+
+// getPinnableMessage returns the message when the user is allowed to change the pins of its conversation
+func (s *PinService) getPinnableMessage(ctx context.Context, userID string, messageID string) (*db.Message, error) {
+	message, participant, err := getReadableMessage(ctx, s.messageRepository, s.participantRepository, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation, err := s.conversationRepository.GetConversationByID(ctx, message.ConversationID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if !canManageConversation(conversation.Type, participant.Role) {
+		return nil, customerrors.ErrForbidden
+	}
+
+	return message, nil
+}
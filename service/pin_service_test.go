@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type fakePinMessageRepository struct {
+	repository.MessageRepository
+	messages map[string]*db.Message
+}
+
+func (r *fakePinMessageRepository) GetMessageByID(ctx context.Context, messageID string) (*db.Message, error) {
+	message, ok := r.messages[messageID]
+	if !ok {
+		return nil, customerrors.ErrResourceNotFound
+	}
+
+	return message, nil
+}
+
+// fakePinConversationRepository returns direct conversations unless their type is set
+type fakePinConversationRepository struct {
+	repository.ConversationRepository
+	types map[string]string
+}
+
+func (r *fakePinConversationRepository) GetConversationByID(ctx context.Context, conversationID string) (*db.Conversation, error) {
+	if conversationType, ok := r.types[conversationID]; ok {
+		return &db.Conversation{Type: conversationType}, nil
+	}
+
+	return &db.Conversation{Type: "DIRECT"}, nil
+}
+
+// fakeRoleParticipantRepository knows the role of the user in each of its conversations
+type fakeRoleParticipantRepository struct {
+	repository.ParticipantRepository
+	roles map[string]string
+}
+
+func (r *fakeRoleParticipantRepository) GetParticipant(ctx context.Context, userID string, conversationID string) (*db.ConversationParticipant, error) {
+	role, ok := r.roles[conversationID]
+	if !ok {
+		return nil, customerrors.ErrResourceNotFound
+	}
+
+	return &db.ConversationParticipant{Role: role}, nil
+}
+
+// fakePinRowRepository only stores the pins, the limit is checked by the transaction of the
+// real repository so the fake returns err instead
+type fakePinRowRepository struct {
+	repository.PinnedMessageRepository
+	pinned map[string]string
+	err    error
+}
+
+func (r *fakePinRowRepository) PinMessage(ctx context.Context, conversationID string, messageID string, pinnedBy string, limit int32) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	r.pinned[messageID] = conversationID
+
+	return nil
+}
+
+func (r *fakePinRowRepository) UnpinMessage(ctx context.Context, conversationID string, messageID string) (bool, error) {
+	_, ok := r.pinned[messageID]
+	delete(r.pinned, messageID)
+
+	return ok, nil
+}
+
+// fakePinnedMessageRepository checks the limit and pins under a lock, like the transaction that
+// locks the conversation row
+type fakePinnedMessageRepository struct {
+	repository.PinnedMessageRepository
+	mu     sync.Mutex
+	pinned []string
+	limits []int32
+}
+
+func (r *fakePinnedMessageRepository) PinMessage(ctx context.Context, conversationID string, messageID string, pinnedBy string, limit int32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.limits = append(r.limits, limit)
+
+	if slices.Contains(r.pinned, messageID) {
+		return nil
+	}
+
+	if int32(len(r.pinned)) >= limit {
+		return customerrors.ErrPinLimitReached
+	}
+
+	r.pinned = append(r.pinned, messageID)
+
+	return nil
+}
+
+func TestPinMessageLimit(t *testing.T) {
+	conversationID := uuid.NewString()
+	messageRepository := &fakePinMessageRepository{messages: map[string]*db.Message{}}
+	messageIDs := []string{}
+
+	for range MaxPinnedMessages + 2 {
+		message := &db.Message{
+			ID:             pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			ConversationID: pgtype.UUID{Bytes: uuid.MustParse(conversationID), Valid: true},
+		}
+		messageRepository.messages[message.ID.String()] = message
+		messageIDs = append(messageIDs, message.ID.String())
+	}
+
+	pinnedMessageRepository := &fakePinnedMessageRepository{}
+	participantRepository := &fakeMembershipParticipantRepository{conversationIDs: []string{conversationID}}
+	pinService := NewPinService(pinnedMessageRepository, messageRepository, &fakePinConversationRepository{}, participantRepository)
+	ctx := context.Background()
+
+	// the messages are pinned at the same time, only MaxPinnedMessages of them can be
+	errs := make([]error, len(messageIDs))
+	var wg sync.WaitGroup
+	for i, messageID := range messageIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = pinService.PinMessage(ctx, "user-id", messageID)
+		}()
+	}
+	wg.Wait()
+
+	rejected := 0
+	for _, err := range errs {
+		if errors.Is(err, customerrors.ErrPinLimitReached) {
+			rejected++
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(pinnedMessageRepository.pinned) != MaxPinnedMessages || rejected != 2 {
+		t.Errorf("expected %d pins and 2 rejected, got %d pins and %d rejected", MaxPinnedMessages, len(pinnedMessageRepository.pinned), rejected)
+	}
+
+	for _, limit := range pinnedMessageRepository.limits {
+		if limit != MaxPinnedMessages {
+			t.Errorf("expected the limit %d to reach the repository, got %d", MaxPinnedMessages, limit)
+		}
+	}
+
+	// pinning an already pinned message at the limit does nothing
+	if _, err := pinService.PinMessage(ctx, "user-id", pinnedMessageRepository.pinned[0]); err != nil {
+		t.Errorf("expected the pinned message to be accepted, got %v", err)
+	}
+}
+
+func TestPinMessagePermissions(t *testing.T) {
+	direct := uuid.NewString()
+	memberGroup := uuid.NewString()
+	adminGroup := uuid.NewString()
+	ownerGroup := uuid.NewString()
+	foreign := uuid.NewString()
+
+	messageRepository := &fakePinMessageRepository{messages: map[string]*db.Message{}}
+	newMessage := func(conversationID string, deleted bool) string {
+		message := &db.Message{
+			ID:             pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			ConversationID: pgtype.UUID{Bytes: uuid.MustParse(conversationID), Valid: true},
+			IsDeleted:      pgtype.Bool{Bool: deleted, Valid: true},
+		}
+		messageRepository.messages[message.ID.String()] = message
+
+		return message.ID.String()
+	}
+
+	conversationRepository := &fakePinConversationRepository{types: map[string]string{
+		memberGroup: "GROUP",
+		adminGroup:  "GROUP",
+		ownerGroup:  "GROUP",
+		foreign:     "GROUP",
+	}}
+	participantRepository := &fakeRoleParticipantRepository{roles: map[string]string{
+		direct:      repository.PARTICIPANT_ROLE_MEMBER,
+		memberGroup: repository.PARTICIPANT_ROLE_MEMBER,
+		adminGroup:  repository.PARTICIPANT_ROLE_ADMIN,
+		ownerGroup:  repository.PARTICIPANT_ROLE_OWNER,
+	}}
+
+	tests := []struct {
+		name      string
+		messageID string
+		expected  error
+	}{
+		{name: "direct conversation", messageID: newMessage(direct, false), expected: nil},
+		{name: "group member", messageID: newMessage(memberGroup, false), expected: customerrors.ErrForbidden},
+		{name: "group admin", messageID: newMessage(adminGroup, false), expected: nil},
+		{name: "group owner", messageID: newMessage(ownerGroup, false), expected: nil},
+		{name: "deleted message", messageID: newMessage(direct, true), expected: customerrors.ErrResourceNotFound},
+		{name: "conversation of other users", messageID: newMessage(foreign, false), expected: customerrors.ErrForbidden},
+		{name: "unknown message", messageID: uuid.NewString(), expected: customerrors.ErrResourceNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pinnedMessageRepository := &fakePinRowRepository{pinned: map[string]string{}}
+			pinService := NewPinService(pinnedMessageRepository, messageRepository, conversationRepository, participantRepository)
+			ctx := context.Background()
+
+			_, err := pinService.PinMessage(ctx, "user-id", test.messageID)
+			if !errors.Is(err, test.expected) {
+				t.Fatalf("expected %v when pinning, got %v", test.expected, err)
+			}
+
+			_, pinned := pinnedMessageRepository.pinned[test.messageID]
+			if pinned != (test.expected == nil) {
+				t.Fatalf("expected the message to be pinned: %v, got %v", test.expected == nil, pinned)
+			}
+
+			// the same rules apply to unpin the message
+			pinnedMessageRepository.pinned[test.messageID] = ""
+			_, err = pinService.UnpinMessage(ctx, "user-id", test.messageID)
+			if !errors.Is(err, test.expected) {
+				t.Fatalf("expected %v when unpinning, got %v", test.expected, err)
+			}
+
+			_, pinned = pinnedMessageRepository.pinned[test.messageID]
+			if pinned != (test.expected != nil) {
+				t.Errorf("expected the message to be unpinned: %v, got %v", test.expected == nil, !pinned)
+			}
+		})
+	}
+}
+
+func TestPinMessageLimitReachedInTheRepository(t *testing.T) {
+	conversationID := uuid.NewString()
+	message := &db.Message{
+		ID:             pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		ConversationID: pgtype.UUID{Bytes: uuid.MustParse(conversationID), Valid: true},
+	}
+	messageRepository := &fakePinMessageRepository{messages: map[string]*db.Message{message.ID.String(): message}}
+	pinnedMessageRepository := &fakePinRowRepository{pinned: map[string]string{}, err: customerrors.ErrPinLimitReached}
+	participantRepository := &fakeMembershipParticipantRepository{conversationIDs: []string{conversationID}}
+	pinService := NewPinService(pinnedMessageRepository, messageRepository, &fakePinConversationRepository{}, participantRepository)
+
+	if _, err := pinService.PinMessage(context.Background(), "user-id", message.ID.String()); !errors.Is(err, customerrors.ErrPinLimitReached) {
+		t.Errorf("expected %v, got %v", customerrors.ErrPinLimitReached, err)
+	}
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// MaxPresenceUsers is the max number of users whose presence can be requested at once
+const MaxPresenceUsers = 100
+
+var LastSeenPrivacyValues = []string{
+	repository.LAST_SEEN_PRIVACY_EVERYONE,
+	repository.LAST_SEEN_PRIVACY_CONTACTS,
+	repository.LAST_SEEN_PRIVACY_NOBODY,
+}
+
+// Presence is what a viewer can see about a user. LastSeenAt is nil while the user is online,
+// when it never connected or when its privacy hides it from the viewer
+type Presence struct {
+	UserID     string
+	IsOnline   bool
+	LastSeenAt *time.Time
+}
+
+// PresenceService tracks which users are online from their open websocket connections. A user
+// is online while it has at least one connection, so closing one tab of many doesn't change it.
+// The connections are kept in memory, with many server instances each one only knows its own
+type PresenceService struct {
+	userRepository repository.UserRepository
+	logger         *zerolog.Logger
+	// onChange is called with the contacts that must be notified about the new presence of the user
+	onChange func(contactIDs []string, presence Presence)
+
+	mutex       sync.Mutex
+	connections map[string]int
+}
+
+func NewPresenceService(
+	userRepository repository.UserRepository,
+	logger *zerolog.Logger,
+	onChange func(contactIDs []string, presence Presence),
+) *PresenceService {
+	return &PresenceService{
+		userRepository: userRepository,
+		logger:         logger,
+		onChange:       onChange,
+		connections:    map[string]int{},
+	}
+}
+
+// Connect registers a new connection of the user, the contacts are notified when it's the first one
+func (s *PresenceService) Connect(ctx context.Context, userID string) {
+	s.mutex.Lock()
+	s.connections[userID]++
+	isFirst := s.connections[userID] == 1
+	s.mutex.Unlock()
+
+	if isFirst {
+		s.notifyContacts(ctx, userID, Presence{UserID: userID, IsOnline: true})
+	}
+}
+
+// Disconnect removes a connection of the user. When it was the last one the last seen is saved
+// and the contacts are notified
+func (s *PresenceService) Disconnect(ctx context.Context, userID string) {
+	s.mutex.Lock()
+	s.connections[userID]--
+	isLast := s.connections[userID] <= 0
+	if isLast {
+		delete(s.connections, userID)
+	}
+	s.mutex.Unlock()
+
+	if !isLast {
+		return
+	}
+
+	lastSeenAt := time.Now()
+	if err := s.userRepository.UpdateLastSeen(ctx, userID, lastSeenAt); err != nil {
+		s.logger.Error().Msgf("PresenceService: error to save the last seen of the user %s: %v", userID, err)
+	}
+
+	s.notifyContacts(ctx, userID, Presence{UserID: userID, LastSeenAt: &lastSeenAt})
+}
+
+func (s *PresenceService) IsOnline(userID string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.connections[userID] > 0
+}
+
+// GetPresences returns the presence of the users as the viewer can see it, the users that don't
+// exist are not included
+func (s *PresenceService) GetPresences(ctx context.Context, viewerID string, userIDs []string) ([]Presence, error) {
+	if len(userIDs) > MaxPresenceUsers {
+		return nil, customerrors.ErrTooManyPresenceUsers
+	}
+
+	if len(userIDs) == 0 {
+		return []Presence{}, nil
+	}
+
+	users, err := s.userRepository.GetUsersByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	contactIDs, err := s.userRepository.GetContactIDs(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	presences := make([]Presence, 0, len(*users))
+	for _, user := range *users {
+		userID := user.ID.String()
+		canSee := userID == viewerID || canSeeLastSeen(user.LastSeenPrivacy, slices.Contains(contactIDs, userID))
+
+		presences = append(presences, s.visiblePresence(user, canSee))
+	}
+
+	return presences, nil
+}
+
+// SetLastSeenPrivacy changes who can see the presence of the user, the contacts are notified
+// because they may stop or start seeing it
+func (s *PresenceService) SetLastSeenPrivacy(ctx context.Context, userID string, privacy string) (*db.User, error) {
+	if !slices.Contains(LastSeenPrivacyValues, privacy) {
+		return nil, customerrors.ErrInvalidPrivacySetting
+	}
+
+	user, err := s.userRepository.UpdateLastSeenPrivacy(ctx, userID, privacy)
+	if err != nil {
+		return nil, err
+	}
+
+	contactIDs, err := s.userRepository.GetContactIDs(ctx, userID)
+	if err != nil {
+		s.logger.Error().Msgf("PresenceService: error to get the contacts of the user %s: %v", userID, err)
+		return user, nil
+	}
+
+	s.onChange(contactIDs, s.visiblePresence(*user, canSeeLastSeen(privacy, true)))
+
+	return user, nil
+}
+
+// notifyContacts sends the presence to the contacts of the user, unless the user hides it from them
+func (s *PresenceService) notifyContacts(ctx context.Context, userID string, presence Presence) {
+	users, err := s.userRepository.GetUsersByIDs(ctx, []string{userID})
+	if err != nil || len(*users) == 0 {
+		s.logger.Error().Msgf("PresenceService: error to get the user %s: %v", userID, err)
+		return
+	}
+
+	if !canSeeLastSeen((*users)[0].LastSeenPrivacy, true) {
+		return
+	}
+
+	contactIDs, err := s.userRepository.GetContactIDs(ctx, userID)
+	if err != nil {
+		s.logger.Error().Msgf("PresenceService: error to get the contacts of the user %s: %v", userID, err)
+		return
+	}
+
+	s.onChange(contactIDs, presence)
+}
+
+func (s *PresenceService) visiblePresence(user db.User, canSee bool) Presence {
+	presence := Presence{UserID: user.ID.String()}
+	if !canSee {
+		return presence
+	}
+
+	if s.IsOnline(presence.UserID) {
+		presence.IsOnline = true
+		return presence
+	}
+
+	if user.LastSeenAt.Valid {
+		presence.LastSeenAt = &user.LastSeenAt.Time
+	}
+
+	return presence
+}
+
+func canSeeLastSeen(privacy string, isContact bool) bool {
+	switch privacy {
+	case repository.LAST_SEEN_PRIVACY_EVERYONE:
+		return true
+	case repository.LAST_SEEN_PRIVACY_CONTACTS:
+		return isContact
+	default:
+		return false
+	}
+}
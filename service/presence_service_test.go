@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	"golang-whatsapp-clone/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+)
+
+type fakePresenceUserRepository struct {
+	repository.UserRepository
+	users    map[string]*db.User
+	contacts map[string][]string
+}
+
+func (r *fakePresenceUserRepository) GetUsersByIDs(ctx context.Context, userIDs []string) (*[]db.User, error) {
+	users := []db.User{}
+	for _, userID := range userIDs {
+		if user, ok := r.users[userID]; ok {
+			users = append(users, *user)
+		}
+	}
+
+	return &users, nil
+}
+
+func (r *fakePresenceUserRepository) UpdateLastSeen(ctx context.Context, userID string, lastSeenAt time.Time) error {
+	r.users[userID].LastSeenAt = pgtype.Timestamptz{Time: lastSeenAt, Valid: true}
+	return nil
+}
+
+func (r *fakePresenceUserRepository) GetContactIDs(ctx context.Context, userID string) ([]string, error) {
+	return r.contacts[userID], nil
+}
+
+type presenceNotification struct {
+	contactIDs []string
+	presence   Presence
+}
+
+func newPresenceTestService(privacy string) (*PresenceService, *fakePresenceUserRepository, *[]presenceNotification, string, string) {
+	ana := db.User{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, LastSeenPrivacy: privacy}
+	bob := db.User{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, LastSeenPrivacy: repository.LAST_SEEN_PRIVACY_EVERYONE}
+	anaID, bobID := ana.ID.String(), bob.ID.String()
+
+	userRepository := &fakePresenceUserRepository{
+		users:    map[string]*db.User{anaID: &ana, bobID: &bob},
+		contacts: map[string][]string{anaID: {bobID}, bobID: {anaID}},
+	}
+
+	notifications := &[]presenceNotification{}
+	logger := zerolog.Nop()
+	presenceService := NewPresenceService(userRepository, &logger, func(contactIDs []string, presence Presence) {
+		*notifications = append(*notifications, presenceNotification{contactIDs, presence})
+	})
+
+	return presenceService, userRepository, notifications, anaID, bobID
+}
+
+func TestPresenceWithManyConnections(t *testing.T) {
+	presenceService, userRepository, notifications, anaID, bobID := newPresenceTestService(repository.LAST_SEEN_PRIVACY_EVERYONE)
+	ctx := context.Background()
+
+	presenceService.Connect(ctx, anaID)
+	presenceService.Connect(ctx, anaID)
+
+	if !presenceService.IsOnline(anaID) {
+		t.Fatal("expected the user to be online")
+	}
+
+	if len(*notifications) != 1 || !(*notifications)[0].presence.IsOnline || (*notifications)[0].contactIDs[0] != bobID {
+		t.Fatalf("expected only the first connection to notify the contacts, got %+v", *notifications)
+	}
+
+	presenceService.Disconnect(ctx, anaID)
+	if !presenceService.IsOnline(anaID) || len(*notifications) != 1 {
+		t.Fatal("expected the user to stay online while it has a connection")
+	}
+
+	presenceService.Disconnect(ctx, anaID)
+	if presenceService.IsOnline(anaID) {
+		t.Fatal("expected the user to be offline after closing all the connections")
+	}
+
+	if !userRepository.users[anaID].LastSeenAt.Valid {
+		t.Error("expected the last seen to be saved")
+	}
+
+	last := (*notifications)[len(*notifications)-1].presence
+	if len(*notifications) != 2 || last.IsOnline || last.LastSeenAt == nil {
+		t.Errorf("expected the contacts to be notified with the last seen, got %+v", *notifications)
+	}
+}
+
+func TestPresencePrivacy(t *testing.T) {
+	tests := []struct {
+		privacy         string
+		contactCanSee   bool
+		strangerCanSee  bool
+		notifiesContact bool
+	}{
+		{repository.LAST_SEEN_PRIVACY_EVERYONE, true, true, true},
+		{repository.LAST_SEEN_PRIVACY_CONTACTS, true, false, true},
+		{repository.LAST_SEEN_PRIVACY_NOBODY, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.privacy, func(t *testing.T) {
+			presenceService, _, notifications, anaID, bobID := newPresenceTestService(tt.privacy)
+			ctx := context.Background()
+			strangerID := uuid.NewString()
+
+			presenceService.Connect(ctx, anaID)
+
+			if notified := len(*notifications) > 0; notified != tt.notifiesContact {
+				t.Errorf("expected contacts notified to be %v", tt.notifiesContact)
+			}
+
+			viewers := []struct {
+				viewerID string
+				canSee   bool
+			}{
+				{bobID, tt.contactCanSee},
+				{strangerID, tt.strangerCanSee},
+				{anaID, true},
+			}
+
+			for _, viewer := range viewers {
+				presences, err := presenceService.GetPresences(ctx, viewer.viewerID, []string{anaID})
+				if err != nil || len(presences) != 1 {
+					t.Fatalf("unexpected result %+v, %v", presences, err)
+				}
+
+				if presences[0].IsOnline != viewer.canSee {
+					t.Errorf("viewer %s: expected online to be %v", viewer.viewerID, viewer.canSee)
+				}
+			}
+		})
+	}
+}
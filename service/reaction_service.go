@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// the column is VARCHAR(64) but a single emoji never needs that much, skin tones
+	// and ZWJ sequences (👨‍👩‍👧‍👦) are the longest ones and they are below 16 code points
+	maxReactionEmojiRunes = 16
+	maxReactionEmojiBytes = 64
+)
+
+type ReactionService struct {
+	reactionRepository    repository.ReactionRepository
+	messageRepository     repository.MessageRepository
+	participantRepository repository.ParticipantRepository
+}
+
+func NewReactionService(
+	reactionRepository repository.ReactionRepository,
+	messageRepository repository.MessageRepository,
+	participantRepository repository.ParticipantRepository,
+) *ReactionService {
+	return &ReactionService{
+		reactionRepository:    reactionRepository,
+		messageRepository:     messageRepository,
+		participantRepository: participantRepository,
+	}
+}
+
+// ReactToMessage stores the reaction of the user, replacing the previous one if it exists
+func (s *ReactionService) ReactToMessage(ctx context.Context, userID string, messageID string, emoji string) (*db.Message, *db.MessageReaction, error) {
+	emoji = strings.TrimSpace(emoji)
+	if !isValidReactionEmoji(emoji) {
+		return nil, nil, customerrors.ErrInvalidReaction
+	}
+
+	message, _, err := getReadableMessage(ctx, s.messageRepository, s.participantRepository, userID, messageID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reaction, err := s.reactionRepository.UpsertReaction(ctx, messageID, userID, emoji)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return message, reaction, nil
+}
+
+// RemoveReaction deletes the reaction of the user. The returned bool is false when there was nothing to remove
+func (s *ReactionService) RemoveReaction(ctx context.Context, userID string, messageID string) (*db.Message, bool, error) {
+	message, _, err := getReadableMessage(ctx, s.messageRepository, s.participantRepository, userID, messageID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	removed, err := s.reactionRepository.DeleteReaction(ctx, messageID, userID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return message, removed, nil
+}
+
+// GetReactionSummaries returns the aggregated reactions grouped by message id
+func (s *ReactionService) GetReactionSummaries(ctx context.Context, messageIDs []string, viewerID string) (map[string][]db.GetMessageReactionSummariesRow, error) {
+	result := make(map[string][]db.GetMessageReactionSummariesRow, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return result, nil
+	}
+
+	summaries, err := s.reactionRepository.GetReactionSummaries(ctx, messageIDs, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, summary := range *summaries {
+		messageID := summary.MessageID.String()
+		result[messageID] = append(result[messageID], summary)
+	}
+
+	return result, nil
+}
+
+func isValidReactionEmoji(emoji string) bool {
+	if emoji == "" || len(emoji) > maxReactionEmojiBytes || utf8.RuneCountInString(emoji) > maxReactionEmojiRunes {
+		return false
+	}
+
+	// digits are allowed because of the keycap emojis (1️⃣), but plain text is not a reaction
+	// so a string with only ASCII characters like "1" or ":)" is rejected
+	hasEmoji := false
+	for _, r := range emoji {
+		if r == utf8.RuneError || unicode.IsLetter(r) || unicode.IsSpace(r) || unicode.IsControl(r) {
+			return false
+		}
+
+		if r > unicode.MaxASCII {
+			hasEmoji = true
+		}
+	}
+
+	return hasEmoji
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeReactionRepository stores the reactions by message id of a single user
+type fakeReactionRepository struct {
+	repository.ReactionRepository
+	reactions map[string]string
+}
+
+func (r *fakeReactionRepository) UpsertReaction(ctx context.Context, messageID string, userID string, emoji string) (*db.MessageReaction, error) {
+	r.reactions[messageID] = emoji
+
+	return &db.MessageReaction{Emoji: emoji}, nil
+}
+
+func (r *fakeReactionRepository) DeleteReaction(ctx context.Context, messageID string, userID string) (bool, error) {
+	_, ok := r.reactions[messageID]
+	delete(r.reactions, messageID)
+
+	return ok, nil
+}
+
+func TestIsValidReactionEmoji(t *testing.T) {
+	tests := []struct {
+		emoji    string
+		expected bool
+	}{
+		{emoji: "👍", expected: true},
+		{emoji: "❤️", expected: true},
+		{emoji: "👍🏽", expected: true},
+		{emoji: "👨‍👩‍👧‍👦", expected: true},
+		{emoji: "🏳️‍🌈", expected: true},
+		{emoji: "🇧🇷", expected: true},
+		{emoji: "1️⃣", expected: true},
+		{emoji: "", expected: false},
+		{emoji: "ok", expected: false},
+		{emoji: "1", expected: false},
+		{emoji: ":)", expected: false},
+		{emoji: "👍 ok", expected: false},
+		{emoji: "👍\n", expected: false},
+		{emoji: "\xff", expected: false},
+		{emoji: strings.Repeat("👍", maxReactionEmojiRunes+1), expected: false},
+	}
+
+	for _, test := range tests {
+		if valid := isValidReactionEmoji(test.emoji); valid != test.expected {
+			t.Errorf("expected %q to be valid: %v, got %v", test.emoji, test.expected, valid)
+		}
+	}
+}
+
+func TestReactToMessageRequiresAParticipant(t *testing.T) {
+	conversationID := uuid.NewString()
+	foreignConversationID := uuid.NewString()
+
+	messageRepository := &fakePinMessageRepository{messages: map[string]*db.Message{}}
+	newMessage := func(conversationID string) string {
+		message := &db.Message{
+			ID:             pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			ConversationID: pgtype.UUID{Bytes: uuid.MustParse(conversationID), Valid: true},
+		}
+		messageRepository.messages[message.ID.String()] = message
+
+		return message.ID.String()
+	}
+	readable := newMessage(conversationID)
+	foreign := newMessage(foreignConversationID)
+
+	reactionRepository := &fakeReactionRepository{reactions: map[string]string{}}
+	participantRepository := &fakeMembershipParticipantRepository{conversationIDs: []string{conversationID}}
+	reactionService := NewReactionService(reactionRepository, messageRepository, participantRepository)
+	ctx := context.Background()
+
+	if _, _, err := reactionService.ReactToMessage(ctx, "user-id", foreign, "👍"); !errors.Is(err, customerrors.ErrForbidden) {
+		t.Errorf("expected %v when reacting, got %v", customerrors.ErrForbidden, err)
+	}
+
+	if _, _, err := reactionService.RemoveReaction(ctx, "user-id", foreign); !errors.Is(err, customerrors.ErrForbidden) {
+		t.Errorf("expected %v when removing the reaction, got %v", customerrors.ErrForbidden, err)
+	}
+
+	if _, ok := reactionRepository.reactions[foreign]; ok {
+		t.Errorf("expected no reaction to the message of other users")
+	}
+
+	if _, _, err := reactionService.ReactToMessage(ctx, "user-id", readable, " 👍 "); err != nil || reactionRepository.reactions[readable] != "👍" {
+		t.Errorf("expected the participant to react with the trimmed emoji, got %q and %v", reactionRepository.reactions[readable], err)
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxMessageLength is the max number of unicode code points of a message content
+const MaxMessageLength = 4096
+
+const (
+	TEXT_STYLE_BOLD          = "BOLD"
+	TEXT_STYLE_ITALIC        = "ITALIC"
+	TEXT_STYLE_STRIKETHROUGH = "STRIKETHROUGH"
+	TEXT_STYLE_CODE          = "CODE"
+)
+
+const codeFence = "```"
+
+var textStyleMarkers = map[rune]string{
+	'*': TEXT_STYLE_BOLD,
+	'_': TEXT_STYLE_ITALIC,
+	'~': TEXT_STYLE_STRIKETHROUGH,
+}
+
+// TextSpan is a piece of the message content with the styles applied to it, the markers
+// (*, _, ~ and ```) are not included in the text
+type TextSpan struct {
+	Text   string
+	Styles []string
+}
+
+// SanitizeMessageContent normalizes the line breaks and removes the invalid utf-8 and the
+// control characters, except new lines and tabs. The bidirectional overrides are removed
+// too because they can be used to make a link or a file name look like a different one.
+func SanitizeMessageContent(content string) string {
+	return strings.TrimSpace(removeControlCharacters(content))
+}
+
+// removeControlCharacters is SanitizeMessageContent without trimming the spaces, for the
+// texts that are still being written
+func removeControlCharacters(content string) string {
+	content = strings.ToValidUTF8(content, "")
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+
+		if r == '\r' {
+			return '\n'
+		}
+
+		if unicode.IsControl(r) || isBidiControl(r) {
+			return -1
+		}
+
+		return r
+	}, content)
+}
+
+func isBidiControl(r rune) bool {
+	return (r >= '\u202a' && r <= '\u202e') || (r >= '\u2066' && r <= '\u2069')
+}
+
+func isMessageTooLong(content string) bool {
+	return utf8.RuneCountInString(content) > MaxMessageLength
+}
+
+// ParseRichText splits the content in spans following the WhatsApp markup: *bold*, _italic_,
+// ~strikethrough~ and ```code```. Styles can be nested (*_bold and italic_*) but nothing is
+// parsed inside a code block. Like WhatsApp, a marker only opens a style when it is not in
+// the middle of a word and it is followed by a non space character, and it only closes it
+// when it is preceded by a non space character, so "2*3*4" and "snake_case_name" stay plain.
+func ParseRichText(content string) []TextSpan {
+	spans := parseRichText([]rune(content), []string{}, []TextSpan{})
+
+	return mergeTextSpans(spans)
+}
+
+func parseRichText(text []rune, styles []string, spans []TextSpan) []TextSpan {
+	plain := []rune{}
+
+	flush := func() {
+		if len(plain) > 0 {
+			spans = append(spans, TextSpan{Text: string(plain), Styles: styles})
+			plain = []rune{}
+		}
+	}
+
+	for i := 0; i < len(text); i++ {
+		if hasRunePrefix(text[i:], codeFence) {
+			end := indexRunes(text, codeFence, i+len(codeFence))
+			if end > i+len(codeFence) {
+				flush()
+				spans = append(spans, TextSpan{
+					Text:   string(text[i+len(codeFence) : end]),
+					Styles: withStyle(styles, TEXT_STYLE_CODE),
+				})
+
+				// -1 because the loop increments it
+				i = end + len(codeFence) - 1
+				continue
+			}
+		}
+
+		style, isMarker := textStyleMarkers[text[i]]
+		if isMarker && !slices.Contains(styles, style) && canOpenStyle(text, i) {
+			end := findStyleEnd(text, i)
+			if end != -1 {
+				flush()
+				spans = parseRichText(text[i+1:end], withStyle(styles, style), spans)
+
+				i = end
+				continue
+			}
+		}
+
+		plain = append(plain, text[i])
+	}
+
+	flush()
+
+	return spans
+}
+
+func canOpenStyle(text []rune, i int) bool {
+	if i > 0 && isWordRune(text[i-1]) {
+		return false
+	}
+
+	return i+1 < len(text) && !unicode.IsSpace(text[i+1])
+}
+
+// findStyleEnd returns the position of the marker that closes the one at start, or -1.
+// Styles don't cross lines
+func findStyleEnd(text []rune, start int) int {
+	marker := text[start]
+
+	for j := start + 2; j < len(text); j++ {
+		if text[j] == '\n' {
+			return -1
+		}
+
+		if text[j] != marker || unicode.IsSpace(text[j-1]) {
+			continue
+		}
+
+		if j+1 == len(text) || !isWordRune(text[j+1]) {
+			return j
+		}
+	}
+
+	return -1
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func withStyle(styles []string, style string) []string {
+	result := make([]string, 0, len(styles)+1)
+	result = append(result, styles...)
+
+	return append(result, style)
+}
+
+func hasRunePrefix(text []rune, prefix string) bool {
+	return len(text) >= len(prefix) && string(text[:len(prefix)]) == prefix
+}
+
+// indexRunes returns the position of value in text starting from start, or -1
+func indexRunes(text []rune, value string, start int) int {
+	for i := start; i+len(value) <= len(text); i++ {
+		if hasRunePrefix(text[i:], value) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// mergeTextSpans joins the consecutive spans that have the same styles
+func mergeTextSpans(spans []TextSpan) []TextSpan {
+	merged := []TextSpan{}
+
+	for _, span := range spans {
+		last := len(merged) - 1
+		if last >= 0 && slices.Equal(merged[last].Styles, span.Styles) {
+			merged[last].Text += span.Text
+			continue
+		}
+
+		merged = append(merged, span)
+	}
+
+	return merged
+}
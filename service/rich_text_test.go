@@ -0,0 +1,111 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRichText(t *testing.T) {
+	plain := []string{}
+	bold := []string{TEXT_STYLE_BOLD}
+
+	tests := []struct {
+		name    string
+		content string
+		want    []TextSpan
+	}{
+		{
+			name:    "plain text",
+			content: "hello world",
+			want:    []TextSpan{{Text: "hello world", Styles: plain}},
+		},
+		{
+			name:    "every style",
+			content: "*bold* _italic_ ~strike~ ```a *b*```",
+			want: []TextSpan{
+				{Text: "bold", Styles: bold},
+				{Text: " ", Styles: plain},
+				{Text: "italic", Styles: []string{TEXT_STYLE_ITALIC}},
+				{Text: " ", Styles: plain},
+				{Text: "strike", Styles: []string{TEXT_STYLE_STRIKETHROUGH}},
+				{Text: " ", Styles: plain},
+				{Text: "a *b*", Styles: []string{TEXT_STYLE_CODE}},
+			},
+		},
+		{
+			name:    "nested styles",
+			content: "*very _important_*",
+			want: []TextSpan{
+				{Text: "very ", Styles: bold},
+				{Text: "important", Styles: []string{TEXT_STYLE_BOLD, TEXT_STYLE_ITALIC}},
+			},
+		},
+		{
+			name:    "markers inside words are not styles",
+			content: "2*3*4 snake_case_name",
+			want:    []TextSpan{{Text: "2*3*4 snake_case_name", Styles: plain}},
+		},
+		{
+			name:    "markers next to spaces are not styles",
+			content: "* not bold * and *not bold *",
+			want:    []TextSpan{{Text: "* not bold * and *not bold *", Styles: plain}},
+		},
+		{
+			name:    "unclosed marker",
+			content: "*hello",
+			want:    []TextSpan{{Text: "*hello", Styles: plain}},
+		},
+		{
+			name:    "styles don't cross lines",
+			content: "*one\ntwo*",
+			want:    []TextSpan{{Text: "*one\ntwo*", Styles: plain}},
+		},
+		{
+			name:    "punctuation around the markers",
+			content: "(*wow*)!",
+			want: []TextSpan{
+				{Text: "(", Styles: plain},
+				{Text: "wow", Styles: bold},
+				{Text: ")!", Styles: plain},
+			},
+		},
+		{
+			name:    "empty content",
+			content: "",
+			want:    []TextSpan{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseRichText(tt.content)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeMessageContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "keeps new lines and tabs", content: "a\r\nb\tc\rd", want: "a\nb\tc\nd"},
+		{name: "removes control characters", content: "bell\x07 null\x00 esc\x1b[31m", want: "bell null esc[31m"},
+		{name: "removes bidi overrides", content: "invoice\u202egnp.exe", want: "invoicegnp.exe"},
+		{name: "keeps emoji sequences", content: "👨‍👩‍👧", want: "👨‍👩‍👧"},
+		{name: "removes invalid utf-8", content: "ok\xff", want: "ok"},
+		{name: "trims spaces", content: "  \n hi \n ", want: "hi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SanitizeMessageContent(tt.content)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"time"
+)
+
+// MaxScheduleAhead is how far in the future a message can be scheduled
+const MaxScheduleAhead = 365 * 24 * time.Hour
+
+type ScheduledMessageService struct {
+	scheduledMessageRepository repository.ScheduledMessageRepository
+	messageRepository          repository.MessageRepository
+	participantRepository      repository.ParticipantRepository
+}
+
+func NewScheduledMessageService(
+	scheduledMessageRepository repository.ScheduledMessageRepository,
+	messageRepository repository.MessageRepository,
+	participantRepository repository.ParticipantRepository,
+) *ScheduledMessageService {
+	return &ScheduledMessageService{
+		scheduledMessageRepository: scheduledMessageRepository,
+		messageRepository:          messageRepository,
+		participantRepository:      participantRepository,
+	}
+}
+
+// ScheduleMessage stores the message to be sent by the MessageScheduler at sendAt. The content
+// is validated now, so the user knows about the problems before leaving the app
+func (s *ScheduledMessageService) ScheduleMessage(
+	ctx context.Context,
+	userID string,
+	conversationID string,
+	content string,
+	messageType string,
+	replyToMessageID *string,
+	sendAt time.Time,
+) (*db.ScheduledMessage, error) {
+	content, err := prepareMessageContent(content, messageType)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !sendAt.After(now) || sendAt.After(now.Add(MaxScheduleAhead)) {
+		return nil, customerrors.ErrInvalidScheduleTime
+	}
+
+	_, err = ensureParticipant(ctx, s.participantRepository, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ensureReplyTarget(ctx, s.messageRepository, s.participantRepository, userID, conversationID, replyToMessageID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.scheduledMessageRepository.CreateScheduledMessage(ctx, conversationID, userID, content, messageType, replyToMessageID, sendAt)
+}
+
+// GetScheduledMessages returns the messages of the user waiting to be sent, the next one first
+func (s *ScheduledMessageService) GetScheduledMessages(ctx context.Context, userID string, conversationID *string, limit int32, offset int32) (*[]db.ScheduledMessage, error) {
+	return s.scheduledMessageRepository.GetPendingScheduledMessages(ctx, userID, conversationID, limit, offset)
+}
+
+// CancelScheduledMessage cancels a pending message of the user. It returns
+// customerrors.ErrScheduledMessageNotPending when it's being sent or it was already sent
+func (s *ScheduledMessageService) CancelScheduledMessage(ctx context.Context, userID string, scheduledMessageID string) error {
+	cancelled, err := s.scheduledMessageRepository.CancelScheduledMessage(ctx, scheduledMessageID, userID)
+	if err != nil {
+		return err
+	}
+
+	if cancelled {
+		return nil
+	}
+
+	scheduled, err := s.scheduledMessageRepository.GetScheduledMessageByID(ctx, scheduledMessageID)
+	if err != nil {
+		return err
+	}
+
+	// the messages of other users don't exist for this user
+	if scheduled.SenderID.String() != userID {
+		return customerrors.ErrResourceNotFound
+	}
+
+	return customerrors.ErrScheduledMessageNotPending
+}
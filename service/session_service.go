@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// maxUserAgentLength keeps a huge user agent header out of the sessions table
+const maxUserAgentLength = 512
+
+// SessionService manages the sessions of the users, one is created on every login. Revoking a
+// session rejects its tokens and closes its websocket connections. The connections are kept in
+// memory, with many server instances each one only closes its own
+type SessionService struct {
+	sessionRepository repository.SessionRepository
+	logger            *zerolog.Logger
+
+	mutex sync.Mutex
+	// connections holds the function that closes every open websocket connection by session
+	connections map[string]map[*connection]struct{}
+}
+
+type connection struct {
+	close context.CancelFunc
+}
+
+func NewSessionService(
+	sessionRepository repository.SessionRepository,
+	logger *zerolog.Logger,
+) *SessionService {
+	return &SessionService{
+		sessionRepository: sessionRepository,
+		logger:            logger,
+		connections:       map[string]map[*connection]struct{}{},
+	}
+}
+
+func (s *SessionService) CreateSession(ctx context.Context, userID string, clientType string, userAgent string, ipAddress string) (*db.Session, error) {
+	if clientType != repository.CLIENT_TYPE_MOBILE {
+		clientType = repository.CLIENT_TYPE_WEB
+	}
+
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	return s.sessionRepository.CreateSession(ctx, userID, clientType, userAgent, ipAddress)
+}
+
+// ValidateSession returns customerrors.ErrSessionRevoked when the session doesn't exist, it was
+// revoked or it belongs to another user
+func (s *SessionService) ValidateSession(ctx context.Context, userID string, sessionID string) error {
+	session, err := s.sessionRepository.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrResourceNotFound) || errors.Is(err, customerrors.ErrInvalidUUIDValue) {
+			return customerrors.ErrSessionRevoked
+		}
+		return err
+	}
+
+	if session.RevokedAt.Valid || session.UserID.String() != userID {
+		return customerrors.ErrSessionRevoked
+	}
+
+	return nil
+}
+
+func (s *SessionService) GetUserSessions(ctx context.Context, userID string) (*[]db.Session, error) {
+	return s.sessionRepository.GetUserActiveSessions(ctx, userID)
+}
+
+// TouchSession updates when the session was last used, it's called when its tokens are refreshed
+func (s *SessionService) TouchSession(ctx context.Context, sessionID string) {
+	if err := s.sessionRepository.TouchSession(ctx, sessionID); err != nil {
+		s.logger.Error().Msgf("SessionService: error to update the last use of the session %s: %v", sessionID, err)
+	}
+}
+
+// RevokeSession revokes a session of the user, customerrors.ErrResourceNotFound is returned when
+// the session is not an active session of the user
+func (s *SessionService) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	if err := s.sessionRepository.RevokeUserSession(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	s.CloseConnections(sessionID)
+
+	return nil
+}
+
+// RevokeOtherSessions revokes every session of the user except the current one and returns how
+// many were revoked
+func (s *SessionService) RevokeOtherSessions(ctx context.Context, userID string, currentSessionID string) (int, error) {
+	sessionIDs, err := s.sessionRepository.RevokeOtherUserSessions(ctx, userID, currentSessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, sessionID := range sessionIDs {
+		s.CloseConnections(sessionID)
+	}
+
+	return len(sessionIDs), nil
+}
+
+// TrackConnection registers an open websocket connection of the session, close is called when
+// the session is revoked. The returned function must be called when the connection is closed
+func (s *SessionService) TrackConnection(sessionID string, close context.CancelFunc) (untrack func()) {
+	conn := &connection{close: close}
+
+	s.mutex.Lock()
+	if _, ok := s.connections[sessionID]; !ok {
+		s.connections[sessionID] = map[*connection]struct{}{}
+	}
+	s.connections[sessionID][conn] = struct{}{}
+	s.mutex.Unlock()
+
+	return func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		delete(s.connections[sessionID], conn)
+		if len(s.connections[sessionID]) == 0 {
+			delete(s.connections, sessionID)
+		}
+	}
+}
+
+// CloseConnections closes the open websocket connections of the session
+func (s *SessionService) CloseConnections(sessionID string) {
+	s.mutex.Lock()
+	conns := s.connections[sessionID]
+	delete(s.connections, sessionID)
+	s.mutex.Unlock()
+
+	for conn := range conns {
+		conn.close()
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+)
+
+type fakeSessionRepository struct {
+	repository.SessionRepository
+	sessions map[string]*db.Session
+}
+
+func (r *fakeSessionRepository) CreateSession(ctx context.Context, userID string, clientType string, userAgent string, ipAddress string) (*db.Session, error) {
+	session := &db.Session{
+		ID:         pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		UserID:     pgtype.UUID{Bytes: uuid.MustParse(userID), Valid: true},
+		ClientType: clientType,
+		UserAgent:  userAgent,
+		IpAddress:  ipAddress,
+	}
+	r.sessions[session.ID.String()] = session
+
+	return session, nil
+}
+
+func (r *fakeSessionRepository) GetSession(ctx context.Context, sessionID string) (*db.Session, error) {
+	session, ok := r.sessions[sessionID]
+	if !ok {
+		return nil, customerrors.ErrResourceNotFound
+	}
+
+	return session, nil
+}
+
+func (r *fakeSessionRepository) RevokeUserSession(ctx context.Context, userID string, sessionID string) error {
+	session, ok := r.sessions[sessionID]
+	if !ok || session.UserID.String() != userID || session.RevokedAt.Valid {
+		return customerrors.ErrResourceNotFound
+	}
+	session.RevokedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	return nil
+}
+
+func (r *fakeSessionRepository) RevokeOtherUserSessions(ctx context.Context, userID string, currentSessionID string) ([]string, error) {
+	revoked := []string{}
+	for sessionID, session := range r.sessions {
+		if session.UserID.String() == userID && sessionID != currentSessionID && !session.RevokedAt.Valid {
+			session.RevokedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+			revoked = append(revoked, sessionID)
+		}
+	}
+
+	return revoked, nil
+}
+
+func newSessionTestService() *SessionService {
+	logger := zerolog.Nop()
+	return NewSessionService(&fakeSessionRepository{sessions: map[string]*db.Session{}}, &logger)
+}
+
+func TestRevokeSessionClosesItsConnections(t *testing.T) {
+	sessionService := newSessionTestService()
+	ctx := context.Background()
+	userID := uuid.NewString()
+
+	session, _ := sessionService.CreateSession(ctx, userID, "desktop", "Firefox", "127.0.0.1")
+	sessionID := session.ID.String()
+
+	if session.ClientType != repository.CLIENT_TYPE_WEB {
+		t.Errorf("expected an unknown client type to be saved as web, got %s", session.ClientType)
+	}
+
+	if err := sessionService.ValidateSession(ctx, userID, sessionID); err != nil {
+		t.Fatalf("expected the session to be valid, got %v", err)
+	}
+
+	if err := sessionService.ValidateSession(ctx, uuid.NewString(), sessionID); !errors.Is(err, customerrors.ErrSessionRevoked) {
+		t.Errorf("expected the session of another user to be rejected, got %v", err)
+	}
+
+	connCtx, closeConnection := context.WithCancel(ctx)
+	sessionService.TrackConnection(sessionID, closeConnection)
+
+	closedCtx, closeClosed := context.WithCancel(ctx)
+	untrack := sessionService.TrackConnection(sessionID, closeClosed)
+	untrack()
+
+	if err := sessionService.RevokeSession(ctx, uuid.NewString(), sessionID); !errors.Is(err, customerrors.ErrResourceNotFound) {
+		t.Fatalf("expected only the owner to revoke the session, got %v", err)
+	}
+
+	if err := sessionService.RevokeSession(ctx, userID, sessionID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if connCtx.Err() == nil {
+		t.Error("expected the connection of the session to be closed")
+	}
+
+	if closedCtx.Err() != nil {
+		t.Error("expected the untracked connection to be left alone")
+	}
+
+	if err := sessionService.ValidateSession(ctx, userID, sessionID); !errors.Is(err, customerrors.ErrSessionRevoked) {
+		t.Errorf("expected the revoked session to be rejected, got %v", err)
+	}
+}
+
+func TestRevokeOtherSessionsKeepsTheCurrentOne(t *testing.T) {
+	sessionService := newSessionTestService()
+	ctx := context.Background()
+	userID := uuid.NewString()
+
+	current, _ := sessionService.CreateSession(ctx, userID, repository.CLIENT_TYPE_WEB, "Firefox", "127.0.0.1")
+	other, _ := sessionService.CreateSession(ctx, userID, repository.CLIENT_TYPE_MOBILE, "okhttp", "10.0.0.2")
+	otherUser, _ := sessionService.CreateSession(ctx, uuid.NewString(), repository.CLIENT_TYPE_WEB, "Chrome", "10.0.0.3")
+
+	currentCtx, closeCurrent := context.WithCancel(ctx)
+	sessionService.TrackConnection(current.ID.String(), closeCurrent)
+	otherCtx, closeOther := context.WithCancel(ctx)
+	sessionService.TrackConnection(other.ID.String(), closeOther)
+
+	revokedCount, err := sessionService.RevokeOtherSessions(ctx, userID, current.ID.String())
+	if err != nil || revokedCount != 1 {
+		t.Fatalf("expected 1 revoked session, got %d (%v)", revokedCount, err)
+	}
+
+	if currentCtx.Err() != nil || otherCtx.Err() == nil {
+		t.Error("expected only the connection of the other session to be closed")
+	}
+
+	if err := sessionService.ValidateSession(ctx, userID, current.ID.String()); err != nil {
+		t.Errorf("expected the current session to stay valid, got %v", err)
+	}
+
+	if err := sessionService.ValidateSession(ctx, otherUser.UserID.String(), otherUser.ID.String()); err != nil {
+		t.Errorf("expected the sessions of other users to stay valid, got %v", err)
+	}
+}
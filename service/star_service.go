@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	"golang-whatsapp-clone/repository"
+)
+
+type StarService struct {
+	starredMessageRepository repository.StarredMessageRepository
+	messageRepository        repository.MessageRepository
+	participantRepository    repository.ParticipantRepository
+}
+
+func NewStarService(
+	starredMessageRepository repository.StarredMessageRepository,
+	messageRepository repository.MessageRepository,
+	participantRepository repository.ParticipantRepository,
+) *StarService {
+	return &StarService{
+		starredMessageRepository: starredMessageRepository,
+		messageRepository:        messageRepository,
+		participantRepository:    participantRepository,
+	}
+}
+
+// StarMessage saves the message in the starred list of the user. Starring it twice does nothing
+func (s *StarService) StarMessage(ctx context.Context, userID string, messageID string) error {
+	_, _, err := getReadableMessage(ctx, s.messageRepository, s.participantRepository, userID, messageID)
+	if err != nil {
+		return err
+	}
+
+	return s.starredMessageRepository.StarMessage(ctx, userID, messageID)
+}
+
+// UnstarMessage doesn't check the message access because the user is only removing its own data
+func (s *StarService) UnstarMessage(ctx context.Context, userID string, messageID string) error {
+	_, err := s.starredMessageRepository.UnstarMessage(ctx, userID, messageID)
+
+	return err
+}
+
+func (s *StarService) GetStarredMessages(ctx context.Context, userID string, limit int32, offset int32) (*[]db.GetStarredMessagesRow, error) {
+	return s.starredMessageRepository.GetStarredMessages(ctx, userID, limit, offset)
+}
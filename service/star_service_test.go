@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeStarredMessageRepository stores the starred message ids of a single user
+type fakeStarredMessageRepository struct {
+	repository.StarredMessageRepository
+	starred map[string]bool
+}
+
+func (r *fakeStarredMessageRepository) StarMessage(ctx context.Context, userID string, messageID string) error {
+	r.starred[messageID] = true
+
+	return nil
+}
+
+func (r *fakeStarredMessageRepository) UnstarMessage(ctx context.Context, userID string, messageID string) (bool, error) {
+	starred := r.starred[messageID]
+	delete(r.starred, messageID)
+
+	return starred, nil
+}
+
+func TestStarMessage(t *testing.T) {
+	conversationID := uuid.NewString()
+	foreignConversationID := uuid.NewString()
+
+	messageRepository := &fakePinMessageRepository{messages: map[string]*db.Message{}}
+	newMessage := func(conversationID string, deleted bool) string {
+		message := &db.Message{
+			ID:             pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			ConversationID: pgtype.UUID{Bytes: uuid.MustParse(conversationID), Valid: true},
+			IsDeleted:      pgtype.Bool{Bool: deleted, Valid: true},
+		}
+		messageRepository.messages[message.ID.String()] = message
+
+		return message.ID.String()
+	}
+	participantRepository := &fakeMembershipParticipantRepository{conversationIDs: []string{conversationID}}
+
+	tests := []struct {
+		name      string
+		messageID string
+		expected  error
+	}{
+		{name: "readable message", messageID: newMessage(conversationID, false), expected: nil},
+		{name: "deleted message", messageID: newMessage(conversationID, true), expected: customerrors.ErrResourceNotFound},
+		{name: "conversation of other users", messageID: newMessage(foreignConversationID, false), expected: customerrors.ErrForbidden},
+		{name: "unknown message", messageID: uuid.NewString(), expected: customerrors.ErrResourceNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			starredMessageRepository := &fakeStarredMessageRepository{starred: map[string]bool{}}
+			starService := NewStarService(starredMessageRepository, messageRepository, participantRepository)
+
+			err := starService.StarMessage(context.Background(), "user-id", test.messageID)
+			if !errors.Is(err, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, err)
+			}
+
+			if starredMessageRepository.starred[test.messageID] != (test.expected == nil) {
+				t.Errorf("expected the message to be starred: %v", test.expected == nil)
+			}
+		})
+	}
+}
+
+func TestUnstarMessageWithoutAccess(t *testing.T) {
+	messageID := uuid.NewString()
+	starredMessageRepository := &fakeStarredMessageRepository{starred: map[string]bool{messageID: true}}
+	// the user left the conversation and the message doesn't exist anymore, it can still be unstarred
+	starService := NewStarService(starredMessageRepository, &fakePinMessageRepository{}, &fakeMembershipParticipantRepository{})
+
+	if err := starService.UnstarMessage(context.Background(), "user-id", messageID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if starredMessageRepository.starred[messageID] {
+		t.Errorf("expected the message to be unstarred")
+	}
+
+	// unstarring it again does nothing
+	if err := starService.UnstarMessage(context.Background(), "user-id", messageID); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	db "golang-whatsapp-clone/database/gen"
+	customerrors "golang-whatsapp-clone/errors"
+	"golang-whatsapp-clone/repository"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MaxProfileNameLength = 100
+	MaxAboutLength       = 500
+	// the length of the avatar_url column
+	MaxAvatarURLLength = 255
+)
+
+type UserService struct {
+	userRepository repository.UserRepository
+}
+
+func NewUserService(userRepository repository.UserRepository) *UserService {
+	return &UserService{
+		userRepository: userRepository,
+	}
+}
+
+// GetUsersByIDs returns the users grouped by id, the ids that don't exist are not included
+func (s *UserService) GetUsersByIDs(ctx context.Context, userIDs []string) (map[string]db.User, error) {
+	result := make(map[string]db.User, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	users, err := s.userRepository.GetUsersByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range *users {
+		result[user.ID.String()] = user
+	}
+
+	return result, nil
+}
+
+func (s *UserService) GetUserByID(ctx context.Context, userID string) (*db.User, error) {
+	users, err := s.userRepository.GetUsersByIDs(ctx, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*users) == 0 {
+		return nil, customerrors.ErrResourceNotFound
+	}
+
+	return &(*users)[0], nil
+}
+
+// UpdateProfile changes the fields of the profile of the user that are set, the name and the
+// avatar edited here are kept on the next logins with a provider. The name can't be removed,
+// the about and the avatar are removed with an empty value. The name and the about are
+// sanitized like the messages, the name is also kept in a single line
+func (s *UserService) UpdateProfile(ctx context.Context, userID string, update repository.ProfileUpdate) (*db.User, error) {
+	if update.Name != nil {
+		name := strings.Join(strings.Fields(SanitizeMessageContent(*update.Name)), " ")
+		if name == "" || utf8.RuneCountInString(name) > MaxProfileNameLength {
+			return nil, customerrors.ErrInvalidProfileName
+		}
+		update.Name = &name
+	}
+
+	if update.About != nil {
+		about := SanitizeMessageContent(*update.About)
+		if utf8.RuneCountInString(about) > MaxAboutLength {
+			return nil, customerrors.ErrInvalidAbout
+		}
+		update.About = &about
+	}
+
+	if update.AvatarURL != nil {
+		avatarURL := strings.TrimSpace(*update.AvatarURL)
+		if avatarURL != "" && !isAvatarURL(avatarURL) {
+			return nil, customerrors.ErrInvalidAvatarURL
+		}
+		update.AvatarURL = &avatarURL
+	}
+
+	return s.userRepository.UpdateProfile(ctx, userID, update)
+}
+
+// GetContactIDs returns the users that see the profile changes of the user
+func (s *UserService) GetContactIDs(ctx context.Context, userID string) ([]string, error) {
+	return s.userRepository.GetContactIDs(ctx, userID)
+}
+
+// isAvatarURL only accepts the absolute http urls, the other schemes like javascript: or data:
+// must not reach the clients
+func isAvatarURL(value string) bool {
+	if len(value) > MaxAvatarURLLength {
+		return false
+	}
+
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != "" && u.User == nil
+}
@@ -23,6 +23,27 @@ type SubscriptionManager struct {
 	//            NOT one channel per user!
 	messageSubscribers map[string][]chan *model.MessageAddedEvent
 
+	// conversationID -> channels listening to reactions added/removed in that conversation
+	reactionSubscribers *topic[*model.MessageReactionEvent]
+
+	// userID -> channels of the user's devices listening to the mentions of that user
+	mentionSubscribers *topic[*model.MentionEvent]
+
+	// conversationID -> channels waiting for the link previews of the messages of that conversation
+	linkPreviewSubscribers *topic[*model.MessageLinkPreviewEvent]
+
+	// conversationID -> channels notified when disappearing messages of that conversation are removed
+	expiredMessageSubscribers *topic[*model.MessagesExpiredEvent]
+
+	// userID -> channels of the user's devices, to keep the drafts in sync between them
+	draftSubscribers *topic[*model.DraftUpdatedEvent]
+
+	// userID -> channels of the user's devices listening to the presence of the user's contacts
+	presenceSubscribers *topic[*model.UserPresence]
+
+	// userID -> channels of the user's devices listening to the profile changes of the user and its contacts
+	profileSubscribers *topic[*model.User]
+
 	// Mutex to protect concurrent access to the subscribers map
 	mutex sync.RWMutex
 }
@@ -30,7 +51,14 @@ type SubscriptionManager struct {
 // NewSubscriptionManager creates a new subscription manager
 func NewSubscriptionManager() *SubscriptionManager {
 	return &SubscriptionManager{
-		messageSubscribers: make(map[string][]chan *model.MessageAddedEvent),
+		messageSubscribers:        make(map[string][]chan *model.MessageAddedEvent),
+		reactionSubscribers:       newTopic[*model.MessageReactionEvent](),
+		mentionSubscribers:        newTopic[*model.MentionEvent](),
+		linkPreviewSubscribers:    newTopic[*model.MessageLinkPreviewEvent](),
+		expiredMessageSubscribers: newTopic[*model.MessagesExpiredEvent](),
+		draftSubscribers:          newTopic[*model.DraftUpdatedEvent](),
+		presenceSubscribers:       newTopic[*model.UserPresence](),
+		profileSubscribers:        newTopic[*model.User](),
 	}
 }
 
@@ -173,3 +201,129 @@ func (sm *SubscriptionManager) GetTotalSubscribers() int {
 
 	return total
 }
+
+// SubscribeToReactions creates a subscription for the reactions added/removed in a conversation
+func (sm *SubscriptionManager) SubscribeToReactions(conversationID string) <-chan *model.MessageReactionEvent {
+	return sm.reactionSubscribers.subscribe(conversationID)
+}
+
+// BroadcastReaction sends the reaction event to all the subscribers of the conversation
+func (sm *SubscriptionManager) BroadcastReaction(conversationID string, event *model.MessageReactionEvent) {
+	delivered := sm.reactionSubscribers.broadcast(conversationID, event)
+
+	fmt.Printf("Reaction event delivered to %d subscribers of conversation %s\n", delivered, conversationID)
+}
+
+func (sm *SubscriptionManager) UnsubscribeFromReactions(conversationID string, ch <-chan *model.MessageReactionEvent) {
+	if !sm.reactionSubscribers.unsubscribe(conversationID, ch) {
+		fmt.Printf("Warning: reaction channel not found for conversation %s during unsubscribe\n", conversationID)
+	}
+}
+
+// SubscribeToMentions creates a subscription for the mentions of the user in any conversation
+func (sm *SubscriptionManager) SubscribeToMentions(userID string) <-chan *model.MentionEvent {
+	return sm.mentionSubscribers.subscribe(userID)
+}
+
+// BroadcastMention sends the mention event to all the devices of the mentioned user
+func (sm *SubscriptionManager) BroadcastMention(userID string, event *model.MentionEvent) {
+	delivered := sm.mentionSubscribers.broadcast(userID, event)
+
+	fmt.Printf("Mention event delivered to %d devices of user %s\n", delivered, userID)
+}
+
+func (sm *SubscriptionManager) UnsubscribeFromMentions(userID string, ch <-chan *model.MentionEvent) {
+	if !sm.mentionSubscribers.unsubscribe(userID, ch) {
+		fmt.Printf("Warning: mention channel not found for user %s during unsubscribe\n", userID)
+	}
+}
+
+// SubscribeToLinkPreviews creates a subscription for the link previews generated in a conversation
+func (sm *SubscriptionManager) SubscribeToLinkPreviews(conversationID string) <-chan *model.MessageLinkPreviewEvent {
+	return sm.linkPreviewSubscribers.subscribe(conversationID)
+}
+
+// BroadcastLinkPreview sends the preview to all subscribers of the conversation
+func (sm *SubscriptionManager) BroadcastLinkPreview(conversationID string, event *model.MessageLinkPreviewEvent) {
+	delivered := sm.linkPreviewSubscribers.broadcast(conversationID, event)
+
+	fmt.Printf("Link preview of message %s delivered to %d subscribers of conversation %s\n", event.MessageID, delivered, conversationID)
+}
+
+func (sm *SubscriptionManager) UnsubscribeFromLinkPreviews(conversationID string, ch <-chan *model.MessageLinkPreviewEvent) {
+	if !sm.linkPreviewSubscribers.unsubscribe(conversationID, ch) {
+		fmt.Printf("Warning: link preview channel not found for conversation %s during unsubscribe\n", conversationID)
+	}
+}
+
+// SubscribeToExpiredMessages creates a subscription for the disappearing messages removed from a conversation
+func (sm *SubscriptionManager) SubscribeToExpiredMessages(conversationID string) <-chan *model.MessagesExpiredEvent {
+	return sm.expiredMessageSubscribers.subscribe(conversationID)
+}
+
+// BroadcastExpiredMessages tells all subscribers of the conversation which messages disappeared
+func (sm *SubscriptionManager) BroadcastExpiredMessages(conversationID string, event *model.MessagesExpiredEvent) {
+	delivered := sm.expiredMessageSubscribers.broadcast(conversationID, event)
+
+	fmt.Printf("%d expired messages of conversation %s notified to %d subscribers\n", len(event.MessageIds), conversationID, delivered)
+}
+
+func (sm *SubscriptionManager) UnsubscribeFromExpiredMessages(conversationID string, ch <-chan *model.MessagesExpiredEvent) {
+	if !sm.expiredMessageSubscribers.unsubscribe(conversationID, ch) {
+		fmt.Printf("Warning: expired messages channel not found for conversation %s during unsubscribe\n", conversationID)
+	}
+}
+
+// SubscribeToDrafts creates a subscription for the draft changes of the user in any conversation
+func (sm *SubscriptionManager) SubscribeToDrafts(userID string) <-chan *model.DraftUpdatedEvent {
+	return sm.draftSubscribers.subscribe(userID)
+}
+
+// BroadcastDraft sends the draft change to all the devices of the user
+func (sm *SubscriptionManager) BroadcastDraft(userID string, event *model.DraftUpdatedEvent) {
+	delivered := sm.draftSubscribers.broadcast(userID, event)
+
+	fmt.Printf("Draft of conversation %s delivered to %d devices of user %s\n", event.ConversationID, delivered, userID)
+}
+
+func (sm *SubscriptionManager) UnsubscribeFromDrafts(userID string, ch <-chan *model.DraftUpdatedEvent) {
+	if !sm.draftSubscribers.unsubscribe(userID, ch) {
+		fmt.Printf("Warning: draft channel not found for user %s during unsubscribe\n", userID)
+	}
+}
+
+// SubscribeToContactsPresence creates a subscription for the presence changes of the user's contacts
+func (sm *SubscriptionManager) SubscribeToContactsPresence(userID string) <-chan *model.UserPresence {
+	return sm.presenceSubscribers.subscribe(userID)
+}
+
+// BroadcastPresence sends the presence of a contact to all the devices of the user
+func (sm *SubscriptionManager) BroadcastPresence(userID string, event *model.UserPresence) {
+	delivered := sm.presenceSubscribers.broadcast(userID, event)
+
+	fmt.Printf("Presence of user %s delivered to %d devices of user %s\n", event.UserID, delivered, userID)
+}
+
+func (sm *SubscriptionManager) UnsubscribeFromContactsPresence(userID string, ch <-chan *model.UserPresence) {
+	if !sm.presenceSubscribers.unsubscribe(userID, ch) {
+		fmt.Printf("Warning: presence channel not found for user %s during unsubscribe\n", userID)
+	}
+}
+
+// SubscribeToProfiles creates a subscription for the profile changes of the user and its contacts
+func (sm *SubscriptionManager) SubscribeToProfiles(userID string) <-chan *model.User {
+	return sm.profileSubscribers.subscribe(userID)
+}
+
+// BroadcastProfile sends the profile of a user to all the devices of the user
+func (sm *SubscriptionManager) BroadcastProfile(userID string, event *model.User) {
+	delivered := sm.profileSubscribers.broadcast(userID, event)
+
+	fmt.Printf("Profile of user %s delivered to %d devices of user %s\n", event.ID, delivered, userID)
+}
+
+func (sm *SubscriptionManager) UnsubscribeFromProfiles(userID string, ch <-chan *model.User) {
+	if !sm.profileSubscribers.unsubscribe(userID, ch) {
+		fmt.Printf("Warning: profile channel not found for user %s during unsubscribe\n", userID)
+	}
+}
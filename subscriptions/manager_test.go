@@ -123,3 +123,40 @@ func TestOneONONeChatScenario(t *testing.T) {
 
 	fmt.Println(">>>> ✅ 1-on-1 Chat test completed! ===")
 }
+
+func TestReactionSubscriptionIsScopedToConversation(t *testing.T) {
+	sm := NewSubscriptionManager()
+
+	conversationChan := sm.SubscribeToReactions("conv-789")
+	otherConversationChan := sm.SubscribeToReactions("conv-other")
+
+	emoji := "👍"
+	sm.BroadcastReaction("conv-789", &model.MessageReactionEvent{
+		ConversationID: "conv-789",
+		MessageID:      "msg-003",
+		UserID:         "user-a",
+		Emoji:          &emoji,
+		Timestamp:      time.Now(),
+	})
+
+	select {
+	case event := <-conversationChan:
+		if event.Emoji == nil || *event.Emoji != emoji {
+			t.Errorf("Expected emoji %s, got %v", emoji, event.Emoji)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Timeout: reaction was not received within 2 seconds")
+	}
+
+	select {
+	case event := <-otherConversationChan:
+		t.Errorf("Reaction leaked to another conversation: %+v", event)
+	default:
+	}
+
+	sm.UnsubscribeFromReactions("conv-789", conversationChan)
+
+	if _, open := <-conversationChan; open {
+		t.Error("Expected the reaction channel to be closed after unsubscribe")
+	}
+}
@@ -0,0 +1,77 @@
+package subscriptions
+
+import "sync"
+
+// topic keeps the channels listening to a kind of event, grouped by a key
+// (a conversation id, a user id, etc). It works exactly like the messageSubscribers
+// map of the SubscriptionManager: each channel is one client connection, NOT one user.
+type topic[T any] struct {
+	subscribers map[string][]chan T
+	mutex       sync.RWMutex
+}
+
+func newTopic[T any]() *topic[T] {
+	return &topic[T]{
+		subscribers: make(map[string][]chan T),
+	}
+}
+
+// subscribe returns a buffered channel that will receive the events broadcast to the key
+func (t *topic[T]) subscribe(key string) <-chan T {
+	ch := make(chan T, 10)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.subscribers[key] = append(t.subscribers[key], ch)
+
+	return ch
+}
+
+// broadcast sends the event to every subscriber of the key and returns how many received it.
+// Subscribers with a full buffer are skipped so a slow client can't block the others
+func (t *topic[T]) broadcast(key string, event T) int {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	delivered := 0
+	for _, ch := range t.subscribers[key] {
+		select {
+		case ch <- event:
+			delivered++
+		default:
+		}
+	}
+
+	return delivered
+}
+
+// unsubscribe closes the channel and removes it from the key. Returns false when it was not found
+func (t *topic[T]) unsubscribe(key string, ch <-chan T) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	subscribers := t.subscribers[key]
+
+	for i, subscriber := range subscribers {
+		if subscriber == ch {
+			close(subscriber)
+
+			t.subscribers[key] = append(subscribers[:i], subscribers[i+1:]...)
+			if len(t.subscribers[key]) == 0 {
+				delete(t.subscribers, key)
+			}
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *topic[T]) count(key string) int {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return len(t.subscribers[key])
+}